@@ -0,0 +1,281 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/sandstormhdr"
+)
+
+// groupACLStream is the name of the meta-stream that stores group ACLs.
+const groupACLStream = "GOSTPASS_GROUP_ACL"
+
+// A groupACL restricts access to a group and its descendants to a set of
+// users, identified by their Sandstorm user IDs.  The ACL of the nearest
+// group (starting from the group itself) applies; groups without an ACL
+// in their ancestry are accessible to everyone.
+type groupACL struct {
+	Owner   string   `json:"owner"`
+	Readers []string `json:"readers,omitempty"`
+	Editors []string `json:"editors,omitempty"`
+}
+
+func (acl *groupACL) canRead(user string) bool {
+	return acl.canWrite(user) || containsString(acl.Readers, user)
+}
+
+func (acl *groupACL) canWrite(user string) bool {
+	return user != "" && (user == acl.Owner || containsString(acl.Editors, user))
+}
+
+// readGroupACLs returns the database's group ACLs, keyed by group ID.
+func readGroupACLs(db *keepass.Database) (map[uint32]*groupACL, error) {
+	acls := make(map[uint32]*groupACL)
+	data := db.MetaStream(groupACLStream)
+	if data == nil {
+		return acls, nil
+	}
+	if err := json.Unmarshal(data, &acls); err != nil {
+		return nil, fmt.Errorf("read group ACLs: %v", err)
+	}
+	return acls, nil
+}
+
+// writeGroupACLs replaces the database's group ACLs.
+func writeGroupACLs(db *keepass.Database, acls map[uint32]*groupACL) error {
+	if len(acls) == 0 {
+		return db.SetMetaStream(groupACLStream, nil)
+	}
+	data, err := json.Marshal(acls)
+	if err != nil {
+		return fmt.Errorf("write group ACLs: %v", err)
+	}
+	return db.SetMetaStream(groupACLStream, data)
+}
+
+// dropGroupACLs removes the ACLs of the groups with the given IDs.  Group
+// IDs are reused, so an ACL must go with its group, or a group created
+// later would inherit it.
+func dropGroupACLs(db *keepass.Database, ids []uint32) error {
+	acls, err := readGroupACLs(db)
+	if err != nil {
+		return err
+	}
+	n := len(acls)
+	for _, id := range ids {
+		delete(acls, id)
+	}
+	if len(acls) == n {
+		return nil
+	}
+	return writeGroupACLs(db, acls)
+}
+
+// subtreeGroupIDs returns the IDs of g and all of its descendants.
+func subtreeGroupIDs(g *keepass.Group) []uint32 {
+	ids := []uint32{g.ID}
+	for _, sub := range g.Groups() {
+		ids = append(ids, subtreeGroupIDs(sub)...)
+	}
+	return ids
+}
+
+// effectiveACL returns the ACL that applies to g or nil if g is unrestricted.
+func effectiveACL(acls map[uint32]*groupACL, g *keepass.Group) *groupACL {
+	for ; g != nil && !g.IsRoot(); g = g.Parent() {
+		if acl := acls[g.ID]; acl != nil {
+			return acl
+		}
+	}
+	return nil
+}
+
+// requestUserID returns the Sandstorm user ID of the request's user or
+// the empty string for anonymous users.
+func requestUserID(r *http.Request) string {
+	u := sandstormhdr.GetUser(r.Header)
+	if u == nil {
+		return ""
+	}
+	return u.ID
+}
+
+// checkGroupAccess returns an error if the request's user may not read
+// (or write, if write is true) the contents of g.
+func checkGroupAccess(r *http.Request, db *keepass.Database, g *keepass.Group, write bool) error {
+	if !*checkPermissions || g == nil {
+		return nil
+	}
+	acls, err := readGroupACLs(db)
+	if err != nil {
+		return err
+	}
+	acl := effectiveACL(acls, g)
+	if acl == nil {
+		return nil
+	}
+	user := requestUserID(r)
+	if write && !acl.canWrite(user) || !write && !acl.canRead(user) {
+		return permissionError{fmt.Sprintf("user %q denied access to group %d", user, g.ID)}
+	}
+	return nil
+}
+
+// filterReadable returns the entries that the request's user may read.
+func filterReadable(r *http.Request, db *keepass.Database, entries []*keepass.Entry) ([]*keepass.Entry, error) {
+	if !*checkPermissions {
+		return entries, nil
+	}
+	acls, err := readGroupACLs(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(acls) == 0 {
+		return entries, nil
+	}
+	user := requestUserID(r)
+	var readable []*keepass.Entry
+	for _, e := range entries {
+		if acl := effectiveACL(acls, e.Parent()); acl == nil || acl.canRead(user) {
+			readable = append(readable, e)
+		}
+	}
+	return readable, nil
+}
+
+// removeUnreadable removes the entries that the request's user may not
+// read from db.
+func removeUnreadable(r *http.Request, db *keepass.Database) error {
+	entries := db.Entries()
+	readable, err := filterReadable(r, db, entries)
+	if err != nil {
+		return err
+	}
+	if len(readable) == len(entries) {
+		return nil
+	}
+	for _, e := range entries {
+		if len(readable) > 0 && readable[0] == e {
+			readable = readable[1:]
+			continue
+		}
+		if err := e.Parent().RemoveEntry(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// viewGroupACL sends the ACL that applies to a group as JSON.
+func viewGroupACL(w http.ResponseWriter, r *http.Request) error {
+	mu.Lock()
+	defer mu.Unlock()
+	db, err := sessions.dbFromRequest(w, r)
+	if err != nil {
+		return err
+	}
+	g, err := requestGroup(db, mux.Vars(r))
+	if err != nil {
+		return err
+	}
+	if err := checkGroupAccess(r, db, g, false); err != nil {
+		return err
+	}
+	acls, err := readGroupACLs(db)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(effectiveACL(acls, g))
+}
+
+// postGroupACL sets or clears a group's ACL.  Only the owner of the
+// currently applicable ACL may change it.  The "readers" and "editors"
+// form values are comma-separated lists of user IDs.  If no owner is
+// given, the requesting user becomes the owner.
+func postGroupACL(w http.ResponseWriter, r *http.Request) error {
+	now := time.Now()
+	mu.Lock()
+	defer mu.Unlock()
+	var g *keepass.Group
+	err := transaction(w, r, func(db *keepass.Database) error {
+		var err error
+		g, err = requestGroup(db, mux.Vars(r))
+		if err != nil {
+			return err
+		}
+		acls, err := readGroupACLs(db)
+		if err != nil {
+			return err
+		}
+		user := requestUserID(r)
+		if acl := effectiveACL(acls, g); *checkPermissions && acl != nil && acl.Owner != user {
+			return permissionError{fmt.Sprintf("user %q is not the owner of group %d", user, g.ID)}
+		}
+		if r.FormValue("clear") != "" {
+			delete(acls, g.ID)
+		} else {
+			acl := &groupACL{
+				Owner:   r.FormValue("owner"),
+				Readers: splitList(r.FormValue("readers")),
+				Editors: splitList(r.FormValue("editors")),
+			}
+			if acl.Owner == "" {
+				acl.Owner = user
+			}
+			if acl.Owner == "" {
+				return userError{
+					msg: "An ACL must have an owner.",
+					err: fmt.Errorf("group %d ACL: no owner", g.ID),
+				}
+			}
+			acls[g.ID] = acl
+		}
+		g.LastModificationTime = now
+		return writeGroupACLs(db, acls)
+	})
+	if err != nil {
+		return err
+	}
+	return redirectRoute(w, r, "viewGroup", "gid", strconv.FormatUint(uint64(g.ID), 10))
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestCheckGroupAccess(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := db.Root().NewSubgroup()
+	sub := shared.NewSubgroup()
+	open := db.Root().NewSubgroup()
+	err = writeGroupACLs(db, map[uint32]*groupACL{
+		shared.ID: {Owner: "alice", Readers: []string{"bob"}, Editors: []string{"carol"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		user  string
+		group *keepass.Group
+		write bool
+		ok    bool
+	}{
+		{user: "alice", group: shared, write: true, ok: true},
+		{user: "bob", group: shared, write: false, ok: true},
+		{user: "bob", group: shared, write: true, ok: false},
+		{user: "carol", group: sub, write: true, ok: true},
+		{user: "mallory", group: sub, write: false, ok: false},
+		{user: "", group: shared, write: false, ok: false},
+		{user: "mallory", group: open, write: true, ok: true},
+	}
+	for _, test := range tests {
+		r := &http.Request{Header: make(http.Header)}
+		if test.user != "" {
+			r.Header.Set("X-Sandstorm-User-Id", test.user)
+		}
+		err := checkGroupAccess(r, db, test.group, test.write)
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("checkGroupAccess(user=%q, group=%d, write=%t) = %v; want ok=%t", test.user, test.group.ID, test.write, err, test.ok)
+		}
+	}
+}
+
+func TestRmDropsGroupACLs(t *testing.T) {
+	_, cleanup := newCommandTestDB(t, `{"key_rounds": 1, "groups": ["Team/Ops/Oncall", "Team/Dev"]}`)
+	defer cleanup()
+	db, err := openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, oncall, dev := db.FindGroupPath("Team/Ops"), db.FindGroupPath("Team/Ops/Oncall"), db.FindGroupPath("Team/Dev")
+	err = writeGroupACLs(db, map[uint32]*groupACL{
+		ops.ID:    {Owner: "alice"},
+		oncall.ID: {Owner: "bob"},
+		dev.ID:    {Owner: "carol"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeDatabase(db); err != nil {
+		t.Fatal(err)
+	}
+	freed := []uint32{ops.ID, oncall.ID}
+
+	if err := runRm([]string{"-r", "Team/Ops"}); err != nil {
+		t.Fatal("rm:", err)
+	}
+	db, err = openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acls, err := readGroupACLs(db); err != nil || len(acls) != 3 {
+		t.Errorf("ACLs after recycling = %v, %v; want all 3 kept", acls, err)
+	}
+	if err := runRm([]string{"-r", keepass.RecycleBinName + "/Ops"}); err != nil {
+		t.Fatal("rm from recycle bin:", err)
+	}
+	if err := runMkdir([]string{"New/Sub"}); err != nil {
+		t.Fatal("mkdir:", err)
+	}
+
+	db, err = openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	acls, err := readGroupACLs(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range freed {
+		if acls[id] != nil {
+			t.Errorf("ACL of deleted group %d kept: %+v", id, acls[id])
+		}
+	}
+	if acl := acls[dev.ID]; acl == nil || acl.Owner != "carol" {
+		t.Errorf("ACL of Team/Dev = %+v; want owner carol", acl)
+	}
+	for _, path := range []string{"New", "New/Sub"} {
+		if acl := effectiveACL(acls, db.FindGroupPath(path)); acl != nil {
+			t.Errorf("new group %s inherited ACL %+v", path, acl)
+		}
+	}
+}
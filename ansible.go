@@ -0,0 +1,133 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// ansibleVaultGroup holds Ansible Vault passwords, one entry per vault ID,
+// titled with the ID.
+const ansibleVaultGroup = "Ansible Vault"
+
+// runAnsibleVaultClient prints an Ansible Vault password, following the
+// protocol of vault password client scripts.  Ansible only runs such a
+// script if its name ends in "-client", so use a wrapper like:
+//
+//	#!/bin/sh
+//	exec gostpass -db ~/vault.kdb -password_file ~/.vault-master ansible-vault-client "$@"
+//
+// saved as gostpass-client and pass --vault-id prod@gostpass-client.
+func runAnsibleVaultClient(args []string) error {
+	fs := flag.NewFlagSet("ansible-vault-client", flag.ContinueOnError)
+	vaultID := fs.String("vault-id", "default", "vault `ID` whose password to print")
+	entry := fs.String("entry", "", "`path` of the entry to use instead of \""+ansibleVaultGroup+"/ID\"")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: ansible-vault-client [-vault-id id | -entry path]")
+	}
+	path := *entry
+	if path == "" {
+		path = ansibleVaultGroup + "/" + *vaultID
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	e, err := findEntryPath(db, path)
+	if err != nil {
+		return err
+	}
+	if err := releaseEntry(e, "ansible-vault-client"); err != nil {
+		return err
+	}
+	_, err = fmt.Println(e.Password)
+	return err
+}
+
+// lookupEntries returns the named fields of the entries at paths, or all
+// fields if none are named.  A single named field gives a list of strings
+// instead of a list of objects.
+func lookupEntries(db *keepass.Database, paths, fields []string) (interface{}, error) {
+	for _, f := range fields {
+		if entryFields[f] == nil {
+			return nil, fmt.Errorf("unknown field %q; want one of %s", f, strings.Join(sortedEntryFields(), ", "))
+		}
+	}
+	if len(fields) == 0 {
+		fields = sortedEntryFields()
+	}
+	var objs []map[string]string
+	var values []string
+	for _, path := range paths {
+		e, err := findEntryPath(db, path)
+		if err != nil {
+			return nil, err
+		}
+		if err := releaseEntry(e, "lookup"); err != nil {
+			return nil, err
+		}
+		if len(fields) == 1 {
+			values = append(values, entryFields[fields[0]](e))
+			continue
+		}
+		obj := make(map[string]string, len(fields))
+		for _, f := range fields {
+			obj[f] = entryFields[f](e)
+		}
+		objs = append(objs, obj)
+	}
+	if len(fields) == 1 {
+		return values, nil
+	}
+	return objs, nil
+}
+
+// runLookup prints entries as JSON, for use from tools like Ansible's pipe
+// lookup:
+//
+//	{{ lookup('pipe', 'gostpass -db vault.kdb -password_file pw lookup -fields password Work/DB') | from_json | first }}
+func runLookup(args []string) error {
+	fs := flag.NewFlagSet("lookup", flag.ContinueOnError)
+	fields := fs.String("fields", "", "comma-separated fields to print (default all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: lookup [-fields list] path...")
+	}
+	var names []string
+	if *fields != "" {
+		names = strings.Split(*fields, ",")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	result, err := lookupEntries(db, fs.Args(), names)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(os.Stdout).Encode(result)
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestLookupEntries(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"DB", "Cache"} {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title = title
+		e.Username = "app"
+		e.Password = title + " password"
+	}
+
+	got, err := lookupEntries(db, []string{"Work/DB", "Work/Cache"}, []string{"password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"DB password", "Cache password"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lookupEntries(password) = %v; want %v", got, want)
+	}
+	got, err = lookupEntries(db, []string{"Work/DB"}, []string{"username", "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []map[string]string{{"username": "app", "password": "DB password"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lookupEntries(username, password) = %v; want %v", got, want)
+	}
+	if _, err := lookupEntries(db, []string{"Work/DB"}, []string{"pin"}); err == nil {
+		t.Error("lookupEntries with unknown field succeeded")
+	}
+	if _, err := lookupEntries(db, []string{"Work/Mail"}, nil); err == nil {
+		t.Error("lookupEntries of missing entry succeeded")
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gogost/gost34112012256"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// apiTokenStream is the name of the meta-stream that stores API tokens.
+const apiTokenStream = "GOSTPASS_API_TOKENS"
+
+// An apiToken lets a secrets-server client read the entries in some
+// groups, or, for a drop token, add entries to one group without reading
+// any.  Only a hash of the token's secret is stored, so the database
+// doesn't reveal it.
+type apiToken struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Hash []byte `json:"hash"`
+	// Groups are the IDs of the groups whose subtrees the token can read.
+	// If empty, it can read every entry the server serves.
+	Groups []uint32 `json:"groups,omitempty"`
+	// Attachments is whether the token can download attachments.
+	Attachments bool `json:"attachments,omitempty"`
+	// Drop is the ID of the group a drop token adds entries to, or nil
+	// if the token isn't a drop token.  Group IDs start at 0, so the ID
+	// alone can't tell.
+	Drop    *uint32   `json:"drop,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// newAPIToken returns a token and its secret, which the client sends as
+// is.  The secret starts with the token's ID.
+func newAPIToken(r io.Reader) (*apiToken, string, error) {
+	var id [4]byte
+	var secret [32]byte
+	if _, err := io.ReadFull(r, id[:]); err != nil {
+		return nil, "", fmt.Errorf("new API token: %v", err)
+	}
+	if _, err := io.ReadFull(r, secret[:]); err != nil {
+		return nil, "", fmt.Errorf("new API token: %v", err)
+	}
+	t := &apiToken{ID: hex.EncodeToString(id[:])}
+	s := t.ID + "." + hex.EncodeToString(secret[:])
+	t.Hash = hashAPIToken(s)
+	return t, s, nil
+}
+
+func hashAPIToken(secret string) []byte {
+	h := gost34112012256.New()
+	io.WriteString(h, secret)
+	return h.Sum(nil)
+}
+
+// allows reports whether the token can read e.
+func (t *apiToken) allows(e *keepass.Entry) bool {
+	if t.Drop != nil {
+		return false
+	}
+	if len(t.Groups) == 0 {
+		return true
+	}
+	for g := e.Parent(); g != nil && !g.IsRoot(); g = g.Parent() {
+		for _, id := range t.Groups {
+			if g.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readAPITokens returns the database's API tokens.
+func readAPITokens(db *keepass.Database) ([]*apiToken, error) {
+	data := db.MetaStream(apiTokenStream)
+	if data == nil {
+		return nil, nil
+	}
+	var tokens []*apiToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("read API tokens: %v", err)
+	}
+	return tokens, nil
+}
+
+// writeAPITokens replaces the database's API tokens.
+func writeAPITokens(db *keepass.Database, tokens []*apiToken) error {
+	if len(tokens) == 0 {
+		return db.SetMetaStream(apiTokenStream, nil)
+	}
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("write API tokens: %v", err)
+	}
+	return db.SetMetaStream(apiTokenStream, data)
+}
+
+// dropTokenGroups removes the groups with the given IDs from the API
+// tokens' scopes.  Group IDs are reused, so a token scoped to a deleted
+// group would otherwise read whatever group gets its ID next.  Tokens left
+// without a group, which would make them read every group, and drop
+// tokens whose group is deleted are revoked.
+func dropTokenGroups(db *keepass.Database, ids []uint32) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	tokens, err := readAPITokens(db)
+	if err != nil {
+		return err
+	}
+	deleted := make(map[uint32]bool)
+	for _, id := range ids {
+		deleted[id] = true
+	}
+	changed := false
+	kept := tokens[:0]
+	for _, t := range tokens {
+		if t.Drop != nil && deleted[*t.Drop] {
+			changed = true
+			continue
+		}
+		if len(t.Groups) > 0 {
+			var groups []uint32
+			for _, id := range t.Groups {
+				if !deleted[id] {
+					groups = append(groups, id)
+				}
+			}
+			if len(groups) != len(t.Groups) {
+				changed = true
+				if len(groups) == 0 {
+					continue
+				}
+				t.Groups = groups
+			}
+		}
+		kept = append(kept, t)
+	}
+	if !changed {
+		return nil
+	}
+	return writeAPITokens(db, kept)
+}
+
+// findAPIToken returns the token whose secret was sent or nil.
+func findAPIToken(tokens []*apiToken, secret string) *apiToken {
+	i := strings.IndexByte(secret, '.')
+	if i < 0 {
+		return nil
+	}
+	hash := hashAPIToken(secret)
+	for _, t := range tokens {
+		if t.ID == secret[:i] && subtle.ConstantTimeCompare(t.Hash, hash) == 1 {
+			return t
+		}
+	}
+	return nil
+}
+
+// A stringList is a flag that can be given more than once.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// runToken creates, lists and revokes API tokens for secrets-server.
+func runToken(args []string) error {
+	const usage = "usage: token create [-name name] [-group path]... [-attachments] | token create [-name name] -drop path | token list | token revoke id"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "create":
+		return tokenCreate(args[1:])
+	case "list":
+		if len(args) != 1 {
+			return errors.New(usage)
+		}
+		return tokenList()
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New(usage)
+		}
+		return tokenRevoke(args[1])
+	default:
+		return errors.New(usage)
+	}
+}
+
+func tokenCreate(args []string) error {
+	fs := flag.NewFlagSet("token create", flag.ContinueOnError)
+	name := fs.String("name", "", "`name` to tell the token apart in token list")
+	var groups stringList
+	fs.Var(&groups, "group", "`path` of a group the token can read, with its subgroups; may be repeated (default is every group)")
+	attachments := fs.Bool("attachments", false, "let the token download attachments")
+	drop := fs.String("drop", "", "make a token that can only add entries to the group at `path`, for dropping off secrets")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || *drop != "" && (len(groups) > 0 || *attachments) {
+		return errors.New("usage: token create [-name name] [-group path]... [-attachments] | token create [-name name] -drop path")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	tokens, err := readAPITokens(db)
+	if err != nil {
+		return err
+	}
+	t, secret, err := newAPIToken(rand.Reader)
+	if err != nil {
+		return err
+	}
+	t.Name = *name
+	t.Attachments = *attachments
+	t.Created = time.Now()
+	for _, path := range groups {
+		g := db.FindGroupPath(path)
+		if g == nil || g.IsRoot() {
+			return fmt.Errorf("%s: no such group", path)
+		}
+		t.Groups = append(t.Groups, g.ID)
+	}
+	if *drop != "" {
+		g := db.FindGroupPath(*drop)
+		if g == nil || g.IsRoot() {
+			return fmt.Errorf("%s: no such group", *drop)
+		}
+		id := g.ID
+		t.Drop = &id
+	}
+	if err := writeAPITokens(db, append(tokens, t)); err != nil {
+		return err
+	}
+	if err := writeDatabase(db); err != nil {
+		return err
+	}
+	fmt.Println(secret)
+	return nil
+}
+
+func tokenList() error {
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	tokens, err := readAPITokens(db)
+	if err != nil {
+		return err
+	}
+	groupPath := func(id uint32) string {
+		if g := db.FindGroup(id); g != nil {
+			return g.Path()
+		}
+		return fmt.Sprintf(tr("missing group %d"), id)
+	}
+	for _, t := range tokens {
+		scope := tr("all groups")
+		if t.Drop != nil {
+			scope = fmt.Sprintf(tr("adds entries to %s"), groupPath(*t.Drop))
+		} else if len(t.Groups) > 0 {
+			var paths []string
+			for _, id := range t.Groups {
+				paths = append(paths, groupPath(id))
+			}
+			scope = strings.Join(paths, ", ")
+		}
+		if t.Attachments {
+			scope += tr(", with attachments")
+		}
+		fmt.Printf("%s  %s  %-20s  %s\n", t.ID, t.Created.Local().Format("2006-01-02"), t.Name, scope)
+	}
+	return nil
+}
+
+func tokenRevoke(id string) error {
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	tokens, err := readAPITokens(db)
+	if err != nil {
+		return err
+	}
+	for i, t := range tokens {
+		if t.ID == id {
+			if err := writeAPITokens(db, append(tokens[:i], tokens[i+1:]...)); err != nil {
+				return err
+			}
+			return writeDatabase(db)
+		}
+	}
+	return fmt.Errorf("%s: no such token", id)
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestDownloadAttachmentRange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_attachment_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(db, key string) { *dbPath, sessions.keyPath = db, key }(*dbPath, sessions.keyPath)
+	*dbPath = filepath.Join(dir, "vault.kdb")
+	sessions.keyPath = filepath.Join(dir, "session_key")
+	if err := initDatabase(); err != nil {
+		t.Fatal(err)
+	}
+	// A database without credentials is opened without a session.
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Backups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := g.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	e.Title = "Backup"
+	e.Attachment.Name = "backup.bin"
+	e.Attachment.Data = data
+	if err := writeDatabase(db); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/entry/"+e.UUID.String()+"/attachment", nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		appHandler{f: downloadAttachment}.ServeHTTP(w, mux.SetURLVars(r, map[string]string{"uuid": e.UUID.String()}))
+		return w
+	}
+
+	w := get(nil)
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), data) {
+		t.Fatalf("full download: status %d, %d bytes; want 200, %d bytes", w.Code, w.Body.Len(), len(data))
+	}
+	if w.Header().Get("Accept-Ranges") != "bytes" {
+		t.Error("Accept-Ranges not set")
+	}
+	etag := w.Header().Get("ETag")
+
+	w = get(http.Header{"Range": {"bytes=100-199"}, "If-Range": {etag}})
+	if w.Code != http.StatusPartialContent || !bytes.Equal(w.Body.Bytes(), data[100:200]) {
+		t.Errorf("range: status %d, body %d bytes; want 206, bytes 100-199", w.Code, w.Body.Len())
+	}
+	if got := w.Header().Get("Content-Range"); got != "bytes 100-199/1000" {
+		t.Errorf("Content-Range = %q; want %q", got, "bytes 100-199/1000")
+	}
+
+	w = get(http.Header{"Range": {"bytes=100-199"}, "If-Range": {`"stale"`}})
+	if w.Code != http.StatusOK || w.Body.Len() != len(data) {
+		t.Errorf("range with stale If-Range: status %d, %d bytes; want 200, full body", w.Code, w.Body.Len())
+	}
+	w = get(http.Header{"Range": {"bytes=2000-"}})
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("range past the end: status %d; want 416", w.Code)
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/breach"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// An auditFinding is a problem reported by the audit command.
+type auditFinding struct {
+	Check   string   `json:"check"`
+	Entries []string `json:"entries"`
+	Detail  string   `json:"detail,omitempty"`
+}
+
+// auditEntries returns the entries that the audit looks at: those with a
+// password, outside the recycle bin.
+func auditEntries(db *keepass.Database) []*keepass.Entry {
+	var entries []*keepass.Entry
+	for _, e := range db.Entries() {
+		if e.Password != "" && !e.Parent().InRecycleBin() {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// checkBreaches reports the entries whose passwords appear in the breach
+// corpus at path, which may be a text corpus or a filter built by the hibp
+// command.
+func checkBreaches(path string, entries []*keepass.Entry) ([]auditFinding, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	breached := make(map[string]bool)
+	filter, err := breach.OpenFilter(f)
+	switch {
+	case err == breach.ErrNotFilter:
+		passwords := make([]string, len(entries))
+		for i, e := range entries {
+			passwords[i] = e.Password
+		}
+		if breached, err = breach.Scan(bufio.NewReader(f), passwords); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		for _, e := range entries {
+			if breached[e.Password] {
+				continue
+			}
+			ok, err := filter.Contains(e.Password)
+			if err != nil {
+				return nil, err
+			}
+			breached[e.Password] = ok
+		}
+	}
+	var findings []auditFinding
+	for _, e := range entries {
+		if breached[e.Password] {
+			findings = append(findings, auditFinding{
+				Check:   "breached",
+				Entries: []string{entryPath(e)},
+				Detail:  "password appears in the breach corpus",
+			})
+		}
+	}
+	return findings, nil
+}
+
+// siteOf returns what identifies the site an entry is for: the host of
+// its URL, or failing that its title.
+func siteOf(e *keepass.Entry) string {
+	if u, err := url.Parse(e.URL); err == nil && u.Hostname() != "" {
+		return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
+	if u, err := url.Parse("//" + e.URL); err == nil && strings.Contains(u.Hostname(), ".") {
+		return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
+	return strings.ToLower(strings.TrimSpace(e.Title))
+}
+
+// An account is the entries that share a username or email address.
+type account struct {
+	Username string
+	Entries  []*keepass.Entry
+}
+
+// groupAccounts groups entries by username, ignoring case, sorted by
+// username.  Entries without a username are left out.
+func groupAccounts(entries []*keepass.Entry) []account {
+	byName := make(map[string]*account)
+	var names []string
+	for _, e := range entries {
+		name := strings.ToLower(strings.TrimSpace(e.Username))
+		if name == "" {
+			continue
+		}
+		a := byName[name]
+		if a == nil {
+			a = &account{Username: name}
+			byName[name] = a
+			names = append(names, name)
+		}
+		a.Entries = append(a.Entries, e)
+	}
+	sort.Strings(names)
+	accounts := make([]account, len(names))
+	for i, name := range names {
+		accounts[i] = *byName[name]
+	}
+	return accounts
+}
+
+// checkCredentialReuse reports each username and password pair used on
+// more than one site.  A breach of any of the sites exposes the others to
+// credential stuffing.
+func checkCredentialReuse(accounts []account) []auditFinding {
+	var findings []auditFinding
+	for _, a := range accounts {
+		byPassword := make(map[string][]*keepass.Entry)
+		var passwords []string
+		for _, e := range a.Entries {
+			if byPassword[e.Password] == nil {
+				passwords = append(passwords, e.Password)
+			}
+			byPassword[e.Password] = append(byPassword[e.Password], e)
+		}
+		for _, pw := range passwords {
+			sites := make(map[string]bool)
+			var paths []string
+			for _, e := range byPassword[pw] {
+				sites[siteOf(e)] = true
+				paths = append(paths, entryPath(e))
+			}
+			if len(sites) < 2 {
+				continue
+			}
+			sort.Strings(paths)
+			findings = append(findings, auditFinding{
+				Check:   "credential-reuse",
+				Entries: paths,
+				Detail:  fmt.Sprintf("%s uses the same password on %d sites", a.Username, len(sites)),
+			})
+		}
+	}
+	return findings
+}
+
+// runAudit reports weaknesses in the database's passwords.  It works
+// offline; breached passwords are found in a local corpus.
+func runAudit(args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	breaches := fs.String("breaches", "", "breach corpus `file` of SHA-1 or NTLM hashes, or a filter built with hibp build")
+	listAccounts := fs.Bool("accounts", false, "list the sites each username or email address is used on")
+	policyPath := fs.String("policy", "", "check the limits in policy `file` (YAML or JSON) and exit with status 3 if any is exceeded (default is the policy stored in the database, if any)")
+	jsonOut := fs.Bool("json", false, "print the report as JSON (implied by -policy)")
+	plugins := fs.Bool("plugins", false, "also run the audit checks of plugins, which are sent the passwords")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: audit [-breaches file] [-accounts] [-policy file] [-json] [-plugins]")
+	}
+	var policy *auditPolicy
+	if *policyPath != "" {
+		var err error
+		if policy, err = readAuditPolicy(*policyPath); err != nil {
+			return err
+		}
+		if policy.MaxBreached != nil && *breaches == "" {
+			return errors.New("policy sets max_breached, but no -breaches corpus was given")
+		}
+		*jsonOut = true
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		if policy, err = storedAuditPolicy(db); err != nil {
+			return err
+		}
+		// The stored policy can't know whether a corpus is at hand.
+		if policy != nil && *breaches == "" {
+			policy.MaxBreached = nil
+		}
+	}
+	entries := auditEntries(db)
+	accounts := groupAccounts(entries)
+	if *listAccounts && !*jsonOut {
+		for _, a := range accounts {
+			passwords := make(map[string]bool)
+			for _, e := range a.Entries {
+				passwords[e.Password] = true
+			}
+			fmt.Printf(tr("%s: %d entries, %d distinct passwords\n"), a.Username, len(a.Entries), len(passwords))
+			for _, e := range a.Entries {
+				fmt.Printf("  %s (%s)\n", entryPath(e), siteOf(e))
+			}
+		}
+	}
+	findings := checkCredentialReuse(accounts)
+	var breached []auditFinding
+	if *breaches != "" {
+		if breached, err = checkBreaches(*breaches, entries); err != nil {
+			return err
+		}
+		findings = append(findings, breached...)
+	}
+	if *plugins {
+		pf, err := checkPlugins(entries)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, pf...)
+	}
+	var violations []auditFinding
+	if policy != nil {
+		violations = checkPolicy(policy, entries, breached, time.Now())
+	}
+	if *jsonOut {
+		report := struct {
+			Entries    int            `json:"entries"`
+			Findings   []auditFinding `json:"findings"`
+			Violations []auditFinding `json:"violations,omitempty"`
+			Pass       bool           `json:"pass"`
+		}{len(entries), findings, violations, len(violations) == 0}
+		if report.Findings == nil {
+			report.Findings = []auditFinding{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range append(findings, violations...) {
+			fmt.Printf("%s: %s: %s\n", f.Check, strings.Join(f.Entries, ", "), f.Detail)
+		}
+		fmt.Printf(tr("%d entries audited, %d findings\n"), len(entries), len(findings))
+	}
+	if len(violations) > 0 {
+		return exitError{policyViolationExit, fmt.Errorf(tr("%d policy violations"), len(violations))}
+	}
+	return nil
+}
+
+// runHIBP builds a filter from a breach corpus, such as the Pwned
+// Passwords SHA-1 or NTLM lists, so that audits don't have to read the
+// whole corpus.
+func runHIBP(args []string) error {
+	const usage = "usage: hibp build [-p rate] corpus.txt filter"
+	if len(args) == 0 || args[0] != "build" {
+		return errors.New(usage)
+	}
+	fs := flag.NewFlagSet("hibp build", flag.ContinueOnError)
+	p := fs.Float64("p", 0.001, "false positive rate")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New(usage)
+	}
+	corpus, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer corpus.Close()
+	n, err := breach.Count(bufio.NewReader(corpus))
+	if err != nil {
+		return err
+	}
+	if _, err := corpus.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	out, err := os.Create(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(out)
+	err = breach.BuildFilter(w, bufio.NewReader(corpus), n, *p)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(fs.Arg(1))
+		return err
+	}
+	fmt.Printf(tr("%d hashes written to %s\n"), n, fs.Arg(1))
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/breach"
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestCheckBreaches(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	work, err := db.MkdirAll("Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pw := range []string{"password", "correct horse battery staple", ""} {
+		e, err := work.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title = "Site " + pw
+		e.Password = pw
+	}
+	entries := auditEntries(db)
+	if len(entries) != 2 {
+		t.Fatalf("auditEntries = %d entries; want 2", len(entries))
+	}
+
+	dir, err := ioutil.TempDir("", "gostpass_audit_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	corpus := new(bytes.Buffer)
+	for _, pw := range []string{"123456", "password", "qwerty"} {
+		fmt.Fprintf(corpus, "%X:1\n", breach.NTLM.Sum(pw))
+	}
+	corpusPath := filepath.Join(dir, "corpus.txt")
+	if err := ioutil.WriteFile(corpusPath, corpus.Bytes(), 0666); err != nil {
+		t.Fatal(err)
+	}
+	filter := new(bytes.Buffer)
+	if err := breach.BuildFilter(filter, bytes.NewReader(corpus.Bytes()), 3, 0.001); err != nil {
+		t.Fatal(err)
+	}
+	filterPath := filepath.Join(dir, "corpus.bloom")
+	if err := ioutil.WriteFile(filterPath, filter.Bytes(), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{corpusPath, filterPath} {
+		findings, err := checkBreaches(path, entries)
+		if err != nil {
+			t.Errorf("checkBreaches(%q): %v", filepath.Base(path), err)
+			continue
+		}
+		if len(findings) != 1 || findings[0].Entries[0] != "Work/Site password" {
+			t.Errorf("checkBreaches(%q) = %+v; want Work/Site password", filepath.Base(path), findings)
+		}
+	}
+}
+
+func TestCheckCredentialReuse(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ent := range []struct{ title, url, username, password string }{
+		{"Mail", "https://mail.example.com/login", "bob@example.com", "hunter2"},
+		{"Shop", "www.shop.example", "Bob@Example.com", "hunter2"},
+		{"Shop again", "https://www.shop.example/", "bob@example.com", "xyzzy"},
+		{"Shop old", "https://shop.example/", "bob@example.com", "xyzzy"},
+		{"Forum", "", "alice", "hunter2"},
+		{"Bank", "", "", "hunter2"},
+	} {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title, e.URL, e.Username, e.Password = ent.title, ent.url, ent.username, ent.password
+	}
+	accounts := groupAccounts(auditEntries(db))
+	if len(accounts) != 2 || accounts[0].Username != "alice" || len(accounts[1].Entries) != 4 {
+		t.Fatalf("groupAccounts = %+v; want alice with 1 entry and bob@example.com with 4", accounts)
+	}
+	findings := checkCredentialReuse(accounts)
+	if len(findings) != 1 {
+		t.Fatalf("checkCredentialReuse = %+v; want 1 finding", findings)
+	}
+	if got := strings.Join(findings[0].Entries, ","); got != "Web/Mail,Web/Shop" {
+		t.Errorf("reused entries = %s; want Web/Mail,Web/Shop", got)
+	}
+}
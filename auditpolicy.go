@@ -0,0 +1,239 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// policyViolationExit is the exit code of an audit that ran but found
+// policy violations, so that automation can tell it from a failure to run.
+const policyViolationExit = 3
+
+// An auditPolicy sets limits for the audit command.  Unset limits aren't
+// checked.
+type auditPolicy struct {
+	// MaxReused is the most entries that may share a password.
+	MaxReused *int `json:"max_reused"`
+	// MinEntropy is the fewest bits of estimated entropy a password may
+	// have.
+	MinEntropy *float64 `json:"min_entropy"`
+	// MaxAge is the oldest a password may be, like "365d".
+	MaxAge string `json:"max_age"`
+	// MaxBreached is the most passwords that may appear in the breach
+	// corpus.  It requires -breaches.
+	MaxBreached *int `json:"max_breached"`
+}
+
+// readAuditPolicy reads a policy file.  It may be JSON or YAML, but only
+// the flat "key: value" subset of YAML is supported, which is all a policy
+// needs.
+func readAuditPolicy(path string) (*auditPolicy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		if data, err = flatYAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("policy %s: %v", path, err)
+		}
+	}
+	p, err := parseAuditPolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("policy %s: %v", path, err)
+	}
+	return p, nil
+}
+
+// parseAuditPolicy parses a policy in JSON.
+func parseAuditPolicy(data []byte) (*auditPolicy, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	p := new(auditPolicy)
+	if err := dec.Decode(p); err != nil {
+		return nil, err
+	}
+	if p.MaxAge != "" {
+		if _, err := parseAge(p.MaxAge); err != nil {
+			return nil, fmt.Errorf("max_age: %v", err)
+		}
+	}
+	return p, nil
+}
+
+// auditPolicyKey is the database custom data key of the policy that audit
+// checks when no -policy is given, as set by a vault template.
+const auditPolicyKey = "gostpass.audit_policy"
+
+// storedAuditPolicy returns the policy stored in db or nil.
+func storedAuditPolicy(db *keepass.Database) (*auditPolicy, error) {
+	data, ok := db.CustomData().Get(auditPolicyKey)
+	if !ok {
+		return nil, nil
+	}
+	p, err := parseAuditPolicy([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("database's audit policy: %v", err)
+	}
+	return p, nil
+}
+
+// flatYAMLToJSON converts YAML made of "key: value" lines and comments to
+// a JSON object.  Values that parse as numbers or booleans are kept as
+// such; everything else is a string.
+func flatYAMLToJSON(data []byte) ([]byte, error) {
+	obj := make(map[string]interface{})
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line == "---" {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: want \"key: value\"", n)
+		}
+		key, val := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if _, dup := obj[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", n, key)
+		}
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			obj[key] = f
+		} else if b, err := strconv.ParseBool(val); err == nil {
+			obj[key] = b
+		} else if uq, err := strconv.Unquote(val); err == nil {
+			obj[key] = uq
+		} else {
+			obj[key] = strings.Trim(val, "'")
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+// passwordEntropy estimates the entropy of a password in bits, assuming
+// it is random over the character classes it uses.  It overestimates
+// passwords made of words, so a policy should use it as a floor only.
+func passwordEntropy(pw string) float64 {
+	var lower, upper, digit, symbol, other bool
+	n := 0
+	for _, c := range pw {
+		n++
+		switch {
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= '0' && c <= '9':
+			digit = true
+		case c < unicode.MaxASCII && unicode.IsPrint(c):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	size := 0
+	for _, class := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.used {
+			size += class.size
+		}
+	}
+	if size == 0 {
+		return 0
+	}
+	return float64(n) * math.Log2(float64(size))
+}
+
+// checkPolicy returns the violations of p among entries.  breached are the
+// breach findings, if a corpus was checked.
+func checkPolicy(p *auditPolicy, entries []*keepass.Entry, breached []auditFinding, now time.Time) []auditFinding {
+	var violations []auditFinding
+	if p.MaxReused != nil {
+		byPassword := make(map[string][]string)
+		for _, e := range entries {
+			byPassword[e.Password] = append(byPassword[e.Password], entryPath(e))
+		}
+		var reused []auditFinding
+		for _, paths := range byPassword {
+			if len(paths) <= *p.MaxReused {
+				continue
+			}
+			sort.Strings(paths)
+			reused = append(reused, auditFinding{
+				Check:   "max_reused",
+				Entries: paths,
+				Detail:  fmt.Sprintf("%d entries share a password; at most %d may", len(paths), *p.MaxReused),
+			})
+		}
+		sort.Slice(reused, func(i, j int) bool { return reused[i].Entries[0] < reused[j].Entries[0] })
+		violations = append(violations, reused...)
+	}
+	if p.MinEntropy != nil {
+		for _, e := range entries {
+			if bits := passwordEntropy(e.Password); bits < *p.MinEntropy {
+				violations = append(violations, auditFinding{
+					Check:   "min_entropy",
+					Entries: []string{entryPath(e)},
+					Detail:  fmt.Sprintf("password has about %.0f bits of entropy; want at least %g", bits, *p.MinEntropy),
+				})
+			}
+		}
+	}
+	if p.MaxAge != "" {
+		age, _ := parseAge(p.MaxAge)
+		for _, e := range entries {
+			if changed := e.PasswordChanged(); changed.Before(now.Add(-age)) {
+				violations = append(violations, auditFinding{
+					Check:   "max_age",
+					Entries: []string{entryPath(e)},
+					Detail:  fmt.Sprintf("password is %d days old; at most %s is allowed", int(now.Sub(changed).Hours()/24), p.MaxAge),
+				})
+			}
+		}
+	}
+	if p.MaxBreached != nil && len(breached) > *p.MaxBreached {
+		var paths []string
+		for _, f := range breached {
+			paths = append(paths, f.Entries...)
+		}
+		violations = append(violations, auditFinding{
+			Check:   "max_breached",
+			Entries: paths,
+			Detail:  fmt.Sprintf("%d passwords are breached; at most %d may be", len(breached), *p.MaxBreached),
+		})
+	}
+	return violations
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestReadAuditPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_policy_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		name, data string
+		ok         bool
+	}{
+		{"policy.yaml", "# vault hygiene\nmax_reused: 1\nmin_entropy: 60   # bits\nmax_age: \"365d\"\n", true},
+		{"policy.json", `{"max_reused": 1, "min_entropy": 60, "max_age": "365d"}`, true},
+		{"unknown.yaml", "max_reuse: 1\n", false},
+		{"badage.yaml", "max_age: 1y\n", false},
+		{"nested.yaml", "limits:\n  - max_reused\n", false},
+	}
+	for _, test := range tests {
+		path := filepath.Join(dir, test.name)
+		if err := ioutil.WriteFile(path, []byte(test.data), 0666); err != nil {
+			t.Fatal(err)
+		}
+		p, err := readAuditPolicy(path)
+		if !test.ok {
+			if err == nil {
+				t.Errorf("readAuditPolicy(%s) = %+v; want error", test.name, p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("readAuditPolicy(%s): %v", test.name, err)
+			continue
+		}
+		if p.MaxReused == nil || *p.MaxReused != 1 || p.MinEntropy == nil || *p.MinEntropy != 60 || p.MaxAge != "365d" || p.MaxBreached != nil {
+			t.Errorf("readAuditPolicy(%s) = %+v; want max_reused 1, min_entropy 60, max_age 365d", test.name, p)
+		}
+	}
+}
+
+func TestPasswordEntropy(t *testing.T) {
+	tests := []struct {
+		pw   string
+		want float64
+	}{
+		{"", 0},
+		{"aaaa", 4 * math.Log2(26)},
+		{"aA1!", 4 * math.Log2(95)},
+		{"пароль", 6 * math.Log2(100)},
+	}
+	for _, test := range tests {
+		if got := passwordEntropy(test.pw); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("passwordEntropy(%q) = %g; want %g", test.pw, got, test.want)
+		}
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2019, time.July, 1, 22, 0, 0, 0, time.UTC)
+	for _, ent := range []struct {
+		title, password string
+		age             time.Duration
+	}{
+		{"A", "hunter2", 0},
+		{"B", "hunter2", 0},
+		{"C", "Tr0ub4dor&3-correct-horse", 400 * 24 * time.Hour},
+		{"D", "Tr0ub4dor&3-correct-horse-battery", 0},
+	} {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title, e.Password = ent.title, ent.password
+		e.CreationTime = now.Add(-ent.age)
+	}
+	one, sixty, zero := 1, 60.0, 0
+	p := &auditPolicy{MaxReused: &one, MinEntropy: &sixty, MaxAge: "365d", MaxBreached: &zero}
+	breached := []auditFinding{{Check: "breached", Entries: []string{"Web/A"}}}
+	got := checkPolicy(p, auditEntries(db), breached, now)
+	want := []string{"max_reused Web/A", "min_entropy Web/A", "min_entropy Web/B", "max_age Web/C", "max_breached Web/A"}
+	if len(got) != len(want) {
+		t.Fatalf("checkPolicy = %+v; want %v", got, want)
+	}
+	for i, v := range got {
+		if s := v.Check + " " + v.Entries[0]; s != want[i] {
+			t.Errorf("violation %d = %q; want %q", i, s, want[i])
+		}
+	}
+
+	if got := checkPolicy(&auditPolicy{}, auditEntries(db), breached, now); len(got) != 0 {
+		t.Errorf("checkPolicy with empty policy = %+v; want none", got)
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/kdbcrypt"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+var (
+	backupsPath    = flag.String("backups", "", "path to the directory of database backups (default is -db path with \".backups\" appended)")
+	backupInterval = flag.Duration("backup_interval", 0, "back up the database on save if the newest backup is older than this; 0 disables automatic backups")
+	backupKeep     = flag.Int("backup_keep", 30, "number of backups to keep; older ones are deleted")
+)
+
+// Backups are copies of the database file named by the UTC time they were
+// made, so they sort from oldest to newest.  Each is read back and
+// decrypted after it is written, so a backup that exists can be restored.
+const backupTimeFormat = "20060102T150405Z"
+
+var backupName = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}Z\.kdb$`)
+
+func backupsDir() string {
+	if *backupsPath != "" {
+		return *backupsPath
+	}
+	return *dbPath + ".backups"
+}
+
+// listBackups returns the names of the backups, oldest first.
+func listBackups() ([]string, error) {
+	infos, err := ioutil.ReadDir(backupsDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("list backups: %v", err)
+	}
+	var names []string
+	for _, fi := range infos {
+		if backupName.MatchString(fi.Name()) {
+			names = append(names, fi.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// backupDue reports whether a save at now should make an automatic backup.
+func backupDue(now time.Time) (bool, error) {
+	if *backupInterval <= 0 {
+		return false, nil
+	}
+	names, err := listBackups()
+	if err != nil || len(names) == 0 {
+		return err == nil, err
+	}
+	last, err := time.Parse(backupTimeFormat, names[len(names)-1][:len(backupTimeFormat)])
+	if err != nil {
+		return false, fmt.Errorf("list backups: %v", err)
+	}
+	return now.Sub(last) >= *backupInterval, nil
+}
+
+// writeBackup stores data, a database file that opens with key, as a
+// backup made at now, and deletes the backups beyond -backup_keep.  A nil
+// key stores data without checking that it opens.
+func writeBackup(data []byte, key kdbcrypt.ComputedKey, now time.Time) (string, error) {
+	if err := os.MkdirAll(backupsDir(), 0700); err != nil {
+		return "", fmt.Errorf("write backup: %v", err)
+	}
+	// Never replace a backup, even one made in the same second.
+	var name, path string
+	for {
+		name = now.UTC().Format(backupTimeFormat) + ".kdb"
+		path = filepath.Join(backupsDir(), name)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("write backup: %v", err)
+		}
+		now = now.Add(time.Second)
+	}
+	st, err := newStorage(path)
+	if err != nil {
+		return "", fmt.Errorf("write backup: %v", err)
+	}
+	defer st.Close()
+	wc, err := st.writer()
+	if err != nil {
+		return "", fmt.Errorf("write backup: %v", err)
+	}
+	_, err = wc.Write(data)
+	cerr := wc.Close()
+	if err != nil {
+		return "", fmt.Errorf("write backup: %v", err)
+	}
+	if cerr != nil {
+		return "", fmt.Errorf("write backup: close: %v", cerr)
+	}
+	if err := verifyBackup(path, data, key); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return name, pruneBackups()
+}
+
+// verifyBackup reads back the backup at path and checks that it holds
+// data and opens with key.
+func verifyBackup(path string, data []byte, key kdbcrypt.ComputedKey) error {
+	written, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("verify backup: %v", err)
+	}
+	if !bytes.Equal(written, data) {
+		return fmt.Errorf("verify backup: %s does not match what was written", path)
+	}
+	if key == nil {
+		return nil
+	}
+	if _, err := keepass.Open(bytes.NewReader(written), &keepass.Options{ComputedKey: key}); err != nil {
+		return fmt.Errorf("verify backup: %s: %v", path, err)
+	}
+	return nil
+}
+
+func pruneBackups() error {
+	names, err := listBackups()
+	if err != nil {
+		return err
+	}
+	for len(names) > *backupKeep && *backupKeep > 0 {
+		if err := os.Remove(filepath.Join(backupsDir(), names[0])); err != nil {
+			return fmt.Errorf("remove old backup: %v", err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// runBackup makes, lists and restores backups.
+func runBackup(args []string) error {
+	const usage = "usage: backup now | backup list | backup restore name"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "now":
+		if len(args) != 1 {
+			return errors.New(usage)
+		}
+		return backupNow()
+	case "list":
+		if len(args) != 1 {
+			return errors.New(usage)
+		}
+		return backupList()
+	case "restore":
+		if len(args) != 2 {
+			return errors.New(usage)
+		}
+		return backupRestore(args[1])
+	default:
+		return errors.New(usage)
+	}
+}
+
+func backupNow() error {
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := db.Write(&buf); err != nil {
+		return fmt.Errorf("write backup: %v", err)
+	}
+	name, err := writeBackup(buf.Bytes(), db.ComputedKey(), time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Println(filepath.Join(backupsDir(), name))
+	return nil
+}
+
+func backupList() error {
+	names, err := listBackups()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fi, err := os.Stat(filepath.Join(backupsDir(), name))
+		if err != nil {
+			return err
+		}
+		t, _ := time.Parse(backupTimeFormat, name[:len(backupTimeFormat)])
+		fmt.Printf("%s  %s  %d\n", name, t.Local().Format("2006-01-02 15:04:05"), fi.Size())
+	}
+	return nil
+}
+
+// backupRestore replaces the database with a backup.  The backup must open
+// with the command's credentials.  The current database file is backed up
+// first, as it is, so that the restore can be undone even if it is
+// damaged.
+func backupRestore(name string) error {
+	if !backupName.MatchString(name) {
+		return fmt.Errorf("%s: not a backup name; see backup list", name)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(backupsDir(), name))
+	if err != nil {
+		return err
+	}
+	opts, err := commandOptions()
+	if err != nil {
+		return err
+	}
+	db, err := keepass.Open(bytes.NewReader(data), opts)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	if !confirm(fmt.Sprintf(tr("Replace %s with backup %s?"), *dbPath, name)) {
+		return errors.New(tr("not restored"))
+	}
+	if err := initDatabase(); err != nil {
+		return err
+	}
+	if current, err := ioutil.ReadFile(*dbPath); err == nil {
+		saved, err := writeBackup(current, nil, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf(tr("Previous database saved as backup %s\n"), saved)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return writeDatabase(db)
+}
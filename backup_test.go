@@ -0,0 +1,106 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_backup_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(db string, interval time.Duration, keep int) {
+		*dbPath, *backupInterval, *backupKeep = db, interval, keep
+	}(*dbPath, *backupInterval, *backupKeep)
+	*dbPath = filepath.Join(dir, "vault.kdb")
+	*backupInterval = time.Hour
+	*backupKeep = 3
+	if err := initDatabase(); err != nil {
+		t.Fatal(err)
+	}
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := g.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Title = "Mail"
+	e.Attachment.Name = "notes.txt"
+	e.Attachment.Data = []byte("backed up")
+
+	// Only the first of two saves within the interval makes a backup.
+	for i := 0; i < 2; i++ {
+		if err := writeDatabase(db); err != nil {
+			t.Fatal("writeDatabase:", err)
+		}
+	}
+	names, err := listBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 {
+		t.Fatalf("backups after two saves = %q; want one", names)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(backupsDir(), names[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bdb, err := keepass.Open(bytes.NewReader(data), &keepass.Options{ComputedKey: db.ComputedKey()})
+	if err != nil {
+		t.Fatal("open backup:", err)
+	}
+	if be, err := findEntryPath(bdb, "Work/Mail"); err != nil || string(be.Attachment.Data) != "backed up" {
+		t.Errorf("backup's Work/Mail = %+v, %v; want it with its attachment", be, err)
+	}
+
+	// Backups in the same second get distinct names, and only the newest
+	// -backup_keep are kept.
+	t0 := time.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if _, err := writeBackup(data, db.ComputedKey(), t0); err != nil {
+			t.Fatal("writeBackup:", err)
+		}
+	}
+	want := []string{"20201001T120001Z.kdb", "20201001T120002Z.kdb", names[0]}
+	if names, err := listBackups(); err != nil || !reflect.DeepEqual(names, want) {
+		t.Errorf("backups = %q, %v; want %q", names, err, want)
+	}
+
+	// A backup that doesn't open with the key is not kept.
+	if _, err := writeBackup([]byte("garbage"), db.ComputedKey(), time.Now()); err == nil {
+		t.Error("writeBackup of a damaged database succeeded")
+	}
+	if names, err := listBackups(); err != nil || !reflect.DeepEqual(names, want) {
+		t.Errorf("backups after failed write = %q, %v; want %q", names, err, want)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pedroalbanese/gogost/gost3412128"
+	"github.com/pedroalbanese/gogost/mgm"
+	"github.com/pedroalbanese/gostpass/pkg/gosthmac"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+var (
+	splitAttachments = flag.Bool("split_attachments", false, "keep attachments in separate encrypted files in the -blobs directory, so that sync services only transfer the ones that changed")
+	blobsPath        = flag.String("blobs", "", "path to the directory of attachment files for -split_attachments (default is -db path with \".blobs\" appended)")
+)
+
+// blobKey is the custom data key of an entry whose attachment data is kept
+// in a blob file.  Its value is the blob's ID.
+const blobKey = "gostpass.blob"
+
+// A blob file holds one attachment, sealed with Kuznyechik-MGM under a key
+// derived from the database key.  It is named by a MAC of the attachment,
+// so an attachment that didn't change keeps its file, and the database,
+// which names the blob, vouches for its content.
+func blobsDir() string {
+	if *blobsPath != "" {
+		return *blobsPath
+	}
+	return *dbPath + ".blobs"
+}
+
+func blobFile(id string) string {
+	return filepath.Join(blobsDir(), id)
+}
+
+// blobKeys derives the keys that name and seal blobs from the database key.
+func blobKeys(db *keepass.Database) (idKey, sealKey []byte) {
+	ck := db.ComputedKey()
+	return gosthmac.Sum256(ck, []byte("gostpass blob id")), gosthmac.Sum256(ck, []byte("gostpass blob seal"))
+}
+
+func blobAEAD(sealKey []byte) (cipher.AEAD, error) {
+	return mgm.NewMGM(gost3412128.NewCipher(sealKey), gost3412128.BlockSize)
+}
+
+// splitBlobs moves the attachments of db's entries to blob files, so that
+// db can be written without them.  It returns the IDs of the blobs that db
+// refers to and a function that puts the attachments back.
+func splitBlobs(db *keepass.Database) (ids map[string]bool, restore func(), err error) {
+	idKey, sealKey := blobKeys(db)
+	aead, err := blobAEAD(sealKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("split attachments: %v", err)
+	}
+	if err := os.MkdirAll(blobsDir(), 0700); err != nil {
+		return nil, nil, fmt.Errorf("split attachments: %v", err)
+	}
+	ids = make(map[string]bool)
+	var split []*keepass.Entry
+	var data [][]byte
+	restore = func() {
+		for i, e := range split {
+			e.Attachment.Data = data[i]
+			e.CustomData.Delete(blobKey)
+		}
+	}
+	for _, e := range db.Entries() {
+		if !e.HasAttachment() || len(e.Attachment.Data) == 0 {
+			continue
+		}
+		id := hex.EncodeToString(gosthmac.Sum256(idKey, e.Attachment.Data))
+		if !ids[id] {
+			if err := writeBlob(aead, id, e.Attachment.Data); err != nil {
+				restore()
+				return nil, nil, err
+			}
+			ids[id] = true
+		}
+		split = append(split, e)
+		data = append(data, e.Attachment.Data)
+		e.CustomData.Set(blobKey, id)
+		e.Attachment.Data = nil
+	}
+	return ids, restore, nil
+}
+
+// writeBlob seals data into the blob file id, unless it already exists.
+func writeBlob(aead cipher.AEAD, id string, data []byte) error {
+	if _, err := os.Stat(blobFile(id)); err == nil {
+		return nil
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("write attachment: %v", err)
+	}
+	// MGM requires the nonce's most significant bit to be clear.
+	nonce[0] &= 0x7f
+	st, err := newStorage(blobFile(id))
+	if err != nil {
+		return fmt.Errorf("write attachment: %v", err)
+	}
+	defer st.Close()
+	wc, err := st.writer()
+	if err != nil {
+		return fmt.Errorf("write attachment: %v", err)
+	}
+	_, err = wc.Write(aead.Seal(nonce, nonce, data, []byte(id)))
+	cerr := wc.Close()
+	if err != nil {
+		return fmt.Errorf("write attachment: %v", err)
+	}
+	if cerr != nil {
+		return fmt.Errorf("write attachment: close: %v", cerr)
+	}
+	return nil
+}
+
+// joinBlobs reads the attachments of db's entries back from their blob
+// files.
+func joinBlobs(db *keepass.Database) error {
+	var aead cipher.AEAD
+	for _, e := range db.Entries() {
+		id, ok := e.CustomData.Get(blobKey)
+		if !ok {
+			continue
+		}
+		if aead == nil {
+			_, sealKey := blobKeys(db)
+			var err error
+			if aead, err = blobAEAD(sealKey); err != nil {
+				return fmt.Errorf("read attachment: %v", err)
+			}
+		}
+		data, err := readBlob(aead, id)
+		if err != nil {
+			return fmt.Errorf("read attachment of %q: %v", e.Title, err)
+		}
+		e.Attachment.Data = data
+		e.CustomData.Delete(blobKey)
+	}
+	return nil
+}
+
+var errBlob = errors.New("attachment file is damaged or belongs to another database")
+
+func readBlob(aead cipher.AEAD, id string) ([]byte, error) {
+	if _, err := hex.DecodeString(id); err != nil || len(id) != 2*gosthmac.Size256 {
+		return nil, errBlob
+	}
+	sealed, err := ioutil.ReadFile(blobFile(id))
+	if err != nil {
+		return nil, err
+	}
+	n := aead.NonceSize()
+	if len(sealed) < n {
+		return nil, errBlob
+	}
+	data, err := aead.Open(nil, sealed[:n], sealed[n:], []byte(id))
+	if err != nil {
+		return nil, errBlob
+	}
+	return data, nil
+}
+
+// removeUnusedBlobs deletes the blob files that the database no longer
+// refers to.
+func removeUnusedBlobs(ids map[string]bool) error {
+	names, err := ioutil.ReadDir(blobsDir())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("remove unused attachments: %v", err)
+	}
+	for _, fi := range names {
+		id := fi.Name()
+		if ids[id] || len(id) != 2*gosthmac.Size256 {
+			continue
+		}
+		if _, err := hex.DecodeString(id); err != nil {
+			continue
+		}
+		if err := os.Remove(blobFile(id)); err != nil {
+			return fmt.Errorf("remove unused attachments: %v", err)
+		}
+	}
+	return nil
+}
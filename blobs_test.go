@@ -0,0 +1,124 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestSplitAttachments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_blobs_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(db string, split bool) { *dbPath, *splitAttachments = db, split }(*dbPath, *splitAttachments)
+	*dbPath = filepath.Join(dir, "vault.kdb")
+	*splitAttachments = true
+	if err := initDatabase(); err != nil {
+		t.Fatal(err)
+	}
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	attachments := map[string]string{"a": "shared", "b": "shared", "c": "own"}
+	entries := make(map[string]*keepass.Entry)
+	for _, title := range []string{"a", "b", "c"} {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title = title
+		e.Attachment.Name = title + ".txt"
+		e.Attachment.Data = []byte(attachments[title])
+		entries[title] = e
+	}
+	blobCount := func() int {
+		t.Helper()
+		infos, err := ioutil.ReadDir(blobsDir())
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		return len(infos)
+	}
+	// checkAttachments opens the database from disk, both with and without
+	// its blobs, and checks the attachment data.
+	checkAttachments := func(inline bool) {
+		t.Helper()
+		odb, err := openDatabase(nil)
+		if err != nil {
+			t.Fatal("openDatabase:", err)
+		}
+		rdb, err := keepass.OpenFile(*dbPath, nil)
+		if err != nil {
+			t.Fatal("keepass.OpenFile:", err)
+		}
+		for _, e := range odb.Entries() {
+			if got := string(e.Attachment.Data); got != attachments[e.Title] {
+				t.Errorf("attachment of %q = %q; want %q", e.Title, got, attachments[e.Title])
+			}
+			if _, ok := e.CustomData.Get(blobKey); ok {
+				t.Errorf("entry %q still refers to a blob after opening", e.Title)
+			}
+		}
+		for _, e := range rdb.Entries() {
+			if got := len(e.Attachment.Data) > 0; got != inline {
+				t.Errorf("attachment of %q in database file: inline = %t; want %t", e.Title, got, inline)
+			}
+		}
+	}
+
+	if err := writeDatabase(db); err != nil {
+		t.Fatal("writeDatabase:", err)
+	}
+	if n := blobCount(); n != 2 {
+		t.Errorf("%d blob files; want 2", n)
+	}
+	if !bytes.Equal(entries["a"].Attachment.Data, []byte("shared")) {
+		t.Error("writeDatabase did not restore attachments in memory")
+	}
+	checkAttachments(false)
+
+	entries["c"].Attachment.Data = []byte("changed")
+	attachments["c"] = "changed"
+	if err := writeDatabase(db); err != nil {
+		t.Fatal("writeDatabase:", err)
+	}
+	if n := blobCount(); n != 2 {
+		t.Errorf("after changing an attachment: %d blob files; want 2", n)
+	}
+	checkAttachments(false)
+
+	*splitAttachments = false
+	if err := writeDatabase(db); err != nil {
+		t.Fatal("writeDatabase:", err)
+	}
+	if n := blobCount(); n != 0 {
+		t.Errorf("without -split_attachments: %d blob files; want 0", n)
+	}
+	checkAttachments(true)
+}
@@ -0,0 +1,328 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"golang.org/x/crypto/pkcs12"
+)
+
+// certExtensions are the attachment names that are looked at for
+// certificates.  Other attachments are arbitrary files and are skipped.
+var certExtensions = map[string]bool{
+	".pem": true,
+	".crt": true,
+	".cer": true,
+	".der": true,
+	".p12": true,
+	".pfx": true,
+}
+
+var errNoCertificates = errors.New("no certificates found")
+
+// errPKCS12Password is returned by parseCertificates when data is a
+// PKCS#12 file that can't be decrypted with the given password.
+var errPKCS12Password = errors.New("wrong PKCS#12 password")
+
+// parseCertificates returns the certificates in data, which may be PEM
+// (with or without private keys), DER or PKCS#12.  PKCS#12 files are
+// decrypted with password.  GOST certificates are parsed, but only the
+// fields common to all algorithms are filled in.
+func parseCertificates(data []byte, password string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	blocks, pemFound := pemCertificates(data)
+	for _, b := range blocks {
+		c, err := x509.ParseCertificate(b.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, c)
+	}
+	if pemFound {
+		if len(certs) == 0 {
+			return nil, errNoCertificates
+		}
+		return certs, nil
+	}
+	if certs, err := x509.ParseCertificates(data); err == nil && len(certs) > 0 {
+		return certs, nil
+	}
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err == pkcs12.ErrIncorrectPassword {
+		return nil, errPKCS12Password
+	} else if err != nil {
+		return nil, errNoCertificates
+	}
+	for _, b := range blocks {
+		if b.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(b.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, c)
+	}
+	if len(certs) == 0 {
+		return nil, errNoCertificates
+	}
+	return certs, nil
+}
+
+// pemCertificates returns the CERTIFICATE blocks in data and whether data
+// has any PEM blocks at all.
+func pemCertificates(data []byte) (certs []*pem.Block, found bool) {
+	for {
+		var b *pem.Block
+		b, data = pem.Decode(data)
+		if b == nil {
+			return certs, found
+		}
+		found = true
+		if b.Type == "CERTIFICATE" {
+			certs = append(certs, b)
+		}
+	}
+}
+
+// entryCertificates returns the certificates stored in e: in an attachment
+// with a certificate file extension, or as PEM in the notes.
+func entryCertificates(e *keepass.Entry) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	if e.HasAttachment() && certExtensions[strings.ToLower(filepath.Ext(e.Attachment.Name))] {
+		c, err := parseCertificates(e.Attachment.Data, e.Password)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", e.Attachment.Name, err)
+		}
+		certs = append(certs, c...)
+	}
+	if strings.Contains(e.Notes, "-----BEGIN CERTIFICATE-----") {
+		c, err := parseCertificates([]byte(e.Notes), "")
+		if err != nil {
+			return nil, fmt.Errorf("notes: %v", err)
+		}
+		certs = append(certs, c...)
+	}
+	return certs, nil
+}
+
+// certSubject returns a short name for c's subject.
+func certSubject(c *x509.Certificate) string {
+	if c.Subject.CommonName != "" {
+		return c.Subject.CommonName
+	}
+	return c.Subject.String()
+}
+
+// An expiringItem is an entry or a certificate in an entry that expires.
+type expiringItem struct {
+	Entry   *keepass.Entry
+	Cert    *x509.Certificate // nil for the entry's own expiry
+	Expires time.Time
+}
+
+// expiringItems returns the entries and certificates in db that expire
+// before cutoff, soonest first.  Already expired ones are included, and a
+// zero cutoff includes everything.
+// Entries in the recycle bin are skipped, and entries whose certificates
+// can't be parsed are passed to warn.
+func expiringItems(db *keepass.Database, cutoff time.Time, warn func(*keepass.Entry, error)) []expiringItem {
+	var items []expiringItem
+	for _, e := range db.Entries() {
+		if e.Parent().InRecycleBin() {
+			continue
+		}
+		if e.Expires() && (cutoff.IsZero() || e.ExpiryTime.Before(cutoff)) {
+			items = append(items, expiringItem{Entry: e, Expires: e.ExpiryTime})
+		}
+		certs, err := entryCertificates(e)
+		if err != nil {
+			warn(e, err)
+			continue
+		}
+		for _, c := range certs {
+			if cutoff.IsZero() || c.NotAfter.Before(cutoff) {
+				items = append(items, expiringItem{Entry: e, Cert: c, Expires: c.NotAfter})
+			}
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Expires.Before(items[j].Expires)
+	})
+	return items
+}
+
+func printCertWarning(e *keepass.Entry, err error) {
+	fmt.Fprintf(stderr, "gostpass: %s: %v\n", entryPath(e), err)
+}
+
+// runExpiring lists entries and certificates that expire within a given
+// time, soonest first.
+func runExpiring(args []string) error {
+	fs := flag.NewFlagSet("expiring", flag.ContinueOnError)
+	within := fs.String("within", "30d", "report what expires within this `age`, like 30d, 8w or 720h")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: expiring [-within age]")
+	}
+	d, err := parseAge(*within)
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	items := expiringItems(db, now.Add(d), printCertWarning)
+	for _, it := range items {
+		expired := it.Expires.Before(now)
+		var what string
+		switch {
+		case it.Cert == nil && expired:
+			what = tr("entry expired")
+		case it.Cert == nil:
+			what = tr("entry expires")
+		case expired:
+			what = fmt.Sprintf(tr("certificate %q expired"), certSubject(it.Cert))
+		default:
+			what = fmt.Sprintf(tr("certificate %q expires"), certSubject(it.Cert))
+		}
+		fmt.Printf("%s  %s  %s\n", it.Expires.Format("2006-01-02"), entryPath(it.Entry), what)
+	}
+	fmt.Printf(tr("%d items expire within %s\n"), len(items), *within)
+	return nil
+}
+
+// runCert stores certificates in entries and lists the stored ones.
+func runCert(args []string) error {
+	const usage = "usage: cert add path file | cert list"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "add":
+		if len(args) != 3 {
+			return errors.New(usage)
+		}
+		return certAdd(args[1], args[2])
+	case "list":
+		if len(args) != 1 {
+			return errors.New(usage)
+		}
+		return certList()
+	default:
+		return errors.New(usage)
+	}
+}
+
+// certAdd attaches a certificate file to the entry at path, creating the
+// entry if necessary.  The entry's password is used as the PKCS#12
+// password; if it doesn't fit, the password is asked for and stored.
+func certAdd(path, file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	e, err := findEntryPath(db, path)
+	if _, ok := err.(pathNotFoundError); ok {
+		dir, title := splitItemPath(path)
+		if title == "" {
+			return err
+		}
+		g, err := db.MkdirAll(dir)
+		if err != nil {
+			return err
+		}
+		if e, err = g.NewEntry(); err != nil {
+			return err
+		}
+		e.Title = title
+		e.CreationTime = now
+	} else if err != nil {
+		return err
+	} else if e.HasAttachment() && !certExtensions[strings.ToLower(filepath.Ext(e.Attachment.Name))] {
+		return fmt.Errorf("%s: already has attachment %q", path, e.Attachment.Name)
+	} else {
+		e.AddRevision()
+	}
+	password := e.Password
+	certs, err := parseCertificates(data, password)
+	if err == errPKCS12Password {
+		if password, err = promptPassword(tr("PKCS#12 password: ")); err != nil {
+			return err
+		}
+		certs, err = parseCertificates(data, password)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	name := filepath.Base(file)
+	if !certExtensions[strings.ToLower(filepath.Ext(name))] {
+		name += ".pem"
+		if !bytes.Contains(data, []byte("-----BEGIN")) {
+			name = strings.TrimSuffix(name, ".pem") + ".der"
+		}
+	}
+	e.Attachment.Name = name
+	e.Attachment.Data = data
+	e.Password = password
+	e.LastModificationTime = now
+	if err := writeDatabase(db); err != nil {
+		return err
+	}
+	for _, c := range certs {
+		fmt.Printf(tr("%s  %q  expires %s\n"), entryPath(e), certSubject(c), c.NotAfter.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// certList prints every stored certificate with its subject, issuer and
+// validity, by expiry.
+func certList() error {
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	items := expiringItems(db, time.Time{}, printCertWarning)
+	for _, it := range items {
+		if it.Cert == nil {
+			continue
+		}
+		c := it.Cert
+		fmt.Printf("%s  %s  subject %q  issuer %q  valid from %s\n",
+			c.NotAfter.Format("2006-01-02"), entryPath(it.Entry), c.Subject, c.Issuer, c.NotBefore.Format("2006-01-02"))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// testCertificate returns a self-signed certificate for name that expires
+// at notAfter, DER-encoded.
+func testCertificate(t *testing.T, name string, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestParseCertificates(t *testing.T) {
+	notAfter := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	der := testCertificate(t, "example.com", notAfter)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("not a real key")})
+
+	tests := []struct {
+		name string
+		data []byte
+		n    int
+		err  error
+	}{
+		{"DER", der, 1, nil},
+		{"PEM", certPEM, 1, nil},
+		{"PEM bundle with key", append(append(append([]byte{}, keyPEM...), certPEM...), certPEM...), 2, nil},
+		{"key only", keyPEM, 0, errNoCertificates},
+		{"garbage", []byte("hello"), 0, errNoCertificates},
+	}
+	for _, test := range tests {
+		certs, err := parseCertificates(test.data, "")
+		if err != test.err || len(certs) != test.n {
+			t.Errorf("parseCertificates(%s) = %d certificates, %v; want %d, %v", test.name, len(certs), err, test.n, test.err)
+			continue
+		}
+		for _, c := range certs {
+			if certSubject(c) != "example.com" || !c.NotAfter.Equal(notAfter) {
+				t.Errorf("parseCertificates(%s) subject, notAfter = %q, %v; want %q, %v", test.name, certSubject(c), c.NotAfter, "example.com", notAfter)
+			}
+		}
+	}
+}
+
+func TestExpiringItems(t *testing.T) {
+	now := time.Now()
+	db, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newEntry := func(title string) *keepass.Entry {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title = title
+		return e
+	}
+	soon := newEntry("soon")
+	soon.Attachment.Name = "soon.der"
+	soon.Attachment.Data = testCertificate(t, "soon.example.com", now.AddDate(0, 0, 10))
+	later := newEntry("later")
+	later.Notes = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: testCertificate(t, "later.example.com", now.AddDate(1, 0, 0))}))
+	account := newEntry("account")
+	account.ExpiryTime = now.AddDate(0, 0, -1)
+	other := newEntry("other")
+	other.Attachment.Name = "photo.jpg"
+	other.Attachment.Data = testCertificate(t, "ignored.example.com", now)
+	broken := newEntry("broken")
+	broken.Attachment.Name = "broken.pem"
+	broken.Attachment.Data = []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n")
+
+	var warned []string
+	items := expiringItems(db, now.AddDate(0, 1, 0), func(e *keepass.Entry, err error) {
+		warned = append(warned, e.Title)
+	})
+	var got []string
+	for _, it := range items {
+		name := it.Entry.Title
+		if it.Cert != nil {
+			name = certSubject(it.Cert)
+		}
+		got = append(got, name)
+	}
+	want := []string{"account", "soon.example.com"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("expiringItems within a month = %q; want %q", got, want)
+	}
+	if len(warned) != 1 || warned[0] != "broken" {
+		t.Errorf("warned about %q; want [\"broken\"]", warned)
+	}
+
+	if items := expiringItems(db, time.Time{}, func(*keepass.Entry, error) {}); len(items) != 3 {
+		t.Errorf("expiringItems with no cutoff = %d items; want 3", len(items))
+	}
+}
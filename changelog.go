@@ -0,0 +1,263 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/changelog"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/sandstormhdr"
+)
+
+var changeLogPath = flag.String("change_log", "", "path to an encrypted, hash-chained log of changes to the database; if empty, changes are not logged")
+
+// The log key lives in the database, so that only those who can open the
+// database can read the log, and it survives changes of the database key.
+// The head of the log is kept next to it to detect truncation.
+const (
+	changeLogKeyKey  = "gostpass.change_log_key"
+	changeLogHeadKey = "gostpass.change_log_head"
+)
+
+// commandAuthor names the user running a command in the change log.
+func commandAuthor() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// requestAuthor names the user making a web request in the change log.
+func requestAuthor(r *http.Request) string {
+	if u := sandstormhdr.GetUser(r.Header); u != nil {
+		return fmt.Sprintf("%s (%s)", u.Name, u.ID)
+	}
+	return "web client " + r.RemoteAddr
+}
+
+// dbChangeLog returns the change log keyed by db and its current head.  If
+// create is set, a key is generated for databases without one.
+func dbChangeLog(db *keepass.Database, create bool) (*changelog.Log, changelog.Head, error) {
+	cd := db.CustomData()
+	v, ok := cd.Get(changeLogKeyKey)
+	if !ok && !create {
+		return nil, changelog.Head{}, errors.New("the database has no change log")
+	}
+	var key []byte
+	if ok {
+		var err error
+		if key, err = hex.DecodeString(v); err != nil {
+			return nil, changelog.Head{}, fmt.Errorf("change log key: %v", err)
+		}
+	} else {
+		key = make([]byte, changelog.KeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, changelog.Head{}, err
+		}
+		cd.Set(changeLogKeyKey, hex.EncodeToString(key))
+	}
+	l, err := changelog.New(key)
+	if err != nil {
+		return nil, changelog.Head{}, err
+	}
+	var head changelog.Head
+	if v, ok := cd.Get(changeLogHeadKey); ok {
+		if head, err = changelog.ParseHead(v); err != nil {
+			return nil, changelog.Head{}, err
+		}
+	}
+	return l, head, nil
+}
+
+// recordChanges appends what changed between the database stored in st
+// and db to the change log, and stores the new head in db.  It must be
+// called before db is written.
+func recordChanges(st *storage, db *keepass.Database, author string) error {
+	l, head, err := dbChangeLog(db, true)
+	if err != nil {
+		return fmt.Errorf("change log: %v", err)
+	}
+	changes := []string{"created the database"}
+	if st.exists() {
+		r, err := st.reader()
+		if err != nil {
+			return fmt.Errorf("change log: %v", err)
+		}
+		if old, err := keepass.Open(r, &keepass.Options{ComputedKey: db.ComputedKey()}); err != nil {
+			changes = []string{"changed the database key"}
+		} else if err := joinBlobs(old); err != nil {
+			return fmt.Errorf("change log: %v", err)
+		} else {
+			changes = diffDatabases(old, db)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(*changeLogPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("change log: %v", err)
+	}
+	head, err = l.Append(f, rand.Reader, head, &changelog.Record{
+		Time:    time.Now(),
+		Author:  author,
+		Changes: changes,
+	})
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("change log: %v", cerr)
+	}
+	if err != nil {
+		return err
+	}
+	db.CustomData().Set(changeLogHeadKey, head.String())
+	return nil
+}
+
+// groupPaths returns the paths of all groups under g, keyed by ID.
+func groupPaths(g *keepass.Group, paths map[uint32]string) map[uint32]string {
+	for _, sub := range g.Groups() {
+		paths[sub.ID] = sub.Path()
+		groupPaths(sub, paths)
+	}
+	return paths
+}
+
+// diffDatabases describes the changes from before to after.  Secrets are never
+// included, only the names of the fields that changed.
+func diffDatabases(before, after *keepass.Database) []string {
+	var groupChanges []string
+	oldGroups := groupPaths(before.Root(), make(map[uint32]string))
+	newGroups := groupPaths(after.Root(), make(map[uint32]string))
+	for id, path := range newGroups {
+		if oldPath, ok := oldGroups[id]; !ok {
+			groupChanges = append(groupChanges, "added group "+path)
+		} else if oldPath != path {
+			groupChanges = append(groupChanges, fmt.Sprintf("moved group %s to %s", oldPath, path))
+		}
+	}
+	for id, path := range oldGroups {
+		if _, ok := newGroups[id]; !ok {
+			groupChanges = append(groupChanges, "deleted group "+path)
+		}
+	}
+	sort.Strings(groupChanges)
+
+	changes := groupChanges
+	for _, e := range after.Entries() {
+		o := before.Find(e.UUID)
+		if o == nil {
+			changes = append(changes, "added entry "+entryPath(e))
+			continue
+		}
+		if oldPath, path := entryPath(o), entryPath(e); oldPath != path {
+			changes = append(changes, fmt.Sprintf("moved entry %s to %s", oldPath, path))
+		}
+		if fields := changedFields(o, e); len(fields) > 0 {
+			changes = append(changes, fmt.Sprintf("changed entry %s: %s", entryPath(e), strings.Join(fields, ", ")))
+		}
+	}
+	for _, o := range before.Entries() {
+		if after.Find(o.UUID) == nil {
+			changes = append(changes, "deleted entry "+entryPath(o))
+		}
+	}
+	return changes
+}
+
+func changedFields(before, after *keepass.Entry) []string {
+	var fields []string
+	for _, name := range sortedEntryFields() {
+		if name == "title" || name == "uuid" {
+			// A new title shows up as a move.
+			continue
+		}
+		if get := entryFields[name]; get(before) != get(after) {
+			fields = append(fields, name)
+		}
+	}
+	if before.Attachment.Name != after.Attachment.Name || !bytes.Equal(before.Attachment.Data, after.Attachment.Data) {
+		fields = append(fields, "attachment")
+	}
+	if !before.ExpiryTime.Equal(after.ExpiryTime) {
+		fields = append(fields, "expiry")
+	}
+	return fields
+}
+
+// runLogChanges prints the change log, checking it on the way.
+func runLogChanges(args []string, verifyOnly bool) error {
+	name := "log changes"
+	if verifyOnly {
+		name = "log verify"
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: " + name)
+	}
+	if *changeLogPath == "" {
+		return errors.New("no change log; give its path with -change_log")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	l, want, err := dbChangeLog(db, false)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(*changeLogPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	recs, head, err := l.Read(f)
+	if !verifyOnly {
+		for _, rec := range recs {
+			for _, c := range rec.Changes {
+				fmt.Printf("%s  %s  %s\n", rec.Time.Local().Format("2006-01-02 15:04:05"), rec.Author, c)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", *changeLogPath, err)
+	}
+	switch {
+	case head.Seq < want.Seq:
+		return fmt.Errorf(tr("%s: %d records, but the database expects %d; the log was truncated"), *changeLogPath, head.Seq, want.Seq)
+	case head.Seq > want.Seq:
+		return fmt.Errorf(tr("%s: %d records, but the database expects %d; a save failed or the database was rolled back"), *changeLogPath, head.Seq, want.Seq)
+	case !head.Equal(want):
+		return fmt.Errorf(tr("%s: the last record does not match the database"), *changeLogPath)
+	}
+	if verifyOnly {
+		fmt.Printf(tr("%d records, chain intact\n"), head.Seq)
+	}
+	return nil
+}
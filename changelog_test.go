@@ -0,0 +1,99 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestChangeLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_changelog_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldPath, oldLog := *dbPath, *changeLogPath
+	*dbPath = filepath.Join(dir, "vault.kdb")
+	*changeLogPath = filepath.Join(dir, "vault.changes")
+	defer func() { *dbPath, *changeLogPath = oldPath, oldLog }()
+	if err := initDatabase(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := g.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Title = "DB"
+	e.Password = "hunter2"
+	if err := writeStorage(dbStorage, db, "alice"); err != nil {
+		t.Fatal("writeStorage:", err)
+	}
+
+	e.Password = "correct horse"
+	e.Notes = "rotated"
+	if _, err := db.MkdirAll("Old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeStorage(dbStorage, db, "bob"); err != nil {
+		t.Fatal("writeStorage:", err)
+	}
+	// Saving without changes adds no record.
+	if err := writeStorage(dbStorage, db, "bob"); err != nil {
+		t.Fatal("writeStorage:", err)
+	}
+
+	l, head, err := dbChangeLog(db, false)
+	if err != nil {
+		t.Fatal("dbChangeLog:", err)
+	}
+	f, err := os.Open(*changeLogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	recs, got, err := l.Read(f)
+	if err != nil {
+		t.Fatal("Read:", err)
+	}
+	if !got.Equal(head) {
+		t.Errorf("log head = %v; database head = %v", got, head)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("log has %d records; want 2", len(recs))
+	}
+	if recs[0].Author != "alice" || !reflect.DeepEqual(recs[0].Changes, []string{"created the database"}) {
+		t.Errorf("record 1 = %+v", recs[0])
+	}
+	want := []string{"added group Old", "changed entry Work/DB: notes, password"}
+	if recs[1].Author != "bob" || !reflect.DeepEqual(recs[1].Changes, want) {
+		t.Errorf("record 2 = %+v; want changes by bob: %q", recs[1], want)
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+var (
+	keyFilePath  = flag.String("keyfile", "", "path to key file, for commands")
+	passwordFile = flag.String("password_file", "", "path to file whose first line is the database password, for commands")
+	passwordCred = flag.String("password_credential", "", "name of the systemd credential holding the database password, for commands run by systemd with LoadCredential=")
+)
+
+// stdinProtocol is set by commands that speak a protocol on standard
+// input, so that the password is read from the terminal instead.
+var stdinProtocol bool
+
+// stdin is shared by everything that reads standard input, so that
+// buffered input isn't lost between reads.
+var stdin = bufio.NewReader(os.Stdin)
+
+// A command is run instead of the server when its name is given as the
+// first argument, like "gostpass -db vault.kdb verify".
+type command struct {
+	run   func(args []string) error
+	usage string
+}
+
+var commands = map[string]command{
+	"ansible-vault-client": {runAnsibleVaultClient, "print an Ansible Vault password for --vault-id"},
+	"audit":                {runAudit, "report breached and reused passwords"},
+	"backup":               {runBackup, "back up the database now, list backups or restore one"},
+	"cert":                 {runCert, "attach certificates to entries and list them"},
+	"clone":                {runClone, "copy an entry with some fields changed, like a variant for staging"},
+	"confirm-release":      {runConfirmRelease, "ask before releasing entries' secrets to other programs"},
+	"dedup":                {runDedup, "merge entries with identical fields"},
+	"docker-credential":    {runDockerCredential, "Docker credential helper backed by the database"},
+	"escrow":               {runEscrow, "let a recovery key holder unlock the database, or stop them"},
+	"eval":                 {runEval, "print the result of an expression over the entries, like a query"},
+	"exec":                 {runExec, "run a command with an entry's fields in its environment"},
+	"expiring":             {runExpiring, "list entries and certificates that expire soon"},
+	"export":               {runExport, "write a copy under another password, or a paper backup"},
+	"find":                 {runFind, "list entries that apply to a URL or contain words"},
+	"git-credential":       {runGitCredential, "git credential helper backed by the database"},
+	"hibp":                 {runHIBP, "build a filter from a breach corpus for audit -breaches"},
+	"history":              {runHistory, "list, compare or restore an entry's revisions"},
+	"init":                 {runInit, "create the database, optionally from a template of groups and entries"},
+	"keyfile":              {runKeyfile, "replace the -keyfile with a new one and erase the old one"},
+	"log":                  {runLog, "show secrets released to other programs, or changes with -change_log"},
+	"lookup":               {runLookup, "print entries as JSON, for Ansible lookups and scripts"},
+	"menu":                 {runMenu, "choose an entry in rofi, wofi or dmenu, and type or copy it"},
+	"mkdir":                {runMkdir, "create groups by path, like Work/VPN"},
+	"mv":                   {runMv, "move or rename a group or entry"},
+	"open":                 {runOpen, "show the entry a kdbx: link points to, or register as the link handler"},
+	"pick":                 {runPick, "list entries for fzf or rofi, and print a field of the chosen one"},
+	"plugin":               {runPlugin, "list plugins, or import a file with one"},
+	"quickunlock":          {runQuickUnlock, "keep the database key in the system keyring, or remove it"},
+	"recover-key":          {runRecoverKey, "open the database with key shares from split-key and set a new password"},
+	"regen":                {runRegen, "replace the passwords of matching entries and report the new ones"},
+	"rekey":                {runRekey, "change the database's password or key derivation strength"},
+	"render":               {runRender, "fill in a config file template with entry fields"},
+	"restore-paper":        {runRestorePaper, "turn a typed or scanned paper backup back into a database file"},
+	"rotate":               {runRotate, "replace passwords older than a given age"},
+	"rm":                   {runRm, "move groups or entries to the recycle bin, or delete them from it"},
+	"secret-ref":           {runSecretRef, "point an entry's password at an env var, file, command or keyring"},
+	"secrets-server":       {runSecretsServer, "serve entries to Kubernetes secret operators over HTTP"},
+	"share":                {runShare, "make a link that reveals an entry's password a limited number of times"},
+	"share-file":           {runShareFile, "write one entry to a database file of its own, under another password"},
+	"show":                 {runShow, "print an entry or group with its notes rendered as Markdown"},
+	"sign-keygen":          {runSignKeygen, "create a key pair for -sign_key and -verify_key"},
+	"split-key":            {runSplitKey, "split the database key into shares, some of which can recover it"},
+	"ssh":                  {runSSH, "store SSH keys in entries and load them into an ssh-agent"},
+	"sync":                 {runSync, "merge changed entries with a copy of the database on another host"},
+	"systemd-cred":         {runSystemdCred, "print one field of an entry exactly, for systemd services"},
+	"token":                {runToken, "create, list or revoke tokens for secrets-server"},
+	"tui":                  {runTUI, "browse groups and entries in the terminal"},
+	"wifi-qr":              {runWiFiQR, "show a QR code that joins a Wi-Fi entry's network, or write an NFC tag"},
+	"verify":               {runVerify, "check the database for damage without repairing it"},
+}
+
+// noDBCommands don't need -db.
+var noDBCommands = map[string]bool{
+	"hibp":          true,
+	"open":          true,
+	"restore-paper": true,
+	"sign-keygen":   true,
+}
+
+// An exitError is returned by a command that finished, but whose result
+// calls for an exit code other than 1.  If err is nil, the command has
+// already reported the error.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e exitError) Error() string {
+	return e.err.Error()
+}
+
+// runCommand runs the command named by args[0] and returns the process
+// exit code.
+func runCommand(args []string) int {
+	if err := initLanguage(); err != nil {
+		fmt.Fprintf(stderr, "gostpass: %v\n", err)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, tr("gostpass: unknown command %q\n"), args[0])
+		commandUsage()
+		return 2
+	}
+	if *dbPath == "" && !noDBCommands[args[0]] {
+		fmt.Fprintln(os.Stderr, tr("gostpass: must specify -db"))
+		return 2
+	}
+	hookCommand = args[0]
+	if err := cmd.run(args[1:]); err != nil {
+		e, ok := err.(exitError)
+		if !ok || e.err != nil {
+			fmt.Fprintf(stderr, "gostpass %s: %v\n", args[0], err)
+		}
+		if ok {
+			return e.code
+		}
+		return 1
+	}
+	return 0
+}
+
+func commandUsage() {
+	names := make([]string, 0, len(commands))
+	width := 0
+	for name := range commands {
+		names = append(names, name)
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, tr("commands:"))
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-*s  %s\n", width, name, tr(commands[name].usage))
+	}
+}
+
+// commandOptions returns the options to open the database with, prompting
+// for the password with the -prompt backend.  On the terminal, if standard
+// input is not one, the password is its first line.  Either way,
+// -password_file and -password_credential take precedence.
+func commandOptions() (*keepass.Options, error) {
+	var password string
+	var err error
+	if *keyPlugin != "" {
+		password, err = pluginPassword()
+	} else {
+		password, err = readPassword(tr("Password: "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	opts := flagOptions(&keepass.Options{Password: password})
+	if *keyFilePath != "" {
+		kf, err := ioutil.ReadFile(*keyFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("read key file: %v", err)
+		}
+		opts.KeyFile = bytes.NewReader(kf)
+	}
+	return opts, nil
+}
+
+func readPassword(prompt string) (string, error) {
+	path := *passwordFile
+	if *passwordCred != "" {
+		dir := os.Getenv("CREDENTIALS_DIRECTORY")
+		if dir == "" {
+			return "", errors.New(tr("-password_credential given, but CREDENTIALS_DIRECTORY is not set"))
+		}
+		path = filepath.Join(dir, *passwordCred)
+	}
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read password: %v", err)
+		}
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[:i]
+		}
+		password := strings.TrimRight(string(data), "\r")
+		secrets.Add(password)
+		return password, nil
+	}
+	return promptPassword(prompt)
+}
+
+// promptPassword asks for a password with the -prompt backend.
+func promptPassword(prompt string) (string, error) {
+	p, err := currentPrompter()
+	if err != nil {
+		return "", err
+	}
+	password, err := p.Password(prompt)
+	secrets.Add(password)
+	return password, err
+}
+
+// openCommandDatabase opens the -db database for a command that modifies
+// it.  Changes are saved with writeDatabase.
+func openCommandDatabase() (*keepass.Database, error) {
+	if err := initDatabase(); err != nil {
+		return nil, err
+	}
+	if db := quickUnlock(); db != nil {
+		runNotifyHook("post-unlock", *postUnlockHook)
+		return db, nil
+	}
+	opts, err := commandOptions()
+	if err != nil {
+		return nil, err
+	}
+	db, err := openDatabase(opts)
+	if err != nil {
+		return nil, err
+	}
+	runNotifyHook("post-unlock", *postUnlockHook)
+	return db, nil
+}
+
+// confirm asks a yes/no question on standard input.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, tr("%s [y/N] "), question)
+	line, _ := stdin.ReadString('\n')
+	return isYes(line)
+}
+
+func isYes(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "д", "да":
+		return true
+	default:
+		return false
+	}
+}
+
+// runVerify reports every problem found in the database and fails if
+// there are any.
+func runVerify(args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: verify")
+	}
+	opts, err := commandOptions()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	problems, err := keepass.Verify(f, opts)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf(tr("%s: %d problems found"), *dbPath, len(problems))
+	}
+	fmt.Printf(tr("%s: no problems found\n"), *dbPath)
+	return nil
+}
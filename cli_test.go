@@ -0,0 +1,91 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newCommandTestDB points -db and -password_file into a new temporary
+// directory, with the password "swordfish", and creates the database from
+// the vault template text unless it is empty.  It returns the directory
+// and a function that restores the flags and removes the directory.
+func newCommandTestDB(t *testing.T, template string) (dir string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "gostpass_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldDB, oldPassword := *dbPath, *passwordFile
+	cleanup = func() {
+		*dbPath, *passwordFile = oldDB, oldPassword
+		os.RemoveAll(dir)
+	}
+	*dbPath = filepath.Join(dir, "vault.kdb")
+	*passwordFile = filepath.Join(dir, "password")
+	if err := ioutil.WriteFile(*passwordFile, []byte("swordfish\n"), 0600); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	if template == "" {
+		return dir, cleanup
+	}
+	templatePath := filepath.Join(dir, "template.json")
+	if err := ioutil.WriteFile(templatePath, []byte(template), 0600); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	if err := runInit([]string{"-template", templatePath}); err != nil {
+		cleanup()
+		t.Fatal("init:", err)
+	}
+	return dir, cleanup
+}
+
+func TestReadPasswordFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_cli_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "master"), []byte("swordfish\r\nignored\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	oldFile, oldCred, oldDir := *passwordFile, *passwordCred, os.Getenv("CREDENTIALS_DIRECTORY")
+	defer func() {
+		*passwordFile, *passwordCred = oldFile, oldCred
+		os.Setenv("CREDENTIALS_DIRECTORY", oldDir)
+	}()
+
+	*passwordFile, *passwordCred = filepath.Join(dir, "master"), ""
+	if pw, err := readPassword(""); err != nil || pw != "swordfish" {
+		t.Errorf("readPassword with -password_file = %q, %v; want \"swordfish\", <nil>", pw, err)
+	}
+	if got := secrets.Scrub("open: wrong password swordfish"); got != "open: wrong password [redacted]" {
+		t.Errorf("after readPassword, secrets.Scrub(...) = %q; want password redacted", got)
+	}
+
+	*passwordFile, *passwordCred = "", "master"
+	os.Setenv("CREDENTIALS_DIRECTORY", dir)
+	if pw, err := readPassword(""); err != nil || pw != "swordfish" {
+		t.Errorf("readPassword with -password_credential = %q, %v; want \"swordfish\", <nil>", pw, err)
+	}
+	os.Setenv("CREDENTIALS_DIRECTORY", "")
+	if _, err := readPassword(""); err == nil {
+		t.Error("readPassword with -password_credential outside systemd succeeded")
+	}
+}
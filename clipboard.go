@@ -0,0 +1,127 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// clipboardCommands lists the programs tried, in order, to set the
+// clipboard on each system.
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"windows": {{"clip"}},
+	"linux": {
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	},
+}
+
+// pasteCommands lists the programs tried, in order, to read the clipboard.
+var pasteCommands = map[string][][]string{
+	"darwin":  {{"pbpaste"}},
+	"windows": {{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"}},
+	"linux": {
+		{"wl-paste", "-n"},
+		{"xclip", "-selection", "clipboard", "-o"},
+		{"xsel", "--clipboard", "--output"},
+	},
+}
+
+// typeCommands lists the programs tried, in order, to type into the
+// focused window in X11 and Wayland sessions.  They all read the text from
+// standard input, so that it doesn't show up in the process list.
+var typeCommands = map[string][][]string{
+	"wayland": {
+		{"wtype", "-"},
+		{"ydotool", "type", "--file", "-"},
+	},
+	"x11": {{"xdotool", "type", "--clearmodifiers", "--file", "-"}},
+}
+
+func systemCommands(cmds map[string][][]string) [][]string {
+	if c := cmds[runtime.GOOS]; c != nil {
+		return c
+	}
+	return cmds["linux"]
+}
+
+// pipeToCommand runs the first available program in cmds with s as its
+// standard input.  what names the purpose in errors.
+func pipeToCommand(what string, cmds [][]string, s string) error {
+	for _, argv := range cmds {
+		path, err := exec.LookPath(argv[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, argv[1:]...)
+		cmd.Stdin = strings.NewReader(s)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %s: %v: %s", what, argv[0], err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: no %s program found", what, what)
+}
+
+// copyToClipboard puts s on the system clipboard using the first available
+// clipboard program.
+func copyToClipboard(s string) error {
+	return pipeToCommand("clipboard", systemCommands(clipboardCommands), s)
+}
+
+// readClipboard returns the contents of the system clipboard.
+func readClipboard() (string, error) {
+	for _, argv := range systemCommands(pasteCommands) {
+		path, err := exec.LookPath(argv[0])
+		if err != nil {
+			continue
+		}
+		out, err := exec.Command(path, argv[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("clipboard: %s: %v", argv[0], err)
+		}
+		return string(out), nil
+	}
+	return "", errors.New("clipboard: no paste program found")
+}
+
+// clearClipboardAfter waits for d and then clears the clipboard, unless
+// something other than s was copied meanwhile.  If the clipboard can't be
+// read, it is cleared anyway.
+func clearClipboardAfter(s string, d time.Duration) error {
+	time.Sleep(d)
+	if current, err := readClipboard(); err == nil && current != s {
+		return nil
+	}
+	return copyToClipboard("")
+}
+
+// typeText types s into the focused window as if on the keyboard, with
+// tabs and newlines as the Tab and Enter keys.
+func typeText(s string) error {
+	session := "x11"
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		session = "wayland"
+	}
+	return pipeToCommand("auto-type", typeCommands[session], s)
+}
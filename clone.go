@@ -0,0 +1,128 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// cloneSetters are the entry fields that clone -set assigns directly.
+// Other names are stored as fields.
+var cloneSetters = map[string]func(*keepass.Entry, string){
+	"title":    func(e *keepass.Entry, v string) { e.Title = v },
+	"username": func(e *keepass.Entry, v string) { e.Username = v },
+	"password": func(e *keepass.Entry, v string) { e.Password = v },
+	"url":      func(e *keepass.Entry, v string) { e.URL = v },
+	"notes":    func(e *keepass.Entry, v string) { e.Notes = v },
+}
+
+// setEntryField sets the named field of e.  A field that e already has
+// keeps its protection.
+func setEntryField(e *keepass.Entry, name, value string) error {
+	if set := cloneSetters[name]; set != nil {
+		set(e, value)
+		if name == "password" {
+			e.CustomData.Delete(secretRefKey)
+		}
+		return nil
+	}
+	old, _, err := e.Field(name)
+	if err != nil {
+		return err
+	}
+	return e.SetField(keepass.Field{Name: name, Value: value, Protected: old.Protected})
+}
+
+// runClone copies an entry, changing some of its fields, to make variants
+// of a credential for other environments or accounts.
+func runClone(args []string) error {
+	fs := flag.NewFlagSet("clone", flag.ContinueOnError)
+	var sets stringList
+	fs.Var(&sets, "set", "set `name=value` in the clone: title, username, password, url, notes or any field; may be repeated")
+	to := fs.String("to", "", "put the clone in the group at `path` instead of the source's group")
+	newPassword := fs.Bool("new-password", false, "generate a new password for the clone")
+	policyText := fs.String("policy", "20-mixed", "generate the new password as LENGTH-CLASS, as in regen")
+	keepHistory := fs.Bool("keep-history", false, "copy the source's password history")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: clone [-to group] [-set name=value]... [-new-password [-policy LENGTH-CLASS]] [-keep-history] entry")
+	}
+	for _, s := range sets {
+		if i := strings.IndexByte(s, '='); i <= 0 {
+			return fmt.Errorf("-set %q: want name=value", s)
+		} else if name := s[:i]; name == "uuid" || name == "password" && *newPassword {
+			return fmt.Errorf("-set %q: %s can't be set here", s, name)
+		}
+	}
+	policy, err := parsePasswordPolicy(*policyText)
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	src, err := findEntryPath(db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	dst := src.Parent()
+	if *to != "" {
+		if dst = db.FindGroupPath(*to); dst == nil {
+			return fmt.Errorf("%s: no such group", *to)
+		}
+	}
+	// Cloning only copies secrets within the database, so src isn't
+	// released.
+	e, err := db.CloneEntry(src, dst, keepass.CloneOptions{KeepHistory: *keepHistory})
+	if err != nil {
+		return err
+	}
+	for _, s := range sets {
+		i := strings.IndexByte(s, '=')
+		if err := setEntryField(e, s[:i], s[i+1:]); err != nil {
+			return fmt.Errorf("-set %s: %v", s[:i], err)
+		}
+	}
+	if *newPassword {
+		password, err := policy.generate()
+		if err != nil {
+			return err
+		}
+		setEntryField(e, "password", password)
+	}
+	for _, other := range dst.Entries() {
+		if other != e && other.Title == e.Title {
+			return fmt.Errorf("%s already exists; -set title= or use -to", entryPath(e))
+		}
+	}
+	now := time.Now()
+	e.CreationTime = now
+	e.LastModificationTime = now
+	e.LastAccessTime = now
+	if err := writeDatabase(db); err != nil {
+		return err
+	}
+	fmt.Printf(tr("%s: cloned to %s\n"), entryPath(src), entryPath(e))
+	return nil
+}
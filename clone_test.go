@@ -0,0 +1,56 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	_, cleanup := newCommandTestDB(t, `{"key_rounds": 1, "groups": ["Staging"], "entries": [{"path": "Prod/DB", "username": "alice", "url": "db.example.com"}]}`)
+	defer cleanup()
+	if code := runCommand([]string{"clone", "Prod/DB"}); code == 0 {
+		t.Error("clone into the same group with the same title succeeded")
+	}
+	args := []string{"clone", "-to", "Staging", "-set", "username=bob", "-set", "region=eu", "-new-password", "-policy", "16-hex", "Prod/DB"}
+	if code := runCommand(args); code != 0 {
+		t.Fatalf("clone exited with %d", code)
+	}
+
+	db, err := openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := findEntryPath(db, "Prod/DB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := findEntryPath(db, "Staging/DB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.UUID == src.UUID || e.Username != "bob" || e.URL != "db.example.com" || len(e.Password) != 16 {
+		t.Errorf("clone = %v user %q url %q password %q; want new UUID, bob, db.example.com, 16 characters", e.UUID, e.Username, e.URL, e.Password)
+	}
+	if f, _, err := e.Field("region"); err != nil || f.Value != "eu" {
+		t.Errorf("clone Field(region) = %q, %v; want eu", f.Value, err)
+	}
+	if src.Username != "alice" || src.Password != "" {
+		t.Errorf("source changed to user %q password %q", src.Username, src.Password)
+	}
+	if len(db.Entries()) != 2 {
+		t.Errorf("%d entries; want 2", len(db.Entries()))
+	}
+}
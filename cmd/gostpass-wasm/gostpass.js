@@ -0,0 +1,105 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// gostpass.js wraps gostpass.wasm in a small synchronous API, once the
+// module has loaded.  It needs wasm_exec.js from the Go distribution
+// ($(go env GOROOT)/misc/wasm or lib/wasm) to be loaded first.
+//
+//	const gp = await GostPass.load("gostpass.wasm");
+//	const vault = gp.open(bytes, "swordfish");
+//	for (const e of vault.search("mail")) {
+//	  console.log(e.title, vault.readEntry(e.uuid).password);
+//	}
+//	vault.close();
+(function(global) {
+  "use strict";
+
+  function toBase64(data) {
+    if (data instanceof ArrayBuffer) {
+      data = new Uint8Array(data);
+    }
+    let s = "";
+    const chunk = 0x8000;
+    for (let i = 0; i < data.length; i += chunk) {
+      s += String.fromCharCode.apply(null, data.subarray(i, i + chunk));
+    }
+    return btoa(s);
+  }
+
+  function fromBase64(s) {
+    const bin = atob(s);
+    const data = new Uint8Array(bin.length);
+    for (let i = 0; i < bin.length; i++) {
+      data[i] = bin.charCodeAt(i);
+    }
+    return data;
+  }
+
+  function check(result) {
+    if (result.error) {
+      throw new Error("gostpass: " + result.error);
+    }
+    return result;
+  }
+
+  class Vault {
+    constructor(go, handle) {
+      this._go = go;
+      this._handle = handle;
+    }
+
+    // search returns summaries ({uuid, title, username, url, group}) of the
+    // entries matching every word of query.
+    search(query) {
+      return check(this._go.search(this._handle, query || "")).entries;
+    }
+
+    // readEntry returns the full entry, including its password, notes and
+    // attachment (as a Uint8Array).
+    readEntry(uuid) {
+      const entry = check(this._go.readEntry(this._handle, uuid)).entry;
+      if (entry.attachment !== undefined) {
+        entry.attachment = fromBase64(entry.attachment);
+      }
+      return entry;
+    }
+
+    // close releases the decrypted database.
+    close() {
+      this._go.close(this._handle);
+      this._handle = 0;
+    }
+  }
+
+  const GostPass = {
+    // load fetches and starts the WebAssembly module at url.
+    async load(url) {
+      const go = new Go();
+      const source = await WebAssembly.instantiateStreaming(fetch(url), go.importObject);
+      go.run(source.instance);
+      const api = global.gostpassGo;
+      return {
+        // open decrypts a database given as an ArrayBuffer or Uint8Array.
+        // keyFile is optional.
+        open(db, password, keyFile) {
+          const kf = keyFile ? toBase64(keyFile) : "";
+          const handle = check(api.open(toBase64(db), password || "", kf)).handle;
+          return new Vault(api, handle);
+        },
+      };
+    },
+  };
+
+  global.GostPass = GostPass;
+})(typeof globalThis !== "undefined" ? globalThis : self);
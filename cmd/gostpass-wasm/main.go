@@ -0,0 +1,181 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js && wasm
+// +build js,wasm
+
+// Command gostpass-wasm exposes read-only access to GOST KeePass databases
+// to JavaScript, so a browser can decrypt a vault client-side.  Build it
+// with:
+//
+//	GOOS=js GOARCH=wasm go build -o gostpass.wasm ./cmd/gostpass-wasm
+//
+// and load it with gostpass.js, which wraps the raw functions below.
+//
+// The program registers a global "gostpassGo" object with the functions
+//
+//	open(dbBase64, password, keyFileBase64) -> {handle} or {error}
+//	search(handle, query)                   -> {entries} or {error}
+//	readEntry(handle, uuid)                 -> {entry} or {error}
+//	close(handle)
+//
+// Binary data crosses the boundary base64-encoded.
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"syscall/js"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/uuids"
+)
+
+var (
+	dbs        = make(map[int]*keepass.Database)
+	nextHandle = 1
+)
+
+func main() {
+	funcs := map[string]func(args []js.Value) (map[string]interface{}, error){
+		"open":      open,
+		"search":    search,
+		"readEntry": readEntry,
+		"close":     closeDB,
+	}
+	obj := js.Global().Get("Object").New()
+	for name, f := range funcs {
+		obj.Set(name, wrap(f))
+	}
+	js.Global().Set("gostpassGo", obj)
+	// Keep the functions callable.
+	select {}
+}
+
+// wrap adapts f to a JavaScript function.  Errors are returned as an
+// object with an "error" message, since Go can't throw JavaScript
+// exceptions.
+func wrap(f func(args []js.Value) (map[string]interface{}, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		result, err := f(args)
+		if err != nil {
+			return map[string]interface{}{"error": err.Error()}
+		}
+		return result
+	})
+}
+
+func open(args []js.Value) (map[string]interface{}, error) {
+	if len(args) < 2 {
+		return nil, errors.New("open: want database and password")
+	}
+	data, err := base64.StdEncoding.DecodeString(args[0].String())
+	if err != nil {
+		return nil, errors.New("open: database is not base64")
+	}
+	opts := &keepass.Options{Password: args[1].String()}
+	if len(args) > 2 && args[2].Type() == js.TypeString && args[2].String() != "" {
+		kf, err := base64.StdEncoding.DecodeString(args[2].String())
+		if err != nil {
+			return nil, errors.New("open: key file is not base64")
+		}
+		opts.KeyFile = bytes.NewReader(kf)
+	}
+	db, err := keepass.Open(bytes.NewReader(data), opts)
+	if err != nil {
+		return nil, err
+	}
+	h := nextHandle
+	nextHandle++
+	dbs[h] = db
+	return map[string]interface{}{"handle": h}, nil
+}
+
+func lookup(args []js.Value) (*keepass.Database, error) {
+	if len(args) < 1 || args[0].Type() != js.TypeNumber {
+		return nil, errors.New("missing database handle")
+	}
+	db := dbs[args[0].Int()]
+	if db == nil {
+		return nil, errors.New("unknown database handle")
+	}
+	return db, nil
+}
+
+// search returns a summary of the entries whose title, username, URL or
+// notes contain every word of the query, ignoring case.  An empty query
+// matches all entries.
+func search(args []js.Value) (map[string]interface{}, error) {
+	db, err := lookup(args)
+	if err != nil {
+		return nil, err
+	}
+	var query string
+	if len(args) > 1 {
+		query = args[1].String()
+	}
+	var results []interface{}
+	for _, e := range db.Search(query) {
+		results = append(results, entrySummary(e))
+	}
+	return map[string]interface{}{"entries": results}, nil
+}
+
+// entrySummary returns the fields of e that are safe to list; the
+// password and notes are only returned by readEntry.
+func entrySummary(e *keepass.Entry) map[string]interface{} {
+	return map[string]interface{}{
+		"uuid":     e.UUID.String(),
+		"title":    e.Title,
+		"username": e.Username,
+		"url":      e.URL,
+		"group":    e.Parent().Name,
+	}
+}
+
+func readEntry(args []js.Value) (map[string]interface{}, error) {
+	db, err := lookup(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < 2 {
+		return nil, errors.New("readEntry: missing UUID")
+	}
+	id, err := uuids.Parse(args[1].String())
+	if err != nil {
+		return nil, err
+	}
+	e := db.Find(id)
+	if e == nil {
+		return nil, errors.New("readEntry: no such entry")
+	}
+	entry := entrySummary(e)
+	entry["password"] = e.Password
+	entry["notes"] = e.Notes
+	entry["created"] = e.CreationTime.Unix()
+	entry["modified"] = e.LastModificationTime.Unix()
+	if e.HasAttachment() {
+		entry["attachmentName"] = e.Attachment.Name
+		entry["attachment"] = base64.StdEncoding.EncodeToString(e.Attachment.Data)
+	}
+	return map[string]interface{}{"entry": entry}, nil
+}
+
+func closeDB(args []js.Value) (map[string]interface{}, error) {
+	if len(args) > 0 && args[0].Type() == js.TypeNumber {
+		delete(dbs, args[0].Int())
+	}
+	return map[string]interface{}{}, nil
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command libgostpass builds a C shared library for reading GOST KeePass
+// databases from other languages:
+//
+//	go build -buildmode=c-shared -o libgostpass.so ./cmd/libgostpass
+//
+// which also writes libgostpass.h.  Databases are referred to by handles,
+// positive integers returned by gostpass_open or gostpass_decrypt and
+// released with gostpass_close.  Strings returned by the library are
+// allocated with malloc and must be released with gostpass_free.  On
+// failure, functions return 0 or NULL and, if err is not NULL, set *err to
+// an error message that must also be released with gostpass_free.
+//
+//	long long gostpass_open(char* path, char* password, char* keyfilePath, char** err);
+//	long long gostpass_decrypt(void* data, int n, char* password, void* keyfile, int keyfileLen, char** err);
+//	void gostpass_close(long long handle);
+//	char* gostpass_list(long long handle, char** err);
+//	char* gostpass_search(long long handle, char* query, char** err);
+//	char* gostpass_get_entry(long long handle, char* uuid, char** err);
+//	void gostpass_free(void* p);
+//
+// gostpass_list returns a JSON array of entry summaries, gostpass_search
+// the same for the entries whose title, username, URL or notes contain
+// every word of query, ignoring case, and gostpass_get_entry returns a
+// JSON object with all of an entry's fields.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/uuids"
+)
+
+var handles struct {
+	sync.Mutex
+	dbs  map[C.longlong]*keepass.Database
+	next C.longlong
+}
+
+func register(db *keepass.Database) C.longlong {
+	handles.Lock()
+	defer handles.Unlock()
+	if handles.dbs == nil {
+		handles.dbs = make(map[C.longlong]*keepass.Database)
+	}
+	handles.next++
+	handles.dbs[handles.next] = db
+	return handles.next
+}
+
+func lookup(h C.longlong) (*keepass.Database, error) {
+	handles.Lock()
+	defer handles.Unlock()
+	db := handles.dbs[h]
+	if db == nil {
+		return nil, errors.New("gostpass: unknown database handle")
+	}
+	return db, nil
+}
+
+// setError stores err in *errp, if errp is not NULL.
+func setError(errp **C.char, err error) {
+	if errp != nil {
+		*errp = C.CString(err.Error())
+	}
+}
+
+//export gostpass_open
+func gostpass_open(path, password, keyfilePath *C.char, errp **C.char) C.longlong {
+	opts := &keepass.Options{Password: goString(password)}
+	if keyfilePath != nil {
+		kf, err := os.Open(C.GoString(keyfilePath))
+		if err != nil {
+			setError(errp, err)
+			return 0
+		}
+		defer kf.Close()
+		opts.KeyFile = kf
+	}
+	db, err := keepass.OpenFile(C.GoString(path), opts)
+	if err != nil {
+		setError(errp, err)
+		return 0
+	}
+	return register(db)
+}
+
+//export gostpass_decrypt
+func gostpass_decrypt(data unsafe.Pointer, n C.int, password *C.char, keyfile unsafe.Pointer, keyfileLen C.int, errp **C.char) C.longlong {
+	opts := &keepass.Options{Password: goString(password)}
+	if keyfile != nil {
+		opts.KeyFile = bytes.NewReader(C.GoBytes(keyfile, keyfileLen))
+	}
+	db, err := keepass.Open(bytes.NewReader(C.GoBytes(data, n)), opts)
+	if err != nil {
+		setError(errp, err)
+		return 0
+	}
+	return register(db)
+}
+
+//export gostpass_close
+func gostpass_close(h C.longlong) {
+	handles.Lock()
+	delete(handles.dbs, h)
+	handles.Unlock()
+}
+
+// entrySummary is the JSON form of an entry in gostpass_list.
+type entrySummary struct {
+	UUID     string `json:"uuid"`
+	Title    string `json:"title"`
+	Username string `json:"username"`
+	URL      string `json:"url"`
+	Group    string `json:"group"`
+}
+
+// entryDetail is the JSON form of an entry in gostpass_get_entry.
+type entryDetail struct {
+	entrySummary
+	Password       string     `json:"password"`
+	Notes          string     `json:"notes"`
+	Created        time.Time  `json:"created"`
+	Modified       time.Time  `json:"modified"`
+	Expires        *time.Time `json:"expires,omitempty"`
+	AttachmentName string     `json:"attachment_name,omitempty"`
+	Attachment     []byte     `json:"attachment,omitempty"`
+}
+
+func summarize(e *keepass.Entry) entrySummary {
+	return entrySummary{
+		UUID:     e.UUID.String(),
+		Title:    e.Title,
+		Username: e.Username,
+		URL:      e.URL,
+		Group:    e.Parent().Name,
+	}
+}
+
+//export gostpass_list
+func gostpass_list(h C.longlong, errp **C.char) *C.char {
+	db, err := lookup(h)
+	if err != nil {
+		setError(errp, err)
+		return nil
+	}
+	list := []entrySummary{}
+	for _, e := range db.Entries() {
+		list = append(list, summarize(e))
+	}
+	return marshal(list, errp)
+}
+
+//export gostpass_search
+func gostpass_search(h C.longlong, query *C.char, errp **C.char) *C.char {
+	db, err := lookup(h)
+	if err != nil {
+		setError(errp, err)
+		return nil
+	}
+	list := []entrySummary{}
+	for _, e := range db.Search(C.GoString(query)) {
+		list = append(list, summarize(e))
+	}
+	return marshal(list, errp)
+}
+
+//export gostpass_get_entry
+func gostpass_get_entry(h C.longlong, uuid *C.char, errp **C.char) *C.char {
+	db, err := lookup(h)
+	if err != nil {
+		setError(errp, err)
+		return nil
+	}
+	id, err := uuids.Parse(C.GoString(uuid))
+	if err != nil {
+		setError(errp, err)
+		return nil
+	}
+	e := db.Find(id)
+	if e == nil {
+		setError(errp, errors.New("gostpass: no such entry"))
+		return nil
+	}
+	d := entryDetail{
+		entrySummary: summarize(e),
+		Password:     e.Password,
+		Notes:        e.Notes,
+		Created:      e.CreationTime,
+		Modified:     e.LastModificationTime,
+	}
+	if e.Expires() {
+		d.Expires = &e.ExpiryTime
+	}
+	if e.HasAttachment() {
+		d.AttachmentName = e.Attachment.Name
+		d.Attachment = e.Attachment.Data
+	}
+	return marshal(d, errp)
+}
+
+//export gostpass_free
+func gostpass_free(p unsafe.Pointer) {
+	C.free(p)
+}
+
+func marshal(v interface{}, errp **C.char) *C.char {
+	data, err := json.Marshal(v)
+	if err != nil {
+		setError(errp, err)
+		return nil
+	}
+	return C.CString(string(data))
+}
+
+func goString(s *C.char) string {
+	if s == nil {
+		return ""
+	}
+	return C.GoString(s)
+}
+
+func main() {}
@@ -0,0 +1,155 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// findDuplicates returns the sets of entries whose named fields are all
+// equal.  Each set has at least two entries, newest first.
+func findDuplicates(db *keepass.Database, fields []string) ([][]*keepass.Entry, error) {
+	getters := make([]func(*keepass.Entry) string, len(fields))
+	for i, f := range fields {
+		getters[i] = entryFields[f]
+		if getters[i] == nil {
+			return nil, fmt.Errorf("unknown field %q", f)
+		}
+	}
+	index := make(map[string]int)
+	var sets [][]*keepass.Entry
+	for _, e := range db.Entries() {
+		vals := make([]string, len(getters))
+		for i, get := range getters {
+			vals[i] = get(e)
+		}
+		// NUL can't appear in KDB1 strings, so it separates fields.
+		key := strings.Join(vals, "\x00")
+		if i, ok := index[key]; ok {
+			sets[i] = append(sets[i], e)
+		} else {
+			index[key] = len(sets)
+			sets = append(sets, []*keepass.Entry{e})
+		}
+	}
+	dups := sets[:0]
+	for _, set := range sets {
+		if len(set) < 2 {
+			continue
+		}
+		sort.SliceStable(set, func(i, j int) bool {
+			return set[i].LastModificationTime.After(set[j].LastModificationTime)
+		})
+		dups = append(dups, set)
+	}
+	return dups, nil
+}
+
+// mergeDuplicates keeps the first entry of dups and removes the others.
+// The removed entries' states and histories are folded into the kept
+// entry's history, fields and custom data it lacks are copied over, and
+// so is an attachment if it has none.
+func mergeDuplicates(dups []*keepass.Entry) error {
+	keep := dups[0]
+	for _, e := range dups[1:] {
+		keep.History = append(keep.History, e.History...)
+		keep.History = append(keep.History, e.Revision())
+		// Protected fields are sealed to their entry, so fields are
+		// copied by value rather than as custom data.
+		for _, name := range e.FieldNames() {
+			f, _, err := e.Field(name)
+			if err != nil {
+				return fmt.Errorf("%s: %v", entryPath(e), err)
+			}
+			e.DeleteField(name)
+			if _, exists, _ := keep.Field(name); exists {
+				continue
+			}
+			if err := keep.SetField(f); err != nil {
+				return fmt.Errorf("%s: %v", entryPath(keep), err)
+			}
+		}
+		for k, v := range e.CustomData {
+			if _, exists := keep.CustomData.Get(k); !exists {
+				keep.CustomData.Set(k, v)
+			}
+		}
+		if !keep.HasAttachment() && e.HasAttachment() {
+			keep.Attachment = e.Attachment
+		}
+		if err := e.Parent().RemoveEntry(e); err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(keep.History, func(i, j int) bool {
+		return keep.History[i].Modified.Before(keep.History[j].Modified)
+	})
+	return nil
+}
+
+// runDedup finds duplicate entries and merges each set, asking first
+// unless -auto is given.
+func runDedup(args []string) error {
+	fs := flag.NewFlagSet("dedup", flag.ContinueOnError)
+	fields := fs.String("fields", "title,username,password", "comma-separated fields that must match: "+strings.Join(sortedEntryFields(), ", "))
+	auto := fs.Bool("auto", false, "merge without asking")
+	dryRun := fs.Bool("n", false, "only report duplicates")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: dedup [-fields list] [-auto] [-n]")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	sets, err := findDuplicates(db, strings.Split(*fields, ","))
+	if err != nil {
+		return err
+	}
+	merged, removed := 0, 0
+	for _, set := range sets {
+		fmt.Printf(tr("%d duplicates of %q (user %q):\n"), len(set), set[0].Title, set[0].Username)
+		for i, e := range set {
+			mark := tr("remove")
+			if i == 0 {
+				mark = tr("keep")
+			}
+			fmt.Printf(tr("  %-6s %v  %s  modified %s\n"), mark, e.UUID, "/"+e.Parent().Path(), e.LastModificationTime.Format("2006-01-02 15:04:05"))
+		}
+		if *dryRun || !*auto && !confirm(tr("Merge?")) {
+			continue
+		}
+		if err := mergeDuplicates(set); err != nil {
+			return err
+		}
+		merged++
+		removed += len(set) - 1
+	}
+	if merged > 0 {
+		if err := writeDatabase(db); err != nil {
+			return err
+		}
+	}
+	fmt.Printf(tr("%d sets of duplicates found, %d merged, %d entries removed\n"), len(sets), merged, removed)
+	return nil
+}
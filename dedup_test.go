@@ -0,0 +1,98 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestDedup(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := db.Root().NewSubgroup()
+	home := db.Root().NewSubgroup()
+	t0 := time.Date(2019, time.July, 1, 22, 0, 0, 0, time.UTC)
+	newEntry := func(g *keepass.Group, title, password string, age time.Duration) *keepass.Entry {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title = title
+		e.Username = "bob"
+		e.Password = password
+		e.LastModificationTime = t0.Add(-age)
+		return e
+	}
+	oldest := newEntry(work, "Mail", "xyzzy", 48*time.Hour)
+	oldest.CustomData.Set("color", "red")
+	if err := oldest.SetField(keepass.Field{Name: "pin", Value: "1234", Protected: true}); err != nil {
+		t.Fatal(err)
+	}
+	oldest.Attachment.Name = "recovery.txt"
+	oldest.Attachment.Data = []byte("codes")
+	newest := newEntry(home, "Mail", "xyzzy", 0)
+	middle := newEntry(work, "Mail", "xyzzy", 24*time.Hour)
+	if err := middle.SetField(keepass.Field{Name: "pin", Value: "0000", Protected: true}); err != nil {
+		t.Fatal(err)
+	}
+	other := newEntry(work, "Mail", "hunter2", 0)
+
+	sets, err := findDuplicates(db, []string{"title", "username", "password"})
+	if err != nil {
+		t.Fatal("findDuplicates:", err)
+	}
+	if len(sets) != 1 || len(sets[0]) != 3 || sets[0][0] != newest || sets[0][1] != middle || sets[0][2] != oldest {
+		t.Fatalf("findDuplicates = %v; want [newest middle oldest]", sets)
+	}
+	if sets, _ := findDuplicates(db, []string{"title"}); len(sets) != 1 || len(sets[0]) != 4 {
+		t.Errorf("findDuplicates by title = %v; want all four entries", sets)
+	}
+	if _, err := findDuplicates(db, []string{"color"}); err == nil {
+		t.Error("findDuplicates with unknown field succeeded")
+	}
+
+	if err := mergeDuplicates(sets[0]); err != nil {
+		t.Fatal("mergeDuplicates:", err)
+	}
+	if entries := db.Entries(); len(entries) != 2 {
+		t.Errorf("after merge, %d entries; want 2", len(entries))
+	}
+	if db.Find(newest.UUID) == nil || db.Find(other.UUID) == nil {
+		t.Error("after merge, newest or unrelated entry is gone")
+	}
+	if n := len(newest.History); n != 2 || !newest.History[0].Modified.Equal(oldest.LastModificationTime) || !newest.History[1].Modified.Equal(middle.LastModificationTime) {
+		t.Errorf("after merge, history = %+v; want oldest then middle", newest.History)
+	}
+	if v, _ := newest.CustomData.Get("color"); v != "red" {
+		t.Errorf("after merge, custom data color = %q; want %q", v, "red")
+	}
+	if newest.Attachment.Name != "recovery.txt" {
+		t.Errorf("after merge, attachment = %q; want %q", newest.Attachment.Name, "recovery.txt")
+	}
+	// Protected fields are resealed to the kept entry.  middle is newer
+	// than oldest, so its pin wins.
+	if f, _, err := newest.Field("pin"); err != nil || f.Value != "0000" || !f.Protected {
+		t.Errorf("after merge, field pin = %+v, %v; want protected 0000", f, err)
+	}
+	if err := db.SetKey(&keepass.Options{Password: "swordfish", KeyRounds: 1}); err != nil {
+		t.Error("SetKey after merge:", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// dockerGroup is the group that registry credentials are kept in.  Each
+// entry's URL is the registry's server URL.
+const dockerGroup = "Docker Registries"
+
+// errDockerNotFound is the message the credential helper protocol uses to
+// tell "no credentials" apart from a failure.
+var errDockerNotFound = errors.New("credentials not found in native keychain")
+
+// dockerCredentials is the credential helper protocol's message.
+type dockerCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+func findDockerEntry(db *keepass.Database, serverURL string) *keepass.Entry {
+	g := db.FindGroupPath(dockerGroup)
+	if g == nil {
+		return nil
+	}
+	for _, e := range g.Entries() {
+		if e.URL == serverURL {
+			return e
+		}
+	}
+	return nil
+}
+
+// dockerCredential runs one action of the Docker credential helper
+// protocol on db, reading the request from r and writing the response to
+// w.  It reports whether db was changed.
+func dockerCredential(db *keepass.Database, action string, r io.Reader, w io.Writer) (changed bool, err error) {
+	switch action {
+	case "store":
+		var c dockerCredentials
+		if err := json.NewDecoder(r).Decode(&c); err != nil {
+			return false, fmt.Errorf("store: %v", err)
+		}
+		if c.ServerURL == "" {
+			return false, errors.New("store: missing server URL")
+		}
+		now := time.Now()
+		e := findDockerEntry(db, c.ServerURL)
+		if e == nil {
+			g, err := db.MkdirAll(dockerGroup)
+			if err != nil {
+				return false, err
+			}
+			if e, err = g.NewEntry(); err != nil {
+				return false, err
+			}
+			e.Title = c.ServerURL
+			e.URL = c.ServerURL
+			e.CreationTime = now
+		} else if e.Username == c.Username && e.Password == c.Secret {
+			return false, nil
+		} else {
+			e.AddRevision()
+		}
+		e.Username = c.Username
+		e.Password = c.Secret
+		e.LastModificationTime = now
+		return true, nil
+	case "get", "erase":
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return false, err
+		}
+		serverURL := strings.TrimSpace(string(data))
+		e := findDockerEntry(db, serverURL)
+		if e == nil {
+			return false, errDockerNotFound
+		}
+		if action == "erase" {
+			return true, db.RecycleEntry(e)
+		}
+		if err := releaseEntry(e, "docker-credential"); err != nil {
+			return false, err
+		}
+		return false, json.NewEncoder(w).Encode(dockerCredentials{
+			ServerURL: serverURL,
+			Username:  e.Username,
+			Secret:    e.Password,
+		})
+	case "list":
+		list := make(map[string]string)
+		if g := db.FindGroupPath(dockerGroup); g != nil {
+			for _, e := range g.Entries() {
+				list[e.URL] = e.Username
+			}
+		}
+		return false, json.NewEncoder(w).Encode(list)
+	default:
+		return false, fmt.Errorf("unknown action %q", action)
+	}
+}
+
+// runDockerCredential implements the Docker credential helper protocol, so
+// that registry logins are kept in the database instead of in
+// ~/.docker/config.json.  Docker runs helpers as docker-credential-NAME
+// with the action as the only argument, so install a wrapper like:
+//
+//	#!/bin/sh
+//	exec gostpass -db ~/vault.kdb docker-credential "$@"
+//
+// as docker-credential-gostpass and set "credsStore": "gostpass".  The
+// password is read from the terminal or from -password_file, since
+// standard input carries the request.
+func runDockerCredential(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: docker-credential store|get|erase|list")
+	}
+	stdinProtocol = true
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	changed, err := dockerCredential(db, args[0], stdin, os.Stdout)
+	if err == errDockerNotFound {
+		// The protocol expects the message on standard output.
+		fmt.Println(err)
+		return err
+	} else if err != nil {
+		return err
+	}
+	if changed {
+		return writeDatabase(db)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestDockerCredential(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	do := func(action, in string) (string, bool, error) {
+		out := new(bytes.Buffer)
+		changed, err := dockerCredential(db, action, strings.NewReader(in), out)
+		return out.String(), changed, err
+	}
+
+	if _, _, err := do("get", "https://index.docker.io/v1/\n"); err != errDockerNotFound {
+		t.Errorf("get before store error = %v; want errDockerNotFound", err)
+	}
+	in := `{"ServerURL":"https://index.docker.io/v1/","Username":"bob","Secret":"hunter2"}`
+	if _, changed, err := do("store", in); err != nil || !changed {
+		t.Fatalf("store = %t, %v; want true, <nil>", changed, err)
+	}
+	if _, changed, err := do("store", in); err != nil || changed {
+		t.Errorf("store again = %t, %v; want false, <nil>", changed, err)
+	}
+	out, _, err := do("get", "https://index.docker.io/v1/")
+	if err != nil {
+		t.Fatal("get:", err)
+	}
+	var c dockerCredentials
+	if err := json.Unmarshal([]byte(out), &c); err != nil || c.Username != "bob" || c.Secret != "hunter2" {
+		t.Errorf("get = %q, %v; want bob's credentials", out, err)
+	}
+	if _, _, err := do("store", `{"ServerURL":"https://index.docker.io/v1/","Username":"bob","Secret":"xyzzy"}`); err != nil {
+		t.Fatal("store update:", err)
+	}
+	e := findDockerEntry(db, "https://index.docker.io/v1/")
+	if e.Password != "xyzzy" || len(e.History) != 1 || e.History[0].Password != "hunter2" {
+		t.Errorf("after update, password = %q, history = %+v; want xyzzy with hunter2 in history", e.Password, e.History)
+	}
+	if out, _, err := do("list", ""); err != nil || strings.TrimSpace(out) != `{"https://index.docker.io/v1/":"bob"}` {
+		t.Errorf("list = %q, %v", out, err)
+	}
+	if _, changed, err := do("erase", "https://index.docker.io/v1/"); err != nil || !changed {
+		t.Errorf("erase = %t, %v; want true, <nil>", changed, err)
+	}
+	if _, _, err := do("get", "https://index.docker.io/v1/"); err != errDockerNotFound {
+		t.Errorf("get after erase error = %v; want errDockerNotFound", err)
+	}
+}
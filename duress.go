@@ -0,0 +1,59 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+var duressDBPath = flag.String("duress_db", "", "path to a decoy database that is opened when its credentials are entered instead of the real ones")
+
+// duressStorage is the decoy database, or nil if none is configured.
+// Protected by mu.
+var duressStorage *storage
+
+func initDuress() error {
+	if *duressDBPath == "" {
+		return nil
+	}
+	var err error
+	duressStorage, err = newStorage(*duressDBPath)
+	return err
+}
+
+// openDuress attempts to open the decoy database with the given
+// credentials.  The caller must hold mu.
+func openDuress(password string, keyfile []byte) (*keepass.Database, error) {
+	if duressStorage == nil {
+		return nil, errors.New("open duress database: not configured")
+	}
+	return openStorage(duressStorage, &keepass.Options{
+		Password: password,
+		KeyFile:  optReader(keyfile),
+	})
+}
+
+// storage returns the storage of the database the session unlocked.
+// Sessions look the same either way, so every handler that goes through
+// the session transparently works on the decoy.
+func (data sessionData) storage() *storage {
+	if data.Decoy && duressStorage != nil {
+		return duressStorage
+	}
+	return dbStorage
+}
@@ -98,6 +98,22 @@ func (notFoundError) StatusCode() int {
 	return http.StatusNotFound
 }
 
+type permissionError struct {
+	reason string
+}
+
+func (e permissionError) Error() string {
+	return "permission denied: " + e.reason
+}
+
+func (permissionError) UserError() string {
+	return "Permission denied"
+}
+
+func (permissionError) StatusCode() int {
+	return http.StatusForbidden
+}
+
 type invalidParentError struct {
 	val string
 }
@@ -0,0 +1,292 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pedroalbanese/gostpass/pkg/escrow"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+var escrowPath = flag.String("escrow", "", "path to emergency access escrow file (default is -db path with \".escrow\" appended)")
+
+// The escrow record has to be readable without the database key, so it is
+// kept next to the database rather than inside it.
+func escrowFile() string {
+	if *escrowPath != "" {
+		return *escrowPath
+	}
+	return *dbPath + ".escrow"
+}
+
+// readEscrow returns the current escrow record or nil if none is enrolled.
+func readEscrow() (*escrow.Record, error) {
+	data, err := ioutil.ReadFile(escrowFile())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read escrow: %v", err)
+	}
+	rec := new(escrow.Record)
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, fmt.Errorf("read escrow: %v", err)
+	}
+	return rec, nil
+}
+
+func writeEscrow(rec *escrow.Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("write escrow: %v", err)
+	}
+	st, err := newStorage(escrowFile())
+	if err != nil {
+		return fmt.Errorf("write escrow: %v", err)
+	}
+	defer st.Close()
+	wc, err := st.writer()
+	if err != nil {
+		return fmt.Errorf("write escrow: %v", err)
+	}
+	_, err = wc.Write(data)
+	cerr := wc.Close()
+	if err != nil {
+		return fmt.Errorf("write escrow: %v", err)
+	}
+	if cerr != nil {
+		return fmt.Errorf("write escrow: close: %v", cerr)
+	}
+	return nil
+}
+
+// rewrapEscrow wraps db's current key to the enrolled recipient, if any.
+// It must be called whenever the database key changes, otherwise the
+// escrow record would keep unlocking nothing.
+func rewrapEscrow(db *keepass.Database) error {
+	rec, err := readEscrow()
+	if err != nil || rec == nil {
+		return err
+	}
+	rec, err = escrow.Wrap(rand.Reader, rec.Recipient, db.ComputedKey())
+	if err != nil {
+		return err
+	}
+	return writeEscrow(rec)
+}
+
+// enrollEscrow wraps the database key to the public key given in the
+// "pubkey" form value (hex-encoded), replacing any previous recipient.
+func enrollEscrow(w http.ResponseWriter, r *http.Request) error {
+	pub, err := hex.DecodeString(strings.TrimSpace(r.FormValue("pubkey")))
+	if err != nil || len(pub) == 0 {
+		return userError{
+			msg: "The recovery public key must be hex-encoded.",
+			err: fmt.Errorf("enroll escrow: bad public key: %v", err),
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	db, err := sessions.dbFromRequest(w, r)
+	if err != nil {
+		return err
+	}
+	rec, err := escrow.Wrap(rand.Reader, pub, db.ComputedKey())
+	if err != nil {
+		return userError{
+			msg: "Invalid recovery public key.",
+			err: fmt.Errorf("enroll escrow: %v", err),
+		}
+	}
+	if err := writeEscrow(rec); err != nil {
+		return err
+	}
+	return redirectRoute(w, r, "listGroups")
+}
+
+// revokeEscrow removes the escrow record.
+func revokeEscrow(w http.ResponseWriter, r *http.Request) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := sessions.dbFromRequest(w, r); err != nil {
+		return err
+	}
+	if err := os.Remove(escrowFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("revoke escrow: %v", err)
+	}
+	return redirectRoute(w, r, "listGroups")
+}
+
+// recoverEscrow starts a session using the recovery private key given in
+// the "recovery_key" form value (hex-encoded).
+func recoverEscrow(w http.ResponseWriter, r *http.Request) error {
+	prv, err := hex.DecodeString(strings.TrimSpace(r.FormValue("recovery_key")))
+	if err != nil {
+		return rootRedirectError{userError{
+			msg: "The recovery key must be hex-encoded.",
+			err: fmt.Errorf("recover escrow: %v", err),
+		}}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if err := unlocks.allow(); err != nil {
+		return rootRedirectError{err}
+	}
+	rec, err := readEscrow()
+	if err != nil {
+		return err
+	}
+	if rec == nil {
+		return rootRedirectError{userError{
+			msg: "No recovery key is enrolled for this database.",
+			err: errors.New("recover escrow: no escrow record"),
+		}}
+	}
+	key, err := rec.Unwrap(prv)
+	if err != nil {
+		unlocks.fail(r)
+		return rootRedirectError{userError{
+			msg: "Unable to recover the database with this key.",
+			err: fmt.Errorf("recover escrow: %v", err),
+		}}
+	}
+	db, err := openDatabase(&keepass.Options{ComputedKey: key})
+	unlocks.record(r, err)
+	if isUserError(err) {
+		return rootRedirectError{err}
+	} else if err != nil {
+		return err
+	}
+	if _, err := sessions.new(w, sessionData{Key: db.ComputedKey()}); err != nil {
+		return err
+	}
+	return redirectRoute(w, r, "listGroups")
+}
+
+const escrowUsage = `usage: escrow keygen private.key public.key
+       escrow enroll public.key
+       escrow revoke
+       escrow recover private.key`
+
+// runEscrow manages the escrow record from the command line, like the
+// /_/escrow pages.  The keys are in hex files, as written by keygen.
+func runEscrow(args []string) error {
+	if len(args) == 0 {
+		return errors.New(escrowUsage)
+	}
+	switch {
+	case args[0] == "keygen" && len(args) == 3:
+		prv, pub, err := escrow.GenerateKey(rand.Reader)
+		if err != nil {
+			return err
+		}
+		if err := writeSecretFile(args[1], []byte(hex.EncodeToString(prv)+"\n")); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(args[2], []byte(hex.EncodeToString(pub)+"\n"), 0644)
+	case args[0] == "enroll" && len(args) == 2:
+		return escrowEnroll(args[1])
+	case args[0] == "revoke" && len(args) == 1:
+		return escrowRevoke()
+	case args[0] == "recover" && len(args) == 2:
+		return escrowRecover(args[1])
+	default:
+		return errors.New(escrowUsage)
+	}
+}
+
+// escrowEnroll wraps the database key to the public key in pubPath,
+// replacing any previous recipient.
+func escrowEnroll(pubPath string) error {
+	pub, err := readKeyFile(pubPath)
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	rec, err := escrow.Wrap(rand.Reader, pub, db.ComputedKey())
+	if err != nil {
+		return fmt.Errorf("enroll escrow: %v", err)
+	}
+	return writeEscrow(rec)
+}
+
+// escrowRevoke removes the escrow record.  Like on the web, it takes the
+// database's credentials.
+func escrowRevoke() error {
+	rec, err := readEscrow()
+	if err != nil {
+		return err
+	}
+	if rec == nil {
+		return errors.New(tr("no recovery key is enrolled"))
+	}
+	if _, err := openCommandDatabase(); err != nil {
+		return err
+	}
+	if err := os.Remove(escrowFile()); err != nil {
+		return fmt.Errorf("revoke escrow: %v", err)
+	}
+	return nil
+}
+
+// escrowRecover opens the database with the recovery private key in
+// prvPath and sets a new password, which the recovery key holder is asked
+// for.  The escrow record is rewrapped, so the recovery key keeps working.
+func escrowRecover(prvPath string) error {
+	prv, err := readKeyFile(prvPath)
+	if err != nil {
+		return err
+	}
+	rec, err := readEscrow()
+	if err != nil {
+		return err
+	}
+	if rec == nil {
+		return errors.New(tr("no recovery key is enrolled"))
+	}
+	key, err := rec.Unwrap(prv)
+	if err != nil {
+		return fmt.Errorf("recover escrow: %v", err)
+	}
+	if err := initDatabase(); err != nil {
+		return err
+	}
+	db, err := openDatabase(&keepass.Options{ComputedKey: key})
+	if err != nil {
+		return fmt.Errorf("recover escrow: %v", err)
+	}
+	opts, err := newDatabaseOptions()
+	if err != nil {
+		return err
+	}
+	opts.KeyRounds = db.KeyRounds()
+	if err := rekeyDatabase(db, opts); err != nil {
+		return fmt.Errorf("recover escrow: %v", err)
+	}
+	fmt.Printf(tr("%s: recovered, with a new password\n"), *dbPath)
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEscrowCommand(t *testing.T) {
+	dir, cleanup := newCommandTestDB(t, `{"key_rounds": 1}`)
+	defer cleanup()
+	oldEscrow := *escrowPath
+	defer func() { *escrowPath = oldEscrow }()
+	*escrowPath = ""
+	prvPath, pubPath := filepath.Join(dir, "recovery.key"), filepath.Join(dir, "recovery.pub")
+
+	if err := runEscrow([]string{"keygen", prvPath, pubPath}); err != nil {
+		t.Fatal("escrow keygen:", err)
+	}
+	if err := runEscrow([]string{"recover", prvPath}); err == nil {
+		t.Error("escrow recover before enroll succeeded")
+	}
+	if err := runEscrow([]string{"enroll", pubPath}); err != nil {
+		t.Fatal("escrow enroll:", err)
+	}
+	if rec, err := readEscrow(); err != nil || rec == nil {
+		t.Fatalf("escrow record after enroll = %v, %v; want one", rec, err)
+	}
+
+	// The recovery key holder doesn't know the password and sets a new one.
+	newPassword := filepath.Join(dir, "new-password")
+	if err := ioutil.WriteFile(newPassword, []byte("hunter2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	*passwordFile = newPassword
+	if err := runEscrow([]string{"recover", prvPath}); err != nil {
+		t.Fatal("escrow recover:", err)
+	}
+	if _, err := openCommandDatabase(); err != nil {
+		t.Error("open with the new password after recover:", err)
+	}
+	// The record is rewrapped to the new key, so it still recovers.
+	if err := runEscrow([]string{"recover", prvPath}); err != nil {
+		t.Error("escrow recover after recover:", err)
+	}
+
+	if err := runEscrow([]string{"revoke"}); err != nil {
+		t.Fatal("escrow revoke:", err)
+	}
+	if _, err := os.Stat(escrowFile()); !os.IsNotExist(err) {
+		t.Errorf("stat %s after revoke = %v; want not exist", escrowFile(), err)
+	}
+	if err := runEscrow([]string{"recover", prvPath}); err == nil {
+		t.Error("escrow recover after revoke succeeded")
+	}
+}
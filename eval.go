@@ -0,0 +1,130 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/expr"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// evalVars returns the variables that eval expressions see: a read-only
+// view of db without passwords, so that a query can't release a secret.
+//
+//	entries  list of {title, username, url, notes, path, group, uuid,
+//	         created, modified, expires, age_days, password_length,
+//	         attachment, recycled, custom}
+//	groups   list of {name, path, entries}
+//	now      the current time
+//
+// Times are RFC 3339 strings in UTC, which compare correctly as strings;
+// expires is null for entries that don't expire.  custom holds the entry's
+// custom data, without gostpass's own keys.
+func evalVars(db *keepass.Database, now time.Time) map[string]interface{} {
+	formatTime := func(t time.Time) interface{} {
+		if t.IsZero() {
+			return nil
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	var entries []interface{}
+	for _, e := range db.Entries() {
+		custom := make(map[string]interface{})
+		for k, v := range e.CustomData {
+			if !strings.HasPrefix(k, "gostpass.") {
+				custom[k] = v
+			}
+		}
+		entries = append(entries, map[string]interface{}{
+			"title":           e.Title,
+			"username":        e.Username,
+			"url":             e.URL,
+			"notes":           e.Notes,
+			"path":            entryPath(e),
+			"group":           e.Parent().Path(),
+			"uuid":            e.UUID.String(),
+			"created":         formatTime(e.CreationTime),
+			"modified":        formatTime(e.LastModificationTime),
+			"expires":         formatTime(e.ExpiryTime),
+			"age_days":        float64(int(now.Sub(e.LastModificationTime).Hours() / 24)),
+			"password_length": float64(len([]rune(e.Password))),
+			"attachment":      e.Attachment.Name,
+			"recycled":        e.Parent().InRecycleBin(),
+			"custom":          custom,
+		})
+	}
+	var groups []interface{}
+	var walk func(g *keepass.Group)
+	walk = func(g *keepass.Group) {
+		for i := 0; i < g.NGroups(); i++ {
+			sub := g.Group(i)
+			groups = append(groups, map[string]interface{}{
+				"name":    sub.Name,
+				"path":    sub.Path(),
+				"entries": float64(sub.NEntries()),
+			})
+			walk(sub)
+		}
+	}
+	walk(db.Root())
+	if entries == nil {
+		entries = []interface{}{}
+	}
+	if groups == nil {
+		groups = []interface{}{}
+	}
+	return map[string]interface{}{
+		"entries": entries,
+		"groups":  groups,
+		"now":     now.UTC().Format(time.RFC3339),
+	}
+}
+
+// runEval evaluates an expression over the database and prints the result
+// as JSON, for queries that the search syntax can't express:
+//
+//	gostpass eval 'entries.filter(e, e.group == "Work" && e.age_days > 365).map(e, e.path)'
+//
+// See package expr for the language.
+func runEval(args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: eval expression")
+	}
+	x, err := expr.Parse(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	v, err := x.Eval(evalVars(db, time.Now()))
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/expr"
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestEvalVars(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	db, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Work/Servers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []struct {
+		title, password, tag string
+		age                  int
+	}{
+		{"prod-db", "correct horse battery", "prod", 400},
+		{"staging-db", "hunter2", "staging", 10},
+		{"prod-web", "hunter2", "prod", 30},
+	} {
+		ent, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ent.Title = e.title
+		ent.Password = e.password
+		ent.LastModificationTime = now.AddDate(0, 0, -e.age)
+		ent.CustomData.Set("tag", e.tag)
+		ent.CustomData.SetBool(confirmReleaseKey, true)
+	}
+	vars := evalVars(db, now)
+
+	tests := []struct {
+		src  string
+		want interface{}
+	}{
+		{`entries.filter(e, e.custom.tag == "prod").map(e, e.title)`, []interface{}{"prod-db", "prod-web"}},
+		{`entries.filter(e, e.age_days > 365).map(e, e.path)`, []interface{}{"Work/Servers/prod-db"}},
+		{`entries.filter(e, e.password_length < 8).size()`, 2.0},
+		{`entries.filter(e, e.modified < "2026-01-15").map(e, e.title)`, []interface{}{"prod-db"}},
+		{`groups.map(g, g.entries)`, []interface{}{0.0, 3.0}},
+		{`entries.exists(e, has(e.password) || has(e.custom["gostpass.confirm_release"]))`, nil},
+		{`entries.all(e, !has(e.custom.gostpass))`, true},
+		{`entries[0].expires == null && now == "2026-03-01T12:00:00Z"`, true},
+	}
+	for _, test := range tests {
+		x, err := expr.Parse(test.src)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.src, err)
+			continue
+		}
+		got, err := x.Eval(vars)
+		if test.want == nil {
+			if err == nil {
+				t.Errorf("Eval(%q) = %#v; want error", test.src, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Eval(%q): %v", test.src, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Eval(%q) = %#v; want %#v", test.src, got, test.want)
+		}
+	}
+}
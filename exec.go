@@ -0,0 +1,166 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+const execUsage = "usage: exec -entry path [-files] NAME=field... -- command [args...]"
+
+// validEnvName reports whether name is a portable environment variable
+// name, which also makes it safe as a file name.
+func validEnvName(name string) bool {
+	for i, c := range name {
+		if !(c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// secretDir returns a directory for secret files, preferring one backed by
+// memory so that secrets never reach the disk.
+func secretDir() (string, error) {
+	base := ""
+	if fi, err := os.Stat("/dev/shm"); err == nil && fi.IsDir() {
+		base = "/dev/shm"
+	}
+	return ioutil.TempDir(base, "gostpass")
+}
+
+// scrubDir overwrites the files in dir with zeros and removes it.
+func scrubDir(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range files {
+		path := filepath.Join(dir, fi.Name())
+		if err := ioutil.WriteFile(path, make([]byte, fi.Size()), 0600); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(dir)
+}
+
+// runExec runs a command with fields of an entry in its environment.  Each
+// NAME=field argument sets the environment variable NAME to the field's
+// value, or with -files, to the path of a file holding the value.  The
+// files are kept in memory where possible and are scrubbed when the
+// command exits.
+func runExec(args []string) error {
+	fs := flag.NewFlagSet("exec", flag.ContinueOnError)
+	entry := fs.String("entry", "", "`path` or UUID of the entry to take fields from")
+	files := fs.Bool("files", false, "pass fields in files named by the variables instead of in the variables")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	rest := fs.Args()
+	sep := -1
+	for i, arg := range rest {
+		if arg == "--" {
+			sep = i
+			break
+		}
+	}
+	if *entry == "" || sep < 1 || sep == len(rest)-1 {
+		return errors.New(execUsage)
+	}
+	mappings, argv := rest[:sep], rest[sep+1:]
+	for _, m := range mappings {
+		i := strings.IndexByte(m, '=')
+		if i <= 0 || !validEnvName(m[:i]) {
+			return fmt.Errorf("%q: want NAME=field", m)
+		}
+		if entryFields[m[i+1:]] == nil {
+			return fmt.Errorf("%q: unknown field %q; want one of %s", m, m[i+1:], strings.Join(sortedEntryFields(), ", "))
+		}
+	}
+
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	e, err := findEntryPath(db, *entry)
+	if err != nil {
+		return err
+	}
+	if err := releaseEntry(e, "exec "+argv[0]); err != nil {
+		return err
+	}
+	env := os.Environ()
+	if *files {
+		dir, err := secretDir()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := scrubDir(dir); err != nil {
+				fmt.Fprintf(stderr, "gostpass exec: scrub %s: %v\n", dir, err)
+			}
+		}()
+		for _, m := range mappings {
+			i := strings.IndexByte(m, '=')
+			path := filepath.Join(dir, m[:i])
+			if err := ioutil.WriteFile(path, []byte(entryFields[m[i+1:]](e)), 0600); err != nil {
+				return err
+			}
+			env = append(env, m[:i]+"="+path)
+		}
+	} else {
+		for _, m := range mappings {
+			i := strings.IndexByte(m, '=')
+			env = append(env, m[:i]+"="+entryFields[m[i+1:]](e))
+		}
+	}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Env = env
+	cmd.Stdin = stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Pass signals on rather than dying, so that secret files are always
+	// scrubbed.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		for sig := range sigs {
+			cmd.Process.Signal(sig)
+		}
+	}()
+	err = cmd.Wait()
+	if exit, ok := err.(*exec.ExitError); ok {
+		code := exit.ExitCode()
+		if code < 0 {
+			code = 1
+		}
+		return exitError{code, fmt.Errorf("%s: %v", argv[0], err)}
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidEnvName(t *testing.T) {
+	for _, name := range []string{"DB_PASS", "_x", "a1"} {
+		if !validEnvName(name) {
+			t.Errorf("validEnvName(%q) = false; want true", name)
+		}
+	}
+	for _, name := range []string{"", "1A", "A-B", "../etc/passwd", "A B"} {
+		if validEnvName(name) {
+			t.Errorf("validEnvName(%q) = true; want false", name)
+		}
+	}
+}
+
+func TestScrubDir(t *testing.T) {
+	dir, err := secretDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "DB_PASS")
+	if err := ioutil.WriteFile(path, []byte("hunter2"), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	if err := scrubDir(dir); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal("scrubDir:", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("after scrubDir, Stat(dir) error = %v; want not exist", err)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// exportDB sends a copy of the database re-encrypted with one-time
+// credentials given in the request.  The copy has its own master and
+// transform seeds, so it can be handed to somebody else without
+// revealing the key of the stored database.
+//
+// The optional "group" and "q" form values restrict the export to a
+// group's subtree and to entries matching a search query, respectively.
+// The "format" form value is "kdb" (the default) or "paper".
+func exportDB(w http.ResponseWriter, r *http.Request) error {
+	format := r.FormValue("format")
+	switch format {
+	case "":
+		format = "kdb"
+	case "kdb", "paper":
+	default:
+		return userError{
+			msg: fmt.Sprintf("Unknown export format %q.", format),
+			err: fmt.Errorf("export database: unknown format %q", format),
+		}
+	}
+	password, keyfile, err := readCredentials(r)
+	if err != nil {
+		return err
+	}
+	if password == "" && len(keyfile) == 0 {
+		return userError{
+			msg: "An export password or key file is required.",
+			err: errors.New("export database: no credentials"),
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// dbFromRequest always reads a fresh copy from disk, so changing the key
+	// here does not affect the stored database.
+	db, err := sessions.dbFromRequest(w, r)
+	if err != nil {
+		return err
+	}
+	secrets.Add(password)
+	g, err := requestGroup(db, map[string]string{"gid": r.FormValue("group")})
+	if err != nil {
+		return err
+	}
+	if err := checkGroupAccess(r, db, g, false); err != nil {
+		return err
+	}
+	if err := removeUnreadable(r, db); err != nil {
+		return fmt.Errorf("export database: %v", err)
+	}
+	buf := new(bytes.Buffer)
+	err = exportDatabase(buf, format, db, g, parseQuery(r.FormValue("q")), flagOptions(&keepass.Options{
+		Password: password,
+		KeyFile:  optReader(keyfile),
+	}))
+	if err != nil {
+		return err
+	}
+	if format == "paper" {
+		w.Header().Set("Content-Disposition", `attachment; filename="gostpass-paper-backup.txt"`)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Disposition", `attachment; filename="gostpass-export.kdb"`)
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// exportDatabase writes the entries of db in g matching q to w, encrypted
+// with the key given by opts.  Format is "kdb" for a database file or
+// "paper" for a printable backup of one.  db is modified.
+func exportDatabase(w io.Writer, format string, db *keepass.Database, g *keepass.Group, q *parsedQuery, opts *keepass.Options) error {
+	if err := filterDatabase(db, g, q); err != nil {
+		return fmt.Errorf("export database: %v", err)
+	}
+	if err := db.SetKey(opts); err != nil {
+		return fmt.Errorf("export database: %v", err)
+	}
+	if format != "paper" {
+		if err := db.Write(w); err != nil {
+			return fmt.Errorf("export database: %v", err)
+		}
+		return nil
+	}
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		return fmt.Errorf("export database: %v", err)
+	}
+	if err := writePaperBackup(w, buf.Bytes(), time.Now()); err != nil {
+		return fmt.Errorf("export database: %v", err)
+	}
+	return nil
+}
+
+// runExport writes an export of the database, like the web export, to
+// standard output or a file.  The export's password is asked for twice.
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "kdb", "`format` of the export: kdb, or paper for a printable backup")
+	group := fs.String("group", "", "export only the group at `path`")
+	query := fs.String("q", "", "export only entries matching `query`")
+	out := fs.String("o", "", "write to `file` instead of standard output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || (*format != "kdb" && *format != "paper") {
+		return errors.New("usage: export [-format kdb|paper] [-group path] [-q query] [-o file]")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	var g *keepass.Group
+	if *group != "" {
+		if g = db.FindGroupPath(*group); g == nil {
+			return fmt.Errorf("%s: no such group", *group)
+		}
+	}
+	password, err := promptExportPassword()
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	if err := exportDatabase(buf, *format, db, g, parseQuery(*query), flagOptions(&keepass.Options{Password: password})); err != nil {
+		return err
+	}
+	return writeExport(*out, buf.Bytes())
+}
+
+// promptExportPassword asks for a new password for an export, twice.
+func promptExportPassword() (string, error) {
+	password, err := promptNewPassword(tr("Export password: "), tr("Repeat export password: "))
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", errors.New(tr("an export password is required"))
+	}
+	return password, nil
+}
+
+// writeExport writes data to the file at path, or to standard output if
+// path is empty.
+func writeExport(path string, data []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// runShareFile writes a database holding only a copy of one entry, under
+// a password of its own, to give the entry to somebody else.
+func runShareFile(args []string) error {
+	fs := flag.NewFlagSet("share-file", flag.ContinueOnError)
+	out := fs.String("o", "", "write to `file` instead of standard output")
+	history := fs.Bool("history", false, "include the entry's history, with its old passwords")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: share-file [-history] [-o file] entry")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	e, err := findEntryPath(db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	password, err := promptExportPassword()
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	if err := shareEntry(buf, e, *history, flagOptions(&keepass.Options{Password: password})); err != nil {
+		return err
+	}
+	return writeExport(*out, buf.Bytes())
+}
+
+// shareEntry writes a new database with a copy of e in a group named like
+// e's, encrypted with the key given by opts.  Custom data, which holds
+// gostpass's own bookkeeping, is left out except for the entry's fields,
+// and so is the history unless history is true.
+func shareEntry(w io.Writer, e *keepass.Entry, history bool, opts *keepass.Options) error {
+	db, err := keepass.New(opts)
+	if err != nil {
+		return fmt.Errorf("share entry: %v", err)
+	}
+	g := db.Root().NewSubgroup()
+	g.Name = e.Parent().Name
+	g.Icon = e.Parent().Icon
+	ce, err := g.ImportEntry(e)
+	if err != nil {
+		return fmt.Errorf("share entry: %v", err)
+	}
+	var fields []keepass.Field
+	for _, name := range ce.FieldNames() {
+		f, _, err := ce.Field(name)
+		if err != nil {
+			return fmt.Errorf("share entry: %v", err)
+		}
+		fields = append(fields, f)
+	}
+	ce.CustomData = nil
+	for _, f := range fields {
+		if err := ce.SetField(f); err != nil {
+			return fmt.Errorf("share entry: %v", err)
+		}
+	}
+	if !history {
+		ce.History = nil
+	}
+	if err := db.Write(w); err != nil {
+		return fmt.Errorf("share entry: %v", err)
+	}
+	return nil
+}
+
+// filterDatabase removes every entry from db that is outside of g or does
+// not match q.  A nil g or q does not restrict the entries.  Groups that
+// end up empty are removed, except for g itself.
+func filterDatabase(db *keepass.Database, g *keepass.Group, q *parsedQuery) error {
+	_, err := filterGroup(db.Root(), g, q, g == nil)
+	return err
+}
+
+// filterGroup implements filterDatabase for a single group and reports
+// whether the group should be kept.
+func filterGroup(parent, g *keepass.Group, q *parsedQuery, inScope bool) (bool, error) {
+	inScope = inScope || parent == g
+	for _, sub := range parent.Groups() {
+		keep, err := filterGroup(sub, g, q, inScope)
+		if err != nil {
+			return false, err
+		}
+		if !keep {
+			if err := parent.RemoveSubgroup(sub); err != nil {
+				return false, err
+			}
+		}
+	}
+	for _, e := range parent.Entries() {
+		if inScope && (q == nil || q.matchesEntry(e)) {
+			continue
+		}
+		if err := parent.RemoveEntry(e); err != nil {
+			return false, err
+		}
+	}
+	return parent == g || parent.NGroups() > 0 || parent.NEntries() > 0, nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/paper"
+)
+
+func TestFilterDatabase(t *testing.T) {
+	tests := []struct {
+		name  string
+		group string
+		query string
+		want  []string
+	}{
+		{name: "Everything", want: []string{"Work/Mail", "Work/VPN/Office", "Home/Mail"}},
+		{name: "Group", group: "Work", want: []string{"Work/Mail", "Work/VPN/Office"}},
+		{name: "Subgroup", group: "VPN", want: []string{"Work/VPN/Office"}},
+		{name: "Query", query: "mail", want: []string{"Work/Mail", "Home/Mail"}},
+		{name: "GroupAndQuery", group: "Work", query: "mail", want: []string{"Work/Mail"}},
+		{name: "NoMatches", query: "bank", want: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			groups := make(map[string]*keepass.Group)
+			add := func(parent *keepass.Group, name string, entries ...string) *keepass.Group {
+				g := parent.NewSubgroup()
+				g.Name = name
+				groups[name] = g
+				for _, title := range entries {
+					e, err := g.NewEntry()
+					if err != nil {
+						t.Fatal(err)
+					}
+					e.Title = title
+				}
+				return g
+			}
+			work := add(db.Root(), "Work", "Mail")
+			add(work, "VPN", "Office")
+			add(db.Root(), "Home", "Mail")
+
+			err = filterDatabase(db, groups[test.group], parseQuery(test.query))
+			if err != nil {
+				t.Fatal("filterDatabase:", err)
+			}
+
+			got := entryPaths(nil, db.Root(), "")
+			sort.Strings(got)
+			want := append([]string(nil), test.want...)
+			sort.Strings(want)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("entries = %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func entryPaths(paths []string, g *keepass.Group, prefix string) []string {
+	for _, e := range g.Entries() {
+		paths = append(paths, prefix+e.Title)
+	}
+	for _, sub := range g.Groups() {
+		paths = entryPaths(paths, sub, prefix+sub.Name+"/")
+	}
+	return paths
+}
+
+func TestExportPaper(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"Work", "Home"} {
+		g, err := db.MkdirAll(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title = "Mail"
+		e.Password = "hunter2"
+	}
+	buf := new(bytes.Buffer)
+	err = exportDatabase(buf, "paper", db, db.FindGroupPath("Work"), nil, &keepass.Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal("exportDatabase:", err)
+	}
+	if !strings.HasPrefix(buf.String(), "GOSTPASS PAPER BACKUP\n") {
+		t.Errorf("paper backup starts with %q; want the instructions", strings.SplitN(buf.String(), "\n", 2)[0])
+	}
+	kdb, err := paper.Decode(buf)
+	if err != nil {
+		t.Fatal("paper.Decode:", err)
+	}
+	rdb, err := keepass.Open(bytes.NewReader(kdb), &keepass.Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal("keepass.Open:", err)
+	}
+	if got := entryPaths(nil, rdb.Root(), ""); len(got) != 1 || got[0] != "Work/Mail" {
+		t.Errorf("restored entries = %q; want [Work/Mail]", got)
+	}
+}
+
+func TestShareEntry(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Work/VPN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"Office", "Lab"} {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title = title
+		e.Password = "old"
+		e.AddRevision()
+		e.Password = "new"
+		e.Attachment.Name = "client.ovpn"
+		e.Attachment.Data = []byte("remote " + title)
+		e.CustomData.Set("gostpass.test", "1")
+		if err := e.SetField(keepass.Field{Name: "pin", Value: "1234", Protected: true}); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.SetField(keepass.Field{Name: "account", Value: "42"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e, err := findEntryPath(db, "Work/VPN/Office")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, history := range []bool{false, true} {
+		buf := new(bytes.Buffer)
+		if err := shareEntry(buf, e, history, &keepass.Options{Password: "swordfish", KeyRounds: 1}); err != nil {
+			t.Fatal("shareEntry:", err)
+		}
+		sdb, err := keepass.Open(buf, &keepass.Options{Password: "swordfish"})
+		if err != nil {
+			t.Fatal("keepass.Open:", err)
+		}
+		if got := entryPaths(nil, sdb.Root(), ""); len(got) != 1 || got[0] != "VPN/Office" {
+			t.Fatalf("shared entries = %q; want [VPN/Office]", got)
+		}
+		se := sdb.Find(e.UUID)
+		if se == nil || se.Password != "new" || string(se.Attachment.Data) != "remote Office" {
+			t.Errorf("shared entry = %+v; want a copy of Office", se)
+			continue
+		}
+		if _, ok := se.CustomData.Get("gostpass.test"); ok {
+			t.Errorf("shared entry's custom data = %v; want only fields", se.CustomData)
+		}
+		for _, want := range []keepass.Field{{Name: "pin", Value: "1234", Protected: true}, {Name: "account", Value: "42"}} {
+			if f, _, err := se.Field(want.Name); err != nil || f != want {
+				t.Errorf("shared entry's field %s = %+v, %v; want %+v", want.Name, f, err, want)
+			}
+		}
+		if got := len(se.History) > 0; got != history {
+			t.Errorf("with history = %t, shared entry's history = %+v", history, se.History)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/urlmatch"
+)
+
+// Custom data keys that override URL matching for an entry.  The mode is
+// a urlmatch mode name; the regular expression is used in "regex" mode.
+const (
+	urlMatchKey = "gostpass.url_match"
+	urlRegexKey = "gostpass.url_regex"
+)
+
+// entryURLRule returns the rule for matching pages against e, using mode
+// unless the entry overrides it.
+func entryURLRule(e *keepass.Entry, mode urlmatch.Mode, list *urlmatch.List) (*urlmatch.Rule, error) {
+	r := &urlmatch.Rule{URL: e.URL, Mode: mode, List: list}
+	if s, ok := e.CustomData.Get(urlMatchKey); ok {
+		m, err := urlmatch.ParseMode(s)
+		if err != nil {
+			return nil, err
+		}
+		r.Mode = m
+	}
+	if r.Mode == urlmatch.Regexp {
+		var ok bool
+		if r.Pattern, ok = e.CustomData.Get(urlRegexKey); !ok {
+			return nil, fmt.Errorf("%s is %q, but %s is not set", urlMatchKey, r.Mode, urlRegexKey)
+		}
+	}
+	return r, nil
+}
+
+// findByURL returns the entries that apply to the page at pageURL.
+// Entries in the recycle bin and entries with broken rules are skipped;
+// the latter are reported through warn.
+func findByURL(db *keepass.Database, pageURL string, mode urlmatch.Mode, list *urlmatch.List, warn func(*keepass.Entry, error)) []*keepass.Entry {
+	var found []*keepass.Entry
+	for _, e := range db.Entries() {
+		if e.Parent().InRecycleBin() {
+			continue
+		}
+		r, err := entryURLRule(e, mode, list)
+		if err != nil {
+			warn(e, err)
+			continue
+		}
+		if r.URL == "" && r.Mode != urlmatch.Regexp {
+			continue
+		}
+		ok, err := r.Match(pageURL)
+		if err != nil {
+			warn(e, err)
+			continue
+		}
+		if ok {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// A foundEntry is what a find -format template sees for each entry.
+// The password and protected fields are methods, so that an entry is only
+// released when the template actually uses them.
+type foundEntry struct {
+	Group    string    `json:"group"`
+	Title    string    `json:"title"`
+	Username string    `json:"username"`
+	URL      string    `json:"url"`
+	Notes    string    `json:"notes"`
+	Path     string    `json:"path"`
+	UUID     string    `json:"uuid"`
+	Modified time.Time `json:"modified"`
+
+	e *keepass.Entry
+}
+
+func newFoundEntry(e *keepass.Entry) foundEntry {
+	return foundEntry{
+		Group:    e.Parent().Path(),
+		Title:    e.Title,
+		Username: e.Username,
+		URL:      e.URL,
+		Notes:    e.Notes,
+		Path:     entryPath(e),
+		UUID:     e.UUID.String(),
+		Modified: e.LastModificationTime.UTC(),
+		e:        e,
+	}
+}
+
+// Password returns the entry's password.
+func (f foundEntry) Password() (string, error) {
+	if err := releaseEntry(f.e, "find"); err != nil {
+		return "", err
+	}
+	return f.e.Password, nil
+}
+
+// Field returns the value of the named field, or "" if the entry has no
+// such field.
+func (f foundEntry) Field(name string) (string, error) {
+	fld, ok, err := f.e.Field(name)
+	if err != nil || !ok {
+		return "", err
+	}
+	if fld.Protected {
+		if err := releaseEntry(f.e, "find"); err != nil {
+			return "", err
+		}
+	}
+	return fld.Value, nil
+}
+
+// findFormats are the -format names that stand for a built-in template.
+var findFormats = map[string]string{
+	"path": "{{.Path}}",
+	"tsv":  "{{.Path}}\t{{.Username}}\t{{.URL}}",
+	"json": "{{json .}}",
+}
+
+// parseFindFormat parses a find -format value: the name of a built-in
+// format or a template.  \t and \n stand for a tab and a newline, since
+// they are awkward to type on a command line.
+func parseFindFormat(format string) (*template.Template, error) {
+	if f, ok := findFormats[format]; ok {
+		format = f
+	}
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	funcs := template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}
+	return template.New("format").Funcs(funcs).Parse(format)
+}
+
+// runFind lists the entries that apply to a URL, contain all of the given
+// words in their title or notes, or both.
+func runFind(args []string) error {
+	fs := flag.NewFlagSet("find", flag.ContinueOnError)
+	pageURL := fs.String("url", "", "list entries that apply to the page at `URL`")
+	match := fs.String("match", "base", "default URL match mode: base, host, prefix, exact or never; entries may override it with "+urlMatchKey)
+	pslPath := fs.String("psl", "", "public suffix list `file` to use instead of the built-in excerpt")
+	format := fs.String("format", "", "print each entry with a Go `template`, or one of path, tsv or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *pageURL == "" && fs.NArg() == 0 {
+		return errors.New("usage: find [-url URL [-match mode] [-psl file]] [-format template] [word...]")
+	}
+	var tmpl *template.Template
+	if *format != "" {
+		var err error
+		if tmpl, err = parseFindFormat(*format); err != nil {
+			return fmt.Errorf("-format: %v", err)
+		}
+	}
+	mode, err := urlmatch.ParseMode(*match)
+	if err != nil {
+		return err
+	}
+	if mode == urlmatch.Regexp {
+		return errors.New("regex mode needs a pattern, so it can only be set per entry")
+	}
+	list := urlmatch.DefaultList
+	if *pslPath != "" {
+		f, err := os.Open(*pslPath)
+		if err != nil {
+			return err
+		}
+		list, err = urlmatch.ParseList(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	var found []*keepass.Entry
+	if *pageURL != "" {
+		found = findByURL(db, *pageURL, mode, list, func(e *keepass.Entry, err error) {
+			fmt.Fprintf(stderr, "gostpass find: %s: %v\n", entryPath(e), err)
+		})
+	} else {
+		for _, e := range db.Entries() {
+			if !e.Parent().InRecycleBin() {
+				found = append(found, e)
+			}
+		}
+	}
+	if fs.NArg() > 0 {
+		pq := parseQuery(strings.Join(fs.Args(), " "))
+		var matched []*keepass.Entry
+		for _, e := range found {
+			if pq.matchesEntry(e) {
+				matched = append(matched, e)
+			}
+		}
+		found = matched
+	}
+	if tmpl == nil {
+		for _, e := range found {
+			fmt.Printf(tr("%s  user %q  %s\n"), entryPath(e), e.Username, e.URL)
+		}
+		return nil
+	}
+	return writeFound(os.Stdout, tmpl, found)
+}
+
+// writeFound executes tmpl for each entry, one per line.
+func writeFound(w io.Writer, tmpl *template.Template, found []*keepass.Entry) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range found {
+		if err := tmpl.Execute(bw, newFoundEntry(e)); err != nil {
+			return fmt.Errorf("-format: %v", err)
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/urlmatch"
+)
+
+func TestFindByURL(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newEntry := func(title, url string, cd map[string]string) {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title, e.URL = title, url
+		for k, v := range cd {
+			e.CustomData.Set(k, v)
+		}
+	}
+	newEntry("Mail", "https://mail.example.com", nil)
+	newEntry("Admin", "https://admin.example.com", map[string]string{urlMatchKey: "host"})
+	newEntry("Pattern", "", map[string]string{urlMatchKey: "regex", urlRegexKey: `https://[a-z]+\.example\.com/admin/.*`})
+	newEntry("Broken", "https://example.com", map[string]string{urlMatchKey: "fuzzy"})
+	newEntry("Other", "https://example.org", nil)
+	newEntry("No URL", "", nil)
+
+	var warned []string
+	warn := func(e *keepass.Entry, err error) { warned = append(warned, e.Title) }
+	found := findByURL(db, "https://www.example.com/admin/users", urlmatch.BaseDomain, nil, warn)
+	var titles []string
+	for _, e := range found {
+		titles = append(titles, e.Title)
+	}
+	if len(titles) != 2 || titles[0] != "Mail" || titles[1] != "Pattern" {
+		t.Errorf("findByURL = %v; want [Mail Pattern]", titles)
+	}
+	if len(warned) != 1 || warned[0] != "Broken" {
+		t.Errorf("warnings for %v; want [Broken]", warned)
+	}
+}
+
+func TestWriteFound(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_find_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldPath, oldAsker := *accessLogPath, releaseAsker
+	*accessLogPath = filepath.Join(dir, "access.log")
+	defer func() { *accessLogPath, releaseAsker = oldPath, oldAsker }()
+	var asked int
+	releaseAsker = func(string) bool {
+		asked++
+		return false
+	}
+
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Work/Web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := g.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Title, e.Username, e.URL, e.Password = "Mail", "alice", "https://mail.example.com", "hunter2"
+	found := []*keepass.Entry{e}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{`{{.Group}}/{{.Title}}\t{{.Username}}`, "Work/Web/Mail\talice\n"},
+		{"path", "Work/Web/Mail\n"},
+		{"tsv", "Work/Web/Mail\talice\thttps://mail.example.com\n"},
+		{"{{.Password}}", "hunter2\n"},
+	}
+	for _, test := range tests {
+		tmpl, err := parseFindFormat(test.format)
+		if err != nil {
+			t.Errorf("parseFindFormat(%q): %v", test.format, err)
+			continue
+		}
+		buf := new(bytes.Buffer)
+		if err := writeFound(buf, tmpl, found); err != nil {
+			t.Errorf("writeFound(%q): %v", test.format, err)
+		} else if buf.String() != test.want {
+			t.Errorf("writeFound(%q) = %q; want %q", test.format, buf.String(), test.want)
+		}
+	}
+
+	tmpl, err := parseFindFormat("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := writeFound(buf, tmpl, found); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); !strings.Contains(s, `"path":"Work/Web/Mail"`) || strings.Contains(s, "hunter2") {
+		t.Errorf("json format = %q; want path and no password", s)
+	}
+
+	// Only templates that use the password release the entry.
+	e.CustomData.SetBool(confirmReleaseKey, true)
+	tmpl, err = parseFindFormat("{{.Title}} {{.Password}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFound(new(bytes.Buffer), tmpl, found); err == nil {
+		t.Error("writeFound with refused password succeeded")
+	}
+	if asked != 1 {
+		t.Errorf("asked %d times; want 1", asked)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// gitGroup is the group that credentials stored by git are added to.
+const gitGroup = "Git"
+
+// readGitCredential reads a git credential request: key=value lines up to
+// a blank line or the end of input.
+func readGitCredential(r *bufio.Reader) (map[string]string, error) {
+	req := make(map[string]string)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			return req, nil
+		}
+		i := strings.IndexByte(line, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("malformed line %q", line)
+		}
+		req[line[:i]] = line[i+1:]
+		if err == io.EOF {
+			return req, nil
+		}
+	}
+}
+
+// gitMatch reports how well e's URL matches a git credential request, or
+// -1 if it doesn't.  Host and username must match exactly; the scheme and
+// path are checked only if the entry's URL has them, and a longer matching
+// path scores higher.
+func gitMatch(e *keepass.Entry, req map[string]string) int {
+	u, err := url.Parse(e.URL)
+	if err != nil || u.Host == "" {
+		return -1
+	}
+	if !strings.EqualFold(u.Host, req["host"]) {
+		return -1
+	}
+	if u.Scheme != "" && req["protocol"] != "" && u.Scheme != req["protocol"] {
+		return -1
+	}
+	if req["username"] != "" && e.Username != req["username"] {
+		return -1
+	}
+	path := strings.Trim(u.Path, "/")
+	if path == "" {
+		return 0
+	}
+	reqPath := strings.Trim(req["path"], "/")
+	if reqPath != path && !strings.HasPrefix(reqPath, path+"/") {
+		return -1
+	}
+	return len(path)
+}
+
+// findGitEntry returns the entry that best matches a request, preferring
+// the most recently modified among equals.  Entries in the recycle bin
+// are ignored.
+func findGitEntry(db *keepass.Database, req map[string]string) *keepass.Entry {
+	var best *keepass.Entry
+	bestScore := -1
+	for _, e := range db.Entries() {
+		if e.Parent().InRecycleBin() {
+			continue
+		}
+		score := gitMatch(e, req)
+		if score < 0 {
+			continue
+		}
+		if score > bestScore || score == bestScore && e.LastModificationTime.After(best.LastModificationTime) {
+			best, bestScore = e, score
+		}
+	}
+	return best
+}
+
+// gitCredential runs one action of git's credential helper protocol on
+// db.  It reports whether db was changed.
+func gitCredential(db *keepass.Database, action string, req map[string]string, w io.Writer) (changed bool, err error) {
+	if req["host"] == "" {
+		// Nothing to match on; let git ask elsewhere.
+		return false, nil
+	}
+	e := findGitEntry(db, req)
+	switch action {
+	case "get":
+		if e == nil {
+			return false, nil
+		}
+		if err := releaseEntry(e, "git-credential"); err != nil {
+			return false, err
+		}
+		_, err := fmt.Fprintf(w, "username=%s\npassword=%s\n", e.Username, e.Password)
+		return false, err
+	case "store":
+		if req["username"] == "" || req["password"] == "" {
+			return false, nil
+		}
+		now := time.Now()
+		if e == nil {
+			g, err := db.MkdirAll(gitGroup)
+			if err != nil {
+				return false, err
+			}
+			if e, err = g.NewEntry(); err != nil {
+				return false, err
+			}
+			u := url.URL{Scheme: req["protocol"], Host: req["host"], Path: "/" + req["path"]}
+			e.Title = req["host"]
+			e.URL = strings.TrimSuffix(u.String(), "/")
+			e.Username = req["username"]
+			e.CreationTime = now
+		} else if e.Password == req["password"] {
+			return false, nil
+		} else {
+			e.AddRevision()
+		}
+		e.Password = req["password"]
+		e.LastModificationTime = now
+		return true, nil
+	case "erase":
+		// Git erases credentials that were rejected.  Only entries git
+		// stored itself are removed; others may just be out of date.
+		if e == nil || e.Password != req["password"] || e.Parent() != db.FindGroupPath(gitGroup) {
+			return false, nil
+		}
+		return true, db.RecycleEntry(e)
+	default:
+		return false, fmt.Errorf("unknown action %q", action)
+	}
+}
+
+// runGitCredential implements git's credential helper protocol.  Set it up
+// with:
+//
+//	git config --global credential.helper '!gostpass -db ~/vault.kdb git-credential'
+//
+// The password is read from the terminal or from -password_file, since
+// standard input carries the request.
+func runGitCredential(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: git-credential get|store|erase")
+	}
+	stdinProtocol = true
+	req, err := readGitCredential(stdin)
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	changed, err := gitCredential(db, args[0], req, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if changed {
+		return writeDatabase(db)
+	}
+	return nil
+}
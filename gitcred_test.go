@@ -0,0 +1,101 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestReadGitCredential(t *testing.T) {
+	req, err := readGitCredential(bufio.NewReader(strings.NewReader("protocol=https\nhost=example.com\npath=a=b\n\nignored=1\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"protocol": "https", "host": "example.com", "path": "a=b"}
+	if !reflect.DeepEqual(req, want) {
+		t.Errorf("readGitCredential = %v; want %v", req, want)
+	}
+	if _, err := readGitCredential(bufio.NewReader(strings.NewReader("host\n"))); err == nil {
+		t.Error("readGitCredential(malformed) succeeded")
+	}
+}
+
+func TestGitCredential(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ent := range []struct{ url, username, password string }{
+		{"https://git.example.com", "bob", "general"},
+		{"https://git.example.com/team/repo", "bob", "specific"},
+		{"http://git.example.com", "bob", "insecure"},
+		{"https://git.example.com.evil", "bob", "phished"},
+	} {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.URL, e.Username, e.Password = ent.url, ent.username, ent.password
+	}
+	get := func(req map[string]string) string {
+		out := new(bytes.Buffer)
+		if _, err := gitCredential(db, "get", req, out); err != nil {
+			t.Fatalf("get %v: %v", req, err)
+		}
+		return out.String()
+	}
+	tests := []struct {
+		req  map[string]string
+		want string
+	}{
+		{map[string]string{"protocol": "https", "host": "git.example.com"}, "username=bob\npassword=general\n"},
+		{map[string]string{"protocol": "https", "host": "GIT.example.com", "path": "team/repo.git"}, "username=bob\npassword=general\n"},
+		{map[string]string{"protocol": "https", "host": "git.example.com", "path": "team/repo/sub"}, "username=bob\npassword=specific\n"},
+		{map[string]string{"protocol": "http", "host": "git.example.com"}, "username=bob\npassword=insecure\n"},
+		{map[string]string{"protocol": "https", "host": "git.example.com", "username": "alice"}, ""},
+		{map[string]string{"protocol": "https", "host": "example.com"}, ""},
+	}
+	for _, test := range tests {
+		if got := get(test.req); got != test.want {
+			t.Errorf("get %v = %q; want %q", test.req, got, test.want)
+		}
+	}
+
+	stored := map[string]string{"protocol": "https", "host": "code.example.org", "username": "carol", "password": "s3cret"}
+	if changed, err := gitCredential(db, "store", stored, nil); err != nil || !changed {
+		t.Fatalf("store = %t, %v; want true, <nil>", changed, err)
+	}
+	if got := get(map[string]string{"protocol": "https", "host": "code.example.org"}); got != "username=carol\npassword=s3cret\n" {
+		t.Errorf("get after store = %q", got)
+	}
+	if changed, err := gitCredential(db, "erase", stored, nil); err != nil || !changed {
+		t.Errorf("erase stored = %t, %v; want true, <nil>", changed, err)
+	}
+	general := map[string]string{"protocol": "https", "host": "git.example.com", "password": "general"}
+	if changed, err := gitCredential(db, "erase", general, nil); err != nil || changed {
+		t.Errorf("erase of entry outside %s = %t, %v; want false, <nil>", gitGroup, changed, err)
+	}
+}
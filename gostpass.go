@@ -37,6 +37,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/memlock"
 	"github.com/pedroalbanese/gostpass/pkg/sandstormhdr"
 	"github.com/pedroalbanese/gostpass/pkg/uuids"
 )
@@ -45,6 +46,10 @@ var (
 	listen       = flag.String("listen", "[::]:8080", "address to listen on")
 	dbPath       = flag.String("db", "", "path to database")
 	templatesDir = flag.String("templates_dir", "templates", "path to template directory")
+	lockMemory   = flag.Bool("mlock", false, "lock all process memory into RAM so secrets are never swapped to disk")
+	tlsCert      = flag.String("tls_cert", "", "path to a PEM certificate chain to serve HTTPS with; requires -tls_key")
+	tlsKey       = flag.String("tls_key", "", "path to the PEM private key for -tls_cert")
+	maxKeyRounds = flag.Int("max_key_rounds", keepass.DefaultMaxKeyRounds, "refuse to open a database that asks for more key transform rounds, so that a crafted file can't tie up the process; negative means no limit")
 )
 
 // Read-only globals
@@ -61,11 +66,33 @@ var (
 )
 
 func main() {
+	defer scrubPanic()
 	flag.Parse()
+	if err := initRand(); err != nil {
+		fmt.Fprintln(stderr, "gostpass:", err)
+		os.Exit(2)
+	}
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(flag.Args()))
+	}
 	if *dbPath == "" || sessions.keyPath == "" {
 		log.Println("must specify -db and -session_key")
 		os.Exit(1)
 	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		log.Println("-tls_cert and -tls_key must be given together")
+		os.Exit(1)
+	}
+	// Secrets are only ever held in memory, so keep them there.  Both of
+	// these are best-effort: the server is still usable without them.
+	if err := memlock.DisableCoreDumps(); err != nil {
+		log.Println("disable core dumps:", err)
+	}
+	if *lockMemory {
+		if err := memlock.LockAll(); err != nil {
+			log.Println("lock memory:", err)
+		}
+	}
 
 	if err := initTemplates(); err != nil {
 		log.Println("failed to parse templates:", err)
@@ -77,7 +104,13 @@ func main() {
 		os.Exit(1)
 	}
 	initHandlers()
-	if err := http.ListenAndServe(*listen, nil); err != nil {
+	var err error
+	if *tlsCert != "" {
+		err = http.ListenAndServeTLS(*listen, *tlsCert, *tlsKey, nil)
+	} else {
+		err = http.ListenAndServe(*listen, nil)
+	}
+	if err != nil {
 		log.Println("listen:", err)
 		os.Exit(1)
 	}
@@ -96,7 +129,10 @@ func initTemplates() error {
 func initDatabase() error {
 	var err error
 	dbStorage, err = newStorage(*dbPath)
-	return err
+	if err != nil {
+		return err
+	}
+	return initDuress()
 }
 
 func initHandlers() {
@@ -106,6 +142,8 @@ func initHandlers() {
 	r.Handle("/search", appHandler{f: handleSearch}).Name("search")
 	r.Handle("/nuke", appHandler{f: confirmNuke, perm: "init"}).Methods("GET").Name("confirmNuke")
 	r.Handle("/nuke", appHandler{f: nuke, perm: "init"}).Methods("POST").Name("nuke")
+	r.Handle("/s/{id}", appHandler{f: viewShare}).Methods("GET", "HEAD").Name("viewShare")
+	r.Handle("/s/{id}", appHandler{f: openShare, noXSRF: true}).Methods("POST").Name("openShare")
 
 	rGroupDir := r.PathPrefix("/g").Subrouter()
 	rGroupDir.Handle("/", appHandler{f: listGroups}).Methods("GET").Name("listGroups")
@@ -118,6 +156,8 @@ func initHandlers() {
 	rGroup.Handle("/edit", appHandler{f: postGroupForm, perm: "write"}).Methods("GET").Name("editGroupForm")
 	rGroup.Handle("/delete", appHandler{f: confirmDeleteGroup, perm: "write"}).Methods("GET").Name("confirmDeleteGroup")
 	rGroup.Handle("/delete", appHandler{f: deleteGroup, perm: "write"}).Methods("POST").Name("deleteGroup")
+	rGroup.Handle("/acl", appHandler{f: viewGroupACL}).Methods("GET").Name("viewGroupACL")
+	rGroup.Handle("/acl", appHandler{f: postGroupACL, perm: "write"}).Methods("POST").Name("editGroupACL")
 
 	rEntryDir := r.PathPrefix("/entry").Subrouter()
 	rEntryDir.Handle("/", appHandler{f: postEntry, perm: "write"}).Methods("POST").Name("newEntry")
@@ -138,6 +178,14 @@ func initHandlers() {
 	meta.Handle("/newdb", appHandler{f: newDB, perm: "init"}).Methods("POST").Name("newDB")
 	meta.Handle("/start", appHandler{f: startSession}).Methods("POST").Name("startSession")
 	meta.Handle("/pwgen", appHandler{f: pwgen}).Methods("GET").Name("pwgen")
+	meta.Handle("/export", appHandler{f: exportDB}).Methods("POST").Name("exportDB")
+	meta.Handle("/escrow", appHandler{f: enrollEscrow, perm: "init"}).Methods("POST").Name("enrollEscrow")
+	meta.Handle("/escrow/revoke", appHandler{f: revokeEscrow, perm: "init"}).Methods("POST").Name("revokeEscrow")
+	meta.Handle("/escrow/recover", appHandler{f: recoverEscrow}).Methods("POST").Name("recoverEscrow")
+	meta.Handle("/splitkey", appHandler{f: splitKey, perm: "init"}).Methods("POST").Name("splitKey")
+	meta.Handle("/recoverkey", appHandler{f: recoverKey}).Methods("POST").Name("recoverKey")
+	meta.Handle("/share.js", appHandler{f: serveShareScript}).Methods("GET", "HEAD").Name("shareScript")
+	meta.Handle("/rotatekeyfile", appHandler{f: rotateKeyFile, perm: "init"}).Methods("POST").Name("rotateKeyFile")
 
 	// Static files
 	staticFiles := []struct {
@@ -264,6 +312,9 @@ func viewGroup(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		return err
 	}
+	if err := checkGroupAccess(r, db, g, false); err != nil {
+		return err
+	}
 	return tmpl.ExecuteTemplate(w, "group.html", struct {
 		Group       *keepass.Group
 		Permissions permissions
@@ -284,6 +335,9 @@ func viewEntry(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		return err
 	}
+	if err := checkGroupAccess(r, db, e.Parent(), false); err != nil {
+		return err
+	}
 	return tmpl.ExecuteTemplate(w, "entry.html", struct {
 		Entry       *keepass.Entry
 		Group       *keepass.Group
@@ -295,30 +349,50 @@ func viewEntry(w http.ResponseWriter, r *http.Request) error {
 	})
 }
 
+// downloadAttachment sends an entry's attachment, or the byte ranges of it
+// that the request asks for, so that large downloads can be resumed.
 func downloadAttachment(w http.ResponseWriter, r *http.Request) error {
-	mu.Lock()
-	defer mu.Unlock()
-	db, err := sessions.dbFromRequest(w, r)
-	if err != nil {
-		return err
-	}
-	e, err := requestEntry(db, mux.Vars(r))
+	e, err := attachmentEntry(w, r)
 	if err != nil {
 		return err
 	}
-	if !e.HasAttachment() {
-		return notFoundError{}
-	}
+	// The database was opened for this request alone, so the attachment
+	// can be sent without holding mu, which would stall every other
+	// request for as long as a large download takes.
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.Attachment.Name))
-	w.Header().Set("Content-Length", strconv.Itoa(len(e.Attachment.Data)))
 	contentType := mime.TypeByExtension(slashpath.Ext(e.Attachment.Name))
 	if contentType == "" {
 		// http.DetectContentType always returns a valid MIME type.
 		contentType = http.DetectContentType(e.Attachment.Data)
 	}
 	w.Header().Set("Content-Type", contentType)
-	_, err = w.Write(e.Attachment.Data)
-	return err
+	// The entry changes whenever its attachment does, which makes this a
+	// strong validator for If-Range.
+	w.Header().Set("ETag", fmt.Sprintf(`"%v-%x"`, e.UUID, e.LastModificationTime.UnixNano()))
+	http.ServeContent(w, r, e.Attachment.Name, e.LastModificationTime, bytes.NewReader(e.Attachment.Data))
+	return nil
+}
+
+// attachmentEntry returns the request's entry if it has an attachment that
+// the user may read.
+func attachmentEntry(w http.ResponseWriter, r *http.Request) (*keepass.Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	db, err := sessions.dbFromRequest(w, r)
+	if err != nil {
+		return nil, err
+	}
+	e, err := requestEntry(db, mux.Vars(r))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGroupAccess(r, db, e.Parent(), false); err != nil {
+		return nil, err
+	}
+	if !e.HasAttachment() {
+		return nil, notFoundError{}
+	}
+	return e, nil
 }
 
 func postEntryForm(w http.ResponseWriter, r *http.Request) error {
@@ -349,6 +423,9 @@ func postEntryForm(w http.ResponseWriter, r *http.Request) error {
 	} else {
 		parent = e.Parent()
 	}
+	if err := checkGroupAccess(r, db, parent, true); err != nil {
+		return err
+	}
 	return tmpl.ExecuteTemplate(w, "editentry.html", struct {
 		Entry         *keepass.Entry
 		Group         *keepass.Group
@@ -372,6 +449,7 @@ func postEntry(w http.ResponseWriter, r *http.Request) error {
 	var e *keepass.Entry
 	err := transaction(w, r, func(db *keepass.Database) error {
 		var err error
+		var prev *keepass.Revision
 		e, err = requestEntry(db, mux.Vars(r))
 		if err != nil {
 			return err
@@ -380,6 +458,14 @@ func postEntry(w http.ResponseWriter, r *http.Request) error {
 		if err != nil {
 			return err
 		}
+		if err := checkGroupAccess(r, db, newParent, true); err != nil {
+			return err
+		}
+		if e != nil {
+			if err := checkGroupAccess(r, db, e.Parent(), true); err != nil {
+				return err
+			}
+		}
 		if e == nil {
 			e, err = newParent.NewEntry()
 			if err != nil {
@@ -389,9 +475,13 @@ func postEntry(w http.ResponseWriter, r *http.Request) error {
 				CreationTime:   now,
 				LastAccessTime: now,
 			}
-		} else if parent := e.Parent(); newParent != parent {
-			if err := e.SetParent(newParent); err != nil {
-				return err
+		} else {
+			rev := e.Revision()
+			prev = &rev
+			if parent := e.Parent(); newParent != parent {
+				if err := e.SetParent(newParent); err != nil {
+					return err
+				}
 			}
 		}
 		e.Title = r.FormValue("title")
@@ -408,6 +498,14 @@ func postEntry(w http.ResponseWriter, r *http.Request) error {
 			return err
 		}
 		e.Notes = r.FormValue("notes")
+		if prev != nil {
+			// Only keep a revision if the save changed something.
+			cur := e.Revision()
+			cur.Modified = prev.Modified
+			if cur != *prev {
+				e.History = append(e.History, *prev)
+			}
+		}
 		e.LastModificationTime = now
 		return nil
 	})
@@ -432,6 +530,9 @@ func confirmDeleteAttachment(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		return err
 	}
+	if err := checkGroupAccess(r, db, e.Parent(), true); err != nil {
+		return err
+	}
 	return tmpl.ExecuteTemplate(w, "deleteattachment.html", struct {
 		Entry     *keepass.Entry
 		Group     *keepass.Group
@@ -454,6 +555,9 @@ func deleteAttachment(w http.ResponseWriter, r *http.Request) error {
 		if err != nil {
 			return err
 		}
+		if err := checkGroupAccess(r, db, e.Parent(), true); err != nil {
+			return err
+		}
 		if !e.HasAttachment() {
 			return notFoundError{}
 		}
@@ -483,6 +587,9 @@ func confirmDeleteEntry(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		return err
 	}
+	if err := checkGroupAccess(r, db, e.Parent(), true); err != nil {
+		return err
+	}
 	return tmpl.ExecuteTemplate(w, "deleteentry.html", struct {
 		Entry     *keepass.Entry
 		Group     *keepass.Group
@@ -504,6 +611,9 @@ func deleteEntry(w http.ResponseWriter, r *http.Request) error {
 			return err
 		}
 		parent = e.Parent()
+		if err := checkGroupAccess(r, db, parent, true); err != nil {
+			return err
+		}
 		parent.RemoveEntry(e)
 		return nil
 	})
@@ -549,10 +659,16 @@ func postGroupForm(w http.ResponseWriter, r *http.Request) error {
 		}
 		params.Parent = parent
 		params.NewGroup = true
+		if err := checkGroupAccess(r, db, parent, true); err != nil {
+			return err
+		}
 	} else {
 		params.Group = g
 		params.Parent = g.Parent()
 		exclude = func(gg *keepass.Group) bool { return gg == g }
+		if err := checkGroupAccess(r, db, g, true); err != nil {
+			return err
+		}
 	}
 	root := db.Root()
 	params.ParentOptions = []groupItem{{root, 0}}
@@ -575,6 +691,14 @@ func postGroup(w http.ResponseWriter, r *http.Request) error {
 		if err != nil {
 			return err
 		}
+		if err := checkGroupAccess(r, db, newParent, true); err != nil {
+			return err
+		}
+		if g != nil {
+			if err := checkGroupAccess(r, db, g, true); err != nil {
+				return err
+			}
+		}
 		if g == nil {
 			g = newParent.NewSubgroup()
 			g.TimeInfo = keepass.TimeInfo{
@@ -611,6 +735,9 @@ func confirmDeleteGroup(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		return err
 	}
+	if err := checkGroupAccess(r, db, g, true); err != nil {
+		return err
+	}
 	return tmpl.ExecuteTemplate(w, "deletegroup.html", struct {
 		Group     *keepass.Group
 		XSRFToken string
@@ -628,7 +755,16 @@ func deleteGroup(w http.ResponseWriter, r *http.Request) error {
 		if err != nil {
 			return err
 		}
-		return g.Parent().RemoveSubgroup(g)
+		if err := checkGroupAccess(r, db, g, true); err != nil {
+			return err
+		}
+		if err := g.Parent().RemoveSubgroup(g); err != nil {
+			return err
+		}
+		if err := dropGroupACLs(db, []uint32{g.ID}); err != nil {
+			return err
+		}
+		return dropTokenGroups(db, []uint32{g.ID})
 	})
 	if err != nil {
 		return err
@@ -658,6 +794,9 @@ func nuke(w http.ResponseWriter, r *http.Request) error {
 	if err := dbStorage.remove(); err != nil {
 		return err
 	}
+	if err := removeUnusedBlobs(nil); err != nil {
+		return err
+	}
 	if err := sessions.invalidateAll(); err != nil {
 		return err
 	}
@@ -688,10 +827,10 @@ func newDB(w http.ResponseWriter, r *http.Request) error {
 	}
 	var db *keepass.Database
 	if f == nil {
-		db, err = keepass.New(&keepass.Options{
+		db, err = keepass.New(flagOptions(&keepass.Options{
 			Password: password,
 			KeyFile:  optReader(keyfile),
-		})
+		}))
 		if err != nil {
 			return err
 		}
@@ -702,6 +841,7 @@ func newDB(w http.ResponseWriter, r *http.Request) error {
 	} else if db, err = importDB(f, password, keyfile); err != nil {
 		return err
 	}
+	secrets.Add(password)
 
 	_, err = sessions.new(w, sessionData{
 		Key: db.ComputedKey(),
@@ -734,15 +874,35 @@ func prepopulateDB(db *keepass.Database, now time.Time) {
 }
 
 func importDB(f io.ReadSeeker, password string, keyfile []byte) (*keepass.Database, error) {
-	db, err := keepass.Open(f, &keepass.Options{
+	db, err := keepass.Open(f, flagOptions(&keepass.Options{
 		Password: password,
 		KeyFile:  optReader(keyfile),
-	})
+	}))
 	if err == keepass.ErrHashMismatch {
 		return nil, rootRedirectError{userError{
 			msg: "Unable to decrypt database. Check password and try again.",
 			err: fmt.Errorf("import database: %v", err),
 		}}
+	} else if _, ok := err.(*keepass.KeyRoundsError); ok {
+		return nil, rootRedirectError{userError{
+			msg: "The database's key derivation is too slow to open here.  Lower its key rounds before importing it.",
+			err: fmt.Errorf("import database: %v", err),
+		}}
+	} else if _, ok := err.(*keepass.LimitError); ok {
+		return nil, rootRedirectError{userError{
+			msg: "The database is too large to import.",
+			err: fmt.Errorf("import database: %v", err),
+		}}
+	} else if fe, ok := err.(*keepass.FormatError); ok {
+		return nil, rootRedirectError{userError{
+			msg: fmt.Sprintf("The file is %s, which can't be imported.  Only KeePass 1 databases with GOST encryption can.", fe.Format),
+			err: fmt.Errorf("import database: %v", err),
+		}}
+	} else if err == keepass.ErrWrongSignature {
+		return nil, rootRedirectError{userError{
+			msg: "The file is not a KeePass database.",
+			err: fmt.Errorf("import database: %v", err),
+		}}
 	} else if err != nil {
 		return nil, fmt.Errorf("import database: %v", err)
 	}
@@ -773,26 +933,43 @@ func startSession(w http.ResponseWriter, r *http.Request) error {
 	mu.Lock()
 	defer mu.Unlock()
 
+	if err := unlocks.allow(); err != nil {
+		return rootRedirectError{err}
+	}
 	db, err := openDatabase(&keepass.Options{
 		Password: password,
 		KeyFile:  optReader(keyfile),
 	})
+	decoy := false
+	if isUserError(err) {
+		if ddb, derr := openDuress(password, keyfile); derr == nil {
+			db, err, decoy = ddb, nil, true
+		}
+	}
+	unlocks.record(r, err)
 	if isUserError(err) {
 		return rootRedirectError{err}
 	} else if err != nil {
 		return err
 	}
+	secrets.Add(password)
 
 	_, err = sessions.new(w, sessionData{
-		Key: db.ComputedKey(),
+		Key:   db.ComputedKey(),
+		Decoy: decoy,
 	})
 	if err != nil {
 		return err
 	}
+	// A duress unlock runs the hook too, so that it looks like any other.
+	runNotifyHook("post-unlock", *postUnlockHook, "GOSTPASS_CLIENT=web "+r.RemoteAddr)
 	return redirectRoute(w, r, "listGroups")
 }
 
-// readCredentials gets credentials from a request.
+// readCredentials gets credentials from a request.  The password is not
+// added to the scrubbed secrets until it has opened a database, so that a
+// failed unlock can't hide arbitrary text, like the client's address, from
+// the logs.
 func readCredentials(req *http.Request) (password string, keyfile []byte, err error) {
 	password = req.FormValue("password")
 	kf, _, err := req.FormFile("keyfile")
@@ -821,38 +998,138 @@ func transaction(w http.ResponseWriter, r *http.Request, f func(*keepass.Databas
 	if err != nil {
 		return err
 	}
-	return writeDatabase(db)
+	return writeStorage(sessions.storageFromRequest(r), db, requestAuthor(r))
 }
 
 func openDatabase(opts *keepass.Options) (*keepass.Database, error) {
-	if !dbStorage.exists() {
+	return openStorage(dbStorage, opts)
+}
+
+func openStorage(st *storage, opts *keepass.Options) (*keepass.Database, error) {
+	if !st.exists() {
 		return nil, userError{
 			msg: "Database does not exist.",
 			err: errors.New("open database: does not exist"),
 		}
 	}
-	r, err := dbStorage.reader()
+	r, err := st.reader()
 	if err != nil {
 		return nil, err
 	}
-	db, err := keepass.Open(r, opts)
+	if st == dbStorage && *verifyKeyPath != "" {
+		if r, err = verifySignature(r); err != nil {
+			return nil, err
+		}
+	}
+	db, err := keepass.Open(r, flagOptions(opts))
 	if err == keepass.ErrHashMismatch {
 		return nil, userError{
 			msg: "Could not decrypt database.  This means either the password you entered is incorrect or the database is corrupt.",
 			err: errors.New("open database: " + err.Error()),
 		}
+	} else if _, ok := err.(*keepass.KeyRoundsError); ok {
+		return nil, userError{
+			msg: "The database's key derivation is too slow to open here.  Lower its key rounds with a client that can open it, or raise -max_key_rounds.",
+			err: fmt.Errorf("open database: %v", err),
+		}
+	} else if _, ok := err.(*keepass.LimitError); ok {
+		return nil, userError{
+			msg: "The database is too large to open here.",
+			err: fmt.Errorf("open database: %v", err),
+		}
+	} else if fe, ok := err.(*keepass.FormatError); ok {
+		return nil, userError{
+			msg: fmt.Sprintf("The database file is %s, which can't be opened.  Only KeePass 1 databases with GOST encryption can.", fe.Format),
+			err: fmt.Errorf("open database: %v", err),
+		}
 	} else if err != nil {
 		return nil, err
 	}
+	if st == dbStorage {
+		if err := joinBlobs(db); err != nil {
+			return nil, fmt.Errorf("open database: %v", err)
+		}
+	}
 	return db, nil
 }
 
+// flagOptions returns opts with the limits and random source set by flags,
+// unless opts sets them.
+func flagOptions(opts *keepass.Options) *keepass.Options {
+	var o keepass.Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxKeyRounds == 0 {
+		o.MaxKeyRounds = *maxKeyRounds
+	}
+	if o.Rand == nil {
+		o.Rand = randSource
+	}
+	return &o
+}
+
 func writeDatabase(db *keepass.Database) error {
-	wc, err := dbStorage.writer()
+	return writeStorage(dbStorage, db, commandAuthor())
+}
+
+// writeStorage writes db to st.  author names whoever made the changes in
+// the change log.
+func writeStorage(st *storage, db *keepass.Database, author string) error {
+	clearSecretRefs(db)
+	if st == dbStorage {
+		if err := runHook("pre-save", *preSaveHook, "GOSTPASS_AUTHOR="+author); err != nil {
+			return err
+		}
+	}
+	if st == dbStorage && *changeLogPath != "" {
+		if err := recordChanges(st, db, author); err != nil {
+			return err
+		}
+	}
+	// Back up before attachments are split out, so that the backup holds
+	// them.
+	var backup []byte
+	if st == dbStorage {
+		due, err := backupDue(time.Now())
+		if err != nil {
+			return err
+		}
+		if due {
+			var buf bytes.Buffer
+			if err := db.Write(&buf); err != nil {
+				return fmt.Errorf("write backup: %v", err)
+			}
+			backup = buf.Bytes()
+		}
+	}
+	var blobs map[string]bool
+	if st == dbStorage && *splitAttachments {
+		ids, restore, err := splitBlobs(db)
+		if err != nil {
+			return err
+		}
+		defer restore()
+		blobs = ids
+	}
+	var buf bytes.Buffer
+	if err := db.Write(&buf); err != nil {
+		return fmt.Errorf("write database: %v", err)
+	}
+	// Sign before touching the file, so that a missing key doesn't leave
+	// a database behind with a stale signature.
+	var sig []byte
+	if st == dbStorage && *signKeyPath != "" {
+		var err error
+		if sig, err = signDatabase(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	wc, err := st.writer()
 	if err != nil {
 		return fmt.Errorf("write database: open: %v", err)
 	}
-	err = db.Write(wc)
+	_, err = wc.Write(buf.Bytes())
 	cerr := wc.Close()
 	if err != nil {
 		return fmt.Errorf("write database: %v", err)
@@ -860,6 +1137,22 @@ func writeDatabase(db *keepass.Database) error {
 	if cerr != nil {
 		return fmt.Errorf("write dtabase: close: %v", cerr)
 	}
+	if sig != nil {
+		if err := writeSignature(sig); err != nil {
+			return err
+		}
+	}
+	if st == dbStorage {
+		runNotifyHook("post-save", *postSaveHook, "GOSTPASS_AUTHOR="+author)
+	}
+	if backup != nil {
+		if _, err := writeBackup(backup, db.ComputedKey(), time.Now()); err != nil {
+			return fmt.Errorf("database saved, but not backed up: %v", err)
+		}
+	}
+	if st == dbStorage {
+		return removeUnusedBlobs(blobs)
+	}
 	return nil
 }
 
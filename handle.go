@@ -32,8 +32,23 @@ var (
 	maxRequestSize   = flag.Int64("max_request_size", 2<<20, "number of bytes to limit requests to")
 	checkPermissions = flag.Bool("permissions", true, "whether to check Sandstorm permissions (can be disabled for development)")
 	xsrfTokenSize    = flag.Int("xsrf_token_size", 33, "size of the XSRF tokens sent to the client (in bytes)")
+	readOnly         = flag.Bool("read_only", false, "refuse every request that would change the database or its keys")
 )
 
+// contentSecurityPolicy only allows the server's own scripts, styles and
+// fonts, so that an entry field that slips past template escaping can't
+// run script or send data elsewhere.  Framing is allowed because Sandstorm
+// shows apps in a frame.
+const contentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; " +
+	"img-src 'self' data:; font-src 'self'; connect-src 'self'; form-action 'self'; base-uri 'none'"
+
+// setSecurityHeaders sets the headers that harden every response.
+func setSecurityHeaders(h http.Header) {
+	h.Set("Content-Security-Policy", contentSecurityPolicy)
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Referrer-Policy", "no-referrer")
+}
+
 // staticFileHandler serves a file from the static directory.
 type staticFileHandler string
 
@@ -43,15 +58,25 @@ func (h staticFileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	setSecurityHeaders(w.Header())
 	http.ServeFile(w, r, filepath.Join(*staticDir, string(h)))
 }
 
 type appHandler struct {
 	f    func(http.ResponseWriter, *http.Request) error
 	perm string
+
+	// noXSRF is set for handlers posted to by people without a session,
+	// like share link recipients, who have no XSRF cookie.
+	noXSRF bool
 }
 
 func (ah appHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w.Header())
+	if *readOnly && (ah.perm == "write" || ah.perm == "init") {
+		http.Error(w, "This server is read-only", http.StatusForbidden)
+		return
+	}
 	if *checkPermissions && ah.perm != "" && !sandstormhdr.HasPermission(r.Header, ah.perm) {
 		http.Error(w, "Permission denied", http.StatusForbidden)
 		return
@@ -62,7 +87,7 @@ func (ah appHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "could not parse form", http.StatusBadRequest)
 		return
 	}
-	if !(r.Method == "GET" || r.Method == "HEAD" || r.Method == "OPTIONS" || r.Method == "TRACE") {
+	if !ah.noXSRF && !(r.Method == "GET" || r.Method == "HEAD" || r.Method == "OPTIONS" || r.Method == "TRACE") {
 		if err := checkXSRF(r); err != nil {
 			log.Printf("%s %s client error: %v", r.Method, r.URL.Path, err)
 			http.Error(w, userErrorMessage(err), errorStatusCode(err))
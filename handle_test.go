@@ -0,0 +1,46 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAppHandlerReadOnly(t *testing.T) {
+	defer func(ro, perms bool) { *readOnly, *checkPermissions = ro, perms }(*readOnly, *checkPermissions)
+	*readOnly, *checkPermissions = true, false
+	ok := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	tests := []struct {
+		perm string
+		want int
+	}{
+		{"", http.StatusOK},
+		{"write", http.StatusForbidden},
+		{"init", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		appHandler{f: ok, perm: test.perm}.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != test.want {
+			t.Errorf("perm %q: status = %d; want %d", test.perm, w.Code, test.want)
+		}
+		if got := w.Header().Get("Content-Security-Policy"); got != contentSecurityPolicy {
+			t.Errorf("perm %q: Content-Security-Policy = %q; want %q", test.perm, got, contentSecurityPolicy)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+const masked = "********"
+
+// revisionField is a field shown by the history command.
+type revisionField struct {
+	name   string
+	secret bool
+	get    func(*keepass.Revision) string
+}
+
+var revisionFields = []revisionField{
+	{"title", false, func(r *keepass.Revision) string { return r.Title }},
+	{"url", false, func(r *keepass.Revision) string { return r.URL }},
+	{"username", false, func(r *keepass.Revision) string { return r.Username }},
+	{"password", true, func(r *keepass.Revision) string { return r.Password }},
+	{"notes", false, func(r *keepass.Revision) string { return r.Notes }},
+	{"icon", false, func(r *keepass.Revision) string { return strconv.FormatUint(uint64(r.Icon), 10) }},
+	{"attachment", false, func(r *keepass.Revision) string { return r.AttachmentName }},
+}
+
+// revisions returns the entry's history followed by its current state, so
+// that revision N (counting from 1) is revs[N-1] and "current" is last.
+func revisions(e *keepass.Entry) []keepass.Revision {
+	revs := make([]keepass.Revision, 0, len(e.History)+1)
+	revs = append(revs, e.History...)
+	return append(revs, e.Revision())
+}
+
+// parseRevision parses a revision number as listed by the history
+// command, or "current".
+func parseRevision(s string, revs []keepass.Revision) (int, error) {
+	if s == "current" {
+		return len(revs) - 1, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > len(revs) {
+		return 0, fmt.Errorf("revision %q: want a number from 1 to %d or \"current\"", s, len(revs))
+	}
+	return n - 1, nil
+}
+
+func revisionName(i int, revs []keepass.Revision) string {
+	if i == len(revs)-1 {
+		return "current"
+	}
+	return strconv.Itoa(i + 1)
+}
+
+// diffRevisions writes the fields that differ between a and b.  Secret
+// fields are masked unless reveal is set, but whether they changed is
+// always shown.
+func diffRevisions(w io.Writer, a, b *keepass.Revision, reveal bool) error {
+	changed := false
+	for _, f := range revisionFields {
+		from, to := f.get(a), f.get(b)
+		if from == to {
+			continue
+		}
+		changed = true
+		if f.secret && !reveal {
+			from, to = masked, masked
+		}
+		var err error
+		if strings.Contains(from, "\n") || strings.Contains(to, "\n") {
+			_, err = fmt.Fprintf(w, "%s:\n  - %s\n  + %s\n", f.name,
+				strings.Replace(from, "\n", "\n    ", -1), strings.Replace(to, "\n", "\n    ", -1))
+		} else {
+			_, err = fmt.Fprintf(w, "%s: %q -> %q\n", f.name, from, to)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if !changed {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+	return nil
+}
+
+// runHistory lists an entry's revisions, shows what changed between two
+// of them, or restores one.
+func runHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	diff := fs.Bool("diff", false, "show the fields that changed between revisions N and M")
+	reveal := fs.Bool("reveal", false, "show passwords instead of masking them")
+	restore := fs.String("restore", "", "make revision `N` the current state")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch {
+	case *diff && fs.NArg() == 3:
+	case !*diff && fs.NArg() == 1:
+	default:
+		return errors.New("usage: history [-reveal] [-restore N] entry | history -diff [-reveal] entry N M")
+	}
+	if *diff && *restore != "" {
+		return errors.New("-diff and -restore are mutually exclusive")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	e, err := findEntryPath(db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	revs := revisions(e)
+
+	if *diff {
+		i, err := parseRevision(fs.Arg(1), revs)
+		if err != nil {
+			return err
+		}
+		j, err := parseRevision(fs.Arg(2), revs)
+		if err != nil {
+			return err
+		}
+		return diffRevisions(os.Stdout, &revs[i], &revs[j], *reveal)
+	}
+
+	if *restore != "" {
+		i, err := parseRevision(*restore, revs)
+		if err != nil {
+			return err
+		}
+		if i == len(revs)-1 {
+			return errors.New(tr("revision is already current"))
+		}
+		e.Restore(revs[i])
+		e.LastModificationTime = time.Now()
+		if err := writeDatabase(db); err != nil {
+			return err
+		}
+		fmt.Printf(tr("restored revision %d of %q\n"), i+1, e.Title)
+		return nil
+	}
+
+	for i := range revs {
+		r := &revs[i]
+		password := masked
+		if *reveal {
+			password = r.Password
+		}
+		fmt.Printf(tr("%-8s %s  %q  user %q  password %s\n"), revisionName(i, revs),
+			r.Modified.Format("2006-01-02 15:04:05"), r.Title, r.Username, password)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestDiffRevisions(t *testing.T) {
+	t0 := time.Date(2019, time.July, 1, 22, 0, 0, 0, time.UTC)
+	a := &keepass.Revision{Title: "Mail", Username: "bob", Password: "hunter2", Modified: t0}
+	b := &keepass.Revision{Title: "Mail", Username: "alice", Password: "xyzzy", Notes: "a\nb", Modified: t0.Add(time.Hour)}
+	tests := []struct {
+		a, b   *keepass.Revision
+		reveal bool
+		want   string
+	}{
+		{a, a, false, "no changes\n"},
+		{a, b, false, "username: \"bob\" -> \"alice\"\npassword: \"********\" -> \"********\"\nnotes:\n  - \n  + a\n    b\n"},
+		{a, b, true, "username: \"bob\" -> \"alice\"\npassword: \"hunter2\" -> \"xyzzy\"\nnotes:\n  - \n  + a\n    b\n"},
+	}
+	for _, test := range tests {
+		buf := new(bytes.Buffer)
+		if err := diffRevisions(buf, test.a, test.b, test.reveal); err != nil {
+			t.Errorf("diffRevisions(%+v, %+v, %t): %v", test.a, test.b, test.reveal, err)
+			continue
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("diffRevisions(%+v, %+v, %t) = %q; want %q", test.a, test.b, test.reveal, got, test.want)
+		}
+	}
+}
+
+func TestParseRevision(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := db.Root().NewSubgroup().NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.AddRevision()
+	e.AddRevision()
+	revs := revisions(e)
+	tests := []struct {
+		s    string
+		want int
+		ok   bool
+	}{
+		{"1", 0, true},
+		{"2", 1, true},
+		{"3", 2, true},
+		{"current", 2, true},
+		{"0", 0, false},
+		{"4", 0, false},
+		{"latest", 0, false},
+	}
+	for _, test := range tests {
+		got, err := parseRevision(test.s, revs)
+		if got != test.want || (err == nil) != test.ok {
+			t.Errorf("parseRevision(%q) = %d, %v; want %d, ok=%t", test.s, got, err, test.want, test.ok)
+		}
+	}
+}
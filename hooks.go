@@ -0,0 +1,73 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	preSaveHook    = flag.String("pre_save_hook", "", "command to run before the database is saved; the save is abandoned if it fails")
+	postSaveHook   = flag.String("post_save_hook", "", "command to run after the database is saved, like a script that commits it to git and pushes")
+	postUnlockHook = flag.String("post_unlock_hook", "", "command to run after the database is unlocked, like a notification")
+)
+
+// hookCommand names what the process is doing for hooks: the command it
+// runs, or "server".
+var hookCommand = "server"
+
+// runHook runs the command for the named hook, if there is one.  The
+// command is split on spaces and not passed to a shell; for anything more,
+// use a script.  Its output goes to standard error, since standard output
+// may be read by another program, and it learns the context from its
+// environment:
+//
+//	GOSTPASS_HOOK     pre-save, post-save or post-unlock
+//	GOSTPASS_DB       absolute path of the database
+//	GOSTPASS_COMMAND  the gostpass command running, or "server"
+//
+// and whatever env adds.  Secrets are never passed.
+func runHook(name, command string, env ...string) error {
+	argv := strings.Fields(command)
+	if len(argv) == 0 {
+		return nil
+	}
+	db, err := filepath.Abs(*dbPath)
+	if err != nil {
+		db = *dbPath
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Env = append(os.Environ(), "GOSTPASS_HOOK="+name, "GOSTPASS_DB="+db, "GOSTPASS_COMMAND="+hookCommand)
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook: %v", name, err)
+	}
+	return nil
+}
+
+// runNotifyHook runs a hook whose failure doesn't undo what happened, so it
+// is only reported.
+func runNotifyHook(name, command string, env ...string) {
+	if err := runHook(name, command, env...); err != nil {
+		fmt.Fprintf(stderr, "gostpass: %v\n", err)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook script needs a Unix shell")
+	}
+	// The hooks must be set before init, so the test creates the database.
+	dir, cleanup := newCommandTestDB(t, "")
+	defer cleanup()
+	defer func(pre, post, unlock, command string) {
+		*preSaveHook, *postSaveHook, *postUnlockHook = pre, post, unlock
+		hookCommand = command
+	}(*preSaveHook, *postSaveHook, *postUnlockHook, hookCommand)
+	logPath := filepath.Join(dir, "hooks.log")
+	script := filepath.Join(dir, "hook.sh")
+	err := ioutil.WriteFile(script, []byte(`#!/bin/sh
+echo "$GOSTPASS_HOOK $GOSTPASS_COMMAND $(basename "$GOSTPASS_DB") $1" >> `+logPath+`
+test "$GOSTPASS_HOOK" != pre-save || test ! -e `+filepath.Join(dir, "veto")+`
+`), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	*preSaveHook = script + " pre"
+	*postSaveHook = script + " post"
+	*postUnlockHook = script + " unlock"
+	templatePath := filepath.Join(dir, "template.json")
+	if err := ioutil.WriteFile(templatePath, []byte(`{"key_rounds": 1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runCommand([]string{"init", "-template", templatePath}); code != 0 {
+		t.Fatalf("init exited with %d", code)
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "veto"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeDatabase(db); err == nil {
+		t.Error("save vetoed by pre-save hook succeeded")
+	}
+
+	data, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"pre-save init vault.kdb pre",
+		"post-save init vault.kdb post",
+		"post-unlock init vault.kdb unlock",
+		"pre-save init vault.kdb pre",
+	}
+	if got := strings.Split(strings.TrimSpace(string(data)), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("hooks ran:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
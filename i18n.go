@@ -0,0 +1,87 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var langFlag = flag.String("lang", "", "language of command output, en or ru (default from LC_ALL, LC_MESSAGES or LANG)")
+
+// catalogs holds the translations of command output for each language
+// other than English.  They are keyed by the English format string, so
+// untranslated messages fall back to English.
+var catalogs = map[string]map[string]string{
+	"ru": ruMessages,
+}
+
+// messages is the catalog for the current language, nil for English.
+var messages map[string]string
+
+// initLanguage selects the language of command output from -lang or the
+// locale environment variables.  An unsupported -lang is an error, but an
+// unsupported locale just means English.
+func initLanguage() error {
+	lang := *langFlag
+	if lang == "" {
+		lang = localeLanguage(os.Getenv)
+	}
+	lang = strings.ToLower(lang)
+	if lang == "en" || lang == "" {
+		messages = nil
+		return nil
+	}
+	c, ok := catalogs[lang]
+	if !ok {
+		if *langFlag != "" {
+			return fmt.Errorf("unsupported language %q", *langFlag)
+		}
+		messages = nil
+		return nil
+	}
+	messages = c
+	return nil
+}
+
+// localeLanguage returns the language part of the locale that governs
+// messages, like "ru" for LANG=ru_RU.UTF-8, following the POSIX order of
+// precedence.  It returns "" for the C and POSIX locales.
+func localeLanguage(getenv func(string) string) string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := getenv(name)
+		if v == "" {
+			continue
+		}
+		if v == "C" || v == "POSIX" || strings.HasPrefix(v, "C.") {
+			return ""
+		}
+		if i := strings.IndexAny(v, "_-.@"); i >= 0 {
+			v = v[:i]
+		}
+		return strings.ToLower(v)
+	}
+	return ""
+}
+
+// tr translates a message or format string into the current language.
+func tr(msg string) string {
+	if t, ok := messages[msg]; ok {
+		return t
+	}
+	return msg
+}
@@ -0,0 +1,232 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// ruMessages is the Russian catalog.  Counts are put after a colon, like
+// "записей: 3", so that they read correctly without plural forms.
+var ruMessages = map[string]string{
+	// Command descriptions
+	"print an Ansible Vault password for --vault-id":                         "вывести пароль Ansible Vault для --vault-id",
+	"report breached and reused passwords":                                   "найти скомпрометированные и повторяющиеся пароли",
+	"attach certificates to entries and list them":                           "прикрепить сертификаты к записям и вывести их список",
+	"ask before releasing entries' secrets to other programs":                "спрашивать перед передачей секретов записей другим программам",
+	"merge entries with identical fields":                                    "объединить записи с одинаковыми полями",
+	"Docker credential helper backed by the database":                        "помощник учётных данных Docker на основе базы",
+	"run a command with an entry's fields in its environment":                "запустить команду с полями записи в окружении",
+	"list entries and certificates that expire soon":                         "вывести записи и сертификаты, срок которых скоро истекает",
+	"list entries that apply to a URL or contain words":                      "вывести записи, подходящие для URL или содержащие слова",
+	"git credential helper backed by the database":                           "помощник учётных данных git на основе базы",
+	"build a filter from a breach corpus for audit -breaches":                "построить фильтр из базы утечек для audit -breaches",
+	"list, compare or restore an entry's revisions":                          "вывести, сравнить или восстановить версии записи",
+	"create the database, optionally from a template of groups and entries":  "создать базу данных, при желании по шаблону групп и записей",
+	"show secrets released to other programs, or changes with -change_log":   "показать секреты, переданные другим программам, или изменения из -change_log",
+	"print entries as JSON, for Ansible lookups and scripts":                 "вывести записи в JSON для Ansible lookup и скриптов",
+	"create groups by path, like Work/VPN":                                   "создать группы по пути, например Work/VPN",
+	"move or rename a group or entry":                                        "переместить или переименовать группу или запись",
+	"change the database's password or key derivation strength":              "сменить пароль базы или стойкость выработки ключа",
+	"fill in a config file template with entry fields":                       "заполнить шаблон файла настроек полями записей",
+	"replace passwords older than a given age":                               "заменить пароли старше заданного возраста",
+	"move groups or entries to the recycle bin, or delete them from it":      "переместить группы или записи в корзину или удалить их из неё",
+	"serve entries to Kubernetes secret operators over HTTP":                 "отдавать записи операторам секретов Kubernetes по HTTP",
+	"create a key pair for -sign_key and -verify_key":                        "создать пару ключей для -sign_key и -verify_key",
+	"store SSH keys in entries and load them into an ssh-agent":              "хранить ключи SSH в записях и загружать их в ssh-agent",
+	"print one field of an entry exactly, for systemd services":              "вывести одно поле записи как есть, для служб systemd",
+	"show the entry a kdbx: link points to, or register as the link handler": "показать запись по ссылке kdbx: или зарегистрироваться как обработчик ссылок",
+	"browse groups and entries in the terminal":                              "просматривать группы и записи в терминале",
+	"list entries for fzf or rofi, and print a field of the chosen one":      "вывести записи для fzf или rofi и показать поле выбранной",
+	"choose an entry in rofi, wofi or dmenu, and type or copy it":            "выбрать запись в rofi, wofi или dmenu и ввести или скопировать её",
+	"print an entry or group with its notes rendered as Markdown":            "показать запись или группу с заметками в разметке Markdown",
+	"show a QR code that joins a Wi-Fi entry's network, or write an NFC tag": "показать QR-код для подключения к сети Wi-Fi из записи или записать метку NFC",
+	"replace the passwords of matching entries and report the new ones":      "заменить пароли подходящих записей и вывести новые",
+	"copy an entry with some fields changed, like a variant for staging":     "скопировать запись с изменением некоторых полей, например вариант для тестовой среды",
+	"make a link that reveals an entry's password a limited number of times": "создать ссылку, которая показывает пароль записи ограниченное число раз",
+	"back up the database now, list backups or restore one":                  "создать резервную копию базы данных, вывести копии или восстановить одну",
+	"write a copy under another password, or a paper backup":                 "записать копию под другим паролем или бумажную резервную копию",
+	"turn a typed or scanned paper backup back into a database file":         "восстановить файл базы данных из набранной или отсканированной бумажной копии",
+	"create, list or revoke tokens for secrets-server":                       "создать, вывести или отозвать токены для secrets-server",
+	"write one entry to a database file of its own, under another password":  "записать одну запись в отдельный файл базы данных под другим паролем",
+	"merge changed entries with a copy of the database on another host":      "объединить изменённые записи с копией базы данных на другом компьютере",
+	"check the database for damage without repairing it":                     "проверить базу на повреждения, не исправляя их",
+
+	// cli.go, prompt.go
+	"gostpass: unknown command %q\n": "gostpass: неизвестная команда %q\n",
+	"gostpass: must specify -db":     "gostpass: нужно указать -db",
+	"commands:":                      "команды:",
+	"Password: ":                     "Пароль: ",
+	"-password_credential given, but CREDENTIALS_DIRECTORY is not set": "указан -password_credential, но CREDENTIALS_DIRECTORY не задан",
+	"%s [y/N] ":               "%s [д/Н] ",
+	"%s: %d problems found":   "%s: найдено проблем: %d",
+	"%s: no problems found\n": "%s: проблем не найдено\n",
+	"no terminal to read the password from; use -password_file": "нет терминала для ввода пароля; используйте -password_file",
+	"no password on standard input":                             "на стандартном вводе нет пароля",
+	"no terminal to ask for confirmation":                       "нет терминала для запроса подтверждения",
+	"The password was pasted; clear the clipboard.":             "Пароль вставлен из буфера обмена; очистите буфер.",
+	"weak":                "слабый",
+	"fair":                "средний",
+	"good":                "хороший",
+	"strong":              "надёжный",
+	"Caps Lock may be on": "возможно, включён Caps Lock",
+
+	// audit.go
+	"%s: %d entries, %d distinct passwords\n": "%s: записей: %d, разных паролей: %d\n",
+	"%d entries audited, %d findings\n":       "проверено записей: %d, замечаний: %d\n",
+	"%d policy violations":                    "нарушений политики: %d",
+	"%d hashes written to %s\n":               "записано хешей: %d в %s\n",
+
+	// certs.go
+	"entry expired":               "срок записи истёк",
+	"entry expires":               "срок записи истекает",
+	"certificate %q expired":      "срок сертификата %q истёк",
+	"certificate %q expires":      "срок сертификата %q истекает",
+	"%d items expire within %s\n": "истекает в течение %[2]s: %[1]d\n",
+	"PKCS#12 password: ":          "Пароль PKCS#12: ",
+	"%s  %q  expires %s\n":        "%s  %q  действителен до %s\n",
+
+	// changelog.go
+	"%s: %d records, but the database expects %d; the log was truncated":                         "%s: записей: %d, а база ожидает %d; журнал был обрезан",
+	"%s: %d records, but the database expects %d; a save failed or the database was rolled back": "%s: записей: %d, а база ожидает %d; сохранение не удалось или база была откачена",
+	"%s: the last record does not match the database":                                            "%s: последняя запись не соответствует базе",
+	"%d records, chain intact\n":                                                                 "записей: %d, цепочка не нарушена\n",
+
+	// dedup.go
+	"%d duplicates of %q (user %q):\n": "дубликаты %[2]q (пользователь %[3]q), всего %[1]d:\n",
+	"remove":                           "удалить",
+	"keep":                             "оставить",
+	"  %-6s %v  %s  modified %s\n":     "  %-8s %v  %s  изменена %s\n",
+	"Merge?":                           "Объединить?",
+	"%d sets of duplicates found, %d merged, %d entries removed\n": "найдено наборов дубликатов: %d, объединено: %d, удалено записей: %d\n",
+
+	// clone.go, find.go, history.go, tree.go
+	"%s  user %q  %s\n":                   "%s  пользователь %q  %s\n",
+	"revision is already current":         "эта версия уже текущая",
+	"restored revision %d of %q\n":        "восстановлена версия %d записи %q\n",
+	"%-8s %s  %q  user %q  password %s\n": "%-8s %s  %q  пользователь %q  пароль %s\n",
+	"%s: deleted\n":                       "%s: удалено\n",
+	"%s: cloned to %s\n":                  "%s: скопировано в %s\n",
+	"%s: moved to %s\n":                   "%s: перемещено в %s\n",
+
+	// open.go, show.go
+	"Press Enter to close.":             "Нажмите Enter, чтобы закрыть.",
+	"  username  %s\n":                  "  пользователь  %s\n",
+	"  password  %s\n":                  "  пароль        %s\n",
+	"  url       %s\n":                  "  url           %s\n",
+	"  file      %s\n":                  "  файл          %s\n",
+	"Password copied to the clipboard.": "Пароль скопирован в буфер обмена.",
+
+	// tui.go
+	"Terminal too small": "Терминал слишком мал",
+	"username: ":         "пользователь: ",
+	"password: ":         "пароль:       ",
+	"url:      ":         "url:          ",
+	"notes:    ":         "заметки:      ",
+	"↑↓ move  Tab switch  / search  c copy password  u copy username  r reveal  e edit  q quit": "↑↓ выбор  Tab панель  / поиск  c копировать пароль  u копировать имя  r показать  e изменить  q выход",
+	"Username copied to the clipboard.": "Имя пользователя скопировано в буфер обмена.",
+	"Saved.":                            "Сохранено.",
+	"Title":                             "Название",
+	"Username":                          "Пользователь",
+	"URL":                               "URL",
+	"Password (empty keeps it): ":       "Пароль (пустой оставляет прежний): ",
+
+	// share.go
+	"%s  %s %s  views left %d  expires %s\n": "%s  %s %s  осталось просмотров: %d  истекает %s\n",
+
+	// release.go
+	"%s: release to %s was refused": "%s: передача в %s отклонена",
+	"Release %s to %s?":             "Передать %s в %s?",
+	"released":                      "передано ",
+	"refused ":                      "отказано ",
+	"%s  %s  %s  to %s\n":           "%s  %s  %s  в %s\n",
+
+	// regen.go
+	"%d passwords regenerated\n": "паролей заменено: %d\n",
+
+	// rotate.go
+	"%s  %q  user %q  password is %d days old\n": "%s  %q  пользователь %q  возраст пароля в днях: %d\n",
+	"Rotate now?":                                           "Заменить сейчас?",
+	"%v\nNew password: %s\n":                                "%v\nНовый пароль: %s\n",
+	"New password copied to the clipboard.":                 "Новый пароль скопирован в буфер обмена.",
+	"Change it at %s\n":                                     "Смените его на %s\n",
+	"Changed it on the site? Record the new password?":      "Пароль на сайте изменён? Сохранить новый пароль?",
+	"%d entries with passwords older than %s, %d rotated\n": "записей с паролями старше %[2]s: %[1]d, заменено: %[3]d\n",
+
+	// ssh.go
+	"Passphrase for %s: ":      "Парольная фраза для %s: ",
+	"Loaded %s\n":              "Загружен %s\n",
+	"Allow use of SSH key %s?": "Разрешить использование ключа SSH %s?",
+
+	// apitoken.go
+	"all groups":         "все группы",
+	"missing group %d":   "отсутствующая группа %d",
+	", with attachments": ", с вложениями",
+	"adds entries to %s": "добавляет записи в %s",
+
+	// backup.go
+	"Replace %s with backup %s?":             "Заменить %s резервной копией %s?",
+	"not restored":                           "не восстановлено",
+	"Previous database saved as backup %s\n": "Прежняя база данных сохранена как копия %s\n",
+
+	// export.go
+	"Export password: ":              "Пароль экспорта: ",
+	"Repeat export password: ":       "Повторите пароль экспорта: ",
+	"passwords do not match":         "пароли не совпадают",
+	"an export password is required": "нужен пароль экспорта",
+
+	// paper.go
+	"%d bytes restored to %s; open it with the export password\n": "восстановлено байт: %d в %s; откройте файл паролем экспорта\n",
+
+	// kdfpreset.go
+	"unknown KDF preset %q":      "неизвестный набор параметров KDF %q",
+	"%s preset: %d key rounds\n": "набор %s: раундов ключа: %d\n",
+
+	// vaulttemplate.go
+	"%s already exists":                  "%s уже существует",
+	"New password: ":                     "Новый пароль: ",
+	"Repeat new password: ":              "Повторите новый пароль: ",
+	"a password or key file is required": "нужен пароль или файл ключа",
+
+	// secretref.go
+	"point an entry's password at an env var, file, command or keyring": "указать пароль записи в переменной, файле, команде или связке ключей",
+	"%s has no secret reference":                                        "у %s нет ссылки на секрет",
+
+	// eval.go
+	"print the result of an expression over the entries, like a query": "вывести результат выражения над записями, как запроса",
+
+	// plugin.go
+	"list plugins, or import a file with one": "показать подключаемые модули или импортировать файл модулем",
+	"%d entries imported\n":                   "импортировано записей: %d\n",
+
+	// sync.go
+	"%d entries received from %s, %d sent\n": "получено записей от %[2]s: %[1]d, отправлено: %[3]d\n",
+
+	// escrow.go
+	"let a recovery key holder unlock the database, or stop them": "дать держателю ключа восстановления открыть базу или отозвать его",
+	"no recovery key is enrolled":                                 "ключ восстановления не зарегистрирован",
+	"%s: recovered, with a new password\n":                        "%s: восстановлена, задан новый пароль\n",
+
+	// rotatekey.go
+	"replace the -keyfile with a new one and erase the old one": "заменить файл ключа -keyfile новым и стереть старый",
+
+	// sharekey.go
+	"open the database with key shares from split-key and set a new password": "открыть базу по долям ключа из split-key и задать новый пароль",
+	"split the database key into shares, some of which can recover it":        "разделить ключ базы на доли, часть которых восстанавливает его",
+	"Enter the key shares, one per line, then an empty line:":                 "Введите доли ключа по одной в строке, затем пустую строку:",
+
+	// quickunlock.go
+	"keep the database key in the system keyring, or remove it":    "хранить ключ базы в системной связке ключей или удалить его",
+	"gostpass: quick unlock failed, asking for the password: %v\n": "gostpass: быстрая разблокировка не удалась, запрашивается пароль: %v\n",
+	"quick unlock is not enabled":                                  "быстрая разблокировка не включена",
+	"quick unlock enabled for %s\n":                                "быстрая разблокировка включена для %s\n",
+	"quick unlock disabled for %s\n":                               "быстрая разблокировка выключена для %s\n",
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestLocaleLanguage(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{}, ""},
+		{map[string]string{"LANG": "ru_RU.UTF-8"}, "ru"},
+		{map[string]string{"LANG": "ru_RU.UTF-8", "LC_MESSAGES": "C"}, ""},
+		{map[string]string{"LANG": "en_US.UTF-8", "LC_ALL": "ru_UA"}, "ru"},
+		{map[string]string{"LANG": "C.UTF-8"}, ""},
+		{map[string]string{"LC_MESSAGES": "sr_RS@latin"}, "sr"},
+	}
+	for _, test := range tests {
+		got := localeLanguage(func(name string) string { return test.env[name] })
+		if got != test.want {
+			t.Errorf("localeLanguage(%v) = %q; want %q", test.env, got, test.want)
+		}
+	}
+}
+
+// formatVerbs returns the verb used for each argument of a format string.
+func formatVerbs(format string) map[int]byte {
+	verbs := make(map[int]byte)
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && (format[i] == '-' || format[i] == '+' || format[i] == '#' || format[i] == ' ' || format[i] >= '0' && format[i] <= '9') {
+			i++
+		}
+		if i < len(format) && format[i] == '[' {
+			end := i + 1
+			for end < len(format) && format[end] != ']' {
+				end++
+			}
+			n, _ := strconv.Atoi(format[i+1 : end])
+			arg = n - 1
+			i = end + 1
+		}
+		if i >= len(format) || format[i] == '%' {
+			continue
+		}
+		verbs[arg] = format[i]
+		arg++
+	}
+	return verbs
+}
+
+func TestCatalogs(t *testing.T) {
+	for lang, c := range catalogs {
+		for msg, translation := range c {
+			if want, got := formatVerbs(msg), formatVerbs(translation); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %q has verbs %q; want %q like %q", lang, translation, got, want, msg)
+			}
+		}
+		for name, cmd := range commands {
+			if _, ok := c[cmd.usage]; !ok {
+				t.Errorf("%s: no translation for the description of %s", lang, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/kdbcrypt"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// A kdfPreset is a named strength of key derivation, for users who don't
+// want to pick a number of key transform rounds.  The rounds are calibrated
+// on the machine running the command to take about target to compute, but
+// never fewer than rounds.  rounds is also what's used if the clock is too
+// coarse to calibrate with, and is the target's round count on the
+// reference CPU: 10,000,000 rounds take about a second on an Intel
+// i7-2600K.
+type kdfPreset struct {
+	target time.Duration
+	rounds int
+}
+
+// kdfPresets are the presets that -kdf-preset accepts:
+//
+//	mobile       2,000,000 rounds, not calibrated, since the database will
+//	             be opened on a slower device than the one creating it
+//	interactive  1s, at least 10,000,000 rounds, the default
+//	server       3s, at least 30,000,000 rounds, for databases unlocked
+//	             once per process, like secrets-server's
+//	paranoid     10s, at least 100,000,000 rounds
+var kdfPresets = map[string]kdfPreset{
+	"mobile":      {0, 2000000},
+	"interactive": {1 * time.Second, 10000000},
+	"server":      {3 * time.Second, 30000000},
+	"paranoid":    {10 * time.Second, 100000000},
+}
+
+// kdfPresetUsage is the usage of the -kdf-preset flag of init and rekey.
+func kdfPresetUsage() string {
+	names := make([]string, 0, len(kdfPresets))
+	for name := range kdfPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "key derivation strength: " + strings.Join(names, ", ")
+}
+
+// kdfRounds returns the key transform rounds for the named preset, timing
+// rounds with measure.  The result is capped at -max_key_rounds, so that
+// gostpass can still open the database.
+func kdfRounds(name string, measure func(rounds uint32) time.Duration) (int, error) {
+	p, ok := kdfPresets[name]
+	if !ok {
+		return 0, fmt.Errorf(tr("unknown KDF preset %q"), name)
+	}
+	rounds := p.rounds
+	if p.target > 0 {
+		if c := calibrateRounds(p.target, measure); c > rounds {
+			rounds = c
+		}
+	}
+	if *maxKeyRounds > 0 && rounds > *maxKeyRounds {
+		rounds = *maxKeyRounds
+	}
+	return rounds, nil
+}
+
+// calibrateRounds returns about how many rounds measure takes target to
+// compute, or 0 if no sample took long enough to tell.
+func calibrateRounds(target time.Duration, measure func(rounds uint32) time.Duration) int {
+	const minSample = 50 * time.Millisecond
+	for n := uint32(100000); n <= 1<<28; n *= 2 {
+		d := measure(n)
+		if d >= minSample {
+			return int(float64(n) * float64(target) / float64(d))
+		}
+	}
+	return 0
+}
+
+// timeKeyRounds returns how long computing a key with the given rounds
+// takes.
+func timeKeyRounds(rounds uint32) time.Duration {
+	k := &kdbcrypt.Key{Password: []byte("calibrate"), TransformRounds: rounds}
+	start := time.Now()
+	k.Compute()
+	return time.Since(start)
+}
+
+// presetKeyRounds returns the rounds for the -kdf-preset flag value name,
+// reporting them on standard error, or 0 if name is empty.
+func presetKeyRounds(name string) (int, error) {
+	if name == "" {
+		return 0, nil
+	}
+	rounds, err := kdfRounds(name, timeKeyRounds)
+	if err != nil {
+		return 0, err
+	}
+	fmt.Fprintf(os.Stderr, tr("%s preset: %d key rounds\n"), name, rounds)
+	return rounds, nil
+}
+
+// runRekey changes the database's password and, with -kdf-preset, its key
+// derivation strength.  Without -kdf-preset, the key rounds are kept.
+func runRekey(args []string) error {
+	fs := flag.NewFlagSet("rekey", flag.ContinueOnError)
+	preset := fs.String("kdf-preset", "", kdfPresetUsage())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("usage: rekey [-kdf-preset name]")
+	}
+	rounds, err := presetKeyRounds(*preset)
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	opts, err := newDatabaseOptions()
+	if err != nil {
+		return err
+	}
+	opts.KeyRounds = db.KeyRounds()
+	if rounds > 0 {
+		opts.KeyRounds = rounds
+	}
+	if err := rekeyDatabase(db, opts); err != nil {
+		return fmt.Errorf("rekey: %v", err)
+	}
+	return nil
+}
+
+// rekeyDatabase sets db's key from opts and saves it, once it is sure to
+// open with the new key.  What holds the old key, the escrow record and
+// quick unlock, is updated to the new one.
+func rekeyDatabase(db *keepass.Database, opts *keepass.Options) error {
+	if err := db.SetKey(opts); err != nil {
+		return err
+	}
+	if err := verifyRekeyed(db, opts.Password); err != nil {
+		return fmt.Errorf("database left unchanged: %v", err)
+	}
+	if err := writeDatabase(db); err != nil {
+		return err
+	}
+	if err := rewrapEscrow(db); err != nil {
+		return fmt.Errorf("database rekeyed, but escrow is stale: %v", err)
+	}
+	if err := refreshQuickUnlock(db); err != nil {
+		return fmt.Errorf("database rekeyed, but quick unlock is stale: %v", err)
+	}
+	return nil
+}
+
+// verifyRekeyed checks that db, once written, opens with password and the
+// -keyfile, deriving the key from scratch, before the original is replaced.
+func verifyRekeyed(db *keepass.Database, password string) error {
+	var buf bytes.Buffer
+	if err := db.Write(&buf); err != nil {
+		return err
+	}
+	opts := flagOptions(&keepass.Options{Password: password})
+	if *keyFilePath != "" {
+		kf, err := ioutil.ReadFile(*keyFilePath)
+		if err != nil {
+			return fmt.Errorf("read key file: %v", err)
+		}
+		opts.KeyFile = bytes.NewReader(kf)
+	}
+	rdb, err := keepass.Open(&buf, opts)
+	if err != nil {
+		return fmt.Errorf("reopen: %v", err)
+	}
+	if got, want := len(rdb.Entries()), len(db.Entries()); got != want {
+		return fmt.Errorf("reopen: %d entries; want %d", got, want)
+	}
+	return nil
+}
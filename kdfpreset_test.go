@@ -0,0 +1,93 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestKDFRounds(t *testing.T) {
+	defer func(max int) { *maxKeyRounds = max }(*maxKeyRounds)
+	*maxKeyRounds = 200000000
+	// rate returns a measure for a machine computing perSecond rounds a
+	// second.
+	rate := func(perSecond float64) func(uint32) time.Duration {
+		return func(rounds uint32) time.Duration {
+			return time.Duration(float64(rounds) / perSecond * float64(time.Second))
+		}
+	}
+	tests := []struct {
+		preset  string
+		measure func(uint32) time.Duration
+		want    int
+	}{
+		{"interactive", rate(10000000), 10000000},
+		{"interactive", rate(40000000), 40000000},
+		// Slow machines still get the preset's rounds.
+		{"interactive", rate(1000000), 10000000},
+		{"server", rate(20000000), 60000000},
+		{"mobile", rate(40000000), 2000000},
+		// Capped at -max_key_rounds.
+		{"paranoid", rate(40000000), 200000000},
+		// A clock too coarse to calibrate with.
+		{"paranoid", func(uint32) time.Duration { return 0 }, 100000000},
+	}
+	for _, test := range tests {
+		got, err := kdfRounds(test.preset, test.measure)
+		if err != nil {
+			t.Errorf("kdfRounds(%q) error: %v", test.preset, err)
+			continue
+		}
+		// Allow for rounding in the calibration.
+		if d := got - test.want; d < -test.want/100 || d > test.want/100 {
+			t.Errorf("kdfRounds(%q) = %d; want %d", test.preset, got, test.want)
+		}
+	}
+	if _, err := kdfRounds("fast", rate(10000000)); err == nil {
+		t.Error("kdfRounds(\"fast\") = <nil>; want error")
+	}
+}
+
+func TestRekey(t *testing.T) {
+	_, cleanup := newCommandTestDB(t, `{"key_rounds": 7}`)
+	defer cleanup()
+	db, err := openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKey := db.ComputedKey()
+
+	if err := runRekey(nil); err != nil {
+		t.Fatal("rekey:", err)
+	}
+	db, err = openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(db.ComputedKey(), oldKey) {
+		t.Error("computed key unchanged after rekey")
+	}
+	if n := db.KeyRounds(); n != 7 {
+		t.Errorf("key rounds after rekey = %d; want 7", n)
+	}
+	if err := verifyRekeyed(db, "swordfish"); err != nil {
+		t.Error("verifyRekeyed with the password:", err)
+	}
+	if err := verifyRekeyed(db, "hunter2"); err == nil {
+		t.Error("verifyRekeyed with another password = <nil>; want error")
+	}
+}
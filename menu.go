@@ -0,0 +1,229 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// menuActions are what the menu can do with the chosen entry.  Copies are
+// cleared from the clipboard after the given duration, unless it is 0.
+var menuActions = map[string]func(e *keepass.Entry, clear time.Duration) error{
+	"type": func(e *keepass.Entry, _ time.Duration) error {
+		return typeText(e.Username + "\t" + e.Password + "\n")
+	},
+	"type-username": func(e *keepass.Entry, _ time.Duration) error {
+		return typeText(e.Username)
+	},
+	"type-password": func(e *keepass.Entry, _ time.Duration) error {
+		return typeText(e.Password)
+	},
+	"copy-username": func(e *keepass.Entry, clear time.Duration) error {
+		return copyWithClear(e.Username, clear)
+	},
+	"copy-password": func(e *keepass.Entry, clear time.Duration) error {
+		return copyWithClear(e.Password, clear)
+	},
+}
+
+func menuActionNames() string {
+	names := make([]string, 0, len(menuActions))
+	for name := range menuActions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func copyWithClear(s string, clear time.Duration) error {
+	if err := copyToClipboard(s); err != nil {
+		return err
+	}
+	if clear <= 0 {
+		return nil
+	}
+	return clearClipboardAfter(s, clear)
+}
+
+// menuTypeDelay gives the launcher time to close, so that typing goes to
+// the window that was focused before it.
+const menuTypeDelay = 300 * time.Millisecond
+
+// A menuBinding is a launcher key, like "Alt+c", and the action it runs.
+type menuBinding struct {
+	key, action string
+}
+
+// parseMenuBindings parses a list like "Alt+c=copy-password,Alt+u=type".
+func parseMenuBindings(s string) ([]menuBinding, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var bindings []menuBinding
+	for _, kv := range strings.Split(s, ",") {
+		i := strings.IndexByte(kv, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("key binding %q: want key=action", kv)
+		}
+		b := menuBinding{strings.TrimSpace(kv[:i]), strings.TrimSpace(kv[i+1:])}
+		if menuActions[b.action] == nil {
+			return nil, fmt.Errorf("key binding %q: unknown action %q; want one of %s", kv, b.action, menuActionNames())
+		}
+		bindings = append(bindings, b)
+	}
+	return bindings, nil
+}
+
+// launcherCommands lists the dmenu-like programs tried, in order, if
+// -launcher isn't given.
+var launcherCommands = map[string][][]string{
+	"wayland": {
+		{"wofi", "--dmenu", "--insensitive", "--prompt", "gostpass"},
+		{"rofi", "-dmenu", "-i", "-p", "gostpass"},
+	},
+	"x11": {
+		{"rofi", "-dmenu", "-i", "-p", "gostpass"},
+		{"dmenu", "-i", "-p", "gostpass"},
+	},
+}
+
+// rofiCustomExit is rofi's exit code for -kb-custom-1; the others follow.
+const rofiCustomExit = 10
+
+// launcherCommand returns the launcher to run: the -launcher command line
+// if given, otherwise the first one available.  Key bindings are only
+// supported by rofi.
+func launcherCommand(launcher string, bindings []menuBinding) ([]string, error) {
+	var argv []string
+	if launcher != "" {
+		argv = strings.Fields(launcher)
+	} else {
+		session := "x11"
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			session = "wayland"
+		}
+		for _, c := range launcherCommands[session] {
+			if _, err := exec.LookPath(c[0]); err == nil {
+				argv = c
+				break
+			}
+		}
+		if argv == nil {
+			return nil, errors.New("no launcher found; install rofi, wofi or dmenu, or use -launcher")
+		}
+	}
+	if len(bindings) == 0 {
+		return argv, nil
+	}
+	if filepath.Base(argv[0]) != "rofi" {
+		return nil, fmt.Errorf("key bindings need rofi, not %s", argv[0])
+	}
+	if len(bindings) > 19 {
+		return nil, errors.New("rofi has at most 19 custom key bindings")
+	}
+	argv = append([]string(nil), argv...)
+	for i, b := range bindings {
+		argv = append(argv, fmt.Sprintf("-kb-custom-%d", i+1), b.key)
+	}
+	return argv, nil
+}
+
+// menuChoice interprets how the launcher exited: the action to run, or ""
+// if the menu was cancelled.
+func menuChoice(code int, action string, bindings []menuBinding) (string, error) {
+	switch {
+	case code == 0:
+		return action, nil
+	case code == 1:
+		return "", nil
+	case code >= rofiCustomExit && code < rofiCustomExit+len(bindings):
+		return bindings[code-rofiCustomExit].action, nil
+	default:
+		return "", fmt.Errorf("launcher exited with status %d", code)
+	}
+}
+
+// runMenu shows the entries in rofi, wofi or dmenu and types or copies the
+// chosen one.  It is meant to be bound to a hotkey, for example in i3:
+//
+//	bindsym $mod+p exec gostpass -db ~/vault.kdb -prompt pinentry menu -kb Alt+c=copy-password
+func runMenu(args []string) error {
+	fs := flag.NewFlagSet("menu", flag.ContinueOnError)
+	launcher := fs.String("launcher", "", "dmenu-like `command` to choose with (default is wofi on Wayland, then rofi, then dmenu)")
+	action := fs.String("action", "type", "what Enter does: "+menuActionNames())
+	kb := fs.String("kb", "", "rofi key bindings for other actions, like `Alt+c=copy-password,Alt+u=copy-username`")
+	clearAfter := fs.Duration("clear", 45*time.Second, "clear copied secrets from the clipboard after this long, or never if 0")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: menu [-launcher command] [-action action] [-kb bindings] [-clear duration]")
+	}
+	if menuActions[*action] == nil {
+		return fmt.Errorf("unknown action %q; want one of %s", *action, menuActionNames())
+	}
+	bindings, err := parseMenuBindings(*kb)
+	if err != nil {
+		return err
+	}
+	argv, err := launcherCommand(*launcher, bindings)
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	var list bytes.Buffer
+	if err := writePickList(&list, db); err != nil {
+		return err
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = &list
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	code := 0
+	if e, ok := err.(*exec.ExitError); ok {
+		code = e.ExitCode()
+	} else if err != nil {
+		return fmt.Errorf("%s: %v", argv[0], err)
+	}
+	chosen, err := menuChoice(code, *action, bindings)
+	if err != nil || chosen == "" {
+		return err
+	}
+	e, err := pickedEntry(db, string(out))
+	if err != nil {
+		return err
+	}
+	if err := releaseEntry(e, "menu"); err != nil {
+		return err
+	}
+	if strings.HasPrefix(chosen, "type") {
+		time.Sleep(menuTypeDelay)
+	}
+	return menuActions[chosen](e, *clearAfter)
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMenuBindings(t *testing.T) {
+	bindings, err := parseMenuBindings("Alt+c=copy-password, Alt+u = copy-username")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []menuBinding{{"Alt+c", "copy-password"}, {"Alt+u", "copy-username"}}
+	if !reflect.DeepEqual(bindings, want) {
+		t.Errorf("parseMenuBindings = %v; want %v", bindings, want)
+	}
+	for _, bad := range []string{"Alt+c", "=type", "Alt+c=paste"} {
+		if _, err := parseMenuBindings(bad); err == nil {
+			t.Errorf("parseMenuBindings(%q) succeeded; want error", bad)
+		}
+	}
+
+	argv, err := launcherCommand("/usr/bin/rofi -dmenu", bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantArgv := []string{"/usr/bin/rofi", "-dmenu", "-kb-custom-1", "Alt+c", "-kb-custom-2", "Alt+u"}
+	if !reflect.DeepEqual(argv, wantArgv) {
+		t.Errorf("launcherCommand = %q; want %q", argv, wantArgv)
+	}
+	if _, err := launcherCommand("dmenu -i", bindings); err == nil {
+		t.Error("launcherCommand(dmenu) with key bindings succeeded; want error")
+	}
+
+	tests := []struct {
+		code int
+		want string
+	}{
+		{0, "type"},
+		{1, ""},
+		{10, "copy-password"},
+		{11, "copy-username"},
+	}
+	for _, test := range tests {
+		got, err := menuChoice(test.code, "type", bindings)
+		if err != nil || got != test.want {
+			t.Errorf("menuChoice(%d) = %q, %v; want %q", test.code, got, err, test.want)
+		}
+	}
+	if _, err := menuChoice(12, "type", bindings); err == nil {
+		t.Error("menuChoice(12) succeeded; want error")
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// entryURIScheme is the scheme of links to a database or an entry in it,
+// like kdbx:///home/me/vault.kdb?entry=Work/VPN.  The entry may be given
+// by path or UUID.
+const entryURIScheme = "kdbx"
+
+// keepassMIMEType is the file type registered for databases.
+const keepassMIMEType = "application/x-keepass"
+
+// parseEntryURI returns the database path and entry of a link.  Anything
+// that isn't a kdbx: URI is taken as the path of a database, as given by
+// file managers.
+func parseEntryURI(s string) (path, entry string, err error) {
+	if !strings.HasPrefix(strings.ToLower(s), entryURIScheme+":") {
+		return s, "", nil
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", "", err
+	}
+	switch {
+	case u.Opaque != "":
+		path, err = url.PathUnescape(u.Opaque)
+		if err != nil {
+			return "", "", err
+		}
+	case u.Host != "" && u.Host != "localhost":
+		// kdbx://vault.kdb is relative, not a host.
+		path = u.Host + u.Path
+	default:
+		path = u.Path
+		// kdbx:///C:/vault.kdb
+		if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+			path = path[1:]
+		}
+	}
+	return filepath.FromSlash(path), u.Query().Get("entry"), nil
+}
+
+// runOpen shows the database or entry that a link or file refers to.  It
+// is what the handlers installed by "open -register" run.
+func runOpen(args []string) (err error) {
+	fs := flag.NewFlagSet("open", flag.ContinueOnError)
+	register := fs.Bool("register", false, "make this program the handler for "+entryURIScheme+": links and .kdb files")
+	reveal := fs.Bool("reveal", false, "show the password instead of masking it")
+	copyPassword := fs.Bool("copy", false, "copy the password to the clipboard")
+	raw := fs.Bool("raw", false, "print notes as written instead of rendering their Markdown")
+	wait := fs.Bool("wait", false, "wait for Enter before exiting, so that a terminal opened for the link stays open")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *register {
+		if fs.NArg() > 0 {
+			return errors.New("usage: open -register")
+		}
+		return registerHandlers()
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: open [-reveal] [-copy] [-raw] [-wait] " + entryURIScheme + "://path?entry=uuid | open file | open -register")
+	}
+	if *wait {
+		// Report the error before waiting, or it would never be seen.
+		defer func() {
+			if err != nil {
+				fmt.Fprintf(stderr, "gostpass open: %v\n", err)
+				err = exitError{code: 1}
+			}
+			fmt.Fprint(os.Stderr, tr("Press Enter to close."))
+			stdin.ReadString('\n')
+		}()
+	}
+	path, entry, err := parseEntryURI(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		*dbPath = path
+	}
+	if *dbPath == "" {
+		return errors.New("no database in link; use -db")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	if entry == "" {
+		var paths []string
+		for _, e := range db.Entries() {
+			paths = append(paths, entryPath(e))
+		}
+		sort.Strings(paths)
+		for _, p := range paths {
+			fmt.Println(p)
+		}
+		return nil
+	}
+	e, err := findEntryPath(db, entry)
+	if err != nil {
+		return err
+	}
+	if *reveal || *copyPassword {
+		if err := releaseEntry(e, "open"); err != nil {
+			return err
+		}
+	}
+	password := masked
+	if *reveal {
+		password = e.Password
+	}
+	writeEntry(os.Stdout, e, password, *raw, notesColor())
+	if *copyPassword {
+		if err := copyToClipboard(e.Password); err != nil {
+			return err
+		}
+		fmt.Println(tr("Password copied to the clipboard."))
+	}
+	return nil
+}
+
+// handlerArgs returns the arguments that the registered handlers run this
+// program with, before the link: the global flags given now, other than
+// -db, so that -keyfile or -prompt carry over.
+func handlerArgs() ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	args := []string{exe}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name != "db" {
+			args = append(args, "-"+f.Name+"="+f.Value.String())
+		}
+	})
+	return append(args, "open", "-wait"), nil
+}
+
+func registerHandlers() error {
+	args, err := handlerArgs()
+	if err != nil {
+		return fmt.Errorf("register: %v", err)
+	}
+	switch runtime.GOOS {
+	case "windows":
+		return registerWindows(args)
+	case "darwin":
+		return errors.New("register: macOS only takes handlers from application bundles; open links with \"gostpass open\" instead")
+	default:
+		return registerXDG(args)
+	}
+}
+
+// desktopQuote quotes an argument for the Exec key of a desktop entry.
+func desktopQuote(arg string) string {
+	arg = strings.Replace(arg, "%", "%%", -1)
+	if !strings.ContainsAny(arg, " \t\n\"'\\><~|&;$*?#()`") {
+		return arg
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+	// The value of a string key is unescaped once more before the Exec
+	// rules apply, so backslashes are doubled again.
+	return strings.Replace(`"`+r.Replace(arg)+`"`, `\`, `\\`, -1)
+}
+
+// desktopEntry returns the desktop entry that runs args for links and
+// database files.
+func desktopEntry(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = desktopQuote(arg)
+	}
+	return "[Desktop Entry]\n" +
+		"Type=Application\n" +
+		"Name=gostpass\n" +
+		"Comment=Open password database entries\n" +
+		"Exec=" + strings.Join(quoted, " ") + " %u\n" +
+		"Terminal=true\n" +
+		"NoDisplay=true\n" +
+		"MimeType=x-scheme-handler/" + entryURIScheme + ";" + keepassMIMEType + ";\n"
+}
+
+const mimePackage = `<?xml version="1.0" encoding="UTF-8"?>
+<mime-info xmlns="http://www.freedesktop.org/standards/shared-mime-info">
+  <mime-type type="` + keepassMIMEType + `">
+    <comment>KeePass 1 password database</comment>
+    <glob pattern="*.kdb"/>
+  </mime-type>
+</mime-info>
+`
+
+// registerXDG installs a desktop entry and a MIME type for the user, and
+// makes the entry the default for both, following the freedesktop.org
+// specifications.
+func registerXDG(args []string) error {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("register: %v", err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	appsDir := filepath.Join(dataHome, "applications")
+	mimeDir := filepath.Join(dataHome, "mime")
+	files := []struct {
+		path, data string
+	}{
+		{filepath.Join(appsDir, "gostpass.desktop"), desktopEntry(args)},
+		{filepath.Join(mimeDir, "packages", "gostpass.xml"), mimePackage},
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			return fmt.Errorf("register: %v", err)
+		}
+		if err := ioutil.WriteFile(f.path, []byte(f.data), 0644); err != nil {
+			return fmt.Errorf("register: %v", err)
+		}
+		fmt.Println(f.path)
+	}
+	// The files above are enough once the caches are rebuilt, which some
+	// desktops do on their own, so missing tools are only warned about.
+	for _, argv := range [][]string{
+		{"update-mime-database", mimeDir},
+		{"update-desktop-database", appsDir},
+		{"xdg-mime", "default", "gostpass.desktop", "x-scheme-handler/" + entryURIScheme, keepassMIMEType},
+	} {
+		if out, err := exec.Command(argv[0], argv[1:]...).CombinedOutput(); err != nil {
+			fmt.Fprintf(stderr, "gostpass open: %s: %v: %s\n", argv[0], err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// windowsCommand quotes args for a shell\open\command registry value.
+func windowsCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = `"` + strings.Replace(arg, `"`, `\"`, -1) + `"`
+	}
+	return strings.Join(quoted, " ") + ` "%1"`
+}
+
+// registerWindows registers the link scheme and the file type for the
+// current user.
+func registerWindows(args []string) error {
+	const classes = `HKCU\Software\Classes\`
+	command := windowsCommand(args)
+	for _, argv := range [][]string{
+		{classes + entryURIScheme, "/ve", "/d", "URL:gostpass link"},
+		{classes + entryURIScheme, "/v", "URL Protocol", "/d", ""},
+		{classes + entryURIScheme + `\shell\open\command`, "/ve", "/d", command},
+		{classes + ".kdb", "/ve", "/d", "gostpass.kdb"},
+		{classes + ".kdb", "/v", "Content Type", "/d", keepassMIMEType},
+		{classes + `gostpass.kdb`, "/ve", "/d", "KeePass 1 password database"},
+		{classes + `gostpass.kdb\shell\open\command`, "/ve", "/d", command},
+	} {
+		cmd := exec.Command("reg", append(append([]string{"add"}, argv...), "/f")...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("register: reg add %s: %v: %s", argv[0], err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseEntryURI(t *testing.T) {
+	tests := []struct {
+		uri         string
+		path, entry string
+	}{
+		{"/home/me/vault.kdb", "/home/me/vault.kdb", ""},
+		{"kdbx:///home/me/vault.kdb", "/home/me/vault.kdb", ""},
+		{"kdbx:///home/me/my%20vault.kdb?entry=Work/VPN", "/home/me/my vault.kdb", "Work/VPN"},
+		{"kdbx://localhost/srv/vault.kdb?entry=6e9f2ef1-2ba8-4e34-9e4d-6f0c3a4a3f10", "/srv/vault.kdb", "6e9f2ef1-2ba8-4e34-9e4d-6f0c3a4a3f10"},
+		{"kdbx://vault.kdb?entry=Mail", "vault.kdb", "Mail"},
+		{"KDBX:vault.kdb", "vault.kdb", ""},
+		{"kdbx:///C:/Users/me/vault.kdb", "C:/Users/me/vault.kdb", ""},
+		{"kdbx:?entry=Mail", "", "Mail"},
+	}
+	for _, test := range tests {
+		path, entry, err := parseEntryURI(test.uri)
+		if err != nil {
+			t.Errorf("parseEntryURI(%q): %v", test.uri, err)
+			continue
+		}
+		if want := filepath.FromSlash(test.path); path != want || entry != test.entry {
+			t.Errorf("parseEntryURI(%q) = %q, %q; want %q, %q", test.uri, path, entry, want, test.entry)
+		}
+	}
+}
+
+func TestDesktopEntry(t *testing.T) {
+	got := desktopEntry([]string{"/opt/my apps/gostpass", "-keyfile=/home/me/$HOME\\key", "open", "-wait"})
+	want := `Exec="/opt/my apps/gostpass" "-keyfile=/home/me/\\$HOME\\\\key" open -wait %u` + "\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("desktopEntry:\n%s\nwant line %s", got, want)
+	}
+	if !strings.Contains(desktopEntry([]string{"100%"}), "Exec=100%% %u\n") {
+		t.Error("desktopEntry: percent sign not escaped")
+	}
+}
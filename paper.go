@@ -0,0 +1,98 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/paper"
+)
+
+// writePaperBackup writes a printable copy of the database file kdb, which
+// is already encrypted, with instructions for restoring it.
+func writePaperBackup(w io.Writer, kdb []byte, now time.Time) error {
+	_, err := fmt.Fprintf(w, `GOSTPASS PAPER BACKUP
+
+Made %s. %d bytes on %d lines.
+
+This is a gostpass database encrypted with the password chosen when it
+was exported. Keep the password apart from this paper.
+
+To restore it, type or scan all of the text below into a file, keeping the
+first line, and run:
+
+	gostpass restore-paper backup.txt restored.kdb
+
+Each line ends in a checksum. A line that was misread is reported by its
+number. Lines may be in any order, and other text is ignored.
+
+`, now.Format("2006-01-02 15:04 MST"), len(kdb), paper.Lines(len(kdb)))
+	if err != nil {
+		return err
+	}
+	return paper.Encode(w, kdb)
+}
+
+// runRestorePaper decodes a paper backup back into a database file.
+func runRestorePaper(args []string) error {
+	fs := flag.NewFlagSet("restore-paper", flag.ContinueOnError)
+	force := fs.Bool("f", false, "overwrite the database file if it exists")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("usage: restore-paper [-f] backup.txt|- restored.kdb")
+	}
+	var (
+		text []byte
+		err  error
+	)
+	if fs.Arg(0) == "-" {
+		text, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		text, err = ioutil.ReadFile(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	kdb, err := paper.Decode(bytes.NewReader(text))
+	if err != nil {
+		return err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if *force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(fs.Arg(1), flags, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(kdb)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf(tr("%d bytes restored to %s; open it with the export password\n"), len(kdb), fs.Arg(1))
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// writePickList writes a "path<TAB>username" line for every entry outside
+// the recycle bin, sorted by path.
+func writePickList(w io.Writer, db *keepass.Database) error {
+	var lines []string
+	for _, e := range db.Entries() {
+		if e.Parent().InRecycleBin() {
+			continue
+		}
+		lines = append(lines, entryPath(e)+"\t"+e.Username)
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pickedEntry returns the entry on a line written by writePickList.  The
+// username tells apart entries that share a path.
+func pickedEntry(db *keepass.Database, line string) (*keepass.Entry, error) {
+	line = strings.TrimRight(line, "\r\n")
+	path, username := line, ""
+	if i := strings.IndexByte(line, '\t'); i >= 0 {
+		path, username = line[:i], line[i+1:]
+	}
+	dir, title := splitItemPath(path)
+	g := db.FindGroupPath(dir)
+	if g == nil || title == "" {
+		return nil, pathNotFoundError(path)
+	}
+	var found []*keepass.Entry
+	for _, e := range g.Entries() {
+		if e.Title == title {
+			found = append(found, e)
+		}
+	}
+	if len(found) > 1 {
+		var sameUser []*keepass.Entry
+		for _, e := range found {
+			if e.Username == username {
+				sameUser = append(sameUser, e)
+			}
+		}
+		found = sameUser
+	}
+	switch len(found) {
+	case 0:
+		return nil, pathNotFoundError(path)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("%s: more than one entry has this title and username", path)
+	}
+}
+
+// runPick lists entries for a fuzzy finder such as fzf, rofi -dmenu or
+// wofi --dmenu, and prints a field of the entry on the line chosen.  For
+// example:
+//
+//	gostpass -db vault.kdb pick | fzf | gostpass -db vault.kdb pick -field password
+func runPick(args []string) error {
+	fs := flag.NewFlagSet("pick", flag.ContinueOnError)
+	field := fs.String("field", "", "print field `name` of the entry on the line given as an argument or on standard input")
+	clip := fs.Bool("clip", false, "copy the field to the clipboard instead of printing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *field == "" {
+		if fs.NArg() > 0 || *clip {
+			return errors.New("usage: pick | pick -field name [-clip] [line]")
+		}
+		db, err := openCommandDatabase()
+		if err != nil {
+			return err
+		}
+		return writePickList(os.Stdout, db)
+	}
+	get := entryFields[*field]
+	if get == nil {
+		return fmt.Errorf("unknown field %q; want one of %s", *field, strings.Join(sortedEntryFields(), ", "))
+	}
+	var line string
+	switch fs.NArg() {
+	case 0:
+		// The line comes from the finder, so the password can't.
+		stdinProtocol = true
+		var err error
+		if line, err = stdin.ReadString('\n'); err != nil && (err != io.EOF || line == "") {
+			return errors.New("no line on standard input; was the selection cancelled?")
+		}
+	case 1:
+		line = fs.Arg(0)
+	default:
+		return errors.New("usage: pick | pick -field name [-clip] [line]")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	e, err := pickedEntry(db, line)
+	if err != nil {
+		return err
+	}
+	if err := releaseEntry(e, "pick"); err != nil {
+		return err
+	}
+	if *clip {
+		return copyToClipboard(get(e))
+	}
+	_, err = fmt.Println(get(e))
+	return err
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestPick(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ent := range []struct{ group, title, username string }{
+		{"Web", "Mail", "alice"},
+		{"Web", "Mail", "bob"},
+		{"Web", "Shop", ""},
+		{"Old", "Forum", "carol"},
+	} {
+		g, err := db.MkdirAll(ent.group)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title, e.Username = ent.title, ent.username
+	}
+	old, _ := findEntryPath(db, "Old/Forum")
+	if err := db.RecycleEntry(old); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writePickList(&buf, db); err != nil {
+		t.Fatal(err)
+	}
+	want := "Web/Mail\talice\nWeb/Mail\tbob\nWeb/Shop\t\n"
+	if buf.String() != want {
+		t.Errorf("list = %q; want %q", buf.String(), want)
+	}
+
+	for _, line := range strings.SplitAfter(want, "\n")[:3] {
+		e, err := pickedEntry(db, line)
+		if err != nil {
+			t.Errorf("pickedEntry(%q): %v", line, err)
+			continue
+		}
+		if got := entryPath(e) + "\t" + e.Username + "\n"; got != line {
+			t.Errorf("pickedEntry(%q) = %q", line, got)
+		}
+	}
+	if e, err := pickedEntry(db, "Web/Shop\tstale"); err != nil || e.Title != "Shop" {
+		t.Errorf("pickedEntry with a changed username = %v, %v; want Web/Shop", e, err)
+	}
+	for _, line := range []string{"Web/Mail\tcarol", "Web/Nothing\t", "Nowhere/Mail\talice"} {
+		if _, err := pickedEntry(db, line); err == nil {
+			t.Errorf("pickedEntry(%q) succeeded; want error", line)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package breach matches passwords against a local copy of a breach
+// corpus, such as the Pwned Passwords lists, without any network access.
+//
+// A corpus is text with one hex-encoded hash per line, optionally followed
+// by a colon and a count: SHA-1 hashes of the password, or NTLM hashes
+// (MD4 of the UTF-16LE password).  The kind is told apart by the hash
+// length.  Since corpora are large, a corpus can be compacted into a Bloom
+// filter with BuildFilter, which can then be queried without reading it
+// into memory.
+package breach // import "github.com/pedroalbanese/gostpass/pkg/breach"
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// Kind is the hash function of a corpus.
+type Kind byte
+
+// Corpus kinds.
+const (
+	SHA1 Kind = 1
+	NTLM Kind = 2
+)
+
+func (k Kind) String() string {
+	switch k {
+	case SHA1:
+		return "SHA-1"
+	case NTLM:
+		return "NTLM"
+	default:
+		return fmt.Sprintf("Kind(%d)", byte(k))
+	}
+}
+
+// Sum returns the hash of password used by corpora of kind k.
+func (k Kind) Sum(password string) []byte {
+	switch k {
+	case SHA1:
+		sum := sha1.Sum([]byte(password))
+		return sum[:]
+	case NTLM:
+		u := utf16.Encode([]rune(password))
+		b := make([]byte, 2*len(u))
+		for i, c := range u {
+			b[2*i] = byte(c)
+			b[2*i+1] = byte(c >> 8)
+		}
+		h := md4.New()
+		h.Write(b)
+		return h.Sum(nil)
+	default:
+		panic("breach: unknown kind")
+	}
+}
+
+// parseLine returns the hash on a corpus line.  Blank lines and lines
+// starting with '#' have no hash.
+func parseLine(line []byte) (hash []byte, kind Kind, err error) {
+	if i := bytes.IndexByte(line, ':'); i >= 0 {
+		line = line[:i]
+	}
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] == '#' {
+		return nil, 0, nil
+	}
+	switch len(line) {
+	case 2 * sha1.Size:
+		kind = SHA1
+	case 2 * md4.Size:
+		kind = NTLM
+	default:
+		return nil, 0, fmt.Errorf("hash has %d digits; want 40 (SHA-1) or 32 (NTLM)", len(line))
+	}
+	hash = make([]byte, len(line)/2)
+	if _, err := hex.Decode(hash, line); err != nil {
+		return nil, 0, err
+	}
+	return hash, kind, nil
+}
+
+// readCorpus calls f with each hash in the corpus, stopping at the first
+// error f returns.
+func readCorpus(r io.Reader, f func(hash []byte, kind Kind) error) error {
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		hash, kind, err := parseLine(s.Bytes())
+		if err != nil {
+			return fmt.Errorf("breach: corpus line %d: %v", n, err)
+		}
+		if hash == nil {
+			continue
+		}
+		if err := f(hash, kind); err != nil {
+			return err
+		}
+	}
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("breach: read corpus: %v", err)
+	}
+	return nil
+}
+
+// Scan reads a text corpus and returns which of passwords appear in it.
+// It reads the whole corpus, so for repeated checks against a large
+// corpus, build a filter instead.
+func Scan(corpus io.Reader, passwords []string) (map[string]bool, error) {
+	want := make(map[string]string, 2*len(passwords))
+	for _, pw := range passwords {
+		want[string(SHA1.Sum(pw))] = pw
+		want[string(NTLM.Sum(pw))] = pw
+	}
+	found := make(map[string]bool)
+	err := readCorpus(corpus, func(hash []byte, _ Kind) error {
+		if pw, ok := want[string(hash)]; ok {
+			found[pw] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// Count returns the number of hashes in a text corpus.
+func Count(corpus io.Reader) (uint64, error) {
+	var n uint64
+	err := readCorpus(corpus, func([]byte, Kind) error {
+		n++
+		return nil
+	})
+	return n, err
+}
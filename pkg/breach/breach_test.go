@@ -0,0 +1,104 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package breach
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testCorpus = `# Pwned Passwords excerpt
+5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493
+7C4A8D09CA3762AF61E59520943DC26494F8941B:123
+
+8846F7EAEE8FB117AD06BDD830B7586C:9
+`
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		kind     Kind
+		password string
+		want     string
+	}{
+		{SHA1, "password", "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8"},
+		{NTLM, "password", "8846f7eaee8fb117ad06bdd830b7586c"},
+		{NTLM, "", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+	}
+	for _, test := range tests {
+		if got := hex.EncodeToString(test.kind.Sum(test.password)); got != test.want {
+			t.Errorf("%v.Sum(%q) = %s; want %s", test.kind, test.password, got, test.want)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	found, err := Scan(strings.NewReader(testCorpus), []string{"password", "123456", "correct horse battery staple"})
+	if err != nil {
+		t.Fatal("Scan:", err)
+	}
+	if !found["password"] || !found["123456"] || len(found) != 2 {
+		t.Errorf("Scan found %v; want password and 123456", found)
+	}
+	if _, err := Scan(strings.NewReader("not a hash\n"), nil); err == nil {
+		t.Error("Scan of malformed corpus succeeded")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var corpus bytes.Buffer
+	const n = 1000
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&corpus, "%X:1\n", SHA1.Sum(fmt.Sprint("pw", i)))
+	}
+	count, err := Count(bytes.NewReader(corpus.Bytes()))
+	if err != nil || count != n {
+		t.Fatalf("Count = %d, %v; want %d, <nil>", count, err, n)
+	}
+	var buf bytes.Buffer
+	if err := BuildFilter(&buf, &corpus, count, 0.01); err != nil {
+		t.Fatal("BuildFilter:", err)
+	}
+	f, err := OpenFilter(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal("OpenFilter:", err)
+	}
+	if f.Kind() != SHA1 {
+		t.Errorf("Kind() = %v; want SHA-1", f.Kind())
+	}
+	for i := 0; i < n; i++ {
+		if ok, err := f.Contains(fmt.Sprint("pw", i)); !ok || err != nil {
+			t.Fatalf("Contains(%q) = %t, %v; want true, <nil>", fmt.Sprint("pw", i), ok, err)
+		}
+	}
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		if ok, _ := f.Contains(fmt.Sprint("other", i)); ok {
+			falsePositives++
+		}
+	}
+	if falsePositives > n/20 {
+		t.Errorf("%d false positives in %d; want about %d", falsePositives, n, n/100)
+	}
+
+	if _, err := OpenFilter(strings.NewReader(testCorpus)); err != ErrNotFilter {
+		t.Errorf("OpenFilter(text corpus) error = %v; want ErrNotFilter", err)
+	}
+	if err := BuildFilter(&buf, strings.NewReader(testCorpus), 3, 0.01); err != ErrMixedKinds {
+		t.Errorf("BuildFilter(mixed corpus) error = %v; want ErrMixedKinds", err)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package breach
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// filterMagic starts every filter file.  The header is followed by the
+// hash kind, the number of probes, the number of bits as a big-endian
+// uint64, and then the bits.
+const filterMagic = "GPBLOOM1"
+
+const filterHeaderSize = len(filterMagic) + 1 + 1 + 8
+
+// Errors
+var (
+	ErrNotFilter  = errors.New("breach: not a filter file")
+	ErrMixedKinds = errors.New("breach: corpus mixes SHA-1 and NTLM hashes")
+	ErrEmpty      = errors.New("breach: corpus is empty")
+)
+
+// A Filter is a Bloom filter of a corpus's hashes.  It may report a
+// password that isn't in the corpus as breached, with the false positive
+// rate given when it was built, but never misses one that is.
+type Filter struct {
+	r    io.ReaderAt
+	kind Kind
+	k    uint8
+	m    uint64
+}
+
+// OpenFilter reads a filter's header from r.  Bits are read from r as
+// needed, so r must stay open while the filter is used.
+func OpenFilter(r io.ReaderAt) (*Filter, error) {
+	var hdr [filterHeaderSize]byte
+	if _, err := r.ReadAt(hdr[:], 0); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrNotFilter
+	} else if err != nil {
+		return nil, fmt.Errorf("breach: read filter: %v", err)
+	}
+	if string(hdr[:len(filterMagic)]) != filterMagic {
+		return nil, ErrNotFilter
+	}
+	f := &Filter{
+		r:    r,
+		kind: Kind(hdr[len(filterMagic)]),
+		k:    hdr[len(filterMagic)+1],
+		m:    binary.BigEndian.Uint64(hdr[len(filterMagic)+2:]),
+	}
+	if (f.kind != SHA1 && f.kind != NTLM) || f.k == 0 || f.m == 0 {
+		return nil, errors.New("breach: corrupt filter header")
+	}
+	return f, nil
+}
+
+// Kind returns the kind of corpus the filter was built from.
+func (f *Filter) Kind() Kind {
+	return f.kind
+}
+
+// Contains reports whether password is probably in the corpus.
+func (f *Filter) Contains(password string) (bool, error) {
+	var b [1]byte
+	for _, i := range probes(f.kind.Sum(password), f.k, f.m) {
+		if _, err := f.r.ReadAt(b[:], int64(filterHeaderSize)+int64(i/8)); err != nil {
+			return false, fmt.Errorf("breach: read filter: %v", err)
+		}
+		if b[0]&(1<<(i%8)) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// probes returns the bits to test for hash.  The hash is already uniformly
+// distributed, so its first 16 bytes seed double hashing directly.
+func probes(hash []byte, k uint8, m uint64) []uint64 {
+	h1 := binary.BigEndian.Uint64(hash[:8])
+	h2 := binary.BigEndian.Uint64(hash[8:16]) | 1
+	idx := make([]uint64, k)
+	for i := range idx {
+		idx[i] = (h1 + uint64(i)*h2) % m
+	}
+	return idx
+}
+
+// filterSize returns the number of bits and probes that give a false
+// positive rate of p for n hashes.
+func filterSize(n uint64, p float64) (m uint64, k uint8) {
+	bits := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	m = uint64(bits)
+	if m < 64 {
+		m = 64
+	}
+	probes := math.Round(float64(m) / float64(n) * math.Ln2)
+	switch {
+	case probes < 1:
+		k = 1
+	case probes > 32:
+		k = 32
+	default:
+		k = uint8(probes)
+	}
+	return m, k
+}
+
+// BuildFilter reads a text corpus of n hashes, as counted by Count, and
+// writes a filter with a false positive rate of about p.  All hashes in
+// the corpus must be of the same kind.
+func BuildFilter(w io.Writer, corpus io.Reader, n uint64, p float64) error {
+	if n == 0 {
+		return ErrEmpty
+	}
+	if !(p > 0 && p < 1) {
+		return errors.New("breach: false positive rate must be between 0 and 1")
+	}
+	m, k := filterSize(n, p)
+	bits := make([]byte, (m+7)/8)
+	var kind Kind
+	err := readCorpus(corpus, func(hash []byte, hk Kind) error {
+		if kind == 0 {
+			kind = hk
+		} else if hk != kind {
+			return ErrMixedKinds
+		}
+		for _, i := range probes(hash, k, m) {
+			bits[i/8] |= 1 << (i % 8)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if kind == 0 {
+		return ErrEmpty
+	}
+	var hdr [filterHeaderSize]byte
+	copy(hdr[:], filterMagic)
+	hdr[len(filterMagic)] = byte(kind)
+	hdr[len(filterMagic)+1] = k
+	binary.BigEndian.PutUint64(hdr[len(filterMagic)+2:], m)
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(bits)
+	return err
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package changelog keeps an encrypted, tamper-evident log of changes to a
+// database.
+//
+// Each record is sealed with Kuznyechik in MGM mode and chained to the
+// previous one with HMAC-Streebog-256, so that records can't be altered,
+// reordered or removed from the middle without the key.  Removing records
+// from the end is detected by comparing the last Head with one kept
+// somewhere the attacker can't write, like the encrypted database.
+package changelog // import "github.com/pedroalbanese/gostpass/pkg/changelog"
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gogost/gost3412128"
+	"github.com/pedroalbanese/gogost/mgm"
+	"github.com/pedroalbanese/gostpass/pkg/gosthmac"
+)
+
+// KeySize is the size of a log key in bytes.
+const KeySize = 32
+
+// Errors
+var (
+	ErrKeySize = errors.New("changelog: key must be 32 bytes")
+	ErrChain   = errors.New("changelog: record does not follow the previous one")
+	ErrDecrypt = errors.New("changelog: record cannot be decrypted")
+)
+
+// A Record describes one saved change to the database.
+type Record struct {
+	Time    time.Time `json:"time"`
+	Author  string    `json:"author"`
+	Changes []string  `json:"changes"`
+}
+
+// A Head identifies the last record of a log.  The zero Head is the head
+// of an empty log.
+type Head struct {
+	Seq uint64
+	MAC []byte
+}
+
+// String formats h as "seq:mac", with the MAC in hex.
+func (h Head) String() string {
+	return strconv.FormatUint(h.Seq, 10) + ":" + hex.EncodeToString(h.MAC)
+}
+
+// ParseHead parses the output of Head.String.
+func ParseHead(s string) (Head, error) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return Head{}, fmt.Errorf("changelog: invalid head %q", s)
+	}
+	seq, err := strconv.ParseUint(s[:i], 10, 64)
+	if err != nil {
+		return Head{}, fmt.Errorf("changelog: invalid head %q", s)
+	}
+	mac, err := hex.DecodeString(s[i+1:])
+	if err != nil {
+		return Head{}, fmt.Errorf("changelog: invalid head %q", s)
+	}
+	return Head{Seq: seq, MAC: mac}, nil
+}
+
+// Equal reports whether h and other identify the same record.
+func (h Head) Equal(other Head) bool {
+	return h.Seq == other.Seq && gosthmac.Equal(h.MAC, other.MAC)
+}
+
+// line is the serialized form of a record: one JSON object per line.
+type line struct {
+	Seq   uint64 `json:"seq"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+	MAC   []byte `json:"mac"`
+}
+
+// A LineError reports a log line that failed verification.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// A Log seals and verifies records with a key.
+type Log struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+// New returns a Log using key, which must be KeySize random bytes.
+func New(key []byte) (*Log, error) {
+	if len(key) != KeySize {
+		return nil, ErrKeySize
+	}
+	aead, err := mgm.NewMGM(gost3412128.NewCipher(gosthmac.Sum256(key, []byte("gostpass changelog encryption"))), gost3412128.BlockSize)
+	if err != nil {
+		return nil, fmt.Errorf("changelog: %v", err)
+	}
+	return &Log{aead: aead, macKey: gosthmac.Sum256(key, []byte("gostpass changelog chain"))}, nil
+}
+
+func (l *Log) mac(prev []byte, ln *line) []byte {
+	h := gosthmac.New256(l.macKey)
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], ln.Seq)
+	h.Write(prev)
+	h.Write(seq[:])
+	h.Write(ln.Nonce)
+	h.Write(ln.Data)
+	return h.Sum(nil)
+}
+
+// Append writes rec to w as the record following prev and returns the new
+// head.
+func (l *Log) Append(w io.Writer, rand io.Reader, prev Head, rec *Record) (Head, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return Head{}, fmt.Errorf("changelog: %v", err)
+	}
+	ln := &line{Seq: prev.Seq + 1, Nonce: make([]byte, l.aead.NonceSize())}
+	if _, err := io.ReadFull(rand, ln.Nonce); err != nil {
+		return Head{}, fmt.Errorf("changelog: %v", err)
+	}
+	// MGM requires the nonce's most significant bit to be clear.
+	ln.Nonce[0] &= 0x7f
+	ln.Data = l.aead.Seal(nil, ln.Nonce, data, prev.MAC)
+	ln.MAC = l.mac(prev.MAC, ln)
+	out, err := json.Marshal(ln)
+	if err != nil {
+		return Head{}, fmt.Errorf("changelog: %v", err)
+	}
+	if _, err := w.Write(append(out, '\n')); err != nil {
+		return Head{}, fmt.Errorf("changelog: %v", err)
+	}
+	return Head{Seq: ln.Seq, MAC: ln.MAC}, nil
+}
+
+// Read verifies and decrypts the log in r.  It returns the records read
+// and the head of the last valid record.  If the chain is broken, the
+// records before the break are returned along with a *LineError.
+func (l *Log) Read(r io.Reader) ([]Record, Head, error) {
+	var recs []Record
+	var head Head
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for n := 1; s.Scan(); n++ {
+		ln := new(line)
+		if err := json.Unmarshal(s.Bytes(), ln); err != nil {
+			return recs, head, &LineError{Line: n, Err: fmt.Errorf("changelog: %v", err)}
+		}
+		if ln.Seq != head.Seq+1 || !gosthmac.Equal(ln.MAC, l.mac(head.MAC, ln)) {
+			return recs, head, &LineError{Line: n, Err: ErrChain}
+		}
+		if len(ln.Nonce) != l.aead.NonceSize() {
+			return recs, head, &LineError{Line: n, Err: ErrDecrypt}
+		}
+		data, err := l.aead.Open(nil, ln.Nonce, ln.Data, head.MAC)
+		if err != nil {
+			return recs, head, &LineError{Line: n, Err: ErrDecrypt}
+		}
+		var rec Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return recs, head, &LineError{Line: n, Err: fmt.Errorf("changelog: %v", err)}
+		}
+		recs = append(recs, rec)
+		head = Head{Seq: ln.Seq, MAC: ln.MAC}
+	}
+	if err := s.Err(); err != nil {
+		return recs, head, fmt.Errorf("changelog: %v", err)
+	}
+	return recs, head, nil
+}
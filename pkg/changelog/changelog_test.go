@@ -0,0 +1,88 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"bytes"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendRead(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	l, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	var head Head
+	for i, change := range []string{"added entry Work/DB", "changed entry Work/DB: password", "deleted entry Work/DB"} {
+		rec := &Record{Time: time.Date(2026, time.March, i+1, 0, 0, 0, 0, time.UTC), Author: "alice", Changes: []string{change}}
+		if head, err = l.Append(&buf, rand.Reader, head, rec); err != nil {
+			t.Fatal("Append:", err)
+		}
+	}
+	if bytes.Contains(buf.Bytes(), []byte("Work/DB")) {
+		t.Error("log contains plaintext")
+	}
+	log := buf.String()
+
+	recs, got, err := l.Read(strings.NewReader(log))
+	if err != nil {
+		t.Fatal("Read:", err)
+	}
+	if len(recs) != 3 || recs[1].Changes[0] != "changed entry Work/DB: password" || recs[2].Author != "alice" {
+		t.Errorf("Read records = %+v", recs)
+	}
+	if !got.Equal(head) {
+		t.Errorf("Read head = %v; want %v", got, head)
+	}
+	if parsed, err := ParseHead(head.String()); err != nil || !parsed.Equal(head) {
+		t.Errorf("ParseHead(%q) = %v, %v; want %v", head.String(), parsed, err, head)
+	}
+
+	lines := strings.SplitAfter(log, "\n")
+	tests := []struct {
+		name string
+		log  string
+		n    int
+	}{
+		{"middle record removed", lines[0] + lines[2], 1},
+		{"records swapped", lines[1] + lines[0] + lines[2], 0},
+		{"record altered", lines[0] + strings.Replace(lines[1], `"data":"`, `"data":"AAAA`, 1) + lines[2], 1},
+	}
+	for _, test := range tests {
+		recs, _, err := l.Read(strings.NewReader(test.log))
+		if _, ok := err.(*LineError); !ok {
+			t.Errorf("Read with %s error = %v; want *LineError", test.name, err)
+		}
+		if len(recs) != test.n {
+			t.Errorf("Read with %s = %d records; want %d", test.name, len(recs), test.n)
+		}
+	}
+
+	other := make([]byte, KeySize)
+	rand.Read(other)
+	ol, err := New(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ol.Read(strings.NewReader(log)); err == nil {
+		t.Error("Read with wrong key succeeded")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cipherio
+
+import (
+	"crypto/cipher"
+	"sync"
+)
+
+// minParallelBlocks is the fewest blocks worth giving a goroutine of their
+// own.
+const minParallelBlocks = 256
+
+type parallelCBCDecrypter struct {
+	b       cipher.Block
+	iv      []byte
+	workers int
+}
+
+// NewParallelCBCDecrypter returns a BlockMode which decrypts in cipher
+// block chaining mode, like cipher.NewCBCDecrypter, but splits large
+// inputs into ranges that are decrypted by up to workers goroutines.
+// Decrypting a CBC block only takes the ciphertext block before it, so
+// once those are saved the ranges don't depend on each other, and each
+// range is written to its own place in dst.  b must be safe for
+// concurrent use, as block ciphers that only hold a key schedule are.
+func NewParallelCBCDecrypter(b cipher.Block, iv []byte, workers int) cipher.BlockMode {
+	if len(iv) != b.BlockSize() {
+		panic("cipherio: IV length must equal block size")
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return &parallelCBCDecrypter{
+		b:       b,
+		iv:      append([]byte(nil), iv...),
+		workers: workers,
+	}
+}
+
+func (x *parallelCBCDecrypter) BlockSize() int {
+	return x.b.BlockSize()
+}
+
+func (x *parallelCBCDecrypter) CryptBlocks(dst, src []byte) {
+	bs := x.b.BlockSize()
+	if len(src)%bs != 0 {
+		panic("cipherio: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("cipherio: output smaller than input")
+	}
+	nblocks := len(src) / bs
+	if nblocks == 0 {
+		return
+	}
+	n := nblocks / minParallelBlocks
+	if n > x.workers {
+		n = x.workers
+	}
+	// The chaining blocks have to be copied before dst, which may be
+	// src, overwrites them.
+	next := append([]byte(nil), src[len(src)-bs:]...)
+	if n <= 1 {
+		cipher.NewCBCDecrypter(x.b, x.iv).CryptBlocks(dst, src)
+		x.iv = next
+		return
+	}
+	bounds := make([]int, n+1)
+	ivs := make([][]byte, n)
+	for i := range ivs {
+		bounds[i] = i * nblocks / n * bs
+		if i == 0 {
+			ivs[i] = x.iv
+		} else {
+			ivs[i] = append([]byte(nil), src[bounds[i]-bs:bounds[i]]...)
+		}
+	}
+	bounds[n] = len(src)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := range ivs {
+		go func(iv, dst, src []byte) {
+			cipher.NewCBCDecrypter(x.b, iv).CryptBlocks(dst, src)
+			wg.Done()
+		}(ivs[i], dst[bounds[i]:bounds[i+1]], src[bounds[i]:bounds[i+1]])
+	}
+	wg.Wait()
+	x.iv = next
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cipherio
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/padding"
+)
+
+func TestParallelCBCDecrypter(t *testing.T) {
+	b, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := bytes.Repeat([]byte{7}, aes.BlockSize)
+	// Enough blocks for four workers, with a remainder that doesn't divide
+	// evenly among them.
+	nblocks := 4*minParallelBlocks + 3
+	plain := make([]byte, nblocks*aes.BlockSize)
+	for i := range plain {
+		plain[i] = byte(i * 31)
+	}
+	crypt := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(b, iv).CryptBlocks(crypt, plain)
+
+	for _, workers := range []int{0, 1, 2, 4, 7} {
+		for _, inPlace := range []bool{false, true} {
+			d := NewParallelCBCDecrypter(b, iv, workers)
+			out := make([]byte, len(crypt))
+			if inPlace {
+				copy(out, crypt)
+			}
+			// Calls of varying size carry the chaining block from one
+			// call to the next.
+			off := 0
+			for _, n := range []int{1, 3 * minParallelBlocks, nblocks - 3*minParallelBlocks - 1} {
+				n *= aes.BlockSize
+				src := crypt[off : off+n]
+				if inPlace {
+					src = out[off : off+n]
+				}
+				d.CryptBlocks(out[off:off+n], src)
+				off += n
+			}
+			if !bytes.Equal(out, plain) {
+				t.Errorf("workers=%d, in place=%t: decrypted data does not match", workers, inPlace)
+			}
+		}
+	}
+}
+
+// benchmarkDecrypt measures decrypting 32 MiB through a reader with the
+// block mode returned by newMode.
+func benchmarkDecrypt(b *testing.B, newMode func(cipher.Block, []byte) cipher.BlockMode, size int) {
+	block, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		b.Fatal(err)
+	}
+	iv := bytes.Repeat([]byte{7}, aes.BlockSize)
+	plain := make([]byte, 32<<20)
+	crypt := new(bytes.Buffer)
+	w := NewWriter(crypt, cipher.NewCBCEncrypter(block, iv), padding.PKCS7)
+	if _, err := w.Write(plain); err != nil {
+		b.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(crypt.Len()))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := NewReaderSize(bytes.NewReader(crypt.Bytes()), newMode(block, iv), padding.PKCS7, size)
+		if _, err := io.Copy(ioutil.Discard, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecryptSerial(b *testing.B) {
+	benchmarkDecrypt(b, cipher.NewCBCDecrypter, defaultReadSize)
+}
+
+func BenchmarkDecryptParallel(b *testing.B) {
+	benchmarkDecrypt(b, func(block cipher.Block, iv []byte) cipher.BlockMode {
+		return NewParallelCBCDecrypter(block, iv, runtime.GOMAXPROCS(0))
+	}, 1<<20)
+}
@@ -16,7 +16,6 @@
 package cipherio // import "github.com/pedroalbanese/gostpass/pkg/cipherio"
 
 import (
-	"bytes"
 	"crypto/cipher"
 	"errors"
 	"io"
@@ -29,43 +28,89 @@ type reader struct {
 	mode cipher.BlockMode
 	pad  padding.Padding
 
-	first  bool
-	buf    bytes.Buffer
-	rbuf   []byte
-	nplain int // number of bytes in buf that have been decrypted
-	err    error
+	first bool
+	// buf holds nplain bytes of plaintext at start, followed by the
+	// ciphertext up to end that has not been decrypted yet.  It comes from
+	// the buffer pool and is returned once the stream ends.
+	buf      []byte
+	start    int
+	nplain   int
+	end      int
+	readSize int
+	maxRead  int
+	err      error
 }
 
+const defaultReadSize = 1024
+
 // NewReader creates a new reader that decrypts and strips padding from r.
 func NewReader(r io.Reader, mode cipher.BlockMode, pad padding.Padding) io.Reader {
+	return NewReaderSize(r, mode, pad, defaultReadSize)
+}
+
+// NewReaderSize is like NewReader, but lets the reader decrypt up to size
+// bytes at a time.  It starts with small reads and doubles them as the
+// stream goes on, so that a short read from a long stream stays cheap
+// while a long one hands mode large inputs, which a parallel mode needs.
+//
+// The reader's buffers are reused by later readers and writers.  They are
+// returned once Read reports an error, including io.EOF, so a reader that
+// is read to the end needs no Close.  A reader abandoned earlier should be
+// closed; otherwise its buffers are left to the garbage collector.
+func NewReaderSize(r io.Reader, mode cipher.BlockMode, pad padding.Padding, size int) io.ReadCloser {
+	n := defaultReadSize
+	if size < n {
+		n = size
+	}
+	// Each fill reads at least one byte past a block, after up to a block
+	// left over from the last one.
+	if bs := mode.BlockSize(); n < 2*bs {
+		n = 2 * bs
+	}
+	if size < n {
+		size = n
+	}
 	return &reader{
-		r:     r,
-		mode:  mode,
-		pad:   pad,
-		rbuf:  make([]byte, 1024),
-		first: true,
+		r:        r,
+		mode:     mode,
+		pad:      pad,
+		readSize: n,
+		maxRead:  size,
+		first:    true,
 	}
 }
 
 func (r *reader) Read(p []byte) (n int, err error) {
-	if r.nplain > 0 {
-		n = r.readPlain(p)
-		return
+	if r.nplain == 0 {
+		r.growBuffer()
 	}
-	r.growBuffer()
 	if r.nplain > 0 {
-		n = r.readPlain(p)
-		return
+		return r.readPlain(p), nil
+	}
+	if r.err != nil {
+		r.release()
 	}
 	return 0, r.err
 }
 
-func (r *reader) readPlain(p []byte) int {
-	n := r.nplain
-	if n > len(p) {
-		n = len(p)
+// Close returns the reader's buffers for reuse.  Reads after Close fail.
+func (r *reader) Close() error {
+	r.release()
+	r.nplain = 0
+	r.err = errReadClosed
+	return nil
+}
+
+func (r *reader) release() {
+	if r.buf != nil {
+		putBuffer(r.buf)
+		r.buf = nil
 	}
-	r.buf.Read(p[:n])
+}
+
+func (r *reader) readPlain(p []byte) int {
+	n := copy(p, r.buf[r.start:r.start+r.nplain])
+	r.start += n
 	r.nplain -= n
 	return n
 }
@@ -75,10 +120,30 @@ func (r *reader) growBuffer() {
 		return
 	}
 	bs := r.mode.BlockSize()
+	if !r.first && r.readSize < r.maxRead {
+		r.readSize *= 2
+		if r.readSize > r.maxRead {
+			r.readSize = r.maxRead
+		}
+	}
+	// Keep the ciphertext that couldn't be decrypted last time at the
+	// front of the buffer.
+	leftover := r.end - r.start
+	if len(r.buf) < r.readSize {
+		buf := getBuffer(r.readSize)
+		if r.buf != nil {
+			copy(buf, r.buf[r.start:r.end])
+			putBuffer(r.buf)
+		}
+		r.buf = buf
+	} else {
+		copy(r.buf, r.buf[r.start:r.end])
+	}
+	r.start, r.end = 0, leftover
 	minSize := bs + 1
-	nn, err := io.ReadAtLeast(r.r, r.rbuf, minSize-r.buf.Len())
-	r.buf.Write(r.rbuf[:nn])
-	bufSize := r.buf.Len()
+	nn, err := io.ReadAtLeast(r.r, r.buf[leftover:r.readSize], minSize-leftover)
+	r.end += nn
+	bufSize := r.end
 	numExtra := bufSize % bs
 	switch {
 	case err == io.EOF || err == io.ErrUnexpectedEOF:
@@ -101,7 +166,7 @@ func (r *reader) growBuffer() {
 		// what to do.
 		r.nplain -= bs
 	}
-	b := r.buf.Bytes()[:r.nplain]
+	b := r.buf[:r.nplain]
 	r.mode.CryptBlocks(b, b)
 
 	// Strip padding at end
@@ -111,7 +176,7 @@ func (r *reader) growBuffer() {
 			r.err = err
 		}
 		r.nplain = len(strip)
-		r.buf.Truncate(r.nplain)
+		r.end = r.nplain
 	}
 }
 
@@ -126,7 +191,9 @@ type writer struct {
 }
 
 // NewWriter creates a new writer that encrypts its input and writes to w.
-// Closing the writer adds the final padding but does not close w.
+// Closing the writer adds the final padding but does not close w.  Close
+// must be called even after a write error, since it also returns the
+// writer's buffer for reuse.
 func NewWriter(w io.Writer, mode cipher.BlockMode, pad padding.Padding) io.WriteCloser {
 	blockSize := mode.BlockSize()
 	bufSize := 1024
@@ -145,7 +212,7 @@ func newWriter(w io.Writer, mode cipher.BlockMode, pad padding.Padding, bufSize
 		w:     w,
 		mode:  mode,
 		pad:   pad,
-		buf:   make([]byte, bufSize),
+		buf:   getBuffer(bufSize),
 		block: make([]byte, 0, blockSize),
 	}
 }
@@ -210,8 +277,13 @@ func (w *writer) writeNext(p []byte) (n int, err error) {
 func (w *writer) Close() error {
 	if w.err == errClosed {
 		return nil
-	} else if w.err != nil {
-		return w.err
+	}
+	putBuffer(w.buf)
+	w.buf = nil
+	if w.err != nil {
+		err := w.err
+		w.err = errClosed
+		return err
 	}
 	last := w.pad.Pad(w.block, w.mode.BlockSize())
 	w.mode.CryptBlocks(last, last)
@@ -220,4 +292,7 @@ func (w *writer) Close() error {
 	return err
 }
 
-var errClosed = errors.New("cipherio: write on closed writer")
+var (
+	errClosed     = errors.New("cipherio: write on closed writer")
+	errReadClosed = errors.New("cipherio: read on closed reader")
+)
@@ -134,6 +134,24 @@ func TestReader_OneByteOutput(t *testing.T) {
 	}
 }
 
+func TestReaderSize_Tight(t *testing.T) {
+	for _, test := range tests {
+		plain := new(bytes.Buffer)
+		mode := fakeBlockMode{size: test.blockSize, delta: 255}
+
+		r := NewReaderSize(bytes.NewReader(test.cipher), mode, padding.PKCS7, 1)
+		_, err := io.Copy(plain, r)
+
+		subject := fmt.Sprintf("io.Copy(..., NewReaderSize(bytes.NewReader(%v), %#v, padding.PKCS7, 1))", test.cipher, mode)
+		if err != test.readErr {
+			t.Errorf("%s error = %v; want %v", subject, err, test.readErr)
+		}
+		if !bytes.Equal(plain.Bytes(), test.plain) {
+			t.Errorf("%s data = %v; want %v", subject, plain.Bytes(), test.plain)
+		}
+	}
+}
+
 func TestWriter(t *testing.T) {
 	for _, test := range tests {
 		if test.readErr != nil {
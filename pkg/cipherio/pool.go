@@ -0,0 +1,64 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cipherio
+
+import "sync"
+
+// Buffers are pooled in power-of-two size classes, since readers ask for
+// sizes that double as a stream goes on.
+const (
+	minPoolShift = 10 // 1 KiB
+	maxPoolShift = 24 // 16 MiB
+)
+
+var bufPools [maxPoolShift - minPoolShift + 1]sync.Pool
+
+// sizeClass returns the index of the smallest pool whose buffers hold n
+// bytes, or -1 if n is too large to pool.
+func sizeClass(n int) int {
+	for c := range bufPools {
+		if n <= 1<<uint(c+minPoolShift) {
+			return c
+		}
+	}
+	return -1
+}
+
+// getBuffer returns a zeroed buffer of length n, reusing a pooled one if
+// there is one.
+func getBuffer(n int) []byte {
+	c := sizeClass(n)
+	if c < 0 {
+		return make([]byte, n)
+	}
+	if p, ok := bufPools[c].Get().(*[]byte); ok {
+		return (*p)[:n]
+	}
+	return make([]byte, n, 1<<uint(c+minPoolShift))
+}
+
+// putBuffer zeroes buf, which may have held plaintext, and pools it if it
+// came from getBuffer.
+func putBuffer(buf []byte) {
+	buf = buf[:cap(buf)]
+	for i := range buf {
+		buf[i] = 0
+	}
+	c := sizeClass(len(buf))
+	if c < 0 || len(buf) != 1<<uint(c+minPoolShift) {
+		return
+	}
+	bufPools[c].Put(&buf)
+}
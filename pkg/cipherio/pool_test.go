@@ -0,0 +1,108 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cipherio
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/padding"
+)
+
+func TestBufferPool(t *testing.T) {
+	for _, n := range []int{1, 1024, 1025, 1 << 20, 1<<maxPoolShift + 1} {
+		buf := getBuffer(n)
+		if len(buf) != n {
+			t.Fatalf("len(getBuffer(%d)) = %d", n, len(buf))
+		}
+		for i := range buf {
+			buf[i] = 0xff
+		}
+		putBuffer(buf)
+		buf = getBuffer(n)
+		for i, b := range buf[:cap(buf)] {
+			if b != 0 {
+				t.Fatalf("getBuffer(%d) after putBuffer: byte %d = %#x; want 0", n, i, b)
+			}
+		}
+	}
+}
+
+func TestReader_Close(t *testing.T) {
+	mode := fakeBlockMode{size: 4, delta: 255}
+	r := NewReaderSize(bytes.NewReader([]byte{1, 2, 3, 4, 5, 6, 7, 8, 5, 5, 5, 5}), mode, padding.PKCS7, 8)
+	var p [2]byte
+	if _, err := io.ReadFull(r, p[:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Error("Close:", err)
+	}
+	if n, err := r.Read(p[:]); n != 0 || err == nil || err == io.EOF {
+		t.Errorf("Read after Close = %d, %v; want 0, <non-EOF error>", n, err)
+	}
+}
+
+// A database of 64 KiB is typical, and small enough that allocation is a
+// noticeable part of opening and saving it.
+const benchmarkDatabaseSize = 64 << 10
+
+func BenchmarkReaderAllocs(b *testing.B) {
+	block, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		b.Fatal(err)
+	}
+	iv := bytes.Repeat([]byte{7}, aes.BlockSize)
+	crypt := new(bytes.Buffer)
+	w := NewWriter(crypt, cipher.NewCBCEncrypter(block, iv), padding.PKCS7)
+	if _, err := w.Write(make([]byte, benchmarkDatabaseSize)); err != nil {
+		b.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := NewReaderSize(bytes.NewReader(crypt.Bytes()), cipher.NewCBCDecrypter(block, iv), padding.PKCS7, 1<<20)
+		if _, err := io.Copy(ioutil.Discard, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriterAllocs(b *testing.B) {
+	block, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		b.Fatal(err)
+	}
+	iv := bytes.Repeat([]byte{7}, aes.BlockSize)
+	plain := make([]byte, benchmarkDatabaseSize)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := NewWriter(ioutil.Discard, cipher.NewCBCEncrypter(block, iv), padding.PKCS7)
+		if _, err := w.Write(plain); err != nil {
+			b.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drbg provides CTR_DRBG from NIST SP 800-90A over the Kuznyechik
+// block cipher (GOST R 34.12-2015), and the continuous health tests of
+// NIST SP 800-90B for the entropy source that seeds it.
+package drbg // import "github.com/pedroalbanese/gostpass/pkg/drbg"
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/pedroalbanese/gogost/gost3412128"
+)
+
+const (
+	keySize   = 32
+	blockSize = gost3412128.BlockSize
+
+	// SeedSize is the number of bytes of entropy input read on
+	// instantiation and on every reseed.
+	SeedSize = keySize + blockSize
+
+	// ReseedInterval is the number of Read calls after which the DRBG
+	// reseeds from its entropy source.
+	ReseedInterval = 1 << 16
+
+	// maxRequest is the most bytes generated between state updates.
+	maxRequest = 1 << 16
+)
+
+// ErrPersonalization is returned by New for personalization strings
+// longer than SeedSize.
+var ErrPersonalization = errors.New("drbg: personalization string too long")
+
+// ErrStuck is returned by Read when two consecutive output blocks are
+// equal, which only happens if the DRBG's state is broken.
+var ErrStuck = errors.New("drbg: output repeated")
+
+// A DRBG is a CTR_DRBG without a derivation function, so its entropy
+// source must provide full entropy.  It is safe for use from multiple
+// goroutines.  Once Read fails, the DRBG stays failed.
+type DRBG struct {
+	mu      sync.Mutex
+	entropy io.Reader
+	block   *gost3412128.Cipher
+	key     [keySize]byte
+	v       [blockSize]byte
+	prev    [blockSize]byte // last output block, for the continuous test
+	primed  bool            // whether prev is set
+	reseeds uint64
+	err     error
+}
+
+// New returns a DRBG instantiated from SeedSize bytes of entropy, which
+// it also reseeds from.  personalization, which may be nil, distinguishes
+// instances seeded from the same source.
+func New(entropy io.Reader, personalization []byte) (*DRBG, error) {
+	if len(personalization) > SeedSize {
+		return nil, ErrPersonalization
+	}
+	d := &DRBG{entropy: entropy}
+	var seed [SeedSize]byte
+	if _, err := io.ReadFull(entropy, seed[:]); err != nil {
+		return nil, err
+	}
+	for i, b := range personalization {
+		seed[i] ^= b
+	}
+	d.block = gost3412128.NewCipher(d.key[:])
+	d.update(&seed)
+	d.reseeds = 1
+	return d, nil
+}
+
+// Reseed mixes fresh entropy into the state.
+func (d *DRBG) Reseed() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return d.err
+	}
+	d.err = d.reseed()
+	return d.err
+}
+
+func (d *DRBG) reseed() error {
+	var seed [SeedSize]byte
+	if _, err := io.ReadFull(d.entropy, seed[:]); err != nil {
+		return err
+	}
+	d.update(&seed)
+	d.reseeds = 1
+	return nil
+}
+
+// Read fills p with pseudorandom bytes.
+func (d *DRBG) Read(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for d.err == nil && n < len(p) {
+		chunk := p[n:]
+		if len(chunk) > maxRequest {
+			chunk = chunk[:maxRequest]
+		}
+		if d.err = d.generate(chunk); d.err == nil {
+			n += len(chunk)
+		}
+	}
+	return n, d.err
+}
+
+// generate is the CTR_DRBG generate function for len(p) <= maxRequest.
+func (d *DRBG) generate(p []byte) error {
+	if d.reseeds > ReseedInterval {
+		if err := d.reseed(); err != nil {
+			return err
+		}
+	}
+	var block [blockSize]byte
+	for len(p) > 0 {
+		d.next(block[:])
+		if d.primed && block == d.prev {
+			return ErrStuck
+		}
+		d.prev, d.primed = block, true
+		p = p[copy(p, block[:]):]
+	}
+	var zero [SeedSize]byte
+	d.update(&zero)
+	d.reseeds++
+	return nil
+}
+
+// next increments V and encrypts it into dst.
+func (d *DRBG) next(dst []byte) {
+	for i := len(d.v) - 1; i >= 0; i-- {
+		d.v[i]++
+		if d.v[i] != 0 {
+			break
+		}
+	}
+	d.block.Encrypt(dst, d.v[:])
+}
+
+// update is the CTR_DRBG update function.
+func (d *DRBG) update(data *[SeedSize]byte) {
+	var temp [SeedSize]byte
+	for i := 0; i < SeedSize; i += blockSize {
+		d.next(temp[i : i+blockSize])
+	}
+	for i := range temp {
+		temp[i] ^= data[i]
+	}
+	copy(d.key[:], temp[:keySize])
+	copy(d.v[:], temp[keySize:])
+	d.block = gost3412128.NewCipher(d.key[:])
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drbg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+)
+
+func TestDRBG_Deterministic(t *testing.T) {
+	read := func(personalization string) []byte {
+		d, err := New(fakerand.New(), []byte(personalization))
+		if err != nil {
+			t.Fatal("New:", err)
+		}
+		// More than one request's worth, to cross a state update.
+		buf := make([]byte, maxRequest+100)
+		if _, err := io.ReadFull(d, buf); err != nil {
+			t.Fatal("Read:", err)
+		}
+		return buf
+	}
+	a, b := read(""), read("")
+	if !bytes.Equal(a, b) {
+		t.Error("same entropy and personalization gave different output")
+	}
+	if bytes.Equal(a[:SeedSize], a[maxRequest:maxRequest+SeedSize]) {
+		t.Error("output repeats across requests")
+	}
+	if c := read("host-1"); bytes.Equal(a, c) {
+		t.Error("personalization didn't change output")
+	}
+	if _, err := New(fakerand.New(), make([]byte, SeedSize+1)); err != ErrPersonalization {
+		t.Errorf("New with long personalization error = %v; want %v", err, ErrPersonalization)
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestDRBG_Reseed(t *testing.T) {
+	src := &countingReader{r: fakerand.New()}
+	d, err := New(src, nil)
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	var buf [1]byte
+	for i := 0; i < ReseedInterval; i++ {
+		if _, err := d.Read(buf[:]); err != nil {
+			t.Fatal("Read:", err)
+		}
+	}
+	if src.n != SeedSize {
+		t.Fatalf("read %d bytes of entropy before the reseed interval; want %d", src.n, SeedSize)
+	}
+	if _, err := d.Read(buf[:]); err != nil {
+		t.Fatal("Read:", err)
+	}
+	if src.n != 2*SeedSize {
+		t.Errorf("read %d bytes of entropy after the reseed interval; want %d", src.n, 2*SeedSize)
+	}
+
+	fail := errors.New("no entropy")
+	d.entropy = errorReader{fail}
+	if err := d.Reseed(); err != fail {
+		t.Errorf("Reseed error = %v; want %v", err, fail)
+	}
+	if _, err := d.Read(buf[:]); err != fail {
+		t.Errorf("Read after failed reseed error = %v; want %v", err, fail)
+	}
+}
+
+type errorReader struct{ err error }
+
+func (r errorReader) Read(p []byte) (int, error) { return 0, r.err }
+
+func TestHealthTested(t *testing.T) {
+	buf := make([]byte, 4096)
+	if _, err := io.ReadFull(HealthTested(fakerand.New()), buf); err != nil {
+		t.Error("random source:", err)
+	}
+	if _, err := HealthTested(bytes.NewReader(make([]byte, 8192))).Read(buf); err != ErrHealth {
+		t.Errorf("stuck source error = %v; want %v", err, ErrHealth)
+	}
+
+	// A source that gets stuck after the start-up tests.
+	random := make([]byte, startupSamples+100)
+	if _, err := io.ReadFull(fakerand.New(), random); err != nil {
+		t.Fatal(err)
+	}
+	r := HealthTested(io.MultiReader(bytes.NewReader(random), bytes.NewReader(make([]byte, 8192))))
+	if _, err := r.Read(buf[:100]); err != nil {
+		t.Fatal("first read:", err)
+	}
+	if _, err := io.ReadFull(r, buf); err != ErrHealth {
+		t.Errorf("read after source got stuck error = %v; want %v", err, ErrHealth)
+	}
+	if _, err := io.ReadFull(HealthTested(fakerand.New()), buf); err != nil {
+		t.Error("fresh reader:", err)
+	}
+	if _, err := r.Read(buf); err != ErrHealth {
+		t.Errorf("read after failure error = %v; want %v", err, ErrHealth)
+	}
+}
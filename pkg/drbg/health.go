@@ -0,0 +1,113 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drbg
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrHealth is returned by a health-tested reader whose source failed a
+// health test.
+var ErrHealth = errors.New("drbg: entropy source failed health test")
+
+// Parameters of the SP 800-90B section 4.4 tests for byte samples.  The
+// source is conservatively assessed at 1 bit of min-entropy per byte, and
+// the cutoffs give a false positive probability of 2^-20.
+const (
+	repetitionCutoff = 21 // 1 + ceil(20 / 1)
+	proportionWindow = 512
+	proportionCutoff = 410
+	startupSamples   = 1024
+)
+
+// HealthTested returns a reader that passes r's bytes through, checking
+// them with the repetition count and adaptive proportion tests.  The first
+// read also runs the start-up tests on 1024 bytes, which are discarded.
+// Once a test fails, every read returns ErrHealth.  The reader is safe for
+// use from multiple goroutines.
+func HealthTested(r io.Reader) io.Reader {
+	return &healthReader{r: r}
+}
+
+type healthReader struct {
+	mu      sync.Mutex
+	r       io.Reader
+	started bool
+	err     error
+
+	// Repetition count test state.
+	last byte
+	run  int
+
+	// Adaptive proportion test state.
+	first byte
+	count int
+	pos   int
+}
+
+func (h *healthReader) Read(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return 0, h.err
+	}
+	if !h.started {
+		buf := make([]byte, startupSamples)
+		if _, err := io.ReadFull(h.r, buf); err != nil {
+			return 0, err
+		}
+		if !h.test(buf) {
+			h.err = ErrHealth
+			return 0, h.err
+		}
+		h.started = true
+	}
+	n, err := h.r.Read(p)
+	if !h.test(p[:n]) {
+		for i := range p[:n] {
+			p[i] = 0
+		}
+		h.err = ErrHealth
+		return 0, h.err
+	}
+	return n, err
+}
+
+// test runs the continuous tests on samples and reports whether they pass.
+func (h *healthReader) test(samples []byte) bool {
+	for _, b := range samples {
+		if h.run > 0 && b == h.last {
+			h.run++
+			if h.run >= repetitionCutoff {
+				return false
+			}
+		} else {
+			h.last, h.run = b, 1
+		}
+
+		if h.pos == 0 {
+			h.first, h.count = b, 1
+		} else if b == h.first {
+			h.count++
+			if h.count >= proportionCutoff {
+				return false
+			}
+		}
+		h.pos = (h.pos + 1) % proportionWindow
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package escrow wraps database keys to a GOST R 34.10-2012 public key so
+// that a designated recovery key holder can unlock the database.
+//
+// The key encryption key is derived with VKO GOST R 34.10-2012 (256-bit)
+// between an ephemeral key and the recipient's key, and the database key is
+// sealed with Kuznyechik in MGM mode.
+package escrow // import "github.com/pedroalbanese/gostpass/pkg/escrow"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pedroalbanese/gogost/gost3410"
+	"github.com/pedroalbanese/gogost/gost3412128"
+	"github.com/pedroalbanese/gogost/mgm"
+)
+
+// Errors
+var (
+	ErrUnwrap = errors.New("escrow: unable to unwrap key")
+)
+
+const ukmSize = 8
+
+func curve() *gost3410.Curve {
+	return gost3410.CurveIdtc26gost341012256paramSetA()
+}
+
+// A Record holds a key wrapped to a recipient's public key.
+type Record struct {
+	Recipient []byte `json:"recipient"` // recipient's raw public key
+	Ephemeral []byte `json:"ephemeral"` // sender's raw ephemeral public key
+	UKM       []byte `json:"ukm"`
+	Nonce     []byte `json:"nonce"`
+	Wrapped   []byte `json:"wrapped"`
+}
+
+// GenerateKey creates a new recipient key pair and returns the raw
+// private and public keys.
+func GenerateKey(rand io.Reader) (prv, pub []byte, err error) {
+	k, err := newPrivateKey(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := k.PublicKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("escrow: generate key: %v", err)
+	}
+	return k.Raw(), p.Raw(), nil
+}
+
+// Wrap encrypts key to the raw public key pub.
+func Wrap(rand io.Reader, pub []byte, key []byte) (*Record, error) {
+	recipient, err := gost3410.NewPublicKey(curve(), pub)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: recipient key: %v", err)
+	}
+	eph, err := newPrivateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	ephPub, err := eph.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("escrow: wrap: %v", err)
+	}
+	rec := &Record{
+		Recipient: append([]byte(nil), pub...),
+		Ephemeral: ephPub.Raw(),
+		UKM:       make([]byte, ukmSize),
+		Nonce:     make([]byte, gost3412128.BlockSize),
+	}
+	if _, err := io.ReadFull(rand, rec.UKM); err != nil {
+		return nil, fmt.Errorf("escrow: wrap: %v", err)
+	}
+	if _, err := io.ReadFull(rand, rec.Nonce); err != nil {
+		return nil, fmt.Errorf("escrow: wrap: %v", err)
+	}
+	// MGM requires the nonce's most significant bit to be clear.
+	rec.Nonce[0] &= 0x7f
+	kek, err := eph.KEK2012256(recipient, gost3410.NewUKM(rec.UKM))
+	if err != nil {
+		return nil, fmt.Errorf("escrow: wrap: %v", err)
+	}
+	aead, err := mgm.NewMGM(gost3412128.NewCipher(kek), gost3412128.BlockSize)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: wrap: %v", err)
+	}
+	rec.Wrapped = aead.Seal(nil, rec.Nonce, key, rec.Recipient)
+	return rec, nil
+}
+
+// Unwrap decrypts the record's key with the raw private key prv.
+func (rec *Record) Unwrap(prv []byte) ([]byte, error) {
+	k, err := gost3410.NewPrivateKey(curve(), prv)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: private key: %v", err)
+	}
+	eph, err := gost3410.NewPublicKey(curve(), rec.Ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: ephemeral key: %v", err)
+	}
+	kek, err := k.KEK2012256(eph, gost3410.NewUKM(rec.UKM))
+	if err != nil {
+		return nil, fmt.Errorf("escrow: unwrap: %v", err)
+	}
+	aead, err := mgm.NewMGM(gost3412128.NewCipher(kek), gost3412128.BlockSize)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: unwrap: %v", err)
+	}
+	if len(rec.Nonce) != aead.NonceSize() {
+		return nil, ErrUnwrap
+	}
+	key, err := aead.Open(nil, rec.Nonce, rec.Wrapped, rec.Recipient)
+	if err != nil {
+		return nil, ErrUnwrap
+	}
+	return key, nil
+}
+
+func newPrivateKey(rand io.Reader) (*gost3410.PrivateKey, error) {
+	raw := make([]byte, curve().PointSize())
+	if _, err := io.ReadFull(rand, raw); err != nil {
+		return nil, fmt.Errorf("escrow: generate key: %v", err)
+	}
+	k, err := gost3410.NewPrivateKey(curve(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: generate key: %v", err)
+	}
+	return k, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package escrow
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestWrapUnwrap(t *testing.T) {
+	prv, pub, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal("GenerateKey:", err)
+	}
+	key := []byte("0123456789abcdef0123456789abcdef")
+	rec, err := Wrap(rand.Reader, pub, key)
+	if err != nil {
+		t.Fatal("Wrap:", err)
+	}
+	if bytes.Contains(rec.Wrapped, key) {
+		t.Error("wrapped key contains plaintext key")
+	}
+	got, err := rec.Unwrap(prv)
+	if err != nil {
+		t.Fatal("Unwrap:", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Errorf("Unwrap = %x; want %x", got, key)
+	}
+
+	otherPrv, _, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal("GenerateKey:", err)
+	}
+	if _, err := rec.Unwrap(otherPrv); err != ErrUnwrap {
+		t.Errorf("Unwrap with wrong key error = %v; want %v", err, ErrUnwrap)
+	}
+
+	rec.Wrapped[0] ^= 1
+	if _, err := rec.Unwrap(prv); err != ErrUnwrap {
+		t.Errorf("Unwrap of tampered record error = %v; want %v", err, ErrUnwrap)
+	}
+}
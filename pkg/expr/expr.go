@@ -0,0 +1,869 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package expr evaluates a small expression language modeled on CEL.
+//
+// Values are nil, bool, float64, string, []interface{} and
+// map[string]interface{}, as decoded by encoding/json.  Expressions may use
+// literals of those types (null, true, false, numbers, quoted strings and
+// [lists]), variables, member access (m.key) and indexing (l[0], m["key"]),
+// the operators
+//
+//	!x  -x  x*y  x/y  x%y  x+y  x-y  x<y  x<=y  x>y  x>=y  x==y  x!=y
+//	x in list  key in map  x&&y  x||y  c ? x : y
+//
+// with CEL's precedence, and these functions:
+//
+//	size(x) or x.size()   length of a string, list or map
+//	has(m.key)            whether m has key
+//	s.contains(t)  s.startsWith(t)  s.endsWith(t)  s.matches(regexp)
+//	s.lowerAscii()  s.upperAscii()
+//	l.filter(x, pred)  l.map(x, expr)  l.exists(x, pred)  l.all(x, pred)
+//
+// where the last four evaluate pred or expr with x bound to each element.
+package expr // import "github.com/pedroalbanese/gostpass/pkg/expr"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// An Expr is a parsed expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses src.
+func Parse(src string) (*Expr, error) {
+	p := &parser{lex: lexer{src: src}}
+	p.next()
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return &Expr{src: src, root: n}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression with vars as its variables.
+func (e *Expr) Eval(vars map[string]interface{}) (interface{}, error) {
+	return e.root.eval(&env{vars: vars})
+}
+
+// env binds variables.  Macros add a scope with one variable.
+type env struct {
+	name   string
+	val    interface{}
+	parent *env
+	vars   map[string]interface{}
+}
+
+func (en *env) lookup(name string) (interface{}, bool) {
+	for ; en != nil; en = en.parent {
+		if en.vars != nil {
+			v, ok := en.vars[name]
+			return v, ok
+		}
+		if en.name == name {
+			return en.val, true
+		}
+	}
+	return nil, false
+}
+
+// Lexer
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+// ops lists the operators, longest first.
+var ops = []string{"&&", "||", "==", "!=", "<=", ">=", "!", "<", ">", "+", "-", "*", "/", "%", "?", ":", ".", ",", "(", ")", "[", "]"}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && strings.IndexByte(" \t\r\n", l.src[l.pos]) >= 0 {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+	c := l.src[l.pos]
+	switch {
+	case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		for l.pos < len(l.src) && isIdentByte(l.src[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}, nil
+	case '0' <= c && c <= '9':
+		for l.pos < len(l.src) && ('0' <= l.src[l.pos] && l.src[l.pos] <= '9' || l.src[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: l.src[start:l.pos], pos: start}, nil
+	case c == '"' || c == '\'':
+		l.pos++
+		for l.pos < len(l.src) && l.src[l.pos] != c {
+			if l.src[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return token{}, fmt.Errorf("expr: at offset %d: unterminated string", start)
+		}
+		l.pos++
+		return token{kind: tokString, text: l.src[start:l.pos], pos: start}, nil
+	}
+	for _, op := range ops {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokOp, text: op, pos: start}, nil
+		}
+	}
+	return token{}, fmt.Errorf("expr: at offset %d: unexpected character %q", start, c)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// Parser
+
+type parser struct {
+	lex lexer
+	tok token
+	err error
+}
+
+func (p *parser) next() {
+	if p.err != nil {
+		return
+	}
+	p.tok, p.err = p.lex.next()
+	if p.err != nil {
+		p.tok = token{kind: tokEOF, pos: p.lex.pos}
+	}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	if p.err != nil {
+		return p.err
+	}
+	return fmt.Errorf("expr: at offset %d: %s", p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) isOp(op string) bool {
+	return p.tok.kind == tokOp && p.tok.text == op
+}
+
+func (p *parser) expect(op string) error {
+	if !p.isOp(op) {
+		return p.errorf("expected %q, found %s", op, p.tok)
+	}
+	p.next()
+	return nil
+}
+
+// binaryPrec gives the precedence of binary operators; higher binds
+// tighter.
+var binaryPrec = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3, "in": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5,
+}
+
+func (p *parser) expr() (node, error) {
+	c, err := p.binary(1)
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp("?") {
+		return c, nil
+	}
+	p.next()
+	t, err := p.binary(1)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	f, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	return &condNode{c, t, f}, nil
+}
+
+func (p *parser) binary(minPrec int) (node, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.tok.text
+		prec, ok := binaryPrec[op]
+		if !ok || p.tok.kind != tokOp && !(p.tok.kind == tokIdent && op == "in") || prec < minPrec {
+			return x, p.err
+		}
+		p.next()
+		y, err := p.binary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryNode{op, x, y}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if p.isOp("!") || p.isOp("-") {
+		op := p.tok.text
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op, x}, nil
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (node, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isOp("."):
+			p.next()
+			if p.tok.kind != tokIdent {
+				return nil, p.errorf("expected name after \".\", found %s", p.tok)
+			}
+			name := p.tok.text
+			p.next()
+			if p.isOp("(") {
+				args, err := p.args()
+				if err != nil {
+					return nil, err
+				}
+				x = &callNode{recv: x, name: name, args: args}
+			} else {
+				x = &memberNode{x, name}
+			}
+		case p.isOp("["):
+			p.next()
+			i, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &indexNode{x, i}
+		default:
+			return x, p.err
+		}
+	}
+}
+
+// args parses a parenthesized argument list.
+func (p *parser) args() ([]node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []node
+	for !p.isOp(")") {
+		a, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	return args, p.expect(")")
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.tok
+	switch {
+	case t.kind == tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf("bad number %s", t)
+		}
+		p.next()
+		return &literalNode{f}, nil
+	case t.kind == tokString:
+		s, err := unquote(t.text)
+		if err != nil {
+			return nil, p.errorf("bad string %s", t)
+		}
+		p.next()
+		return &literalNode{s}, nil
+	case t.kind == tokIdent:
+		p.next()
+		switch t.text {
+		case "true":
+			return &literalNode{true}, nil
+		case "false":
+			return &literalNode{false}, nil
+		case "null":
+			return &literalNode{nil}, nil
+		}
+		if p.isOp("(") {
+			args, err := p.args()
+			if err != nil {
+				return nil, err
+			}
+			return &callNode{name: t.text, args: args}, nil
+		}
+		return &identNode{t.text}, nil
+	case p.isOp("("):
+		p.next()
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	case p.isOp("["):
+		p.next()
+		var elems []node
+		for !p.isOp("]") {
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, x)
+			if !p.isOp(",") {
+				break
+			}
+			p.next()
+		}
+		return &listNode{elems}, p.expect("]")
+	}
+	return nil, p.errorf("unexpected %s", t)
+}
+
+// unquote accepts strings in double or single quotes with Go escapes.
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		body := strings.Replace(s[1:len(s)-1], `"`, `\"`, -1)
+		body = strings.Replace(body, `\'`, `'`, -1)
+		s = `"` + body + `"`
+	}
+	return strconv.Unquote(s)
+}
+
+// Evaluation
+
+type node interface {
+	eval(en *env) (interface{}, error)
+}
+
+type literalNode struct{ v interface{} }
+
+func (n *literalNode) eval(*env) (interface{}, error) { return n.v, nil }
+
+type identNode struct{ name string }
+
+func (n *identNode) eval(en *env) (interface{}, error) {
+	v, ok := en.lookup(n.name)
+	if !ok {
+		return nil, fmt.Errorf("expr: undeclared reference to %q", n.name)
+	}
+	return v, nil
+}
+
+type listNode struct{ elems []node }
+
+func (n *listNode) eval(en *env) (interface{}, error) {
+	l := make([]interface{}, len(n.elems))
+	for i, x := range n.elems {
+		v, err := x.eval(en)
+		if err != nil {
+			return nil, err
+		}
+		l[i] = v
+	}
+	return l, nil
+}
+
+type memberNode struct {
+	x    node
+	name string
+}
+
+func (n *memberNode) eval(en *env) (interface{}, error) {
+	x, err := n.x.eval(en)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := x.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expr: .%s of %s", n.name, typeName(x))
+	}
+	v, ok := m[n.name]
+	if !ok {
+		return nil, fmt.Errorf("expr: no such key: %s", n.name)
+	}
+	return v, nil
+}
+
+type indexNode struct{ x, i node }
+
+func (n *indexNode) eval(en *env) (interface{}, error) {
+	x, err := n.x.eval(en)
+	if err != nil {
+		return nil, err
+	}
+	i, err := n.i.eval(en)
+	if err != nil {
+		return nil, err
+	}
+	switch x := x.(type) {
+	case []interface{}:
+		f, ok := i.(float64)
+		if !ok || f != math.Trunc(f) {
+			return nil, fmt.Errorf("expr: list index must be an integer, not %s", typeName(i))
+		}
+		if f < 0 || f >= float64(len(x)) {
+			return nil, fmt.Errorf("expr: index %v out of range [0, %d)", f, len(x))
+		}
+		return x[int(f)], nil
+	case map[string]interface{}:
+		k, ok := i.(string)
+		if !ok {
+			return nil, fmt.Errorf("expr: map key must be a string, not %s", typeName(i))
+		}
+		v, ok := x[k]
+		if !ok {
+			return nil, fmt.Errorf("expr: no such key: %s", k)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("expr: cannot index %s", typeName(x))
+}
+
+type unaryNode struct {
+	op string
+	x  node
+}
+
+func (n *unaryNode) eval(en *env) (interface{}, error) {
+	x, err := n.x.eval(en)
+	if err != nil {
+		return nil, err
+	}
+	switch x := x.(type) {
+	case bool:
+		if n.op == "!" {
+			return !x, nil
+		}
+	case float64:
+		if n.op == "-" {
+			return -x, nil
+		}
+	}
+	return nil, fmt.Errorf("expr: %s%s", n.op, typeName(x))
+}
+
+type condNode struct{ c, t, f node }
+
+func (n *condNode) eval(en *env) (interface{}, error) {
+	c, err := evalBool(n.c, en)
+	if err != nil {
+		return nil, err
+	}
+	if c {
+		return n.t.eval(en)
+	}
+	return n.f.eval(en)
+}
+
+func evalBool(n node, en *env) (bool, error) {
+	v, err := n.eval(en)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expr: want bool, found %s", typeName(v))
+	}
+	return b, nil
+}
+
+type binaryNode struct {
+	op   string
+	x, y node
+}
+
+func (n *binaryNode) eval(en *env) (interface{}, error) {
+	if n.op == "&&" || n.op == "||" {
+		x, err := evalBool(n.x, en)
+		if err != nil {
+			return nil, err
+		}
+		if x == (n.op == "||") {
+			return x, nil
+		}
+		return evalBool(n.y, en)
+	}
+	x, err := n.x.eval(en)
+	if err != nil {
+		return nil, err
+	}
+	y, err := n.y.eval(en)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return reflect.DeepEqual(x, y), nil
+	case "!=":
+		return !reflect.DeepEqual(x, y), nil
+	case "in":
+		switch y := y.(type) {
+		case []interface{}:
+			for _, v := range y {
+				if reflect.DeepEqual(x, v) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]interface{}:
+			k, ok := x.(string)
+			if !ok {
+				return nil, fmt.Errorf("expr: %s in map", typeName(x))
+			}
+			_, ok = y[k]
+			return ok, nil
+		}
+	}
+	switch x := x.(type) {
+	case float64:
+		if y, ok := y.(float64); ok {
+			return numberOp(n.op, x, y)
+		}
+	case string:
+		if y, ok := y.(string); ok {
+			switch n.op {
+			case "+":
+				return x + y, nil
+			case "<":
+				return x < y, nil
+			case "<=":
+				return x <= y, nil
+			case ">":
+				return x > y, nil
+			case ">=":
+				return x >= y, nil
+			}
+		}
+	case []interface{}:
+		if y, ok := y.([]interface{}); ok && n.op == "+" {
+			return append(append([]interface{}(nil), x...), y...), nil
+		}
+	}
+	return nil, fmt.Errorf("expr: %s %s %s", typeName(x), n.op, typeName(y))
+}
+
+func numberOp(op string, x, y float64) (interface{}, error) {
+	switch op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/", "%":
+		if y == 0 {
+			return nil, errors.New("expr: division by zero")
+		}
+		if op == "/" {
+			return x / y, nil
+		}
+		return math.Mod(x, y), nil
+	case "<":
+		return x < y, nil
+	case "<=":
+		return x <= y, nil
+	case ">":
+		return x > y, nil
+	case ">=":
+		return x >= y, nil
+	}
+	return nil, fmt.Errorf("expr: number %s number", op)
+}
+
+type callNode struct {
+	recv node // nil for global functions
+	name string
+	args []node
+}
+
+func (n *callNode) eval(en *env) (interface{}, error) {
+	if n.recv == nil && n.name == "has" {
+		return n.has(en)
+	}
+	var args []interface{}
+	if n.recv != nil {
+		recv, err := n.recv.eval(en)
+		if err != nil {
+			return nil, err
+		}
+		if l, ok := recv.([]interface{}); ok {
+			if _, isMacro := macros[n.name]; isMacro {
+				return n.macro(en, l)
+			}
+		}
+		args = append(args, recv)
+	}
+	for _, a := range n.args {
+		v, err := a.eval(en)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	f := functions[n.name]
+	if f == nil {
+		return nil, fmt.Errorf("expr: unknown function %q", n.name)
+	}
+	v, err := f(args)
+	if err != nil {
+		return nil, fmt.Errorf("expr: %s: %v", n.name, err)
+	}
+	return v, nil
+}
+
+// has implements has(m.key), which doesn't evaluate m.key.
+func (n *callNode) has(en *env) (interface{}, error) {
+	var m *memberNode
+	if len(n.args) == 1 {
+		m, _ = n.args[0].(*memberNode)
+	}
+	if m == nil {
+		return nil, errors.New("expr: has: argument must be a member access, like has(m.key)")
+	}
+	x, err := m.x.eval(en)
+	if err != nil {
+		return nil, err
+	}
+	mm, ok := x.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expr: has: %s is not a map", typeName(x))
+	}
+	_, ok = mm[m.name]
+	return ok, nil
+}
+
+// A macro combines a list's elements, given the results of evaluating the
+// macro's expression on them in turn.  It returns done to stop early.
+type macro func(acc, elem, result interface{}) (newAcc interface{}, done bool, err error)
+
+var macros = map[string]struct {
+	init func() interface{}
+	step macro
+}{
+	"filter": {
+		func() interface{} { return []interface{}{} },
+		func(acc, elem, result interface{}) (interface{}, bool, error) {
+			b, ok := result.(bool)
+			if !ok {
+				return nil, false, fmt.Errorf("predicate is %s, not bool", typeName(result))
+			}
+			if b {
+				acc = append(acc.([]interface{}), elem)
+			}
+			return acc, false, nil
+		},
+	},
+	"map": {
+		func() interface{} { return []interface{}{} },
+		func(acc, elem, result interface{}) (interface{}, bool, error) {
+			return append(acc.([]interface{}), result), false, nil
+		},
+	},
+	"exists": {
+		func() interface{} { return false },
+		func(acc, elem, result interface{}) (interface{}, bool, error) {
+			b, ok := result.(bool)
+			if !ok {
+				return nil, false, fmt.Errorf("predicate is %s, not bool", typeName(result))
+			}
+			return b, b, nil
+		},
+	},
+	"all": {
+		func() interface{} { return true },
+		func(acc, elem, result interface{}) (interface{}, bool, error) {
+			b, ok := result.(bool)
+			if !ok {
+				return nil, false, fmt.Errorf("predicate is %s, not bool", typeName(result))
+			}
+			return b, !b, nil
+		},
+	},
+}
+
+func (n *callNode) macro(en *env, l []interface{}) (interface{}, error) {
+	var v *identNode
+	if len(n.args) == 2 {
+		v, _ = n.args[0].(*identNode)
+	}
+	if v == nil {
+		return nil, fmt.Errorf("expr: %s: want (variable, expression)", n.name)
+	}
+	m := macros[n.name]
+	acc := m.init()
+	for _, elem := range l {
+		result, err := n.args[1].eval(&env{name: v.name, val: elem, parent: en})
+		if err != nil {
+			return nil, err
+		}
+		var done bool
+		if acc, done, err = m.step(acc, elem, result); err != nil {
+			return nil, fmt.Errorf("expr: %s: %v", n.name, err)
+		}
+		if done {
+			break
+		}
+	}
+	return acc, nil
+}
+
+// functions take the receiver, if any, as their first argument.
+var functions = map[string]func(args []interface{}) (interface{}, error){
+	"size": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, errors.New("want one argument")
+		}
+		switch x := args[0].(type) {
+		case string:
+			return float64(utf8.RuneCountInString(x)), nil
+		case []interface{}:
+			return float64(len(x)), nil
+		case map[string]interface{}:
+			return float64(len(x)), nil
+		}
+		return nil, fmt.Errorf("no size for %s", typeName(args[0]))
+	},
+	"contains":   stringPredicate(strings.Contains),
+	"startsWith": stringPredicate(strings.HasPrefix),
+	"endsWith":   stringPredicate(strings.HasSuffix),
+	"matches": stringFunc(2, func(s []string) (interface{}, error) {
+		return regexp.MatchString(s[1], s[0])
+	}),
+	"lowerAscii": stringFunc(1, func(s []string) (interface{}, error) {
+		return strings.Map(func(r rune) rune {
+			if 'A' <= r && r <= 'Z' {
+				r += 'a' - 'A'
+			}
+			return r
+		}, s[0]), nil
+	}),
+	"upperAscii": stringFunc(1, func(s []string) (interface{}, error) {
+		return strings.Map(func(r rune) rune {
+			if 'a' <= r && r <= 'z' {
+				r -= 'a' - 'A'
+			}
+			return r
+		}, s[0]), nil
+	}),
+}
+
+func stringPredicate(f func(s, t string) bool) func([]interface{}) (interface{}, error) {
+	return stringFunc(2, func(s []string) (interface{}, error) {
+		return f(s[0], s[1]), nil
+	})
+}
+
+// stringFunc checks that there are n string arguments.
+func stringFunc(n int, f func([]string) (interface{}, error)) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if len(args) != n {
+			return nil, fmt.Errorf("want %d string arguments, including the receiver", n)
+		}
+		s := make([]string, n)
+		for i, a := range args {
+			var ok bool
+			if s[i], ok = a.(string); !ok {
+				return nil, fmt.Errorf("want string, found %s", typeName(a))
+			}
+		}
+		return f(s)
+	}
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]interface{}{
+		"entries": []interface{}{
+			map[string]interface{}{"title": "GitHub", "group": "Work", "age": 10.0, "custom": map[string]interface{}{"tag": "prod"}},
+			map[string]interface{}{"title": "Bank", "group": "Personal", "age": 400.0, "custom": map[string]interface{}{}},
+			map[string]interface{}{"title": "Grafana", "group": "Work", "age": 120.0, "custom": map[string]interface{}{"tag": "staging"}},
+		},
+		"n": 3.0,
+	}
+	tests := []struct {
+		src  string
+		want interface{}
+	}{
+		{`1 + 2 * 3`, 7.0},
+		{`(1 + 2) * 3`, 9.0},
+		{`-n + 1`, -2.0},
+		{`7 % 4`, 3.0},
+		{`"a" + 'b'`, "ab"},
+		{`'it\'s "quoted"'`, `it's "quoted"`},
+		{`[1, 2] + [3]`, []interface{}{1.0, 2.0, 3.0}},
+		{`n > 2 && n < 4`, true},
+		{`n == 3 || undefined`, true},
+		{`false && undefined`, false},
+		{`!(n != 3)`, true},
+		{`"b" > "a"`, true},
+		{`n > 2 ? "big" : "small"`, "big"},
+		{`2 in [1, 2]`, true},
+		{`"tag" in entries[0].custom`, true},
+		{`null == null`, true},
+		{`size(entries)`, 3.0},
+		{`entries.size()`, 3.0},
+		{`"héllo".size()`, 5.0},
+		{`entries[1]["title"]`, "Bank"},
+		{`entries.map(e, e.title)`, []interface{}{"GitHub", "Bank", "Grafana"}},
+		{`entries.filter(e, has(e.custom.tag) && e.custom.tag == "prod").map(e, e.title)`, []interface{}{"GitHub"}},
+		{`entries.filter(e, e.group == "Work" && e.age > 90).map(e, e.title)`, []interface{}{"Grafana"}},
+		{`entries.exists(e, e.title.startsWith("Gr"))`, true},
+		{`entries.all(e, e.age > 100)`, false},
+		{`[].all(e, e.missing)`, true},
+		{`entries.map(e, e.title.lowerAscii())[0]`, "github"},
+		{`"GitHub".upperAscii()`, "GITHUB"},
+		{`"GitHub".contains("Hub") && "GitHub".endsWith("Hub")`, true},
+		{`"v1.2.3".matches("^v[0-9.]+$")`, true},
+		{`[1, 2].map(x, [3, 4].map(y, x * y))`, []interface{}{[]interface{}{3.0, 4.0}, []interface{}{6.0, 8.0}}},
+	}
+	for _, test := range tests {
+		e, err := Parse(test.src)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.src, err)
+			continue
+		}
+		got, err := e.Eval(vars)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", test.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Eval(%q) = %#v; want %#v", test.src, got, test.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	parseErrors := []string{
+		``,
+		`1 +`,
+		`(1`,
+		`"unterminated`,
+		`a ? b`,
+		`a.`,
+		`1 2`,
+		`#`,
+		`{}`,
+	}
+	for _, src := range parseErrors {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Parse(%q) succeeded", src)
+		}
+	}
+	evalErrors := []string{
+		`undefined`,
+		`1 + "a"`,
+		`1 / 0`,
+		`!1`,
+		`[1][1]`,
+		`[1][100000000000000000000000]`,
+		`[1][-100000000000000000000000]`,
+		`[1][0.5]`,
+		`m.missing`,
+		`m.k.size()`,
+		`1 ? 2 : 3`,
+		`[1].filter(x, x)`,
+		`[1].filter(1, true)`,
+		`has(m)`,
+		`nosuch(1)`,
+		`"a".matches("(")`,
+		`1 && true`,
+	}
+	vars := map[string]interface{}{"m": map[string]interface{}{"k": 1.0}}
+	for _, src := range evalErrors {
+		e, err := Parse(src)
+		if err != nil {
+			continue
+		}
+		if v, err := e.Eval(vars); err == nil {
+			t.Errorf("Eval(%q) = %#v; want error", src, v)
+		}
+	}
+}
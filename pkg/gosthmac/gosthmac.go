@@ -0,0 +1,60 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gosthmac provides HMAC over the GOST R 34.11-2012 (Streebog)
+// hash functions, as specified in RFC 7836 section 4.1.
+package gosthmac // import "github.com/pedroalbanese/gostpass/pkg/gosthmac"
+
+import (
+	"crypto/hmac"
+	"hash"
+
+	"github.com/pedroalbanese/gogost/gost34112012256"
+	"github.com/pedroalbanese/gogost/gost34112012512"
+)
+
+// Sizes of the MACs in bytes.
+const (
+	Size256 = 32
+	Size512 = 64
+)
+
+// New256 returns a new HMAC_GOSTR3411_2012_256 hash using the given key.
+func New256(key []byte) hash.Hash {
+	return hmac.New(func() hash.Hash { return gost34112012256.New() }, key)
+}
+
+// New512 returns a new HMAC_GOSTR3411_2012_512 hash using the given key.
+func New512(key []byte) hash.Hash {
+	return hmac.New(func() hash.Hash { return gost34112012512.New() }, key)
+}
+
+// Sum256 returns the HMAC_GOSTR3411_2012_256 of data.
+func Sum256(key, data []byte) []byte {
+	h := New256(key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Sum512 returns the HMAC_GOSTR3411_2012_512 of data.
+func Sum512(key, data []byte) []byte {
+	h := New512(key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Equal compares two MACs in constant time.
+func Equal(mac1, mac2 []byte) bool {
+	return hmac.Equal(mac1, mac2)
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosthmac
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Test vectors from RFC 7836 sections A.1.1 and A.1.2.
+var (
+	rfcKey  = mustHex("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	rfcData = mustHex("0126bdb87800af214341456563780100")
+)
+
+func TestSum256(t *testing.T) {
+	want := mustHex("a1aa5f7de402d7b3d323f2991c8d4534013137010a83754fd0af6d7cd4922ed9")
+	if got := Sum256(rfcKey, rfcData); !bytes.Equal(got, want) {
+		t.Errorf("Sum256 = %x; want %x", got, want)
+	}
+}
+
+func TestSum512(t *testing.T) {
+	want := mustHex("a59bab22ecae19c65fbde6e5f4e9f5d8549d31f037f9df9b905500e171923a773d5f1530f2ed7e964cb2eedc29e9ad2f3afe93b2814f79f5000ffc0366c251e6")
+	if got := Sum512(rfcKey, rfcData); !bytes.Equal(got, want) {
+		t.Errorf("Sum512 = %x; want %x", got, want)
+	}
+}
+
+func TestIncremental(t *testing.T) {
+	h := New256(rfcKey)
+	h.Write(rfcData[:5])
+	h.Write(rfcData[5:])
+	if got, want := h.Sum(nil), Sum256(rfcKey, rfcData); !Equal(got, want) {
+		t.Errorf("incremental MAC = %x; want %x", got, want)
+	}
+	if h.Size() != Size256 {
+		t.Errorf("New256().Size() = %d; want %d", h.Size(), Size256)
+	}
+	if n := New512(rfcKey).Size(); n != Size512 {
+		t.Errorf("New512().Size() = %d; want %d", n, Size512)
+	}
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
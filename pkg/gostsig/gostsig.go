@@ -0,0 +1,89 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gostsig signs and verifies byte strings with GOST R 34.10-2012
+// (256-bit, parameter set A) over a Streebog-256 digest.
+package gostsig // import "github.com/pedroalbanese/gostpass/pkg/gostsig"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pedroalbanese/gogost/gost3410"
+	"github.com/pedroalbanese/gogost/gost34112012256"
+)
+
+// Errors
+var (
+	ErrBadSignature = errors.New("gostsig: signature does not match")
+)
+
+func curve() *gost3410.Curve {
+	return gost3410.CurveIdtc26gost341012256paramSetA()
+}
+
+func digest(data []byte) []byte {
+	h := gost34112012256.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// GenerateKey creates a new signing key pair and returns the raw private
+// and public keys.
+func GenerateKey(rand io.Reader) (prv, pub []byte, err error) {
+	raw := make([]byte, curve().PointSize())
+	if _, err := io.ReadFull(rand, raw); err != nil {
+		return nil, nil, fmt.Errorf("gostsig: generate key: %v", err)
+	}
+	k, err := gost3410.NewPrivateKey(curve(), raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gostsig: generate key: %v", err)
+	}
+	p, err := k.PublicKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("gostsig: generate key: %v", err)
+	}
+	return k.Raw(), p.Raw(), nil
+}
+
+// Sign returns a signature of data made with the raw private key prv.
+func Sign(rand io.Reader, prv, data []byte) ([]byte, error) {
+	k, err := gost3410.NewPrivateKey(curve(), prv)
+	if err != nil {
+		return nil, fmt.Errorf("gostsig: private key: %v", err)
+	}
+	sig, err := k.SignDigest(digest(data), rand)
+	if err != nil {
+		return nil, fmt.Errorf("gostsig: sign: %v", err)
+	}
+	return sig, nil
+}
+
+// Verify checks that sig is a signature of data by the raw public key pub.
+// It returns ErrBadSignature if the signature doesn't match.
+func Verify(pub, data, sig []byte) error {
+	k, err := gost3410.NewPublicKey(curve(), pub)
+	if err != nil {
+		return fmt.Errorf("gostsig: public key: %v", err)
+	}
+	ok, err := k.VerifyDigest(digest(data), sig)
+	if err != nil {
+		return fmt.Errorf("gostsig: verify: %v", err)
+	}
+	if !ok {
+		return ErrBadSignature
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gostsig
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	prv, pub, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal("GenerateKey:", err)
+	}
+	data := []byte("database contents")
+	sig, err := Sign(rand.Reader, prv, data)
+	if err != nil {
+		t.Fatal("Sign:", err)
+	}
+	if err := Verify(pub, data, sig); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+
+	if err := Verify(pub, []byte("database Contents"), sig); err != ErrBadSignature {
+		t.Errorf("Verify of modified data error = %v; want %v", err, ErrBadSignature)
+	}
+	_, otherPub, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal("GenerateKey:", err)
+	}
+	if err := Verify(otherPub, data, sig); err != ErrBadSignature {
+		t.Errorf("Verify with wrong key error = %v; want %v", err, ErrBadSignature)
+	}
+	sig[0] ^= 1
+	if err := Verify(pub, data, sig); err != ErrBadSignature {
+		t.Errorf("Verify of tampered signature error = %v; want %v", err, ErrBadSignature)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kdbcrypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// knownAnswers is the format of testdata/kat.json, the published test
+// vectors of the GOST-KeePass1 scheme.  Byte strings are hex-encoded.
+type knownAnswers struct {
+	Comment  string          `json:"comment"`
+	Keys     []keyAnswer     `json:"keys"`
+	Ciphers  []cipherAnswer  `json:"ciphers"`
+	KeyFiles []keyFileAnswer `json:"key_files"`
+}
+
+// A keyAnswer is a key derivation: password, key file hash or composite
+// key, seeds and rounds in; the hash before the transform rounds and the
+// computed key out.
+type keyAnswer struct {
+	Password      string `json:"password,omitempty"`
+	KeyFileHash   string `json:"key_file_hash,omitempty"`
+	Composite     string `json:"composite,omitempty"`
+	MasterSeed    string `json:"master_seed"`
+	TransformSeed string `json:"transform_seed"`
+	Rounds        uint32 `json:"rounds"`
+	BaseHash      string `json:"base_hash"`
+	ComputedKey   string `json:"computed_key"`
+}
+
+// A cipherAnswer is an encryption of plaintext with the computed key and
+// IV, padding included.
+type cipherAnswer struct {
+	ComputedKey string `json:"computed_key"`
+	IV          string `json:"iv"`
+	Plaintext   string `json:"plaintext"`
+	Ciphertext  string `json:"ciphertext"`
+}
+
+// A keyFileAnswer is a key file and the hash it contributes to the key.
+type keyFileAnswer struct {
+	Data string `json:"data"`
+	Hash string `json:"hash"`
+}
+
+const knownAnswersFile = "kat.json"
+
+func readKnownAnswers(t *testing.T) *knownAnswers {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", knownAnswersFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kat := new(knownAnswers)
+	if err := json.Unmarshal(data, kat); err != nil {
+		t.Fatal(err)
+	}
+	return kat
+}
+
+// unhex decodes s, failing the test if it isn't hex.  The empty string
+// decodes to nil.
+func unhex(t *testing.T, s string) []byte {
+	if s == "" {
+		return nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("%s: %v", knownAnswersFile, err)
+	}
+	return b
+}
+
+func (a *keyAnswer) key(t *testing.T) *Key {
+	k := &Key{
+		Password:        []byte(a.Password),
+		KeyFileHash:     unhex(t, a.KeyFileHash),
+		Composite:       unhex(t, a.Composite),
+		TransformRounds: a.Rounds,
+	}
+	copy(k.MasterSeed[:], unhex(t, a.MasterSeed))
+	copy(k.TransformSeed[:], unhex(t, a.TransformSeed))
+	return k
+}
+
+func TestKnownAnswers(t *testing.T) {
+	kat := readKnownAnswers(t)
+	if len(kat.Keys) == 0 || len(kat.Ciphers) == 0 || len(kat.KeyFiles) == 0 {
+		t.Fatalf("%s is missing vectors", knownAnswersFile)
+	}
+	for i, a := range kat.Keys {
+		k := a.key(t)
+		if got := k.baseHash(); hex.EncodeToString(got[:]) != a.BaseHash {
+			t.Errorf("keys[%d]: base hash = %x; want %s", i, got, a.BaseHash)
+		}
+		if got := k.Compute(); hex.EncodeToString(got) != a.ComputedKey {
+			t.Errorf("keys[%d]: computed key = %x; want %s", i, got, a.ComputedKey)
+		}
+	}
+	for i, a := range kat.Ciphers {
+		p, err := NewParams(WithComputedKey(unhex(t, a.ComputedKey)), WithIV(unhex(t, a.IV)))
+		if err != nil {
+			t.Errorf("ciphers[%d]: %v", i, err)
+			continue
+		}
+		buf := new(bytes.Buffer)
+		enc, err := NewEncrypter(buf, p)
+		if err != nil {
+			t.Errorf("ciphers[%d]: NewEncrypter: %v", i, err)
+			continue
+		}
+		enc.Write(unhex(t, a.Plaintext))
+		if err := enc.Close(); err != nil {
+			t.Errorf("ciphers[%d]: encrypt: %v", i, err)
+			continue
+		}
+		if got := hex.EncodeToString(buf.Bytes()); got != a.Ciphertext {
+			t.Errorf("ciphers[%d]: ciphertext = %s; want %s", i, got, a.Ciphertext)
+		}
+		dec, err := NewDecrypter(bytes.NewReader(unhex(t, a.Ciphertext)), p)
+		if err != nil {
+			t.Errorf("ciphers[%d]: NewDecrypter: %v", i, err)
+			continue
+		}
+		if got, err := ioutil.ReadAll(dec); err != nil || hex.EncodeToString(got) != a.Plaintext {
+			t.Errorf("ciphers[%d]: decrypt = %x, %v; want %s, <nil>", i, got, err, a.Plaintext)
+		}
+	}
+	for i, a := range kat.KeyFiles {
+		got, err := ReadKeyFile(bytes.NewReader(unhex(t, a.Data)))
+		if err != nil || hex.EncodeToString(got) != a.Hash {
+			t.Errorf("key_files[%d]: ReadKeyFile = %x, %v; want %s, <nil>", i, got, err, a.Hash)
+		}
+	}
+}
@@ -23,9 +23,11 @@ import (
 	"github.com/pedroalbanese/gogost/gost34112012256"
 	"crypto/cipher"
 	"encoding/hex"
+	"crypto/subtle"
 	"errors"
+	"hash"
 	"io"
-	"io/ioutil"
+	"runtime"
 	"unsafe"
 	"sync"
 
@@ -35,8 +37,17 @@ import (
 
 // Errors
 var (
-	ErrUnknownCipher = errors.New("keepass: unknown cipher")
-	ErrSize          = errors.New("keepass: data size not a multiple of 16")
+	ErrUnknownCipher   = errors.New("keepass: unknown cipher")
+	ErrSize            = errors.New("keepass: data size not a multiple of 16")
+	ErrKeySize         = errors.New("keepass: computed key must be 32 bytes")
+	ErrIVSize          = errors.New("keepass: IV must be 16 bytes")
+	ErrZeroIV          = errors.New("keepass: IV is all zeros")
+	ErrNoKey           = errors.New("keepass: no key or key derivation parameters")
+	ErrRounds          = errors.New("keepass: transform rounds must be positive")
+	ErrHashSize        = errors.New("keepass: content hash must be 32 bytes")
+	ErrIntegrity       = errors.New("keepass: decrypted content hash mismatch")
+	ErrEmptyKeyFile    = errors.New("keepass: key file is empty")
+	ErrKeyFileHashSize = errors.New("keepass: key file hash must be 32 bytes")
 )
 
 // Block size in bytes.
@@ -46,14 +57,146 @@ const BlockSize = 16
 type Params struct {
 	Key         Key
 	ComputedKey ComputedKey // if non-nil, this will be used instead of Key.
+	Cryptor     *Cryptor    // if non-nil, this will be used instead of ComputedKey and Cipher.
 	Cipher      Cipher
 	IV          [16]byte
+
+	// ContentHash is the expected Streebog-256 hash of the plaintext.  If
+	// non-nil, the reader returned by NewDecrypter reports ErrIntegrity
+	// instead of io.EOF when the hash does not match.  NewEncrypter
+	// ignores it.
+	ContentHash []byte
+}
+
+// An Option configures Params built by NewParams.
+type Option func(*Params) error
+
+// NewParams builds encryption parameters from opts, validating each option
+// as it is applied.  An IV and either a computed key or key derivation
+// parameters (WithKDF) are required.
+func NewParams(opts ...Option) (*Params, error) {
+	p := new(Params)
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+	if p.IV == ([16]byte{}) {
+		return nil, ErrZeroIV
+	}
+	if p.ComputedKey == nil && p.Key.TransformRounds == 0 {
+		return nil, ErrNoKey
+	}
+	return p, nil
+}
+
+// WithCipher sets the cipher.
+func WithCipher(c Cipher) Option {
+	return func(p *Params) error {
+		if c != RijndaelCipher && c != TwofishCipher {
+			return ErrUnknownCipher
+		}
+		p.Cipher = c
+		return nil
+	}
+}
+
+// WithIV sets the initialization vector, which must be 16 bytes and not
+// all zeros.
+func WithIV(iv []byte) Option {
+	return func(p *Params) error {
+		if len(iv) != len(p.IV) {
+			return ErrIVSize
+		}
+		copy(p.IV[:], iv)
+		if p.IV == ([16]byte{}) {
+			return ErrZeroIV
+		}
+		return nil
+	}
+}
+
+// WithPassword sets the password and key file hash used to derive the key.
+// Either may be nil.
+func WithPassword(password, keyFileHash []byte) Option {
+	return func(p *Params) error {
+		if keyFileHash != nil && len(keyFileHash) != KeyFileSize {
+			return ErrKeyFileHashSize
+		}
+		p.Key.Password = password
+		p.Key.KeyFileHash = keyFileHash
+		return nil
+	}
+}
+
+// WithCompositeKey sets the 32-byte composite key used to derive the key,
+// instead of a password and key file.
+func WithCompositeKey(composite []byte) Option {
+	return func(p *Params) error {
+		if len(composite) != 32 {
+			return ErrKeySize
+		}
+		p.Key.Composite = composite
+		return nil
+	}
+}
+
+// WithKDF sets the seeds and number of rounds used to derive the key.
+func WithKDF(masterSeed [16]byte, transformSeed [32]byte, rounds uint32) Option {
+	return func(p *Params) error {
+		if rounds == 0 {
+			return ErrRounds
+		}
+		p.Key.MasterSeed = masterSeed
+		p.Key.TransformSeed = transformSeed
+		p.Key.TransformRounds = rounds
+		return nil
+	}
+}
+
+// WithComputedKey sets a previously computed key, skipping key derivation.
+func WithComputedKey(ck ComputedKey) Option {
+	return func(p *Params) error {
+		if len(ck) != 32 {
+			return ErrKeySize
+		}
+		p.ComputedKey = ck
+		return nil
+	}
+}
+
+// WithIntegrity sets the expected Streebog-256 hash of the plaintext, which
+// the decrypter verifies at the end of the stream.
+func WithIntegrity(contentHash []byte) Option {
+	return func(p *Params) error {
+		if len(contentHash) != gost34112012256.Size {
+			return ErrHashSize
+		}
+		p.ContentHash = contentHash
+		return nil
+	}
+}
+
+// computedKey returns the key to pass to the cipher, computing it if
+// necessary.
+func (p *Params) computedKey() (ComputedKey, error) {
+	if p.ComputedKey == nil {
+		if err := p.Key.Validate(); err != nil {
+			return nil, err
+		}
+		return p.Key.Compute(), nil
+	}
+	if len(p.ComputedKey) != 32 {
+		return nil, ErrKeySize
+	}
+	return p.ComputedKey, nil
 }
 
 // A Key is the set of parameters used to build the cipher key.
 type Key struct {
 	Password        []byte // optional
-	KeyFileHash     []byte // must be nil or length 16
+	KeyFileHash     []byte // must be nil or length KeyFileSize
+	Composite       []byte // optional; if non-nil, used instead of Password and KeyFileHash
 	MasterSeed      [16]byte
 	TransformSeed   [32]byte
 	TransformRounds uint32
@@ -92,6 +235,11 @@ func (k *Key) baseHash() [32]byte {
 		hash := res.Sum(nil)
 		return *byte32([]byte(hash))
 	}
+	if k.Composite != nil {
+		var a [32]byte
+		copy(a[:], k.Composite)
+		return a
+	}
 	if len(k.KeyFileHash) == 0 {
 		return Sum256(k.Password)
 	}
@@ -140,55 +288,152 @@ func (c Cipher) cipher(key ComputedKey) (*gost3412128.Cipher) {
 	return gost3412128.NewCipher([]byte(key))
 }
 
+// A Cryptor encrypts and decrypts with a computed key whose cipher key
+// schedule has been expanded once, for callers that encrypt or decrypt
+// with the same key many times, like a database that is saved after every
+// change.  A Cryptor is safe for concurrent use.
+type Cryptor struct {
+	block cipher.Block
+}
+
+// NewCryptor expands the key schedule of ck for c.
+func NewCryptor(c Cipher, ck ComputedKey) (*Cryptor, error) {
+	if c != RijndaelCipher && c != TwofishCipher {
+		return nil, ErrUnknownCipher
+	}
+	if len(ck) != 32 {
+		return nil, ErrKeySize
+	}
+	return &Cryptor{block: c.cipher(ck)}, nil
+}
+
+// cryptor returns params.Cryptor or a new Cryptor for the computed key.
+func (p *Params) cryptor() (*Cryptor, error) {
+	if p.Cryptor != nil {
+		return p.Cryptor, nil
+	}
+	ck, err := p.computedKey()
+	if err != nil {
+		return nil, err
+	}
+	return NewCryptor(p.Cipher, ck)
+}
+
 // NewEncrypter creates a new writer that encrypts to w.  Closing the
 // new writer writes the final, padded block but does not close w.
 func NewEncrypter(w io.Writer, params *Params) (io.WriteCloser, error) {
-	ck := params.ComputedKey
-	if ck == nil {
-		ck = params.Key.Compute()
+	c, err := params.cryptor()
+	if err != nil {
+		return nil, err
 	}
-	ciph := params.Cipher.cipher(ck)
+	return c.NewEncrypter(w, params.IV), nil
+}
 
-	e := cipher.NewCBCEncrypter(ciph, params.IV[:])
-	return cipherio.NewWriter(w, e, padding.PKCS7), nil
+// NewEncrypter is like the package's NewEncrypter, with c's key.
+func (c *Cryptor) NewEncrypter(w io.Writer, iv [16]byte) io.WriteCloser {
+	e := cipher.NewCBCEncrypter(c.block, iv[:])
+	return cipherio.NewWriter(w, e, padding.PKCS7)
 }
 
+// decryptReadSize is how much ciphertext a decrypter decrypts at once, at
+// most.  It is large enough to keep every core busy.
+const decryptReadSize = 1 << 20
+
 // NewDecrypter creates a new reader that decrypts and strips padding from r.
-func NewDecrypter(r io.Reader, params *Params) (io.Reader, error) {
-	ck := params.ComputedKey
-	if ck == nil {
-		ck = params.Key.Compute()
+// Large inputs are decrypted on all available cores.  The reader's buffers
+// are reused once it has been read to the end; a reader that is abandoned
+// earlier should be closed so that they are reused as well.
+func NewDecrypter(r io.Reader, params *Params) (io.ReadCloser, error) {
+	c, err := params.cryptor()
+	if err != nil {
+		return nil, err
+	}
+	return c.NewDecrypter(r, params.IV, params.ContentHash), nil
+}
+
+// NewDecrypter is like the package's NewDecrypter, with c's key.  If
+// contentHash is non-nil, it is checked as Params.ContentHash is.
+func (c *Cryptor) NewDecrypter(r io.Reader, iv [16]byte, contentHash []byte) io.ReadCloser {
+	d := cipherio.NewParallelCBCDecrypter(c.block, iv[:], runtime.GOMAXPROCS(0))
+	pr := cipherio.NewReaderSize(r, d, padding.PKCS7, decryptReadSize)
+	if contentHash == nil {
+		return pr
+	}
+	return &verifyReader{r: pr, h: gost34112012256.New(), want: contentHash}
+}
+
+// verifyReader hashes everything read through it and checks the hash once
+// the underlying reader is exhausted.
+type verifyReader struct {
+	r    io.ReadCloser
+	h    hash.Hash
+	want []byte
+}
+
+func (vr *verifyReader) Read(p []byte) (int, error) {
+	n, err := vr.r.Read(p)
+	vr.h.Write(p[:n])
+	if err == io.EOF && subtle.ConstantTimeCompare(vr.h.Sum(nil), vr.want) != 1 {
+		err = ErrIntegrity
 	}
-	ciph := params.Cipher.cipher(ck)
+	return n, err
+}
 
-	d := cipher.NewCBCDecrypter(ciph, params.IV[:])
-	return cipherio.NewReader(r, d, padding.PKCS7), nil
+func (vr *verifyReader) Close() error {
+	return vr.r.Close()
 }
 
+// Key file formats.  A key file of exactly KeyFileSize bytes is used as
+// is, and one of exactly 2*KeyFileSize hexadecimal digits is decoded.  Any
+// other non-empty file, of any size, is hashed with Streebog-256.
+const KeyFileSize = 32
+
 // ReadKeyFile reads a key file and returns its hash for use in a Key.
+// The file is read in a streaming fashion, so it may be arbitrarily large.
 func ReadKeyFile(r io.Reader) ([]byte, error) {
-	const maxSize = 64
-	data, err := ioutil.ReadAll(&io.LimitedReader{R: r, N: maxSize + 1})
-	if err != nil {
-		return data, err
+	// Read one byte past the longest special format to tell whether the
+	// file has to be hashed.
+	var prefix [2*KeyFileSize + 1]byte
+	n, err := io.ReadFull(r, prefix[:])
+	switch {
+	case err == io.EOF:
+		return nil, ErrEmptyKeyFile
+	case err == io.ErrUnexpectedEOF:
+		// File is shorter than prefix.
+	case err != nil:
+		return nil, err
 	}
-	switch len(data) {
-	case 32:
-		return data, nil
-	case 64:
-		h := make([]byte, hex.DecodedLen(len(data)))
+	data := prefix[:n]
+	switch n {
+	case KeyFileSize:
+		return append([]byte(nil), data...), nil
+	case 2 * KeyFileSize:
+		h := make([]byte, KeyFileSize)
 		if _, err := hex.Decode(h, data); err == nil {
 			return h, nil
 		}
 	}
 	s := gost34112012256.New()
-	s.Write(data[:])
-	if _, err := io.Copy(s, r); err != nil {
-		return nil, err
+	s.Write(data)
+	if n == len(prefix) {
+		if _, err := io.Copy(s, r); err != nil {
+			return nil, err
+		}
 	}
 	return s.Sum(nil), nil
 }
 
+// Validate reports whether the key's hashes have valid lengths.
+func (k *Key) Validate() error {
+	if k.KeyFileHash != nil && len(k.KeyFileHash) != KeyFileSize {
+		return ErrKeyFileHashSize
+	}
+	if k.Composite != nil && len(k.Composite) != 32 {
+		return ErrKeySize
+	}
+	return nil
+}
+
 func byte32(s []byte) (a *[32]byte) {
     if len(a) <= len(s) {
         a = (*[len(a)]byte)(unsafe.Pointer(&s[0]))
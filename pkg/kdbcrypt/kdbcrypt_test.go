@@ -16,12 +16,14 @@ package kdbcrypt
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/iotest"
+
+	"github.com/pedroalbanese/gogost/gost34112012256"
 )
 
 func TestDecrypter(t *testing.T) {
@@ -84,6 +86,191 @@ func TestDecrypter(t *testing.T) {
 	}
 }
 
+func TestNewParams(t *testing.T) {
+	iv := bytes.Repeat([]byte{0x59}, 16)
+	var masterSeed [16]byte
+	var transformSeed [32]byte
+	tests := []struct {
+		name string
+		opts []Option
+		err  error
+	}{
+		{"ComputedKey", []Option{WithIV(iv), WithComputedKey(make(ComputedKey, 32))}, nil},
+		{"KDF", []Option{WithIV(iv), WithPassword([]byte("swordfish"), nil), WithKDF(masterSeed, transformSeed, 1)}, nil},
+		{"NoIV", []Option{WithComputedKey(make(ComputedKey, 32))}, ErrZeroIV},
+		{"ZeroIV", []Option{WithIV(make([]byte, 16)), WithComputedKey(make(ComputedKey, 32))}, ErrZeroIV},
+		{"ShortIV", []Option{WithIV(iv[:8]), WithComputedKey(make(ComputedKey, 32))}, ErrIVSize},
+		{"NoKey", []Option{WithIV(iv)}, ErrNoKey},
+		{"NilComputedKey", []Option{WithIV(iv), WithComputedKey(nil)}, ErrKeySize},
+		{"ShortComputedKey", []Option{WithIV(iv), WithComputedKey(make(ComputedKey, 16))}, ErrKeySize},
+		{"ShortComposite", []Option{WithIV(iv), WithCompositeKey(make([]byte, 16)), WithKDF(masterSeed, transformSeed, 1)}, ErrKeySize},
+		{"ZeroRounds", []Option{WithIV(iv), WithKDF(masterSeed, transformSeed, 0)}, ErrRounds},
+		{"UnknownCipher", []Option{WithIV(iv), WithComputedKey(make(ComputedKey, 32)), WithCipher(42)}, ErrUnknownCipher},
+		{"ShortContentHash", []Option{WithIV(iv), WithComputedKey(make(ComputedKey, 32)), WithIntegrity(make([]byte, 16))}, ErrHashSize},
+	}
+	for _, test := range tests {
+		p, err := NewParams(test.opts...)
+		if err != test.err {
+			t.Errorf("%s: NewParams(...) error = %v; want %v", test.name, err, test.err)
+		}
+		if err == nil && p == nil {
+			t.Errorf("%s: NewParams(...) = nil, <nil>", test.name)
+		}
+	}
+}
+
+func TestDecrypter_Integrity(t *testing.T) {
+	plaintext := []byte("Hello, World!")
+	h := gost34112012256.New()
+	h.Write(plaintext)
+	good := h.Sum(nil)
+	bad := append([]byte(nil), good...)
+	bad[0] ^= 1
+
+	p, err := NewParams(WithIV(bytes.Repeat([]byte{0x59}, 16)), WithComputedKey(bytes.Repeat([]byte{0x42}, 32)))
+	if err != nil {
+		t.Fatal("NewParams:", err)
+	}
+	buf := new(bytes.Buffer)
+	enc, err := NewEncrypter(buf, p)
+	if err != nil {
+		t.Fatal("NewEncrypter:", err)
+	}
+	enc.Write(plaintext)
+	if err := enc.Close(); err != nil {
+		t.Fatal("encrypt:", err)
+	}
+
+	for _, test := range []struct {
+		hash []byte
+		err  error
+	}{
+		{good, nil},
+		{bad, ErrIntegrity},
+	} {
+		p.ContentHash = test.hash
+		dec, err := NewDecrypter(bytes.NewReader(buf.Bytes()), p)
+		if err != nil {
+			t.Fatal("NewDecrypter:", err)
+		}
+		got, err := ioutil.ReadAll(dec)
+		if err != test.err {
+			t.Errorf("read with content hash %x: error = %v; want %v", test.hash, err, test.err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("read with content hash %x = %q; want %q", test.hash, got, plaintext)
+		}
+	}
+}
+
+func TestCryptor(t *testing.T) {
+	plaintext := []byte("Hello, World!")
+	ck := ComputedKey(bytes.Repeat([]byte{0x42}, 32))
+	p, err := NewParams(WithIV(bytes.Repeat([]byte{0x59}, 16)), WithComputedKey(ck))
+	if err != nil {
+		t.Fatal("NewParams:", err)
+	}
+	c, err := NewCryptor(RijndaelCipher, ck)
+	if err != nil {
+		t.Fatal("NewCryptor:", err)
+	}
+
+	// A Cryptor encrypts like the key it was made from.
+	want := new(bytes.Buffer)
+	enc, err := NewEncrypter(want, p)
+	if err != nil {
+		t.Fatal("NewEncrypter:", err)
+	}
+	enc.Write(plaintext)
+	if err := enc.Close(); err != nil {
+		t.Fatal("encrypt:", err)
+	}
+	got := new(bytes.Buffer)
+	enc = c.NewEncrypter(got, p.IV)
+	enc.Write(plaintext)
+	if err := enc.Close(); err != nil {
+		t.Fatal("encrypt with Cryptor:", err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Errorf("Cryptor ciphertext = %x; want %x", got.Bytes(), want.Bytes())
+	}
+
+	// Params.Cryptor takes precedence over the computed key.
+	p.ComputedKey = make(ComputedKey, 32)
+	p.Cryptor = c
+	dec, err := NewDecrypter(bytes.NewReader(want.Bytes()), p)
+	if err != nil {
+		t.Fatal("NewDecrypter:", err)
+	}
+	if plain, err := ioutil.ReadAll(dec); err != nil || !bytes.Equal(plain, plaintext) {
+		t.Errorf("decrypt with Params.Cryptor = %q, %v; want %q, <nil>", plain, err, plaintext)
+	}
+
+	if _, err := NewCryptor(RijndaelCipher, ck[:16]); err != ErrKeySize {
+		t.Errorf("NewCryptor with 16-byte key error = %v; want %v", err, ErrKeySize)
+	}
+	if _, err := NewCryptor(42, ck); err != ErrUnknownCipher {
+		t.Errorf("NewCryptor with cipher 42 error = %v; want %v", err, ErrUnknownCipher)
+	}
+}
+
+func TestReadKeyFile(t *testing.T) {
+	sum := func(data []byte) []byte {
+		h := gost34112012256.New()
+		h.Write(data)
+		return h.Sum(nil)
+	}
+	raw := bytes.Repeat([]byte{0xa5}, 32)
+	hexKey := bytes.Repeat([]byte("a5"), 32)
+	notHex := bytes.Repeat([]byte("zz"), 32)
+	big := bytes.Repeat([]byte("0123456789abcdef"), 100000)
+	tests := []struct {
+		name string
+		data []byte
+		want []byte
+		err  error
+	}{
+		{"Empty", nil, nil, ErrEmptyKeyFile},
+		{"Raw", raw, raw, nil},
+		{"Hex", hexKey, raw, nil},
+		{"NotHex", notHex, sum(notHex), nil},
+		{"Short", []byte("swordfish"), sum([]byte("swordfish")), nil},
+		{"OneOverRaw", append(raw, 0), sum(append(raw, 0)), nil},
+		{"OneOverHex", append(hexKey, 'a'), sum(append(hexKey, 'a')), nil},
+		{"Large", big, sum(big), nil},
+	}
+	for _, test := range tests {
+		// One-byte reads exercise the streaming path.
+		got, err := ReadKeyFile(iotest.OneByteReader(bytes.NewReader(test.data)))
+		if err != test.err {
+			t.Errorf("%s: ReadKeyFile error = %v; want %v", test.name, err, test.err)
+			continue
+		}
+		if !bytes.Equal(got, test.want) {
+			t.Errorf("%s: ReadKeyFile = %x; want %x", test.name, got, test.want)
+		}
+	}
+}
+
+func TestKeyValidate(t *testing.T) {
+	tests := []struct {
+		key Key
+		err error
+	}{
+		{Key{Password: []byte("swordfish")}, nil},
+		{Key{KeyFileHash: make([]byte, 32)}, nil},
+		{Key{KeyFileHash: make([]byte, 16)}, ErrKeyFileHashSize},
+		{Key{KeyFileHash: []byte{}}, ErrKeyFileHashSize},
+		{Key{Composite: make([]byte, 32)}, nil},
+		{Key{Composite: make([]byte, 31)}, ErrKeySize},
+	}
+	for _, test := range tests {
+		if err := test.key.Validate(); err != test.err {
+			t.Errorf("%+v.Validate() = %v; want %v", test.key, err, test.err)
+		}
+	}
+}
+
 func testFile(name string) *bytes.Buffer {
 	p := filepath.Join("testdata", name)
 	f, err := os.Open(p)
@@ -0,0 +1,64 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import "errors"
+
+// ErrUUIDInUse is returned by CloneEntry if the clone is to keep a UUID
+// that the database already has.
+var ErrUUIDInUse = errors.New("keepass: entry UUID is already in use")
+
+// CloneOptions control how CloneEntry copies an entry.
+type CloneOptions struct {
+	// KeepUUID gives the clone src's UUID instead of a new one, unless
+	// src has none.  This
+	// only works across databases, for example to create a variant of a
+	// credential for another environment's database that still refers to
+	// the same item.
+	KeepUUID bool
+
+	// KeepHistory copies src's revisions.  Without it the clone starts
+	// with no history, since the old passwords belong to src.
+	KeepHistory bool
+}
+
+// CloneEntry adds a copy of src to dst, which must belong to db; src may
+// belong to any database.  If dst is nil, the copy goes in src's group.
+// Fields, custom data and the attachment are copied, and protected fields
+// are resealed for the clone.  The caller sets the clone's times and
+// changes its other fields as needed.
+func (db *Database) CloneEntry(src *Entry, dst *Group, opts CloneOptions) (*Entry, error) {
+	if dst == nil {
+		if src.db != db {
+			return nil, ErrDetached
+		}
+		dst = src.Parent()
+	}
+	if dst.db != db {
+		return nil, errors.New("keepass: clone destination is in another database")
+	}
+	keep := opts.KeepUUID && !src.UUID.IsZero()
+	if keep && db.Find(src.UUID) != nil {
+		return nil, ErrUUIDInUse
+	}
+	e, err := dst.copyEntry(src, !keep)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.KeepHistory {
+		e.History = nil
+	}
+	return e, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import "testing"
+
+func TestCloneEntry(t *testing.T) {
+	db, err := New(sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	g := db.Root().NewSubgroup()
+	g.Name = "Prod"
+	src, err := g.NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	src.Title, src.Username, src.Password = "DB", "alice", "hunter2"
+	src.Attachment.Name, src.Attachment.Data = "ca.pem", []byte("cert")
+	src.CustomData.Set("gostpass.url_match", "host")
+	if err := src.SetField(Field{Name: "pin", Value: "8675309", Protected: true}); err != nil {
+		t.Fatal("SetField:", err)
+	}
+	src.AddRevision()
+	src.Password = "hunter3"
+
+	staging := db.Root().NewSubgroup()
+	staging.Name = "Staging"
+	e, err := db.CloneEntry(src, staging, CloneOptions{})
+	if err != nil {
+		t.Fatal("CloneEntry:", err)
+	}
+	if e.UUID == src.UUID || e.Parent() != staging {
+		t.Errorf("clone UUID %v in %s; want new UUID in Staging", e.UUID, e.Parent().Name)
+	}
+	if e.Title != "DB" || e.Username != "alice" || e.Password != "hunter3" || len(e.History) != 0 {
+		t.Errorf("clone = %q %q %q with %d revisions; want DB alice hunter3 without history", e.Title, e.Username, e.Password, len(e.History))
+	}
+	e.Attachment.Data[0] = 'C'
+	e.CustomData.Set("gostpass.url_match", "exact")
+	if string(src.Attachment.Data) != "cert" || src.CustomData["gostpass.url_match"] != "host" {
+		t.Error("changing the clone changed the source")
+	}
+	if f, _, err := e.Field("pin"); err != nil || f.Value != "8675309" {
+		t.Errorf("clone Field(pin) = %q, %v; want 8675309", f.Value, err)
+	}
+
+	same, err := db.CloneEntry(src, nil, CloneOptions{KeepHistory: true})
+	if err != nil {
+		t.Fatal("CloneEntry into same group:", err)
+	}
+	if same.Parent() != g || len(same.History) != 1 {
+		t.Errorf("clone in %s with %d revisions; want Prod with 1", same.Parent().Name, len(same.History))
+	}
+	if _, err := db.CloneEntry(src, nil, CloneOptions{KeepUUID: true}); err != ErrUUIDInUse {
+		t.Errorf("CloneEntry keeping UUID in the same database = %v; want %v", err, ErrUUIDInUse)
+	}
+
+	other, err := New(sanitizeOptions(&Options{Password: "hunter2", KeyRounds: 1}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	og := other.Root().NewSubgroup()
+	kept, err := other.CloneEntry(src, og, CloneOptions{KeepUUID: true})
+	if err != nil {
+		t.Fatal("CloneEntry into another database:", err)
+	}
+	if kept.UUID != src.UUID {
+		t.Errorf("clone UUID = %v; want %v", kept.UUID, src.UUID)
+	}
+	if f, _, err := kept.Field("pin"); err != nil || f.Value != "8675309" {
+		t.Errorf("clone in other database Field(pin) = %q, %v; want 8675309", f.Value, err)
+	}
+	if _, err := db.CloneEntry(src, og, CloneOptions{}); err == nil {
+		t.Error("CloneEntry into a group of another database succeeded")
+	}
+}
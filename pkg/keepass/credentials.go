@@ -0,0 +1,89 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"io"
+
+	"github.com/pedroalbanese/gogost/gost34112012256"
+	"github.com/pedroalbanese/gostpass/pkg/kdbcrypt"
+)
+
+// A KeySource is one factor of a database's credentials.
+type KeySource interface {
+	// KeyHash returns the factor's 32-byte contribution to the
+	// composite key.
+	KeyHash() ([]byte, error)
+}
+
+// PasswordSource is a textual password.
+type PasswordSource string
+
+// KeyHash returns the Streebog-256 hash of the password.
+func (p PasswordSource) KeyHash() ([]byte, error) {
+	h := gost34112012256.New()
+	h.Write([]byte(p))
+	return h.Sum(nil), nil
+}
+
+// KeyFileSource is a key file, read once when the key is composed.
+type KeyFileSource struct {
+	R io.Reader
+}
+
+// KeyHash reads the key file as described in kdbcrypt.ReadKeyFile.
+func (kf KeyFileSource) KeyHash() ([]byte, error) {
+	return kdbcrypt.ReadKeyFile(kf.R)
+}
+
+// Credentials is an ordered list of key sources that together unlock a
+// database.  New factor types (hardware tokens, key providers) only need
+// to implement KeySource.
+type Credentials struct {
+	Sources []KeySource
+}
+
+// NewCredentials returns the credentials for a password and an optional
+// key file, as used by KeePass 1.  An empty password is omitted if a key
+// file is given.
+func NewCredentials(password string, keyFile io.Reader) *Credentials {
+	c := new(Credentials)
+	if password != "" || keyFile == nil {
+		c.Sources = append(c.Sources, PasswordSource(password))
+	}
+	if keyFile != nil {
+		c.Sources = append(c.Sources, KeyFileSource{keyFile})
+	}
+	return c
+}
+
+// ComposeKey combines the sources into the 32-byte composite key that is
+// then transformed into the encryption key.  A single source contributes
+// its hash directly; multiple sources are hashed together in order.  For a
+// password and a key file this is the KeePass 1 composite key.
+func (c *Credentials) ComposeKey() ([]byte, error) {
+	if len(c.Sources) == 1 {
+		return c.Sources[0].KeyHash()
+	}
+	h := gost34112012256.New()
+	for _, src := range c.Sources {
+		kh, err := src.KeyHash()
+		if err != nil {
+			return nil, err
+		}
+		h.Write(kh)
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"bytes"
+	"testing"
+)
+
+// fixedSource is a KeySource with a constant hash, standing in for a
+// hardware token.
+type fixedSource []byte
+
+func (s fixedSource) KeyHash() ([]byte, error) {
+	return []byte(s), nil
+}
+
+func TestCredentials(t *testing.T) {
+	keyFile := bytes.Repeat([]byte{0x42}, 32)
+	token := fixedSource(bytes.Repeat([]byte{0x17}, 32))
+	tests := []struct {
+		name  string
+		opts  *Options
+		creds *Credentials
+	}{
+		{
+			name:  "Password",
+			opts:  &Options{Password: "swordfish"},
+			creds: NewCredentials("swordfish", nil),
+		},
+		{
+			name:  "KeyFile",
+			opts:  &Options{KeyFile: bytes.NewReader(keyFile)},
+			creds: &Credentials{Sources: []KeySource{KeyFileSource{bytes.NewReader(keyFile)}}},
+		},
+		{
+			name:  "PasswordAndKeyFile",
+			opts:  &Options{Password: "swordfish", KeyFile: bytes.NewReader(keyFile)},
+			creds: NewCredentials("swordfish", bytes.NewReader(keyFile)),
+		},
+		{
+			name: "ThreeFactors",
+			opts: &Options{Credentials: &Credentials{Sources: []KeySource{
+				PasswordSource("swordfish"),
+				KeyFileSource{bytes.NewReader(keyFile)},
+				token,
+			}}},
+			creds: &Credentials{Sources: []KeySource{
+				PasswordSource("swordfish"),
+				KeyFileSource{bytes.NewReader(keyFile)},
+				token,
+			}},
+		},
+	}
+	for _, test := range tests {
+		opts := sanitizeOptions(test.opts)
+		opts.KeyRounds = 1000
+		db, err := New(opts)
+		if err != nil {
+			t.Errorf("%s: New: %v", test.name, err)
+			continue
+		}
+		buf := new(bytes.Buffer)
+		if err := db.Write(buf); err != nil {
+			t.Errorf("%s: Write: %v", test.name, err)
+			continue
+		}
+		if _, err := Open(bytes.NewReader(buf.Bytes()), &Options{Credentials: test.creds}); err != nil {
+			t.Errorf("%s: Open with credentials: %v", test.name, err)
+		}
+		wrong := &Credentials{Sources: []KeySource{PasswordSource("swordfish"), token}}
+		if _, err := Open(bytes.NewReader(buf.Bytes()), &Options{Credentials: wrong}); err != ErrHashMismatch {
+			t.Errorf("%s: Open with wrong credentials error = %v; want %v", test.name, err, ErrHashMismatch)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// customDataStream is the name of the meta-stream that stores custom data.
+// KDB1 has no field for it, so it is kept as JSON in a meta-stream, which
+// other KeePass1 implementations preserve without interpreting.
+const customDataStream = "GOSTPASS_CUSTOM_DATA"
+
+// ErrCustomDataUnwritable is returned by Write if custom data was set in a
+// database whose custom data meta-stream could not be decoded.  The stream
+// is kept as it was read, so the new custom data would be lost.
+var ErrCustomDataUnwritable = errors.New("keepass: custom data stream is undecodable, so custom data changes cannot be saved")
+
+// CustomData holds application-defined key-value pairs, like the KDBX
+// CustomData element.  All keys are written back on save, including ones
+// that this package's callers don't know about.
+type CustomData map[string]string
+
+// Get returns the value for key and whether it is present.
+func (cd CustomData) Get(key string) (string, bool) {
+	v, ok := cd[key]
+	return v, ok
+}
+
+// Int returns the value for key parsed as a decimal integer.
+func (cd CustomData) Int(key string) (int64, bool) {
+	v, ok := cd[key]
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	return i, err == nil
+}
+
+// Bool returns the value for key parsed as a boolean.
+func (cd CustomData) Bool(key string) (bool, bool) {
+	v, ok := cd[key]
+	if !ok {
+		return false, false
+	}
+	b, err := strconv.ParseBool(v)
+	return b, err == nil
+}
+
+// Time returns the value for key parsed as an RFC 3339 timestamp.
+func (cd CustomData) Time(key string) (time.Time, bool) {
+	v, ok := cd[key]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	return t, err == nil
+}
+
+// Set stores value for key, allocating the map if necessary.
+func (cd *CustomData) Set(key, value string) {
+	if *cd == nil {
+		*cd = make(CustomData)
+	}
+	(*cd)[key] = value
+}
+
+// SetInt stores i for key.
+func (cd *CustomData) SetInt(key string, i int64) {
+	cd.Set(key, strconv.FormatInt(i, 10))
+}
+
+// SetBool stores b for key.
+func (cd *CustomData) SetBool(key string, b bool) {
+	cd.Set(key, strconv.FormatBool(b))
+}
+
+// SetTime stores t for key as an RFC 3339 timestamp.
+func (cd *CustomData) SetTime(key string, t time.Time) {
+	cd.Set(key, t.Format(time.RFC3339Nano))
+}
+
+// Delete removes key.
+func (cd CustomData) Delete(key string) {
+	delete(cd, key)
+}
+
+// CustomData returns the database-wide custom data.
+func (db *Database) CustomData() *CustomData {
+	return &db.customData
+}
+
+// customDataJSON is the serialized form of the custom data meta-stream.
+type customDataJSON struct {
+	Database CustomData            `json:"database,omitempty"`
+	Groups   map[uint32]CustomData `json:"groups,omitempty"`
+	Entries  map[string]CustomData `json:"entries,omitempty"`
+}
+
+// readCustomData distributes the custom data meta-stream to the database,
+// its groups and its entries.  If the stream can't be decoded, it is left
+// untouched and will be written back as it was.
+func (db *Database) readCustomData() {
+	data := db.MetaStream(customDataStream)
+	if data == nil {
+		return
+	}
+	var cd customDataJSON
+	if err := json.Unmarshal(data, &cd); err != nil {
+		db.keepCustomData = true
+		return
+	}
+	db.customData = cd.Database
+	for id, gcd := range cd.Groups {
+		if g := db.groups[id]; g != nil {
+			g.CustomData = gcd
+		}
+	}
+	for _, e := range db.entries {
+		e.CustomData = cd.Entries[e.UUID.String()]
+	}
+}
+
+// writeCustomData collects custom data into its meta-stream.
+func (db *Database) writeCustomData() error {
+	if db.keepCustomData {
+		// Nothing was read from the stream, so any custom data now
+		// present was set since.
+		if db.hasCustomData() {
+			return ErrCustomDataUnwritable
+		}
+		return nil
+	}
+	var cd customDataJSON
+	if len(db.customData) > 0 {
+		cd.Database = db.customData
+	}
+	for id, g := range db.groups {
+		if g.db != db || len(g.CustomData) == 0 {
+			continue
+		}
+		if cd.Groups == nil {
+			cd.Groups = make(map[uint32]CustomData)
+		}
+		cd.Groups[id] = g.CustomData
+	}
+	for _, e := range db.entries {
+		if len(e.CustomData) == 0 {
+			continue
+		}
+		if cd.Entries == nil {
+			cd.Entries = make(map[string]CustomData)
+		}
+		cd.Entries[e.UUID.String()] = e.CustomData
+	}
+	if cd.Database == nil && cd.Groups == nil && cd.Entries == nil {
+		return db.SetMetaStream(customDataStream, nil)
+	}
+	data, err := json.Marshal(cd)
+	if err != nil {
+		return err
+	}
+	return db.SetMetaStream(customDataStream, data)
+}
+
+// hasCustomData reports whether the database or any of its groups or
+// entries has custom data.
+func (db *Database) hasCustomData() bool {
+	if len(db.customData) > 0 {
+		return true
+	}
+	for _, g := range db.groups {
+		if g.db == db && len(g.CustomData) > 0 {
+			return true
+		}
+	}
+	for _, e := range db.entries {
+		if len(e.CustomData) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"time"
@@ -39,6 +40,12 @@ type Database struct {
 	entries  []*Entry
 	meta     []*Entry
 	rand     io.Reader
+
+	customData     CustomData
+	keepCustomData bool   // custom data stream is undecodable; write it back as-is
+	keepHistory    bool   // history stream is undecodable; write it back as-is
+	path           string // file for Save
+	saved          *savedFile
 }
 
 // init is called after cparams is filled in to initialize the database.
@@ -47,7 +54,7 @@ func (db *Database) init(g []Group, e []Entry, opts *Options) {
 	if db.cparams.ComputedKey == nil {
 		panic("key should have been precomputed")
 	}
-	db.cparams.Key.Password, db.cparams.Key.KeyFileHash = nil, nil
+	db.cparams.Key.Password, db.cparams.Key.KeyFileHash, db.cparams.Key.Composite = nil, nil, nil
 
 	db.staticIV = opts.staticIV()
 	db.groups = make(map[uint32]*Group, len(g))
@@ -74,6 +81,9 @@ func New(opts *Options) (*Database, error) {
 	if err := opts.initCryptParams(&db.cparams); err != nil {
 		return nil, err
 	}
+	if err := expandKey(&db.cparams); err != nil {
+		return nil, err
+	}
 	db.init(nil, nil, opts)
 	return db, nil
 }
@@ -103,6 +113,117 @@ func (db *Database) ComputedKey() kdbcrypt.ComputedKey {
 	return db.cparams.ComputedKey
 }
 
+// KeyRounds returns the number of key transform rounds the database is
+// encrypted with.
+func (db *Database) KeyRounds() int {
+	return int(db.cparams.Key.TransformRounds)
+}
+
+// SetKey changes the credentials that the database is encrypted with.
+// New master and transform seeds are generated, so the database's
+// computed key will change.  ComputedKey in opts is ignored.
+func (db *Database) SetKey(opts *Options) error {
+	var p kdbcrypt.Params
+	if err := opts.initCryptParams(&p); err != nil {
+		return err
+	}
+	if db.staticIV {
+		p.IV = db.cparams.IV
+	}
+	p.Key.Password, p.Key.KeyFileHash, p.Key.Composite = nil, nil, nil
+	if err := expandKey(&p); err != nil {
+		return err
+	}
+	// Protected fields are sealed with a key derived from the computed
+	// key, so they are resealed before the key is replaced.
+	resealed := make(map[*Entry]CustomData)
+	for _, e := range db.entries {
+		fields, err := e.protectedFields()
+		if err != nil {
+			return err
+		}
+		for _, f := range fields {
+			sealed, err := db.sealField(p.ComputedKey, e, f.Name, f.Value)
+			if err != nil {
+				return err
+			}
+			cd := resealed[e]
+			cd.Set(protectedFieldPrefix+f.Name, sealed)
+			resealed[e] = cd
+		}
+	}
+	db.cparams = p
+	for e, cd := range resealed {
+		for k, v := range cd {
+			e.CustomData.Set(k, v)
+		}
+	}
+	return nil
+}
+
+// expandKey sets p.Cryptor for p's computed key, so that the key schedule
+// is expanded once rather than on every save.
+func expandKey(p *kdbcrypt.Params) error {
+	c, err := kdbcrypt.NewCryptor(p.Cipher, p.ComputedKey)
+	if err != nil {
+		return err
+	}
+	p.Cryptor = c
+	return nil
+}
+
+// MetaStream returns the data of the named meta-stream or nil if the
+// database does not have one.  Meta-streams are special entries that
+// KeePass uses to store application data, like custom icons.
+func (db *Database) MetaStream(name string) []byte {
+	for _, m := range db.meta {
+		if m.Notes == name {
+			return m.Attachment.Data
+		}
+	}
+	return nil
+}
+
+// SetMetaStream stores data in the named meta-stream, creating it if
+// necessary.  Setting nil data removes the meta-stream.
+func (db *Database) SetMetaStream(name string, data []byte) error {
+	if name == "" {
+		return errors.New("keepass: empty meta-stream name")
+	}
+	for i, m := range db.meta {
+		if m.Notes != name {
+			continue
+		}
+		if data == nil {
+			copy(db.meta[i:], db.meta[i+1:])
+			db.meta[len(db.meta)-1] = nil
+			db.meta = db.meta[:len(db.meta)-1]
+			return nil
+		}
+		m.Attachment.Data = data
+		return nil
+	}
+	if data == nil {
+		return nil
+	}
+	id, err := uuids.New4(db.rand)
+	if err != nil {
+		return err
+	}
+	m := &Entry{
+		UUID:     id,
+		Title:    "Meta-Info",
+		Username: "SYSTEM",
+		URL:      "$",
+		Notes:    name,
+		db:       db,
+	}
+	m.Attachment.Name = "bin-stream"
+	m.Attachment.Data = data
+	db.meta = append(db.meta, m)
+	return nil
+}
+
 // Entries returns a list of all entries in the database.
 func (db *Database) Entries() []*Entry {
 	e := make([]*Entry, len(db.entries))
@@ -112,27 +233,67 @@ func (db *Database) Entries() []*Entry {
 
 // Write encodes the database to a writer.
 func (db *Database) Write(w io.Writer) error {
+	_, err := db.write(w)
+	return err
+}
+
+// write encodes the database to w and returns the header it wrote.
+func (db *Database) write(w io.Writer) (header, error) {
+	if err := db.writeMetaStreams(); err != nil {
+		return header{}, err
+	}
+	// Every write gets a fresh IV.  The seeds stay, since changing the
+	// master seed changes the computed key that callers may hold; SetKey
+	// replaces them.
 	if !db.staticIV {
-		_, err := io.ReadFull(db.rand, db.cparams.IV[:])
-		if err != nil {
-			return err
+		var iv [16]byte
+		if _, err := io.ReadFull(db.rand, iv[:]); err != nil {
+			return header{}, err
 		}
+		if iv == db.cparams.IV || iv == ([16]byte{}) {
+			return header{}, ErrIVReuse
+		}
+		db.cparams.IV = iv
 	}
 	buf := new(bytes.Buffer)
 	enc, err := kdbcrypt.NewEncrypter(buf, &db.cparams)
 	if err != nil {
-		return err
+		return header{}, err
 	}
 	ch := gost34112012256.New()
 	ngroups, nentries, err := db.writePlaintext(io.MultiWriter(enc, ch))
 	if err != nil {
-		return err
+		enc.Close()
+		return header{}, err
 	}
 	err = enc.Close()
 	if err != nil {
-		return err
+		return header{}, err
+	}
+
+	h := db.newHeader(ngroups, nentries, ch)
+	if err := h.write(w); err != nil {
+		return header{}, err
+	}
+	_, err = io.Copy(w, buf)
+	return h, err
+}
+
+// plaintextHeader returns the header that Write would write, without
+// encrypting anything.  Its IV is the one used by the last write.
+func (db *Database) plaintextHeader() (header, error) {
+	if err := db.writeMetaStreams(); err != nil {
+		return header{}, err
 	}
+	ch := gost34112012256.New()
+	ngroups, nentries, err := db.writePlaintext(ch)
+	if err != nil {
+		return header{}, err
+	}
+	return db.newHeader(ngroups, nentries, ch), nil
+}
 
+func (db *Database) newHeader(ngroups, nentries int, contentHash hash.Hash) header {
 	h := header{
 		// TODO(light): what does bit 1 do?
 		encryptionFlags: makeEncryptionFlags(&db.cparams) | 1,
@@ -143,12 +304,30 @@ func (db *Database) Write(w io.Writer) error {
 		transformSeed:   db.cparams.Key.TransformSeed,
 		transformRounds: db.cparams.Key.TransformRounds,
 	}
-	ch.Sum(h.contentHash[:0])
+	contentHash.Sum(h.contentHash[:0])
+	return h
+}
 
-	if err := h.write(w); err != nil {
+// writeMetaStreams stores the data that KDB1 has no fields for in
+// meta-streams, ready to be written.
+func (db *Database) writeMetaStreams() error {
+	if err := db.writeCustomData(); err != nil {
 		return err
 	}
-	_, err = io.Copy(w, buf)
+	return db.writeHistory()
+}
+
+// WritePlaintext writes the database's decrypted contents to w, as they
+// would be encrypted by Write.  The output is canonical: groups are written
+// depth-first in tree order, followed by entries in the order of their
+// groups and then meta-streams, with all times in UTC at one second
+// precision.  Writing an unchanged database always produces the same bytes,
+// which makes the output suitable for diffing and for test fixtures.
+func (db *Database) WritePlaintext(w io.Writer) error {
+	if err := db.writeMetaStreams(); err != nil {
+		return err
+	}
+	_, _, err := db.writePlaintext(w)
 	return err
 }
 
@@ -215,10 +394,13 @@ type Group struct {
 	Name string
 	Icon Icon
 	TimeInfo
+	CustomData CustomData
 
 	db      *Database
 	groups  []*Group
 	entries []*Entry
+	flags   uint32
+	extra   []rawField
 }
 
 // Parent returns the containing group or nil if the group is the
@@ -374,6 +556,49 @@ func (g *Group) NewEntry() (*Entry, error) {
 	return e, nil
 }
 
+// ImportEntry adds a copy of src, which may belong to another database, to
+// the group.  The copy keeps src's UUID so that identities stay stable
+// across imports, unless the UUID is zero or already used in this
+// database, in which case the copy gets a new UUID.
+func (g *Group) ImportEntry(src *Entry) (*Entry, error) {
+	return g.copyEntry(src, src.UUID.IsZero() || g.db.Find(src.UUID) != nil)
+}
+
+// copyEntry adds a deep copy of src to the group, with a new UUID if
+// newUUID is set.
+func (g *Group) copyEntry(src *Entry, newUUID bool) (*Entry, error) {
+	fields, err := src.protectedFields()
+	if err != nil {
+		return nil, err
+	}
+	e := new(Entry)
+	*e = *src
+	e.db = g.db
+	e.Attachment.Data = append([]byte(nil), src.Attachment.Data...)
+	e.extra = append([]rawField(nil), src.extra...)
+	e.CustomData = nil
+	for k, v := range src.CustomData {
+		e.CustomData.Set(k, v)
+	}
+	e.History = append([]Revision(nil), src.History...)
+	if newUUID {
+		id, err := uuids.New4(g.db.rand)
+		if err != nil {
+			return nil, err
+		}
+		e.UUID = id
+	}
+	// Protected fields are sealed to the source database's key and UUID.
+	for _, f := range fields {
+		if err := e.SetField(f); err != nil {
+			return nil, err
+		}
+	}
+	g.entries = append(g.entries, e)
+	g.db.entries = append(g.db.entries, e)
+	return e, nil
+}
+
 // RemoveEntry removes e from the group's entries.
 func (g *Group) RemoveEntry(e *Entry) error {
 	var ok bool
@@ -415,12 +640,20 @@ type Entry struct {
 		Name string
 		Data []byte
 	}
+	CustomData CustomData
+	History    []Revision // oldest first
 
-	db *Database
+	db    *Database
+	extra []rawField
 }
 
 // Parent returns the entry's group.
 func (e *Entry) Parent() *Group {
+	for _, ee := range e.db.root.entries {
+		if ee == e {
+			return e.db.root
+		}
+	}
 	for _, g := range e.db.groups {
 		for _, ee := range g.entries {
 			if ee == e {
@@ -474,53 +707,81 @@ func (ti *TimeInfo) Expires() bool {
 
 // Open decrypts and reads a database.
 func Open(r io.Reader, opts *Options) (*Database, error) {
+	db, _, err := open(r, opts)
+	return db, err
+}
+
+// open is Open that also returns the database's header.
+func open(r io.Reader, opts *Options) (*Database, header, error) {
 	var buf bytes.Buffer
-	if _, err := io.CopyN(&buf, r, headerSize); err != nil {
-		return nil, err
+	_, err := io.CopyN(&buf, r, headerSize)
+	if ferr := sniffFormat(buf.Bytes()); ferr != nil {
+		return nil, header{}, ferr
+	}
+	if err != nil {
+		return nil, header{}, err
 	}
 	var h header
 	if err := h.read(&buf); err != nil {
-		return nil, err
+		return nil, header{}, err
+	}
+	if err := opts.checkCounts(h.numGroups, h.numEntries); err != nil {
+		return nil, header{}, err
+	}
+	if max := opts.maxFileSize(); max > 0 {
+		r = io.LimitReader(r, max-headerSize+1)
 	}
-	// TODO(light): put a limit on this read
 	crypt, err := ioutil.ReadAll(r)
 	if err != nil {
-		return nil, err
+		return nil, header{}, err
 	}
-	kh, err := opts.getKeyFileHash()
-	if err != nil {
-		return nil, err
+	if max := opts.maxFileSize(); max > 0 && int64(len(crypt)) > max-headerSize {
+		return nil, header{}, &LimitError{What: "bytes", Max: max}
 	}
-
 	// TODO(light): try non-UTF8 encodings
 	db := new(Database)
 	if opts != nil && opts.ComputedKey != nil {
 		err = h.initComputedCryptParams(&db.cparams, opts.ComputedKey)
 	} else {
-		err = h.initCryptParams(&db.cparams, []byte(opts.getPassword()), kh)
+		err = h.initCryptParams(&db.cparams, opts)
 	}
 	if err != nil {
-		return nil, err
+		return nil, header{}, err
+	}
+	if err := expandKey(&db.cparams); err != nil {
+		return nil, header{}, err
+	}
+	if err := probeKey(crypt, &db.cparams, h.numGroups); err != nil {
+		return nil, header{}, err
 	}
 	plain, err := decryptDatabase(crypt, &db.cparams, h.contentHash[:])
 	if err != nil {
-		return nil, err
+		return nil, header{}, err
+	}
+	// Every record ends with a terminator field, so the counts can't be
+	// more than the data holds.  Checking keeps a bad header from
+	// allocating records that aren't there.
+	if (int64(h.numGroups)+int64(h.numEntries))*fieldHeaderSize > int64(len(plain)) {
+		return nil, header{}, errRecordCount
 	}
 
-	return parse(db, bytes.NewReader(plain), int(h.numGroups), int(h.numEntries), opts)
+	db, err = parse(db, bytes.NewReader(plain), int(h.numGroups), int(h.numEntries), opts)
+	return db, h, err
 }
 
 type parseState struct {
-	groups        map[uint32]*Group
-	groupLevels   map[*Group]uint16
-	entryGroupIDs map[*Entry]uint32
+	groups            map[uint32]*Group
+	groupLevels       map[*Group]uint16
+	entryGroupIDs     map[*Entry]uint32
+	maxAttachmentSize int64
 }
 
 func parse(db *Database, r io.Reader, numGroups, numEntries int, opts *Options) (*Database, error) {
 	state := parseState{
-		groups:        make(map[uint32]*Group),
-		groupLevels:   make(map[*Group]uint16),
-		entryGroupIDs: make(map[*Entry]uint32),
+		groups:            make(map[uint32]*Group),
+		groupLevels:       make(map[*Group]uint16),
+		entryGroupIDs:     make(map[*Entry]uint32),
+		maxAttachmentSize: opts.maxAttachmentSize(),
 	}
 	groups := make([]Group, numGroups)
 	for i := range groups {
@@ -539,6 +800,9 @@ func parse(db *Database, r io.Reader, numGroups, numEntries int, opts *Options)
 		}
 	}
 	db.init(groups, entries, opts)
+	if err := db.fixEntryUUIDs(); err != nil {
+		return nil, err
+	}
 
 	for i := range groups {
 		g := &groups[i]
@@ -562,10 +826,36 @@ func parse(db *Database, r io.Reader, numGroups, numEntries int, opts *Options)
 			db.root.entries = append(db.root.entries, e)
 		}
 	}
+	db.readCustomData()
+	db.readHistory()
 
 	return db, nil
 }
 
+// fixEntryUUIDs gives new UUIDs to entries whose UUID is zero or the same
+// as an earlier entry's, so that every entry can be found by its UUID.
+func (db *Database) fixEntryUUIDs() error {
+	seen := make(map[uuids.UUID]bool, len(db.entries))
+	for _, e := range db.entries {
+		if !e.UUID.IsZero() && !seen[e.UUID] {
+			seen[e.UUID] = true
+			continue
+		}
+		for {
+			id, err := uuids.New4(db.rand)
+			if err != nil {
+				return err
+			}
+			if !seen[id] {
+				e.UUID = id
+				seen[id] = true
+				break
+			}
+		}
+	}
+	return nil
+}
+
 func (state *parseState) findGroupParent(db *Database, groups []Group, i int) *Group {
 	g := &groups[i]
 	level := state.groupLevels[g]
@@ -615,8 +905,6 @@ func (g *Group) read(state *parseState, r io.Reader) error {
 func (g *Group) readField(state *parseState, key uint16, value []byte) error {
 	var err error
 	switch key {
-	case 0x0000:
-		// ignore
 	case groupIDField:
 		if err = verifyFieldSize("group ID", value, 4); err != nil {
 			return err
@@ -645,9 +933,12 @@ func (g *Group) readField(state *parseState, key uint16, value []byte) error {
 		}
 		state.groupLevels[g] = binary.LittleEndian.Uint16(value)
 	case groupFlagsField:
-		// ignore flags field
+		if err = verifyFieldSize("group flags", value, 4); err != nil {
+			return err
+		}
+		g.flags = binary.LittleEndian.Uint32(value)
 	default:
-		return fmt.Errorf("keepass: unknown group field %04x", key)
+		g.extra = appendRawField(g.extra, key, value)
 	}
 	return err
 }
@@ -662,7 +953,8 @@ func (g *Group) write(w io.Writer, level int) error {
 	writeDateField(ww, groupExpiryTimeField, g.ExpiryTime)
 	writeUint32Field(ww, groupIconField, uint32(g.Icon))
 	writeUint16Field(ww, groupLevelField, uint16(level))
-	writeUint32Field(ww, groupFlagsField, 0)
+	writeUint32Field(ww, groupFlagsField, g.flags)
+	writeRawFields(ww, g.extra)
 	writeField(ww, fieldTerminator, []byte{})
 	return ww.err
 }
@@ -686,8 +978,6 @@ func (e *Entry) read(state *parseState, r io.Reader) error {
 func (e *Entry) readField(state *parseState, key uint16, value []byte) error {
 	var err error
 	switch key {
-	case 0x0000:
-		// ignore field
 	case entryUUIDField:
 		if err = verifyFieldSize("entry UUID", value, 16); err != nil {
 			return err
@@ -726,10 +1016,13 @@ func (e *Entry) readField(state *parseState, key uint16, value []byte) error {
 	case entryAttachmentNameField:
 		e.Attachment.Name = string(stripNull(value))
 	case entryAttachmentDataField:
+		if max := state.maxAttachmentSize; max > 0 && int64(len(value)) > max {
+			return &LimitError{What: "bytes in an attachment", Max: max}
+		}
 		e.Attachment.Data = make([]byte, len(value))
 		copy(e.Attachment.Data, value)
 	default:
-		return fmt.Errorf("keepass: unknown entry field %04x", key)
+		e.extra = appendRawField(e.extra, key, value)
 	}
 	return err
 }
@@ -756,6 +1049,7 @@ func (e *Entry) write(w io.Writer, gid uint32) error {
 		writeStringField(ww, entryAttachmentNameField, "")
 		writeField(ww, entryAttachmentDataField, nil)
 	}
+	writeRawFields(ww, e.extra)
 	writeField(ww, fieldTerminator, []byte{})
 	return ww.err
 }
@@ -790,6 +1084,24 @@ const (
 	fieldTerminator = 0xffff
 )
 
+// A rawField is a group or entry field that this package doesn't
+// interpret, such as a comment field or one added by a newer KeePass.
+// It is kept so it can be written back unchanged.
+type rawField struct {
+	key   uint16
+	value []byte
+}
+
+func appendRawField(fields []rawField, key uint16, value []byte) []rawField {
+	return append(fields, rawField{key: key, value: append([]byte(nil), value...)})
+}
+
+func writeRawFields(w *writer, fields []rawField) {
+	for _, f := range fields {
+		writeField(w, f.key, f.value)
+	}
+}
+
 func decryptDatabase(crypt []byte, p *kdbcrypt.Params, contentHash []byte) ([]byte, error) {
 	if len(crypt)%kdbcrypt.BlockSize != 0 {
 		return nil, errDatabaseUnaligned
@@ -811,6 +1123,39 @@ func decryptDatabase(crypt []byte, p *kdbcrypt.Params, contentHash []byte) ([]by
 	return plain, nil
 }
 
+// probeKey decrypts only the start of the content to reject a wrong key
+// before the whole database is decrypted and hashed.  The content of a
+// database with groups starts with a group field, whose type and size are
+// unlikely to be plausible if decrypted with the wrong key.  A plausible
+// start is not proof of the right key; the content hash is still checked.
+func probeKey(crypt []byte, p *kdbcrypt.Params, numGroups uint32) error {
+	if numGroups == 0 {
+		return nil
+	}
+	dec, err := kdbcrypt.NewDecrypter(bytes.NewReader(crypt), p)
+	if err != nil {
+		return err
+	}
+	defer dec.Close()
+	var start [6]byte
+	if _, err := io.ReadFull(dec, start[:]); err != nil {
+		// Let the full decryption report it.
+		return nil
+	}
+	if !plausibleGroupStart(start[:], len(crypt)) {
+		return ErrHashMismatch
+	}
+	return nil
+}
+
+// plausibleGroupStart reports whether start, the first bytes of decrypted
+// content of size n, could be the header of a group field.
+func plausibleGroupStart(start []byte, n int) bool {
+	key := binary.LittleEndian.Uint16(start)
+	size := binary.LittleEndian.Uint32(start[2:])
+	return (key <= groupFlagsField || key == fieldTerminator) && int64(size) <= int64(n)
+}
+
 // Encryption flags
 const (
 	rijndaelFlag uint32 = 2
@@ -859,16 +1204,32 @@ func (h *header) cipher() (kdbcrypt.Cipher, error) {
 }
 
 // initCryptParams returns kdbcrypt parameters for an existing database.
-func (h *header) initCryptParams(p *kdbcrypt.Params, password, keyFileHash []byte) error {
+func (h *header) initCryptParams(p *kdbcrypt.Params, opts *Options) error {
 	var err error
 	p.Cipher, err = h.cipher()
 	if err != nil {
 		return err
 	}
+	if err := opts.checkKeyRounds(h.transformRounds); err != nil {
+		return err
+	}
+	composite, err := opts.getCompositeKey()
+	if err != nil {
+		return err
+	}
+	var password, keyFileHash []byte
+	if composite == nil {
+		keyFileHash, err = opts.getKeyFileHash()
+		if err != nil {
+			return err
+		}
+		password = []byte(opts.getPassword())
+	}
 	p.IV = h.encryptionIV
 	p.Key = kdbcrypt.Key{
 		Password:        password,
 		KeyFileHash:     keyFileHash,
+		Composite:       composite,
 		MasterSeed:      h.masterSeed,
 		TransformSeed:   h.transformSeed,
 		TransformRounds: h.transformRounds,
@@ -941,10 +1302,12 @@ var (
 	ErrWrongSignature    = errors.New("keepass: not a KeePass file")
 	ErrWrongVersion      = errors.New("keepass: unsupported version")
 	ErrUnknownEncryption = errors.New("keepass: unknown encryption algorithm")
+	ErrIVReuse           = errors.New("keepass: random source gave a repeated or all-zero IV")
 )
 
 // Data validation errors
 var (
 	errDatabaseUnaligned  = errors.New("keepass: database does not match block size")
 	errGroupsInconsistent = errors.New("keepass: inconsistent group tree")
+	errRecordCount        = errors.New("keepass: header counts more groups and entries than the database holds")
 )
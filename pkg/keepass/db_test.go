@@ -16,12 +16,16 @@ package keepass
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pedroalbanese/gostpass/pkg/fakerand"
 	"github.com/pedroalbanese/gostpass/pkg/kdbcrypt"
@@ -398,6 +402,48 @@ func TestWrite_New(t *testing.T) {
 	}
 }
 
+func TestWrite_FreshIV(t *testing.T) {
+	opts := &Options{Password: "swordfish", KeyRounds: 1}
+	db, err := New(sanitizeOptions(opts))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	var outs [][]byte
+	for i := 0; i < 3; i++ {
+		buf := new(bytes.Buffer)
+		if err := db.Write(buf); err != nil {
+			t.Fatal("Write:", err)
+		}
+		if _, err := Open(bytes.NewReader(buf.Bytes()), opts); err != nil {
+			t.Fatalf("Open after write %d: %v", i+1, err)
+		}
+		outs = append(outs, buf.Bytes())
+	}
+	ivStart := 16 + len(header{}.masterSeed)
+	for i := 1; i < len(outs); i++ {
+		for j := 0; j < i; j++ {
+			if bytes.Equal(outs[i][ivStart:ivStart+16], outs[j][ivStart:ivStart+16]) {
+				t.Errorf("writes %d and %d have the same IV", j+1, i+1)
+			}
+			if bytes.Equal(outs[i][headerSize:], outs[j][headerSize:]) {
+				t.Errorf("writes %d and %d of the same plaintext have the same ciphertext", j+1, i+1)
+			}
+		}
+	}
+
+	// A stuck random source would reuse the IV.
+	db, err = New(&Options{Password: "swordfish", KeyRounds: 1, Rand: bytes.NewReader(bytes.Repeat([]byte{0x42}, 4096))})
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	if err := db.Write(new(bytes.Buffer)); err != nil {
+		t.Fatal("Write:", err)
+	}
+	if err := db.Write(new(bytes.Buffer)); err != ErrIVReuse {
+		t.Errorf("second Write with a stuck random source error = %v; want %v", err, ErrIVReuse)
+	}
+}
+
 func TestWrite_GroupAndEntry(t *testing.T) {
 	opts := &Options{
 		Password: "swordfish",
@@ -445,6 +491,480 @@ func TestWrite_GroupAndEntry(t *testing.T) {
 	}
 }
 
+func TestSetKey(t *testing.T) {
+	oldOpts := &Options{Password: "swordfish", KeyRounds: 1000}
+	newOpts := &Options{Password: "correct horse", KeyRounds: 2000}
+	db, err := New(sanitizeOptions(oldOpts))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	g := db.Root().NewSubgroup()
+	g.Name = "My Group"
+	oldKey := db.ComputedKey()
+
+	if err := db.SetKey(sanitizeOptions(newOpts)); err != nil {
+		t.Fatal("SetKey:", err)
+	}
+
+	if bytes.Equal(db.ComputedKey(), oldKey) {
+		t.Error("db.ComputedKey() unchanged after SetKey")
+	}
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+	if _, err := Open(bytes.NewReader(buf.Bytes()), oldOpts); err != ErrHashMismatch {
+		t.Errorf("Open with old password error: %v; want %v", err, ErrHashMismatch)
+	}
+	rdb, err := Open(bytes.NewReader(buf.Bytes()), newOpts)
+	if err != nil {
+		t.Fatal("Open with new password:", err)
+	}
+	if n := rdb.Root().NGroups(); n != 1 {
+		t.Errorf("rdb.Root().NGroups() = %d; want 1", n)
+	}
+	if n := rdb.KeyRounds(); n != 2000 {
+		t.Errorf("rdb.KeyRounds() = %d; want 2000", n)
+	}
+}
+
+func TestProbeKey(t *testing.T) {
+	db, err := New(sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1000}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	e, err := db.Root().NewSubgroup().NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	e.Attachment.Name = "big.bin"
+	e.Attachment.Data = make([]byte, 1<<20)
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+	var h header
+	if err := h.read(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal("read header:", err)
+	}
+	crypt := buf.Bytes()[headerSize:]
+
+	var p kdbcrypt.Params
+	if err := h.initCryptParams(&p, &Options{Password: "swordfish"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := probeKey(crypt, &p, h.numGroups); err != nil {
+		t.Errorf("probeKey with right password = %v; want <nil>", err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := h.initCryptParams(&p, &Options{Password: fmt.Sprint("wrong", i)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := probeKey(crypt, &p, h.numGroups); err != ErrHashMismatch {
+			t.Errorf("probeKey with password %q = %v; want %v", fmt.Sprint("wrong", i), err, ErrHashMismatch)
+		}
+	}
+}
+
+func TestMetaStream(t *testing.T) {
+	opts := &Options{Password: "swordfish", KeyRounds: 1000}
+	db, err := New(sanitizeOptions(opts))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	g := db.Root().NewSubgroup()
+	g.Name = "My Group"
+	if err := db.SetMetaStream("Test Stream", []byte("Hello")); err != nil {
+		t.Fatal("SetMetaStream:", err)
+	}
+	if err := db.SetMetaStream("Deleted Stream", []byte("Bye")); err != nil {
+		t.Fatal("SetMetaStream:", err)
+	}
+	if err := db.SetMetaStream("Deleted Stream", nil); err != nil {
+		t.Fatal("SetMetaStream(nil):", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+
+	rdb, err := Open(buf, opts)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	if got := rdb.MetaStream("Test Stream"); string(got) != "Hello" {
+		t.Errorf("rdb.MetaStream(%q) = %q; want %q", "Test Stream", got, "Hello")
+	}
+	if got := rdb.MetaStream("Deleted Stream"); got != nil {
+		t.Errorf("rdb.MetaStream(%q) = %q; want <nil>", "Deleted Stream", got)
+	}
+	if n := len(rdb.Entries()); n != 0 {
+		t.Errorf("len(rdb.Entries()) = %d; want 0", n)
+	}
+}
+
+func TestCustomData(t *testing.T) {
+	opts := &Options{Password: "swordfish", KeyRounds: 1000}
+	db, err := New(sanitizeOptions(opts))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	g := db.Root().NewSubgroup()
+	g.CustomData.Set("color", "red")
+	e, err := g.NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	e.CustomData.SetInt("uses", 42)
+	e.CustomData.SetBool("favorite", true)
+	deleted, err := g.NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	deleted.CustomData.Set("gone", "yes")
+	if err := g.RemoveEntry(deleted); err != nil {
+		t.Fatal("RemoveEntry:", err)
+	}
+	db.CustomData().Set("other.app/setting", "kept")
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+
+	rdb, err := Open(buf, opts)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	if v, _ := rdb.CustomData().Get("other.app/setting"); v != "kept" {
+		t.Errorf("database custom data %q = %q; want %q", "other.app/setting", v, "kept")
+	}
+	rg := rdb.FindGroup(g.ID)
+	if v, _ := rg.CustomData.Get("color"); v != "red" {
+		t.Errorf("group custom data %q = %q; want %q", "color", v, "red")
+	}
+	re := rdb.Find(e.UUID)
+	if n, ok := re.CustomData.Int("uses"); !ok || n != 42 {
+		t.Errorf("entry custom data Int(%q) = %d, %t; want 42, true", "uses", n, ok)
+	}
+	if b, ok := re.CustomData.Bool("favorite"); !ok || !b {
+		t.Errorf("entry custom data Bool(%q) = %t, %t; want true, true", "favorite", b, ok)
+	}
+	if got := string(rdb.MetaStream(customDataStream)); strings.Contains(got, "gone") {
+		t.Errorf("custom data of removed entry was written: %s", got)
+	}
+	if n := len(rdb.Entries()); n != 1 {
+		t.Errorf("len(rdb.Entries()) = %d; want 1", n)
+	}
+}
+
+func TestCustomData_Undecodable(t *testing.T) {
+	opts := &Options{Password: "swordfish", KeyRounds: 1000}
+	db, err := New(sanitizeOptions(opts))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	e, err := db.Root().NewSubgroup().NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	if err := db.SetMetaStream(customDataStream, []byte("{not json")); err != nil {
+		t.Fatal("SetMetaStream:", err)
+	}
+	db.keepCustomData = true
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+
+	rdb, err := Open(buf, opts)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	if err := rdb.Write(new(bytes.Buffer)); err != nil {
+		t.Errorf("Write unchanged: %v", err)
+	}
+	rdb.Find(e.UUID).CustomData.Set("gostpass.blob", "1234")
+	if err := rdb.Write(new(bytes.Buffer)); err != ErrCustomDataUnwritable {
+		t.Errorf("Write after setting custom data = %v; want %v", err, ErrCustomDataUnwritable)
+	}
+	if got := string(rdb.MetaStream(customDataStream)); got != "{not json" {
+		t.Errorf("custom data stream = %q; want it kept as read", got)
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	opts := &Options{Password: "swordfish", KeyRounds: 1000}
+	db, err := New(sanitizeOptions(opts))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	g := db.Root().NewSubgroup()
+	g.flags = 0x10
+	g.extra = []rawField{{key: 0x0000, value: []byte("comment")}, {key: 0x0042, value: []byte{1, 2, 3}}}
+	e, err := g.NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	e.extra = []rawField{{key: 0x0100, value: []byte("from the future")}}
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+
+	rdb, err := Open(buf, opts)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	rg := rdb.FindGroup(g.ID)
+	if rg.flags != g.flags {
+		t.Errorf("group flags = %#x; want %#x", rg.flags, g.flags)
+	}
+	if !reflect.DeepEqual(rg.extra, g.extra) {
+		t.Errorf("group unknown fields = %v; want %v", rg.extra, g.extra)
+	}
+	if re := rdb.Find(e.UUID); !reflect.DeepEqual(re.extra, e.extra) {
+		t.Errorf("entry unknown fields = %v; want %v", re.extra, e.extra)
+	}
+}
+
+func TestWritePlaintext_Deterministic(t *testing.T) {
+	build := func(loc *time.Location, nsec int) *Database {
+		db, err := New(sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1000}))
+		if err != nil {
+			t.Fatal("New:", err)
+		}
+		now := time.Date(2019, time.July, 1, 22, 0, 0, nsec, time.UTC).In(loc)
+		for _, name := range []string{"Internet", "Misc"} {
+			g := db.Root().NewSubgroup()
+			g.Name = name
+			g.CreationTime = now
+			g.CustomData.Set("b", "2")
+			g.CustomData.Set("a", "1")
+			sub := g.NewSubgroup()
+			sub.Name = name + " Sub"
+		}
+		e, err := db.Root().Group(0).NewEntry()
+		if err != nil {
+			t.Fatal("NewEntry:", err)
+		}
+		e.Title = "Example"
+		e.CreationTime = now
+		e.CustomData.Set("z", "last")
+		e.CustomData.Set("y", "first")
+		return db
+	}
+	plaintext := func(db *Database) []byte {
+		buf := new(bytes.Buffer)
+		if err := db.WritePlaintext(buf); err != nil {
+			t.Fatal("WritePlaintext:", err)
+		}
+		return buf.Bytes()
+	}
+
+	want := plaintext(build(time.UTC, 0))
+	if got := plaintext(build(time.FixedZone("MSK", 3*60*60), 123456789)); !bytes.Equal(got, want) {
+		t.Error("databases differing only in time zone and sub-second times have different plaintext")
+	}
+
+	// Saving and reopening must not change the plaintext either.
+	db := build(time.UTC, 0)
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+	rdb, err := Open(buf, &Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	if got := plaintext(rdb); !bytes.Equal(got, want) {
+		t.Error("plaintext changed after a Write/Open round trip")
+	}
+	if got := plaintext(rdb); !bytes.Equal(got, want) {
+		t.Error("plaintext changed on second WritePlaintext")
+	}
+}
+
+func TestOpen_DuplicateUUIDs(t *testing.T) {
+	opts := &Options{Password: "swordfish", KeyRounds: 1000}
+	db, err := New(sanitizeOptions(opts))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	g := db.Root().NewSubgroup()
+	var entries [3]*Entry
+	for i := range entries {
+		if entries[i], err = g.NewEntry(); err != nil {
+			t.Fatal("NewEntry:", err)
+		}
+		entries[i].Title = fmt.Sprint("Entry ", i)
+	}
+	entries[1].UUID = entries[0].UUID
+	entries[2].UUID = [16]byte{}
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+
+	rdb, err := Open(buf, sanitizeOptions(opts))
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	seen := make(map[[16]byte]string)
+	for _, e := range rdb.Entries() {
+		if e.UUID == ([16]byte{}) {
+			t.Errorf("entry %q has zero UUID", e.Title)
+		}
+		if other, dup := seen[e.UUID]; dup {
+			t.Errorf("entries %q and %q share UUID %v", other, e.Title, e.UUID)
+		}
+		seen[e.UUID] = e.Title
+	}
+	if e := rdb.Find(entries[0].UUID); e == nil || e.Title != "Entry 0" {
+		t.Errorf("Find(%v) = %v; want first entry to keep its UUID", entries[0].UUID, e)
+	}
+}
+
+func TestOpen_MaxKeyRounds(t *testing.T) {
+	db, err := New(sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1000}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+	tests := []struct {
+		max     int
+		tooMany bool
+	}{
+		{max: 0},
+		{max: 1000},
+		{max: 999, tooMany: true},
+		{max: -1},
+	}
+	for _, test := range tests {
+		_, err := Open(bytes.NewReader(buf.Bytes()), &Options{Password: "swordfish", MaxKeyRounds: test.max})
+		if test.tooMany {
+			if e, ok := err.(*KeyRoundsError); !ok || e.Rounds != 1000 || e.Max != test.max {
+				t.Errorf("Open with MaxKeyRounds: %d = %v; want KeyRoundsError for 1000 rounds", test.max, err)
+			}
+		} else if err != nil {
+			t.Errorf("Open with MaxKeyRounds: %d: %v", test.max, err)
+		}
+	}
+	// A computed key skips the transform, so the limit doesn't apply.
+	_, err = Open(bytes.NewReader(buf.Bytes()), &Options{ComputedKey: db.ComputedKey(), MaxKeyRounds: 1})
+	if err != nil {
+		t.Errorf("Open with ComputedKey and MaxKeyRounds: 1: %v", err)
+	}
+}
+
+func TestOpen_Limits(t *testing.T) {
+	db, err := New(sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1000}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	g := db.Root().NewSubgroup()
+	g.NewSubgroup()
+	for i := 0; i < 3; i++ {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal("NewEntry:", err)
+		}
+		e.Attachment.Name = "data.bin"
+		e.Attachment.Data = make([]byte, 100*i)
+	}
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+	tests := []struct {
+		opts Options
+		what string
+	}{
+		{opts: Options{MaxGroups: 1}, what: "groups"},
+		{opts: Options{MaxEntries: 2}, what: "entries"},
+		{opts: Options{MaxAttachmentSize: 199}, what: "bytes in an attachment"},
+		{opts: Options{MaxFileSize: int64(buf.Len() - 1)}, what: "bytes"},
+		{opts: Options{MaxGroups: 2, MaxEntries: 3, MaxAttachmentSize: 200, MaxFileSize: int64(buf.Len())}},
+		{opts: Options{MaxGroups: -1, MaxEntries: -1, MaxAttachmentSize: -1, MaxFileSize: -1}},
+	}
+	for _, test := range tests {
+		opts := test.opts
+		opts.Password = "swordfish"
+		_, err := Open(bytes.NewReader(buf.Bytes()), &opts)
+		if test.what == "" {
+			if err != nil {
+				t.Errorf("Open with %+v: %v", test.opts, err)
+			}
+			continue
+		}
+		if e, ok := err.(*LimitError); !ok || e.What != test.what {
+			t.Errorf("Open with %+v = %v; want LimitError for %s", test.opts, err, test.what)
+		}
+	}
+
+	// A header that counts more records than the data holds is rejected
+	// before they are allocated.
+	data := append([]byte(nil), buf.Bytes()...)
+	binary.LittleEndian.PutUint32(data[48:], 1<<28)
+	_, err = Open(bytes.NewReader(data), &Options{Password: "swordfish", MaxGroups: -1})
+	if err != errRecordCount {
+		t.Errorf("Open with a huge group count = %v; want %v", err, errRecordCount)
+	}
+}
+
+func TestImportEntry(t *testing.T) {
+	src, err := New(sanitizeOptions(&Options{KeyRounds: 1}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	srcGroup := src.Root().NewSubgroup()
+	orig, err := srcGroup.NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	orig.Title = "Imported"
+	orig.Attachment.Name = "a.txt"
+	orig.Attachment.Data = []byte("data")
+	orig.CustomData.Set("k", "v")
+
+	dst, err := New(&Options{KeyRounds: 1})
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	dstGroup := dst.Root().NewSubgroup()
+	first, err := dstGroup.ImportEntry(orig)
+	if err != nil {
+		t.Fatal("ImportEntry:", err)
+	}
+	if first.UUID != orig.UUID {
+		t.Errorf("first import UUID = %v; want %v", first.UUID, orig.UUID)
+	}
+	if first.Title != orig.Title || string(first.Attachment.Data) != "data" {
+		t.Errorf("first import = %+v; want copy of %+v", first, orig)
+	}
+	if first.Parent() != dstGroup {
+		t.Error("imported entry is not in destination group")
+	}
+	orig.Attachment.Data[0] = 'D'
+	orig.CustomData.Set("k", "changed")
+	if string(first.Attachment.Data) != "data" || first.CustomData["k"] != "v" {
+		t.Error("imported entry shares data with source")
+	}
+
+	second, err := dstGroup.ImportEntry(orig)
+	if err != nil {
+		t.Fatal("ImportEntry:", err)
+	}
+	if second.UUID == orig.UUID || second.UUID == ([16]byte{}) {
+		t.Errorf("colliding import UUID = %v; want a new UUID", second.UUID)
+	}
+	if n := len(dst.Entries()); n != 2 {
+		t.Errorf("len(dst.Entries()) = %d; want 2", n)
+	}
+}
+
 func TestWrite_Identity(t *testing.T) {
 	tests := []struct {
 		openParams
@@ -573,8 +1093,7 @@ func debugDecrypt(data []byte, opts *Options) ([]byte, error) {
 	if opts != nil && opts.ComputedKey != nil {
 		err = h.initComputedCryptParams(p, opts.ComputedKey)
 	} else {
-		// TODO(light): keyfile
-		err = h.initCryptParams(p, []byte(opts.getPassword()), nil)
+		err = h.initCryptParams(p, opts)
 	}
 	if err != nil {
 		return nil, err
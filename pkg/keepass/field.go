@@ -0,0 +1,176 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pedroalbanese/gogost/gost3412128"
+	"github.com/pedroalbanese/gogost/mgm"
+	"github.com/pedroalbanese/gostpass/pkg/gosthmac"
+	"github.com/pedroalbanese/gostpass/pkg/kdbcrypt"
+)
+
+// Custom data key prefixes for fields.
+const (
+	fieldPrefix          = "gostpass.field."
+	protectedFieldPrefix = "gostpass.protected."
+)
+
+// Errors returned by protected fields.
+var (
+	ErrFieldName      = errors.New("keepass: empty field name")
+	ErrDetached       = errors.New("keepass: entry is not in a database")
+	ErrProtectedField = errors.New("keepass: protected field cannot be decrypted")
+)
+
+// A Field is a named value stored in an entry's custom data.
+//
+// A Protected field is encrypted a second time, with a key derived from
+// the database key and the entry's UUID, and stays encrypted in memory
+// until it is read.  A dump of the decrypted database then doesn't show
+// it, although the database key in the same memory still unlocks it.
+type Field struct {
+	Name      string
+	Value     string
+	Protected bool
+}
+
+// SetField stores f, replacing any field with the same name.
+func (e *Entry) SetField(f Field) error {
+	if f.Name == "" {
+		return ErrFieldName
+	}
+	if !f.Protected {
+		e.CustomData.Delete(protectedFieldPrefix + f.Name)
+		e.CustomData.Set(fieldPrefix+f.Name, f.Value)
+		return nil
+	}
+	if e.db == nil {
+		return ErrDetached
+	}
+	sealed, err := e.db.sealField(e.db.cparams.ComputedKey, e, f.Name, f.Value)
+	if err != nil {
+		return err
+	}
+	e.CustomData.Delete(fieldPrefix + f.Name)
+	e.CustomData.Set(protectedFieldPrefix+f.Name, sealed)
+	return nil
+}
+
+// Field returns the named field and whether the entry has it.  A
+// protected field is decrypted.
+func (e *Entry) Field(name string) (Field, bool, error) {
+	if v, ok := e.CustomData.Get(fieldPrefix + name); ok {
+		return Field{Name: name, Value: v}, true, nil
+	}
+	sealed, ok := e.CustomData.Get(protectedFieldPrefix + name)
+	if !ok {
+		return Field{}, false, nil
+	}
+	if e.db == nil {
+		return Field{}, true, ErrDetached
+	}
+	v, err := openField(e.db.cparams.ComputedKey, e, name, sealed)
+	if err != nil {
+		return Field{}, true, err
+	}
+	return Field{Name: name, Value: v, Protected: true}, true, nil
+}
+
+// FieldNames returns the names of the entry's fields in sorted order.
+func (e *Entry) FieldNames() []string {
+	var names []string
+	for k := range e.CustomData {
+		switch {
+		case strings.HasPrefix(k, fieldPrefix):
+			names = append(names, k[len(fieldPrefix):])
+		case strings.HasPrefix(k, protectedFieldPrefix):
+			names = append(names, k[len(protectedFieldPrefix):])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DeleteField removes the named field.
+func (e *Entry) DeleteField(name string) {
+	e.CustomData.Delete(fieldPrefix + name)
+	e.CustomData.Delete(protectedFieldPrefix + name)
+}
+
+// protectedFields decrypts e's protected fields, for resealing them after
+// the database key or the entry's UUID changes.
+func (e *Entry) protectedFields() ([]Field, error) {
+	var fields []Field
+	for _, name := range e.FieldNames() {
+		f, _, err := e.Field(name)
+		if err != nil {
+			return nil, fmt.Errorf("keepass: field %q of %s (id=%v): %v", name, e.Title, e.UUID, err)
+		}
+		if f.Protected {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// fieldCipher returns the AEAD for e's protected fields under the
+// database key ck.  The key is bound to the entry's UUID, so that sealed
+// values can't be moved to another entry, and the field name is
+// authenticated, so that they can't be moved to another field.
+func fieldCipher(ck kdbcrypt.ComputedKey, e *Entry) (cipher.AEAD, error) {
+	key := gosthmac.Sum256(ck, append([]byte("gostpass field key "), e.UUID[:]...))
+	return mgm.NewMGM(gost3412128.NewCipher(key), gost3412128.BlockSize)
+}
+
+// sealField encrypts value as e's protected field name and returns it in
+// base64, nonce first.
+func (db *Database) sealField(ck kdbcrypt.ComputedKey, e *Entry, name, value string) (string, error) {
+	aead, err := fieldCipher(ck, e)
+	if err != nil {
+		return "", fmt.Errorf("keepass: %v", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(db.rand, nonce); err != nil {
+		return "", fmt.Errorf("keepass: %v", err)
+	}
+	// MGM requires the nonce's most significant bit to be clear.
+	nonce[0] &= 0x7f
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(value), []byte(name))), nil
+}
+
+func openField(ck kdbcrypt.ComputedKey, e *Entry, name, sealed string) (string, error) {
+	aead, err := fieldCipher(ck, e)
+	if err != nil {
+		return "", fmt.Errorf("keepass: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", ErrProtectedField
+	}
+	n := aead.NonceSize()
+	value, err := aead.Open(nil, data[:n], data[n:], []byte(name))
+	if err != nil {
+		return "", ErrProtectedField
+	}
+	return string(value), nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestField(t *testing.T) {
+	opts := &Options{Password: "swordfish", KeyRounds: 1000}
+	db, err := New(sanitizeOptions(opts))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	e, err := db.Root().NewSubgroup().NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	if err := e.SetField(Field{Name: "pin", Value: "8675309", Protected: true}); err != nil {
+		t.Fatal("SetField pin:", err)
+	}
+	if err := e.SetField(Field{Name: "account", Value: "12-345"}); err != nil {
+		t.Fatal("SetField account:", err)
+	}
+	if err := e.SetField(Field{Value: "x"}); err != ErrFieldName {
+		t.Errorf("SetField without name error = %v; want %v", err, ErrFieldName)
+	}
+	for k, v := range e.CustomData {
+		if strings.Contains(v, "8675309") {
+			t.Errorf("protected field stored in clear in custom data %q = %q", k, v)
+		}
+	}
+	if names, want := e.FieldNames(), []string{"account", "pin"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("FieldNames() = %q; want %q", names, want)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+	rdb, err := Open(buf, opts)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	re := rdb.Find(e.UUID)
+	want := Field{Name: "pin", Value: "8675309", Protected: true}
+	if f, ok, err := re.Field("pin"); err != nil || !ok || f != want {
+		t.Errorf("after reopening, Field(pin) = %+v, %t, %v; want %+v, true, <nil>", f, ok, err, want)
+	}
+	if f, ok, err := re.Field("account"); err != nil || !ok || f.Value != "12-345" || f.Protected {
+		t.Errorf("after reopening, Field(account) = %+v, %t, %v; want unprotected 12-345", f, ok, err)
+	}
+	if _, ok, err := re.Field("missing"); ok || err != nil {
+		t.Errorf("Field(missing) = _, %t, %v; want false, <nil>", ok, err)
+	}
+
+	// A sealed value is bound to its entry and field.
+	other, err := re.Parent().NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	other.CustomData.Set(protectedFieldPrefix+"pin", re.CustomData[protectedFieldPrefix+"pin"])
+	if _, _, err := other.Field("pin"); err != ErrProtectedField {
+		t.Errorf("Field of value moved to other entry error = %v; want %v", err, ErrProtectedField)
+	}
+	re.CustomData.Set(protectedFieldPrefix+"code", re.CustomData[protectedFieldPrefix+"pin"])
+	if _, _, err := re.Field("code"); err != ErrProtectedField {
+		t.Errorf("Field of value moved to other field error = %v; want %v", err, ErrProtectedField)
+	}
+	re.DeleteField("code")
+	if names, want := re.FieldNames(), []string{"account", "pin"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("after DeleteField, FieldNames() = %q; want %q", names, want)
+	}
+	if err := other.SetField(Field{Name: "pin", Value: "1234", Protected: true}); err != nil {
+		t.Fatal("SetField:", err)
+	}
+	if err := other.SetField(Field{Name: "pin", Value: "1234"}); err != nil {
+		t.Fatal("SetField:", err)
+	}
+	if _, ok := other.CustomData.Get(protectedFieldPrefix + "pin"); ok {
+		t.Error("unprotecting a field left the sealed value behind")
+	}
+}
+
+func TestField_Rekey(t *testing.T) {
+	db, err := New(sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1000}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	e, err := db.Root().NewSubgroup().NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	want := Field{Name: "pin", Value: "8675309", Protected: true}
+	if err := e.SetField(want); err != nil {
+		t.Fatal("SetField:", err)
+	}
+
+	if err := db.SetKey(sanitizeOptions(&Options{Password: "correct horse", KeyRounds: 1000})); err != nil {
+		t.Fatal("SetKey:", err)
+	}
+	if f, _, err := e.Field("pin"); err != nil || f != want {
+		t.Errorf("after SetKey, Field(pin) = %+v, %v; want %+v, <nil>", f, err, want)
+	}
+
+	dst, err := New(sanitizeOptions(&Options{Password: "other", KeyRounds: 1000}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	g := dst.Root().NewSubgroup()
+	if _, err := g.ImportEntry(e); err != nil {
+		t.Fatal("ImportEntry:", err)
+	}
+	second, err := g.ImportEntry(e)
+	if err != nil {
+		t.Fatal("ImportEntry:", err)
+	}
+	if second.UUID == e.UUID {
+		t.Fatal("second import kept the UUID")
+	}
+	if f, _, err := second.Field("pin"); err != nil || f != want {
+		t.Errorf("after ImportEntry with new UUID, Field(pin) = %+v, %v; want %+v, <nil>", f, err, want)
+	}
+
+	// An entry whose protected field can't be read stops a rekey, rather
+	// than leaving it sealed to the old key.
+	e.CustomData.Set(protectedFieldPrefix+"broken", "AAAA")
+	oldKey := db.ComputedKey()
+	if err := db.SetKey(sanitizeOptions(&Options{Password: "third", KeyRounds: 1000})); err == nil {
+		t.Error("SetKey with a broken protected field succeeded")
+	}
+	if !bytes.Equal(db.ComputedKey(), oldKey) {
+		t.Error("failed SetKey changed the key")
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pedroalbanese/gostpass/pkg/kdbcrypt"
+)
+
+// OpenFile decrypts and reads the database stored at path.  The database
+// remembers the path for Save.
+func OpenFile(path string, opts *Options) (*Database, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	db, h, err := open(f, opts)
+	if err != nil {
+		return nil, err
+	}
+	db.path = path
+	if info, err := f.Stat(); err == nil {
+		db.saved = &savedFile{header: h, key: db.cparams.ComputedKey, info: info}
+	}
+	return db, nil
+}
+
+// A savedFile records what was last read from or written to a database's
+// file, so that Save can tell when there is nothing new to write.
+type savedFile struct {
+	header header
+	key    kdbcrypt.ComputedKey
+	info   os.FileInfo
+}
+
+// unchanged reports whether saving db would only change the IV of the
+// file at db.path: db's content, key and key derivation parameters are the
+// ones last read or written, and the file has not been replaced or
+// modified since.
+func (db *Database) unchanged() bool {
+	if db.saved == nil {
+		return false
+	}
+	info, err := os.Stat(db.path)
+	if err != nil || !os.SameFile(info, db.saved.info) ||
+		!info.ModTime().Equal(db.saved.info.ModTime()) || info.Size() != db.saved.info.Size() {
+		return false
+	}
+	h, err := db.plaintextHeader()
+	if err != nil {
+		return false
+	}
+	h.encryptionIV = db.saved.header.encryptionIV
+	return h == db.saved.header && bytes.Equal(db.cparams.ComputedKey, db.saved.key)
+}
+
+// Path returns the file that the database was opened from or last saved
+// to, or the empty string if there is none.
+func (db *Database) Path() string {
+	return db.path
+}
+
+// Save writes the database back to the file it was opened from or last
+// saved to.  If nothing has changed since, the file is left alone, so that
+// sync services and backups don't see a new file for every save.
+func (db *Database) Save() error {
+	if db.path == "" {
+		return errors.New("keepass: save: database has no path; use SaveAs")
+	}
+	if db.unchanged() {
+		return nil
+	}
+	return db.SaveAs(db.path)
+}
+
+// SaveAs writes the database to path and remembers path for Save.  The
+// file is replaced atomically: the database is written to a temporary file
+// in the same directory, synced and then renamed over path, so a failed
+// save leaves any existing file intact.
+func (db *Database) SaveAs(path string) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	h, err := db.write(f)
+	if err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	db.path = path
+	db.saved = nil
+	if info, err := os.Stat(path); err == nil {
+		db.saved = &savedFile{header: h, key: db.cparams.ComputedKey, info: info}
+	}
+	return nil
+}
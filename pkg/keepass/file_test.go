@@ -0,0 +1,145 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAsOpenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keepass_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.kdb")
+
+	db, err := New(sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1000}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	if err := db.Save(); err == nil {
+		t.Error("Save on new database did not return an error")
+	}
+	g := db.Root().NewSubgroup()
+	g.Name = "First"
+	if err := db.SaveAs(path); err != nil {
+		t.Fatal("SaveAs:", err)
+	}
+	if db.Path() != path {
+		t.Errorf("Path() = %q; want %q", db.Path(), path)
+	}
+
+	rdb, err := OpenFile(path, &Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal("OpenFile:", err)
+	}
+	if rdb.Path() != path {
+		t.Errorf("opened Path() = %q; want %q", rdb.Path(), path)
+	}
+	rdb.FindGroup(g.ID).Name = "Second"
+	if err := rdb.Save(); err != nil {
+		t.Fatal("Save:", err)
+	}
+
+	rdb, err = OpenFile(path, &Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal("OpenFile after Save:", err)
+	}
+	if name := rdb.FindGroup(g.ID).Name; name != "Second" {
+		t.Errorf("group name after Save = %q; want %q", name, "Second")
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 {
+		t.Errorf("directory has %d files after saving; want 1 (no leftover temporary files)", len(infos))
+	}
+	if _, err := OpenFile(path, &Options{Password: "wrong"}); err != ErrHashMismatch {
+		t.Errorf("OpenFile with wrong password error = %v; want %v", err, ErrHashMismatch)
+	}
+}
+
+func TestSaveUnchanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keepass_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.kdb")
+
+	db, err := New(sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1000}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	g := db.Root().NewSubgroup()
+	g.Name = "First"
+	if err := db.SaveAs(path); err != nil {
+		t.Fatal("SaveAs:", err)
+	}
+	// Save replaces the file, so a rewrite shows up as a different file.
+	rewritten := func(db *Database) bool {
+		t.Helper()
+		before, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Save(); err != nil {
+			t.Fatal("Save:", err)
+		}
+		after, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return !os.SameFile(before, after)
+	}
+	if rewritten(db) {
+		t.Error("Save after SaveAs rewrote the unchanged database")
+	}
+	g.Name = "Second"
+	if !rewritten(db) {
+		t.Error("Save after renaming a group did not rewrite the database")
+	}
+
+	rdb, err := OpenFile(path, &Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal("OpenFile:", err)
+	}
+	if rewritten(rdb) {
+		t.Error("Save after OpenFile rewrote the unchanged database")
+	}
+	if err := rdb.SetKey(sanitizeOptions(&Options{Password: "hunter2", KeyRounds: 1000})); err != nil {
+		t.Fatal("SetKey:", err)
+	}
+	if !rewritten(rdb) {
+		t.Error("Save after SetKey did not rewrite the database")
+	}
+
+	// The file changed behind the database's back, so Save must not trust
+	// that it still holds the database.
+	if err := ioutil.WriteFile(path, []byte("clobbered"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if !rewritten(rdb) {
+		t.Error("Save after the file was overwritten did not rewrite the database")
+	}
+	if _, err := OpenFile(path, &Options{Password: "hunter2"}); err != nil {
+		t.Error("OpenFile after Save:", err)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build fixturegen
+// +build fixturegen
+
+package keepass
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestGenerateFixture rewrites testdata/gost.kdb:
+//
+//	go test -tags fixturegen -run TestGenerateFixture ./pkg/keepass
+//
+// The file only needs regenerating when fillFixture changes.  A format
+// change that makes TestFixture_RoundTrip fail must not be papered over by
+// regenerating it, since users' databases are in the old format.
+func TestGenerateFixture(t *testing.T) {
+	db, err := New(sanitizeOptions(fixtureOptions))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	if err := fillFixture(db); err != nil {
+		t.Fatal("fillFixture:", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join("testdata", gostFixture), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
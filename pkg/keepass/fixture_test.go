@@ -0,0 +1,170 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// gostFixture is a database written by this package, so that changes to
+// the file format that would break existing GOST databases are caught.  It
+// holds what fillFixture adds and is regenerated by TestGenerateFixture,
+// which is built with the fixturegen tag.
+const gostFixture = "gost.kdb"
+
+var fixtureOptions = &Options{Password: "swordfish", KeyRounds: 1000}
+
+// fillFixture adds the fixture's contents to db: nested groups, entries
+// with every field, an attachment, history and custom data.
+func fillFixture(db *Database) error {
+	t0 := time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC)
+	times := TimeInfo{CreationTime: t0, LastModificationTime: t0, LastAccessTime: t0}
+
+	internet := db.Root().NewSubgroup()
+	internet.Name = "Internet"
+	internet.TimeInfo = times
+	internet.CustomData.Set("gostpass.color", "blue")
+	e, err := internet.NewEntry()
+	if err != nil {
+		return err
+	}
+	e.Title = "Example"
+	e.URL = "https://example.com/login"
+	e.Username = "alice"
+	e.Password = "correct horse battery staple"
+	e.Notes = "Line one\nLine two, with ünïcödé"
+	e.TimeInfo = times
+	e.AddRevision()
+	e.Password = "Tr0ub4dor&3"
+	e.LastModificationTime = t0.Add(time.Hour)
+	e.Attachment.Name = "recovery-codes.txt"
+	e.Attachment.Data = []byte("1234-5678\n8765-4321\n")
+	e.CustomData.Set("gostpass.totp", "otpauth://totp/Example?secret=JBSWY3DPEHPK3PXP")
+
+	home := db.Root().NewSubgroup()
+	home.Name = "Home"
+	home.TimeInfo = times
+	wifi := home.NewSubgroup()
+	wifi.Name = "Wi-Fi"
+	wifi.TimeInfo = times
+	e, err = wifi.NewEntry()
+	if err != nil {
+		return err
+	}
+	e.Title = "Сеть"
+	e.Password = "пароль"
+	e.TimeInfo = times
+	e.ExpiryTime = t0.AddDate(1, 0, 0)
+
+	db.CustomData().Set("gostpass.fixture", "1")
+	return nil
+}
+
+// dumpDatabase describes db's groups and entries as text, for comparing
+// databases.  Times are to the second, like the file format stores them.
+func dumpDatabase(db *Database) string {
+	ts := func(t time.Time) string {
+		return t.UTC().Truncate(time.Second).Format(time.RFC3339)
+	}
+	var b strings.Builder
+	var walk func(g *Group)
+	walk = func(g *Group) {
+		if !g.IsRoot() {
+			fmt.Fprintf(&b, "group %q %s %v\n", g.Path(), ts(g.CreationTime), g.CustomData)
+		}
+		for _, e := range g.Entries() {
+			fmt.Fprintf(&b, "entry %q %q %q %q %q %v %s %s %s\n", e.Title, e.URL, e.Username, e.Password, e.Notes, e.Icon,
+				ts(e.CreationTime), ts(e.LastModificationTime), ts(e.ExpiryTime))
+			fmt.Fprintf(&b, "  attachment %q %q\n", e.Attachment.Name, e.Attachment.Data)
+			fmt.Fprintf(&b, "  custom data %v\n", e.CustomData)
+			for _, rev := range e.History {
+				fmt.Fprintf(&b, "  revision %q %q %s\n", rev.Title, rev.Password, ts(rev.Modified))
+			}
+		}
+		for _, sub := range g.Groups() {
+			walk(sub)
+		}
+	}
+	walk(db.Root())
+	fmt.Fprintf(&b, "custom data %v\n", *db.CustomData())
+	return b.String()
+}
+
+func TestFixture_RoundTrip(t *testing.T) {
+	want, err := New(sanitizeOptions(fixtureOptions))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	if err := fillFixture(want); err != nil {
+		t.Fatal("fillFixture:", err)
+	}
+	f, err := testFile(gostFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := f.Bytes()
+	db, err := Open(bytes.NewReader(data), sanitizeOptions(fixtureOptions))
+	if err != nil {
+		t.Fatalf("Open(%q): %v", gostFixture, err)
+	}
+	if got, want := dumpDatabase(db), dumpDatabase(want); got != want {
+		t.Fatalf("%s contents:\n%s\nwant:\n%s", gostFixture, got, want)
+	}
+
+	// Writing it back unchanged, with the same IV, gives the same file.
+	opts := sanitizeOptions(fixtureOptions)
+	opts.StaticIVForTesting = true
+	db, err = Open(bytes.NewReader(data), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := new(bytes.Buffer)
+	if err := db.Write(out); err != nil {
+		t.Fatal("Write:", err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("unchanged %s written back differs", gostFixture)
+	}
+
+	// Modifications survive a save.
+	e := db.FindGroupPath("Internet").Entry(0)
+	e.AddRevision()
+	e.Password = "new password"
+	g, err := db.MkdirAll("Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.NewEntry(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Root().RemoveSubgroupAll(db.FindGroupPath("Home")); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := db.Write(out); err != nil {
+		t.Fatal("Write:", err)
+	}
+	rdb, err := Open(bytes.NewReader(out.Bytes()), sanitizeOptions(fixtureOptions))
+	if err != nil {
+		t.Fatal("Open after modifying:", err)
+	}
+	if got, want := dumpDatabase(rdb), dumpDatabase(db); got != want {
+		t.Errorf("modified %s reopened:\n%s\nwant:\n%s", gostFixture, got, want)
+	}
+}
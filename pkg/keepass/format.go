@@ -0,0 +1,73 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Second signature words of KeePass 2 files.  The first word is magic1,
+// shared with KeePass 1.
+const (
+	kdbxMagic2Pre = 0xb54bfb66 // pre-release KeePass 2
+	kdbxMagic2    = 0xb54bfb67
+)
+
+// A FormatError is returned by Open for a file in a format that it
+// recognizes but can't read, like KeePass 2's KDBX.  Files that Open
+// doesn't recognize at all still get ErrWrongSignature.
+type FormatError struct {
+	Format string // like "KDBX 4.0"
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("keepass: file is %s, not a KeePass 1 database with GOST encryption", e.Format)
+}
+
+// sniffFormat returns a *FormatError for a file starting with prefix if it
+// is in a format Open can't read, or nil if it may be a database Open can.
+func sniffFormat(prefix []byte) error {
+	switch {
+	case bytes.HasPrefix(prefix, []byte("gostpass-paper-")):
+		return &FormatError{Format: "a gostpass paper backup (use restore-paper)"}
+	case bytes.HasPrefix(prefix, []byte("PWS3")):
+		return &FormatError{Format: "a Password Safe v3 database"}
+	case len(prefix) < 12:
+		return nil
+	}
+	if binary.LittleEndian.Uint32(prefix) != magic1 {
+		return nil
+	}
+	// KDBX has the version right after the signature; KDB has the
+	// encryption flags first.
+	version := binary.LittleEndian.Uint32(prefix[8:])
+	switch binary.LittleEndian.Uint32(prefix[4:]) {
+	case magic2:
+		if len(prefix) < 16 {
+			return nil
+		}
+		version = binary.LittleEndian.Uint32(prefix[12:])
+		if version&fileVersionCriticalMask != fileVersion&fileVersionCriticalMask {
+			return &FormatError{Format: fmt.Sprintf("KDB %d.%d", version>>16, version&0xffff)}
+		}
+	case kdbxMagic2:
+		return &FormatError{Format: fmt.Sprintf("KDBX %d.%d", version>>16, version&0xffff)}
+	case kdbxMagic2Pre:
+		return &FormatError{Format: "a pre-release KDBX"}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestOpen_Format(t *testing.T) {
+	header := func(sig2, version uint32) []byte {
+		b := make([]byte, headerSize+64)
+		binary.LittleEndian.PutUint32(b, magic1)
+		binary.LittleEndian.PutUint32(b[4:], sig2)
+		if sig2 == magic2 {
+			binary.LittleEndian.PutUint32(b[12:], version)
+		} else {
+			binary.LittleEndian.PutUint32(b[8:], version)
+		}
+		return b
+	}
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+	}{
+		{"KDBX4", header(kdbxMagic2, 0x00040000), "KDBX 4.0"},
+		{"KDBX3", header(kdbxMagic2, 0x00030001), "KDBX 3.1"},
+		{"KDBXPre", header(kdbxMagic2Pre, 0x00020000), "a pre-release KDBX"},
+		{"KDBTooNew", header(magic2, 0x00040000), "KDB 4.0"},
+		// Short files are still recognized.
+		{"ShortKDBX", header(kdbxMagic2, 0x00040001)[:12], "KDBX 4.1"},
+		{"Paper", []byte("gostpass-paper-1 1234 abcd\n"), "a gostpass paper backup (use restore-paper)"},
+		{"PasswordSafe", append([]byte("PWS3"), make([]byte, 200)...), "a Password Safe v3 database"},
+	}
+	for _, test := range tests {
+		_, err := Open(bytes.NewReader(test.data), &Options{Password: "swordfish"})
+		fe, ok := err.(*FormatError)
+		if !ok {
+			t.Errorf("%s: Open error = %v; want *FormatError", test.name, err)
+			continue
+		}
+		if fe.Format != test.format {
+			t.Errorf("%s: format = %q; want %q", test.name, fe.Format, test.format)
+		}
+	}
+
+	if _, err := Open(bytes.NewReader(make([]byte, headerSize+16)), nil); err != ErrWrongSignature {
+		t.Errorf("Open(zeros) error = %v; want %v", err, ErrWrongSignature)
+	}
+
+	// Databases this package writes open as before.
+	db, err := New(sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(buf, &Options{Password: "swordfish"}); err != nil {
+		t.Errorf("Open(KDB) error: %v", err)
+	}
+}
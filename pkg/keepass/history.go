@@ -0,0 +1,135 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// historyStream is the name of the meta-stream that stores entry history.
+// Like custom data, KDB1 has no place for it, so it is kept as JSON.
+const historyStream = "GOSTPASS_HISTORY"
+
+// ErrHistoryUnwritable is returned by Write if revisions were added to a
+// database whose history meta-stream could not be decoded.  The stream is
+// kept as it was read, so the new revisions would be lost.
+var ErrHistoryUnwritable = errors.New("keepass: history stream is undecodable, so history changes cannot be saved")
+
+// A Revision is a past state of an entry.  Attachment data is not kept.
+type Revision struct {
+	Title          string    `json:"title,omitempty"`
+	URL            string    `json:"url,omitempty"`
+	Username       string    `json:"username,omitempty"`
+	Password       string    `json:"password,omitempty"`
+	Notes          string    `json:"notes,omitempty"`
+	Icon           Icon      `json:"icon,omitempty"`
+	AttachmentName string    `json:"attachment_name,omitempty"`
+	Modified       time.Time `json:"modified"`
+}
+
+// Revision returns the entry's current state.
+func (e *Entry) Revision() Revision {
+	return Revision{
+		Title:          e.Title,
+		URL:            e.URL,
+		Username:       e.Username,
+		Password:       e.Password,
+		Notes:          e.Notes,
+		Icon:           e.Icon,
+		AttachmentName: e.Attachment.Name,
+		Modified:       e.LastModificationTime,
+	}
+}
+
+// AddRevision appends the entry's current state to its history.  Call it
+// before modifying the entry.
+func (e *Entry) AddRevision() {
+	e.History = append(e.History, e.Revision())
+}
+
+// Restore sets the entry's fields to those of rev, keeping the current
+// attachment.  The current state is added to the history first.
+func (e *Entry) Restore(rev Revision) {
+	e.AddRevision()
+	e.Title = rev.Title
+	e.URL = rev.URL
+	e.Username = rev.Username
+	e.Password = rev.Password
+	e.Notes = rev.Notes
+	e.Icon = rev.Icon
+}
+
+// readHistory distributes the history meta-stream to the entries.  If the
+// stream can't be decoded, it is left untouched and will be written back
+// as it was.
+func (db *Database) readHistory() {
+	data := db.MetaStream(historyStream)
+	if data == nil {
+		return
+	}
+	var hist map[string][]Revision
+	if err := json.Unmarshal(data, &hist); err != nil {
+		db.keepHistory = true
+		return
+	}
+	for _, e := range db.entries {
+		e.History = hist[e.UUID.String()]
+	}
+}
+
+// writeHistory collects entry history into its meta-stream.
+func (db *Database) writeHistory() error {
+	if db.keepHistory {
+		// Nothing was read from the stream, so any history now present
+		// was added since.
+		for _, e := range db.entries {
+			if len(e.History) > 0 {
+				return ErrHistoryUnwritable
+			}
+		}
+		return nil
+	}
+	hist := make(map[string][]Revision)
+	for _, e := range db.entries {
+		if len(e.History) > 0 {
+			hist[e.UUID.String()] = e.History
+		}
+	}
+	if len(hist) == 0 {
+		return db.SetMetaStream(historyStream, nil)
+	}
+	data, err := json.Marshal(hist)
+	if err != nil {
+		return err
+	}
+	return db.SetMetaStream(historyStream, data)
+}
+
+// PasswordChanged returns when the entry's current password was set, based
+// on its history.  Without history showing a change, it is the entry's
+// creation time.
+func (e *Entry) PasswordChanged() time.Time {
+	for i := len(e.History) - 1; i >= 0; i-- {
+		if e.History[i].Password != e.Password {
+			if i+1 < len(e.History) {
+				return e.History[i+1].Modified
+			}
+			return e.LastModificationTime
+		}
+	}
+	return e.CreationTime
+}
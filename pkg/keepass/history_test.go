@@ -0,0 +1,136 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	opts := &Options{Password: "swordfish", KeyRounds: 1000}
+	db, err := New(sanitizeOptions(opts))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	e, err := db.Root().NewSubgroup().NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	t0 := time.Date(2019, time.July, 1, 22, 0, 0, 0, time.UTC)
+	e.Title = "Mail"
+	e.Password = "hunter2"
+	e.LastModificationTime = t0
+	e.AddRevision()
+	e.Password = "correct horse"
+	e.LastModificationTime = t0.Add(time.Hour)
+	want := []Revision{{Title: "Mail", Password: "hunter2", Modified: t0}}
+	if !reflect.DeepEqual(e.History, want) {
+		t.Fatalf("History after AddRevision = %+v; want %+v", e.History, want)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+	rdb, err := Open(buf, opts)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	if n := len(rdb.Entries()); n != 1 {
+		t.Fatalf("len(rdb.Entries()) = %d; want 1", n)
+	}
+	re := rdb.Entries()[0]
+	if !reflect.DeepEqual(re.History, want) {
+		t.Errorf("History after round trip = %+v; want %+v", re.History, want)
+	}
+
+	re.Restore(re.History[0])
+	if re.Password != "hunter2" {
+		t.Errorf("Password after Restore = %q; want %q", re.Password, "hunter2")
+	}
+	if n := len(re.History); n != 2 || re.History[1].Password != "correct horse" {
+		t.Errorf("History after Restore = %+v; want current state appended", re.History)
+	}
+
+	copied, err := db.Root().Group(0).ImportEntry(re)
+	if err != nil {
+		t.Fatal("ImportEntry:", err)
+	}
+	copied.History[0].Password = "changed"
+	if re.History[0].Password != "hunter2" {
+		t.Error("ImportEntry shares history with the source entry")
+	}
+}
+
+func TestHistory_Undecodable(t *testing.T) {
+	opts := &Options{Password: "swordfish", KeyRounds: 1000}
+	db, err := New(sanitizeOptions(opts))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	e, err := db.Root().NewSubgroup().NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	if err := db.SetMetaStream(historyStream, []byte("{not json")); err != nil {
+		t.Fatal("SetMetaStream:", err)
+	}
+	db.keepHistory = true
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+
+	rdb, err := Open(buf, opts)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	if err := rdb.Write(new(bytes.Buffer)); err != nil {
+		t.Errorf("Write unchanged: %v", err)
+	}
+	rdb.Find(e.UUID).AddRevision()
+	if err := rdb.Write(new(bytes.Buffer)); err != ErrHistoryUnwritable {
+		t.Errorf("Write after adding a revision = %v; want %v", err, ErrHistoryUnwritable)
+	}
+	if got := string(rdb.MetaStream(historyStream)); got != "{not json" {
+		t.Errorf("history stream = %q; want it kept as read", got)
+	}
+}
+
+func TestPasswordChanged(t *testing.T) {
+	t0 := time.Date(2019, time.July, 1, 22, 0, 0, 0, time.UTC)
+	e := &Entry{Password: "c"}
+	e.CreationTime = t0
+	e.LastModificationTime = t0.Add(4 * time.Hour)
+	if got := e.PasswordChanged(); !got.Equal(t0) {
+		t.Errorf("PasswordChanged() without history = %v; want %v", got, t0)
+	}
+	e.History = []Revision{
+		{Password: "a", Modified: t0},
+		{Password: "b", Modified: t0.Add(time.Hour)},
+		{Password: "c", Modified: t0.Add(2 * time.Hour)},
+		{Password: "c", Modified: t0.Add(3 * time.Hour)},
+	}
+	if got, want := e.PasswordChanged(), t0.Add(2*time.Hour); !got.Equal(want) {
+		t.Errorf("PasswordChanged() = %v; want %v", got, want)
+	}
+	e.Password = "d"
+	if got, want := e.PasswordChanged(), e.LastModificationTime; !got.Equal(want) {
+		t.Errorf("PasswordChanged() after change = %v; want %v", got, want)
+	}
+}
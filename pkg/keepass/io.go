@@ -21,6 +21,9 @@ import (
 	"time"
 )
 
+// fieldHeaderSize is the size of a field's type and length.
+const fieldHeaderSize = 6
+
 type fieldReader struct {
 	r   reader
 	buf []byte
@@ -41,7 +44,15 @@ func (fr *fieldReader) next() (key uint16, val []byte, err error) {
 		return 0, nil, fr.r.err
 	}
 	key = fr.r.readUint16()
-	sz := int(fr.r.readUint32())
+	n := fr.r.readUint32()
+	// Don't allocate for a size that runs past the end of the data.
+	if l, ok := fr.r.r.(interface{ Len() int }); ok && fr.r.err == nil && int64(n) > int64(l.Len()) {
+		fr.r.err = io.ErrUnexpectedEOF
+	}
+	if fr.r.err != nil {
+		return 0, nil, fr.r.err
+	}
+	sz := int(n)
 	if cap(fr.buf) < sz {
 		fr.buf = make([]byte, sz)
 	}
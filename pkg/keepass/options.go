@@ -16,6 +16,7 @@ package keepass
 
 import (
 	"crypto/rand"
+	"fmt"
 	"io"
 
 	"github.com/pedroalbanese/gostpass/pkg/kdbcrypt"
@@ -31,6 +32,11 @@ type Options struct {
 	// KeyFile is an optional binary file to encrypt/decrypt the database.
 	KeyFile io.Reader
 
+	// If Credentials is non-nil, it will be used instead of Password/KeyFile.
+	// Password and KeyFile are equivalent to
+	// NewCredentials(Password, KeyFile).
+	Credentials *Credentials
+
 	// If ComputedKey is non-nil, it will be used instead of Password/KeyFile
 	// to decrypt an existing database.
 	ComputedKey kdbcrypt.ComputedKey
@@ -44,6 +50,22 @@ type Options struct {
 	// a reasonable default is used.  Only used for creation.
 	KeyRounds int
 
+	// MaxKeyRounds is the most key transform rounds that Open accepts from
+	// a file, so that a crafted file can't make opening it take hours.  If
+	// zero, DefaultMaxKeyRounds is used; if negative, there is no limit.
+	// Only used for opening.
+	MaxKeyRounds int
+
+	// MaxFileSize, MaxGroups, MaxEntries and MaxAttachmentSize bound what
+	// Open accepts from a file, so that a crafted file can't exhaust memory.
+	// Open fails with a *LimitError for a file over a limit.  If zero, the
+	// matching default below is used; if negative, there is no limit.
+	// Only used for opening.
+	MaxFileSize       int64
+	MaxGroups         int
+	MaxEntries        int
+	MaxAttachmentSize int
+
 	// Cipher to encrypt with.  Defaults to Kuznechik.
 	// Only used for creation.
 	Cipher kdbcrypt.Cipher
@@ -62,11 +84,16 @@ func (opts *Options) initCryptParams(p *kdbcrypt.Params) error {
 		// Error checked after seeds, since this is uncommon to set in prod.
 	}
 	var err error
-	p.Key.KeyFileHash, err = opts.getKeyFileHash()
-	if err != nil {
+	if p.Key.Composite, err = opts.getCompositeKey(); err != nil {
 		return err
 	}
-	p.Key.Password = []byte(opts.getPassword())
+	if p.Key.Composite == nil {
+		p.Key.KeyFileHash, err = opts.getKeyFileHash()
+		if err != nil {
+			return err
+		}
+		p.Key.Password = []byte(opts.getPassword())
+	}
 	p.Key.TransformRounds = uint32(opts.getKeyRounds())
 	r.readFull(p.Key.MasterSeed[:])
 	r.readFull(p.Key.TransformSeed[:])
@@ -84,6 +111,13 @@ func (opts *Options) getPassword() string {
 	return opts.Password
 }
 
+func (opts *Options) getCompositeKey() ([]byte, error) {
+	if opts == nil || opts.Credentials == nil {
+		return nil, nil
+	}
+	return opts.Credentials.ComposeKey()
+}
+
 func (opts *Options) getKeyFileHash() ([]byte, error) {
 	if opts == nil || opts.KeyFile == nil {
 		return nil, nil
@@ -106,6 +140,96 @@ func (opts *Options) getKeyRounds() uint32 {
 	return uint32(opts.KeyRounds)
 }
 
+// DefaultMaxKeyRounds is the default for Options.MaxKeyRounds, 50 times the
+// default number of rounds.
+const DefaultMaxKeyRounds = 500000000
+
+// checkKeyRounds returns a *KeyRoundsError if rounds is over the limit.
+func (opts *Options) checkKeyRounds(rounds uint32) error {
+	max := DefaultMaxKeyRounds
+	if opts != nil && opts.MaxKeyRounds != 0 {
+		max = opts.MaxKeyRounds
+	}
+	if max > 0 && uint64(rounds) > uint64(max) {
+		return &KeyRoundsError{Rounds: rounds, Max: max}
+	}
+	return nil
+}
+
+// A KeyRoundsError is returned by Open for a database that asks for more
+// key transform rounds than Options.MaxKeyRounds allows.
+type KeyRoundsError struct {
+	Rounds uint32
+	Max    int
+}
+
+func (e *KeyRoundsError) Error() string {
+	return fmt.Sprintf("keepass: database asks for %d key transform rounds, more than the limit of %d", e.Rounds, e.Max)
+}
+
+// Defaults for the limits in Options.
+const (
+	DefaultMaxFileSize       = 1 << 30
+	DefaultMaxGroups         = 1000000
+	DefaultMaxEntries        = 1000000
+	DefaultMaxAttachmentSize = 256 << 20
+)
+
+// A LimitError is returned by Open for a database that is over one of the
+// limits in Options.
+type LimitError struct {
+	What string // "bytes", "groups", "entries" or "bytes in an attachment"
+	Max  int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("keepass: database has more than the limit of %d %s", e.Max, e.What)
+}
+
+// limit returns the limit set by v, or def if v is zero, or 0 for no
+// limit if v is negative.
+func limit(v, def int64) int64 {
+	switch {
+	case v == 0:
+		return def
+	case v < 0:
+		return 0
+	default:
+		return v
+	}
+}
+
+func (opts *Options) maxFileSize() int64 {
+	if opts == nil {
+		return DefaultMaxFileSize
+	}
+	return limit(opts.MaxFileSize, DefaultMaxFileSize)
+}
+
+// checkCounts returns a *LimitError if a header's counts are over the
+// limits.
+func (opts *Options) checkCounts(numGroups, numEntries uint32) error {
+	var maxGroups, maxEntries int64 = DefaultMaxGroups, DefaultMaxEntries
+	if opts != nil {
+		maxGroups = limit(int64(opts.MaxGroups), DefaultMaxGroups)
+		maxEntries = limit(int64(opts.MaxEntries), DefaultMaxEntries)
+	}
+	if maxGroups > 0 && int64(numGroups) > maxGroups {
+		return &LimitError{What: "groups", Max: maxGroups}
+	}
+	if maxEntries > 0 && int64(numEntries) > maxEntries {
+		return &LimitError{What: "entries", Max: maxEntries}
+	}
+	return nil
+}
+
+func (opts *Options) maxAttachmentSize() int64 {
+	if opts == nil {
+		return DefaultMaxAttachmentSize
+	}
+	return limit(int64(opts.MaxAttachmentSize), DefaultMaxAttachmentSize)
+}
+
 func (opts *Options) getCipher() kdbcrypt.Cipher {
 	if opts == nil {
 		// Return the default cipher
@@ -0,0 +1,40 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import "strings"
+
+// Search returns the entries whose title, username, URL or notes contain
+// every word of query, ignoring case.  An empty query matches all entries.
+func (db *Database) Search(query string) []*Entry {
+	words := strings.Fields(strings.ToLower(query))
+	var found []*Entry
+	for _, e := range db.entries {
+		if e.matches(words) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+func (e *Entry) matches(words []string) bool {
+	text := strings.ToLower(strings.Join([]string{e.Title, e.Username, e.URL, e.Notes}, "\n"))
+	for _, w := range words {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import "testing"
+
+func TestSearch(t *testing.T) {
+	db, err := New(sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	g := db.Root().NewSubgroup()
+	mail, err := g.NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	mail.Title, mail.Username = "Mail", "bob"
+	bank, err := g.NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	bank.Title, bank.URL, bank.Notes = "Bank", "https://bank.example", "Account for bob"
+	bank.Password = "mail"
+
+	tests := []struct {
+		query string
+		want  []*Entry
+	}{
+		{"", []*Entry{mail, bank}},
+		{"MAIL bob", []*Entry{mail}},
+		{"bob", []*Entry{mail, bank}},
+		{"  example\tACCOUNT ", []*Entry{bank}},
+		{"mail bank", nil},
+	}
+	for _, test := range tests {
+		got := db.Search(test.query)
+		if len(got) != len(test.want) {
+			t.Errorf("Search(%q) returned %d entries; want %d", test.query, len(got), len(test.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("Search(%q)[%d] = %s; want %s", test.query, i, got[i].Title, test.want[i].Title)
+			}
+		}
+	}
+}
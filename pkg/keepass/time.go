@@ -0,0 +1,80 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// kdbxEpoch is the zero point of KDBX 4 binary times: 0001-01-01 UTC, the
+// start of the .NET DateTime range.
+var kdbxEpoch = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ParseKDBXTime parses a time as found in KDBX XML: either an ISO 8601
+// string (KDBX 3.1 and earlier) or base64 of a little-endian int64 number
+// of seconds since 0001-01-01 UTC (KDBX 4).  The result is in UTC.
+func ParseKDBXTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(b) != 8 {
+		return time.Time{}, fmt.Errorf("keepass: parse time %q: not ISO 8601 or KDBX 4 binary", s)
+	}
+	secs := int64(binary.LittleEndian.Uint64(b))
+	if secs < 0 {
+		return time.Time{}, errors.New("keepass: parse time: negative KDBX 4 time")
+	}
+	// Adding in two steps avoids overflowing time.Duration.
+	days := secs / (24 * 60 * 60)
+	rest := secs % (24 * 60 * 60)
+	return kdbxEpoch.AddDate(0, 0, int(days)).Add(time.Duration(rest) * time.Second), nil
+}
+
+// FormatKDBXTime formats t in UTC for KDBX XML, either as KDBX 4 base64
+// seconds (if kdbx4 is true) or as an ISO 8601 string.  Sub-second
+// precision is dropped, like KeePass does.
+func FormatKDBXTime(t time.Time, kdbx4 bool) string {
+	t = t.UTC().Truncate(time.Second)
+	if !kdbx4 {
+		return t.Format(time.RFC3339)
+	}
+	secs := t.Unix() - kdbxEpoch.Unix()
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(secs))
+	return base64.StdEncoding.EncodeToString(b[:])
+}
+
+// UTC returns a copy of ti with all times converted to UTC.  Unset (zero)
+// times stay unset.
+func (ti TimeInfo) UTC() TimeInfo {
+	return TimeInfo{
+		LastModificationTime: utcOrZero(ti.LastModificationTime),
+		CreationTime:         utcOrZero(ti.CreationTime),
+		LastAccessTime:       utcOrZero(ti.LastAccessTime),
+		ExpiryTime:           utcOrZero(ti.ExpiryTime),
+	}
+}
+
+func utcOrZero(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.UTC()
+}
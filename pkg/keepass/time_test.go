@@ -0,0 +1,85 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"testing"
+	"time"
+)
+
+var kdbxTimeTests = []struct {
+	t      time.Time
+	iso    string
+	binary string
+}{
+	{
+		t:      time.Date(2019, time.July, 1, 22, 0, 0, 0, time.UTC),
+		iso:    "2019-07-01T22:00:00Z",
+		binary: "YHms1A4AAAA=", // 63697615200 seconds
+	},
+	{
+		t:      time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC),
+		iso:    "0001-01-01T00:00:00Z",
+		binary: "AAAAAAAAAAA=",
+	},
+}
+
+func TestParseKDBXTime(t *testing.T) {
+	for _, test := range kdbxTimeTests {
+		for _, s := range []string{test.iso, test.binary} {
+			got, err := ParseKDBXTime(s)
+			if err != nil {
+				t.Errorf("ParseKDBXTime(%q): %v", s, err)
+				continue
+			}
+			if !got.Equal(test.t) || got.Location() != time.UTC {
+				t.Errorf("ParseKDBXTime(%q) = %v; want %v", s, got, test.t)
+			}
+		}
+	}
+	if got, err := ParseKDBXTime("2019-07-02T01:00:00+03:00"); err != nil || got.Location() != time.UTC || !got.Equal(kdbxTimeTests[0].t) {
+		t.Errorf("ParseKDBXTime with offset = %v, %v; want %v in UTC", got, err, kdbxTimeTests[0].t)
+	}
+	for _, bad := range []string{"", "yesterday", "AAAA"} {
+		if _, err := ParseKDBXTime(bad); err == nil {
+			t.Errorf("ParseKDBXTime(%q) did not return an error", bad)
+		}
+	}
+}
+
+func TestFormatKDBXTime(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	for _, test := range kdbxTimeTests {
+		in := test.t.In(msk).Add(500 * time.Millisecond)
+		if got := FormatKDBXTime(in, false); got != test.iso {
+			t.Errorf("FormatKDBXTime(%v, false) = %q; want %q", in, got, test.iso)
+		}
+		if got := FormatKDBXTime(in, true); got != test.binary {
+			t.Errorf("FormatKDBXTime(%v, true) = %q; want %q", in, got, test.binary)
+		}
+	}
+}
+
+func TestTimeInfoUTC(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	ti := TimeInfo{CreationTime: time.Date(2019, time.July, 2, 1, 0, 0, 0, msk)}
+	got := ti.UTC()
+	if got.CreationTime.Location() != time.UTC || !got.CreationTime.Equal(ti.CreationTime) {
+		t.Errorf("UTC().CreationTime = %v; want %v in UTC", got.CreationTime, ti.CreationTime)
+	}
+	if !got.ExpiryTime.IsZero() || got.Expires() {
+		t.Errorf("UTC().ExpiryTime = %v; want zero", got.ExpiryTime)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Path returns the slash-separated names of the group and its ancestors,
+// like "Internet/Mail".  The root's path is empty.
+func (g *Group) Path() string {
+	var names []string
+	for ; g != nil && !g.IsRoot(); g = g.Parent() {
+		names = append(names, g.Name)
+	}
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return strings.Join(names, "/")
+}
+
+// splitPath splits a slash-separated group path into names, ignoring
+// leading, trailing and repeated slashes.
+func splitPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// FindGroupPath returns the group at path, as returned by Group.Path, or
+// nil if there is none.  If several sibling groups have the same name, the
+// first is used.  The empty path is the root.
+func (db *Database) FindGroupPath(path string) *Group {
+	g := db.root
+	for _, name := range splitPath(path) {
+		g = g.subgroupNamed(name)
+		if g == nil {
+			return nil
+		}
+	}
+	return g
+}
+
+func (g *Group) subgroupNamed(name string) *Group {
+	for _, sub := range g.groups {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// MkdirAll returns the group at path, creating it and any missing
+// ancestors.
+func (db *Database) MkdirAll(path string) (*Group, error) {
+	names := splitPath(path)
+	if len(names) == 0 {
+		return nil, errors.New("keepass: empty group path")
+	}
+	g := db.root
+	for _, name := range names {
+		sub := g.subgroupNamed(name)
+		if sub == nil {
+			sub = g.NewSubgroup()
+			sub.Name = name
+			now := time.Now()
+			sub.CreationTime = now
+			sub.LastModificationTime = now
+			sub.LastAccessTime = now
+		}
+		g = sub
+	}
+	return g, nil
+}
+
+// RemoveSubgroupAll removes sub, its entries and all of its descendants.
+func (g *Group) RemoveSubgroupAll(sub *Group) error {
+	for _, e := range sub.Entries() {
+		if err := sub.RemoveEntry(e); err != nil {
+			return err
+		}
+	}
+	for _, child := range sub.Groups() {
+		if err := sub.RemoveSubgroupAll(child); err != nil {
+			return err
+		}
+	}
+	return g.RemoveSubgroup(sub)
+}
+
+// recycleBinKey is the database custom data key that holds the ID of the
+// recycle bin group.
+const recycleBinKey = "gostpass.recycle_bin"
+
+// RecycleBinName is the name given to a new recycle bin group.
+const RecycleBinName = "Recycle Bin"
+
+// RecycleBin returns the group that deleted groups and entries are moved
+// to, or nil if there is none yet.
+func (db *Database) RecycleBin() *Group {
+	id, ok := db.customData.Int(recycleBinKey)
+	if !ok || id < 0 || id > 0xffffffff {
+		return nil
+	}
+	g := db.groups[uint32(id)]
+	if g == nil || g.db != db || g.IsRoot() {
+		return nil
+	}
+	return g
+}
+
+func (db *Database) recycleBin() *Group {
+	if bin := db.RecycleBin(); bin != nil {
+		return bin
+	}
+	bin := db.root.NewSubgroup()
+	bin.Name = RecycleBinName
+	now := time.Now()
+	bin.CreationTime = now
+	bin.LastModificationTime = now
+	bin.LastAccessTime = now
+	db.customData.SetInt(recycleBinKey, int64(bin.ID))
+	return bin
+}
+
+// InRecycleBin reports whether g is the recycle bin or inside it.
+func (g *Group) InRecycleBin() bool {
+	bin := g.db.RecycleBin()
+	return bin != nil && (g == bin || g.hasAncestor(bin))
+}
+
+// RecycleGroup moves sub and everything in it to the recycle bin, creating
+// the bin if necessary.  If sub is already in the recycle bin, it is
+// removed for good.  The recycle bin itself can't be recycled.
+func (db *Database) RecycleGroup(sub *Group) error {
+	if sub.IsRoot() {
+		return errors.New("keepass: can't delete root group")
+	}
+	if sub == db.RecycleBin() {
+		return errors.New("keepass: can't recycle the recycle bin")
+	}
+	if sub.InRecycleBin() {
+		return sub.Parent().RemoveSubgroupAll(sub)
+	}
+	return sub.SetParent(db.recycleBin())
+}
+
+// RecycleEntry moves e to the recycle bin, creating the bin if necessary.
+// If e is already in the recycle bin, it is removed for good.
+func (db *Database) RecycleEntry(e *Entry) error {
+	parent := e.Parent()
+	if parent.InRecycleBin() {
+		return parent.RemoveEntry(e)
+	}
+	return e.SetParent(db.recycleBin())
+}
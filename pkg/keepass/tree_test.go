@@ -0,0 +1,117 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGroupPaths(t *testing.T) {
+	db, err := New(sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1}))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	vpn, err := db.MkdirAll("/Work//VPN/")
+	if err != nil {
+		t.Fatal("MkdirAll:", err)
+	}
+	if p := vpn.Path(); p != "Work/VPN" {
+		t.Errorf("vpn.Path() = %q; want %q", p, "Work/VPN")
+	}
+	if g, err := db.MkdirAll("Work/VPN"); err != nil || g != vpn {
+		t.Errorf("MkdirAll existing = %v, %v; want vpn, <nil>", g, err)
+	}
+	if g := db.FindGroupPath("Work/VPN"); g != vpn {
+		t.Errorf("FindGroupPath(%q) = %v; want vpn", "Work/VPN", g)
+	}
+	if g := db.FindGroupPath(""); g != db.Root() {
+		t.Errorf("FindGroupPath(\"\") = %v; want root", g)
+	}
+	if g := db.FindGroupPath("Work/Mail"); g != nil {
+		t.Errorf("FindGroupPath(%q) = %v; want <nil>", "Work/Mail", g)
+	}
+	if _, err := db.MkdirAll("/"); err == nil {
+		t.Error("MkdirAll(\"/\") = <nil>; want error")
+	}
+}
+
+func TestRecycle(t *testing.T) {
+	opts := &Options{Password: "swordfish", KeyRounds: 1}
+	db, err := New(sanitizeOptions(opts))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	work, err := db.MkdirAll("Work/VPN")
+	if err != nil {
+		t.Fatal("MkdirAll:", err)
+	}
+	work = work.Parent()
+	e, err := work.NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	if db.RecycleBin() != nil {
+		t.Fatal("new database has a recycle bin")
+	}
+	if err := db.RecycleGroup(work); err != nil {
+		t.Fatal("RecycleGroup:", err)
+	}
+	bin := db.RecycleBin()
+	if bin == nil || work.Parent() != bin {
+		t.Fatalf("after RecycleGroup, work.Parent() = %v; want recycle bin %v", work.Parent(), bin)
+	}
+	if !work.InRecycleBin() || !e.Parent().InRecycleBin() {
+		t.Error("recycled group is not in the recycle bin")
+	}
+	if err := db.RecycleGroup(bin); err == nil {
+		t.Error("RecycleGroup(bin) = <nil>; want error")
+	}
+
+	// The recycle bin is remembered across saves.
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+	db, err = Open(buf, opts)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	bin = db.RecycleBin()
+	if bin == nil || bin.Path() != RecycleBinName {
+		t.Fatalf("RecycleBin() after round trip = %v; want %q", bin, RecycleBinName)
+	}
+	work = db.FindGroupPath(RecycleBinName + "/Work")
+	if err := db.RecycleGroup(work); err != nil {
+		t.Fatal("RecycleGroup in bin:", err)
+	}
+	if n := bin.NGroups(); n != 0 {
+		t.Errorf("bin.NGroups() = %d after deleting from bin; want 0", n)
+	}
+	if n := len(db.Entries()); n != 0 {
+		t.Errorf("len(db.Entries()) = %d after deleting from bin; want 0", n)
+	}
+
+	e, err = db.Root().NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	if err := db.RecycleEntry(e); err != nil || e.Parent() != bin {
+		t.Errorf("RecycleEntry = %v, parent %v; want <nil>, recycle bin", err, e.Parent())
+	}
+	if err := db.RecycleEntry(e); err != nil || bin.NEntries() != 0 {
+		t.Errorf("RecycleEntry in bin = %v, %d entries left; want <nil>, 0", err, bin.NEntries())
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/pedroalbanese/gogost/gost34112012256"
+	"github.com/pedroalbanese/gostpass/pkg/kdbcrypt"
+	"github.com/pedroalbanese/gostpass/pkg/uuids"
+)
+
+// A Problem is a defect found by Verify.
+type Problem struct {
+	Where string // part of the database, like "header" or `entry 3 "Email"`
+	What  string
+}
+
+func (p Problem) String() string {
+	return p.Where + ": " + p.What
+}
+
+// Verify reads a database and reports every defect it finds, without
+// repairing anything.  Unlike Open, it does not stop at the first problem
+// nor fix up duplicate UUIDs or orphaned entries.
+//
+// KDB1 only protects the content with a single hash, so beyond the header
+// and that hash, damage is located by checking the structure: field sizes,
+// record counts, the group tree, entry UUIDs and group references, and
+// references from meta-streams.  A wrong key can't be told apart from
+// damaged content; both are reported as a content hash problem.
+//
+// The error is non-nil only if r can't be read.
+func Verify(r io.Reader, opts *Options) ([]Problem, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	v := new(verifier)
+	v.verify(data, opts)
+	return v.problems, nil
+}
+
+type verifier struct {
+	problems []Problem
+}
+
+func (v *verifier) add(where string, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{Where: where, What: fmt.Sprintf(format, args...)})
+}
+
+func (v *verifier) verify(data []byte, opts *Options) {
+	if len(data) < headerSize {
+		v.add("header", "file is %d bytes, shorter than the %d-byte header", len(data), headerSize)
+		return
+	}
+	var h header
+	if err := h.read(bytes.NewReader(data[:headerSize])); err != nil {
+		v.add("header", "%v", err)
+		return
+	}
+	if _, err := h.cipher(); err != nil {
+		v.add("header", "%v", err)
+		return
+	}
+	if h.transformRounds == 0 {
+		v.add("header", "key transform rounds is zero")
+	}
+	var p kdbcrypt.Params
+	var err error
+	if opts != nil && opts.ComputedKey != nil {
+		err = h.initComputedCryptParams(&p, opts.ComputedKey)
+	} else {
+		err = h.initCryptParams(&p, opts)
+	}
+	if err != nil {
+		v.add("credentials", "%v", err)
+		return
+	}
+
+	crypt := data[headerSize:]
+	if n := len(crypt) % kdbcrypt.BlockSize; n != 0 {
+		v.add("content", "encrypted size %d is not a multiple of %d; the file is truncated or has %d trailing bytes", len(crypt), kdbcrypt.BlockSize, n)
+		crypt = crypt[:len(crypt)-n]
+	}
+	dec, err := kdbcrypt.NewDecrypter(bytes.NewReader(crypt), &p)
+	if err != nil {
+		v.add("credentials", "%v", err)
+		return
+	}
+	hash := gost34112012256.New()
+	plain, err := ioutil.ReadAll(io.TeeReader(dec, hash))
+	if err != nil {
+		v.add("content", "decryption failed (wrong credentials or damaged final block): %v", err)
+	} else if !bytes.Equal(hash.Sum(nil), h.contentHash[:]) {
+		v.add("content", "content hash mismatch (wrong credentials or damaged data)")
+	} else {
+		v.verifyRecords(plain, int(h.numGroups), int(h.numEntries))
+		return
+	}
+	// The content is damaged or the key is wrong.  Only look further if
+	// the plaintext starts like a group record, which it wouldn't if the
+	// key were wrong.
+	if h.numGroups > 0 && (len(plain) < 6 || !plausibleGroupStart(plain, len(plain))) {
+		v.add("content", "content does not start with a group record; the credentials are probably wrong")
+		return
+	}
+	v.verifyRecords(plain, int(h.numGroups), int(h.numEntries))
+}
+
+// verifyRecords checks the decrypted group and entry records.
+func (v *verifier) verifyRecords(plain []byte, numGroups, numEntries int) {
+	r := bytes.NewReader(plain)
+	state := parseState{
+		groups:        make(map[uint32]*Group),
+		groupLevels:   make(map[*Group]uint16),
+		entryGroupIDs: make(map[*Entry]uint32),
+	}
+
+	var groups []*Group
+	groupIndex := make(map[uint32]int)
+	for i := 0; i < numGroups; i++ {
+		g := new(Group)
+		var dup []string
+		msgs, seen, ok := readRecord(r, func(key uint16, value []byte) error {
+			if key == groupIDField && len(value) == 4 {
+				id := binary.LittleEndian.Uint32(value)
+				if j, exists := groupIndex[id]; exists {
+					dup = append(dup, fmt.Sprintf("duplicate group ID %d (also group %d)", id, j))
+				} else {
+					groupIndex[id] = i
+				}
+			}
+			return g.readField(&state, key, value)
+		})
+		where := fmt.Sprintf("group %d %q", i, g.Name)
+		for _, m := range append(msgs, dup...) {
+			v.add(where, "%s", m)
+		}
+		if _, present := seen[groupIDField]; !present {
+			v.add(where, "missing group ID")
+		}
+		if _, present := seen[groupLevelField]; !present {
+			v.add(where, "missing group level")
+		}
+		groups = append(groups, g)
+		if !ok {
+			v.add("content", "header declares %d groups and %d entries, but content ends in group %d", numGroups, numEntries, i)
+			return
+		}
+	}
+	for i, g := range groups {
+		level := state.groupLevels[g]
+		if level == 0 {
+			continue
+		}
+		found := false
+		for j := i - 1; j >= 0; j-- {
+			if delta := int16(state.groupLevels[groups[j]] - level); delta == -1 {
+				found = true
+				break
+			} else if delta < 0 {
+				break
+			}
+		}
+		if !found {
+			v.add(fmt.Sprintf("group %d %q", i, g.Name), "level %d has no parent group at level %d", level, level-1)
+		}
+	}
+
+	entryIndex := make(map[uuids.UUID]int)
+	var meta []*Entry
+	for i := 0; i < numEntries; i++ {
+		e := new(Entry)
+		msgs, seen, ok := readRecord(r, func(key uint16, value []byte) error {
+			return e.readField(&state, key, value)
+		})
+		where := fmt.Sprintf("entry %d %q", i, e.Title)
+		isMeta := e.isMetaStream()
+		if isMeta {
+			where = fmt.Sprintf("meta-stream %d %q", i, e.Notes)
+			meta = append(meta, e)
+		}
+		for _, m := range msgs {
+			v.add(where, "%s", m)
+		}
+		valid, present := seen[entryUUIDField]
+		switch {
+		case !present:
+			v.add(where, "missing UUID")
+		case !valid:
+			// Already reported.
+		case e.UUID.IsZero():
+			v.add(where, "UUID is zero")
+		default:
+			if j, exists := entryIndex[e.UUID]; exists {
+				v.add(where, "duplicate UUID %v (also entry %d)", e.UUID, j)
+			} else {
+				entryIndex[e.UUID] = i
+			}
+		}
+		if _, present := seen[entryGroupIDField]; !present {
+			v.add(where, "missing group ID")
+		} else if gid, valid := state.entryGroupIDs[e]; !valid {
+			// Already reported.
+		} else if !isMeta && state.groups[gid] == nil {
+			v.add(where, "refers to nonexistent group ID %d", gid)
+		}
+		if e.Attachment.Name == "" && len(e.Attachment.Data) > 0 {
+			v.add(where, "attachment has %d bytes of data but no name, so it would be lost on save", len(e.Attachment.Data))
+		}
+		if !ok {
+			v.add("content", "header declares %d entries, but content ends in entry %d", numEntries, i)
+			return
+		}
+	}
+	if r.Len() > 0 {
+		v.add("content", "%d unexpected bytes after the last entry", r.Len())
+	}
+
+	for _, m := range meta {
+		switch m.Notes {
+		case customDataStream:
+			v.verifyCustomData(m.Attachment.Data, state.groups, entryIndex)
+		case historyStream:
+			v.verifyHistory(m.Attachment.Data, entryIndex)
+		}
+	}
+}
+
+// readRecord reads the fields of one group or entry up to its terminator,
+// passing each to readField.  It returns a message for each bad field and
+// the field types seen, mapped to whether they were valid.  ok is false if
+// the record is cut short.
+func readRecord(r io.Reader, readField func(key uint16, value []byte) error) (msgs []string, seen map[uint16]bool, ok bool) {
+	fr := newFieldReader(r)
+	seen = make(map[uint16]bool)
+	for {
+		key, value, err := fr.next()
+		if err == io.EOF && key == fieldTerminator {
+			return msgs, seen, true
+		} else if err != nil {
+			msgs = append(msgs, "record is truncated")
+			return msgs, seen, false
+		}
+		err = readField(key, value)
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+		seen[key] = err == nil
+	}
+}
+
+// verifyCustomData checks that the custom data meta-stream decodes and
+// refers only to groups and entries that exist.
+func (v *verifier) verifyCustomData(data []byte, groups map[uint32]*Group, entries map[uuids.UUID]int) {
+	where := fmt.Sprintf("meta-stream %q", customDataStream)
+	var cd customDataJSON
+	if err := json.Unmarshal(data, &cd); err != nil {
+		v.add(where, "cannot decode: %v", err)
+		return
+	}
+	gids := make([]int, 0, len(cd.Groups))
+	for id := range cd.Groups {
+		gids = append(gids, int(id))
+	}
+	sort.Ints(gids)
+	for _, id := range gids {
+		if groups[uint32(id)] == nil {
+			v.add(where, "custom data for nonexistent group ID %d", id)
+		}
+	}
+	eids := make([]string, 0, len(cd.Entries))
+	for s := range cd.Entries {
+		eids = append(eids, s)
+	}
+	sort.Strings(eids)
+	for _, s := range eids {
+		id, err := uuids.Parse(s)
+		if err != nil {
+			v.add(where, "custom data for malformed entry UUID %q", s)
+			continue
+		}
+		if _, exists := entries[id]; !exists {
+			v.add(where, "custom data for nonexistent entry %v", id)
+		}
+	}
+}
+
+// verifyHistory checks that the history meta-stream decodes, refers only
+// to entries that exist, and lists each entry's revisions oldest first.
+func (v *verifier) verifyHistory(data []byte, entries map[uuids.UUID]int) {
+	where := fmt.Sprintf("meta-stream %q", historyStream)
+	var hist map[string][]Revision
+	if err := json.Unmarshal(data, &hist); err != nil {
+		v.add(where, "cannot decode: %v", err)
+		return
+	}
+	eids := make([]string, 0, len(hist))
+	for s := range hist {
+		eids = append(eids, s)
+	}
+	sort.Strings(eids)
+	for _, s := range eids {
+		revs := hist[s]
+		id, err := uuids.Parse(s)
+		if err != nil {
+			v.add(where, "history for malformed entry UUID %q", s)
+			continue
+		}
+		if _, exists := entries[id]; !exists {
+			v.add(where, "history for nonexistent entry %v", id)
+		}
+		for i := 1; i < len(revs); i++ {
+			if revs[i].Modified.Before(revs[i-1].Modified) {
+				v.add(where, "history for entry %v: revision %d is older than revision %d", id, i, i-1)
+			}
+		}
+	}
+}
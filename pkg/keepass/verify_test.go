@@ -0,0 +1,192 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keepass
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/gogost/gost34112012256"
+	"github.com/pedroalbanese/gostpass/pkg/kdbcrypt"
+)
+
+func TestVerify_Clean(t *testing.T) {
+	opts := sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1000})
+	db, err := New(opts)
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	g := db.Root().NewSubgroup()
+	g.NewSubgroup()
+	e, err := g.NewEntry()
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	e.CustomData.Set("color", "red")
+	buf := new(bytes.Buffer)
+	if err := db.Write(buf); err != nil {
+		t.Fatal("Write:", err)
+	}
+	data := buf.Bytes()
+
+	problems, err := Verify(bytes.NewReader(data), &Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal("Verify:", err)
+	}
+	if len(problems) > 0 {
+		t.Errorf("Verify(clean database) = %v; want no problems", problems)
+	}
+
+	problems, err = Verify(bytes.NewReader(data), &Options{Password: "xyzzy"})
+	if err != nil {
+		t.Fatal("Verify:", err)
+	}
+	// Depending on the padding, a wrong key fails either decryption or
+	// the hash.
+	checkProblems(t, "wrong password", problems, []string{
+		"content: ",
+		"content: content does not start with a group record",
+	})
+
+	problems, err = Verify(bytes.NewReader(data[:len(data)-7]), &Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal("Verify:", err)
+	}
+	if len(problems) == 0 || !strings.Contains(problems[0].String(), "not a multiple of 16") {
+		t.Errorf("Verify(truncated database) = %v; want size problem first", problems)
+	}
+
+	problems, err = Verify(bytes.NewReader(data[:100]), &Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal("Verify:", err)
+	}
+	checkProblems(t, "truncated header", problems, []string{"header: file is 100 bytes"})
+}
+
+func TestVerify_Structure(t *testing.T) {
+	opts := sanitizeOptions(&Options{Password: "swordfish", KeyRounds: 1000})
+	db, err := New(opts)
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	groups := []*Group{
+		{ID: 1, Name: "Internet"},
+		{ID: 2, Name: "Orphan"},
+		{ID: 1, Name: "Twin"},
+	}
+	levels := []int{0, 2, 0}
+	entries := []*Entry{
+		{UUID: [16]byte{1}, Title: "Mail"},
+		{UUID: [16]byte{1}, Title: "Bank"},
+		{UUID: [16]byte{}, Title: "Blank"},
+		{UUID: [16]byte{2}, Title: "Lost"},
+	}
+	// Write never produces data without a name, so add it as a raw field.
+	entries[0].extra = []rawField{{key: entryAttachmentDataField, value: []byte("nameless")}}
+	entryGroups := []uint32{1, 1, 2, 99}
+
+	plain := new(bytes.Buffer)
+	for i, g := range groups {
+		if err := g.write(plain, levels[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, e := range entries {
+		if err := e.write(plain, entryGroups[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cd := &Entry{UUID: [16]byte{3}, Title: "Meta-Info", Username: "SYSTEM", URL: "$", Notes: customDataStream}
+	cd.Attachment.Name = "bin-stream"
+	cd.Attachment.Data = []byte(`{"groups":{"7":{"a":"b"}},"entries":{"09000000-0000-0000-0000-000000000000":{"a":"b"}}}`)
+	if err := cd.write(plain, 1); err != nil {
+		t.Fatal(err)
+	}
+	plain.WriteString("junk")
+
+	data := sealForTest(t, db, plain.Bytes(), len(groups), len(entries)+1)
+	problems, err := Verify(bytes.NewReader(data), &Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal("Verify:", err)
+	}
+	checkProblems(t, "damaged structure", problems, []string{
+		`group 2 "Twin": duplicate group ID 1 (also group 0)`,
+		`group 1 "Orphan": level 2 has no parent group at level 1`,
+		`entry 0 "Mail": attachment has 8 bytes of data but no name`,
+		`entry 1 "Bank": duplicate UUID 01000000-0000-0000-0000-000000000000 (also entry 0)`,
+		`entry 2 "Blank": UUID is zero`,
+		`entry 3 "Lost": refers to nonexistent group ID 99`,
+		`content: 4 unexpected bytes after the last entry`,
+		`meta-stream "GOSTPASS_CUSTOM_DATA": custom data for nonexistent group ID 7`,
+		`meta-stream "GOSTPASS_CUSTOM_DATA": custom data for nonexistent entry 09000000-0000-0000-0000-000000000000`,
+	})
+
+	data = sealForTest(t, db, plain.Bytes()[:plain.Len()-30], len(groups), len(entries)+1)
+	problems, err = Verify(bytes.NewReader(data), &Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal("Verify:", err)
+	}
+	if n := len(problems); n < 2 || problems[n-1].What != "header declares 5 entries, but content ends in entry 4" {
+		t.Errorf("Verify(truncated records) = %v; want premature end last", problems)
+	}
+}
+
+// checkProblems reports an error unless each problem starts with the
+// corresponding prefix in want.
+func checkProblems(t *testing.T, name string, problems []Problem, want []string) {
+	t.Helper()
+	if len(problems) != len(want) {
+		t.Errorf("%s: got %d problems; want %d", name, len(problems), len(want))
+	}
+	for i := 0; i < len(problems) && i < len(want); i++ {
+		if !strings.HasPrefix(problems[i].String(), want[i]) {
+			t.Errorf("%s: problem[%d] = %q; want prefix %q", name, i, problems[i], want[i])
+		}
+	}
+}
+
+// sealForTest encrypts arbitrary plaintext records with db's key, as Write
+// would.
+func sealForTest(t *testing.T, db *Database, plain []byte, ngroups, nentries int) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	enc, err := kdbcrypt.NewEncrypter(buf, &db.cparams)
+	if err != nil {
+		t.Fatal("NewEncrypter:", err)
+	}
+	enc.Write(plain)
+	if err := enc.Close(); err != nil {
+		t.Fatal("encrypt:", err)
+	}
+	h := header{
+		encryptionFlags: makeEncryptionFlags(&db.cparams) | 1,
+		masterSeed:      db.cparams.Key.MasterSeed,
+		encryptionIV:    db.cparams.IV,
+		numGroups:       uint32(ngroups),
+		numEntries:      uint32(nentries),
+		transformSeed:   db.cparams.Key.TransformSeed,
+		transformRounds: db.cparams.Key.TransformRounds,
+	}
+	ch := gost34112012256.New()
+	ch.Write(plain)
+	ch.Sum(h.contentHash[:0])
+	out := new(bytes.Buffer)
+	if err := h.write(out); err != nil {
+		t.Fatal("write header:", err)
+	}
+	out.Write(buf.Bytes())
+	return out.Bytes()
+}
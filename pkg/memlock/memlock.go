@@ -0,0 +1,23 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memlock keeps secrets held in process memory from reaching disk,
+// by locking memory against swapping and disabling core dumps where the
+// platform allows it.
+package memlock // import "github.com/pedroalbanese/gostpass/pkg/memlock"
+
+import "errors"
+
+// ErrUnsupported is returned on platforms without the needed system calls.
+var ErrUnsupported = errors.New("memlock: not supported on this platform")
@@ -0,0 +1,27 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package memlock
+
+import "syscall"
+
+// LockAll locks all current and future pages of the process into RAM, so
+// keys and decrypted entries are never swapped out.  It usually requires
+// CAP_IPC_LOCK or a large enough RLIMIT_MEMLOCK.
+func LockAll() error {
+	return syscall.Mlockall(syscall.MCL_CURRENT | syscall.MCL_FUTURE)
+}
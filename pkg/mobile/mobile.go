@@ -0,0 +1,343 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mobile is an API over package keepass that can be bound with
+// gomobile for Android and iOS:
+//
+//	gomobile bind -target=android github.com/pedroalbanese/gostpass/pkg/mobile
+//
+// gomobile can only bind a subset of Go types, so lists are returned as
+// types with Len and Get methods, entries are edited through setters, and
+// attachments are read in chunks.
+package mobile // import "github.com/pedroalbanese/gostpass/pkg/mobile"
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/uuids"
+)
+
+// Vault is an open database.
+type Vault struct {
+	db *keepass.Database
+}
+
+// New creates an empty database protected by password and an optional
+// key file.
+func New(password string, keyFile []byte) (*Vault, error) {
+	db, err := keepass.New(options(password, keyFile))
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{db}, nil
+}
+
+// Open decrypts a database from its encrypted bytes.  keyFile may be nil.
+func Open(data []byte, password string, keyFile []byte) (*Vault, error) {
+	db, err := keepass.Open(bytes.NewReader(data), options(password, keyFile))
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{db}, nil
+}
+
+// OpenFile decrypts the database at path.  keyFile may be nil.  Changes
+// can be written back with Save.
+func OpenFile(path, password string, keyFile []byte) (*Vault, error) {
+	db, err := keepass.OpenFile(path, options(password, keyFile))
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{db}, nil
+}
+
+func options(password string, keyFile []byte) *keepass.Options {
+	opts := &keepass.Options{Password: password}
+	if keyFile != nil {
+		opts.KeyFile = bytes.NewReader(keyFile)
+	}
+	return opts
+}
+
+// Bytes returns the encrypted database.
+func (v *Vault) Bytes() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := v.db.Write(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Save writes the database back to the file it was opened from.
+func (v *Vault) Save() error {
+	return v.db.Save()
+}
+
+// SaveAs writes the database to path.  Later calls to Save write to path.
+func (v *Vault) SaveAs(path string) error {
+	return v.db.SaveAs(path)
+}
+
+// Groups returns all groups in the database, parents before children.
+func (v *Vault) Groups() *GroupList {
+	var list GroupList
+	var walk func(g *keepass.Group)
+	walk = func(g *keepass.Group) {
+		for _, sub := range g.Groups() {
+			list.groups = append(list.groups, &Group{sub})
+			walk(sub)
+		}
+	}
+	walk(v.db.Root())
+	return &list
+}
+
+// NewGroup creates a group named name.  If parentID is negative, the group
+// is created at the top level.
+func (v *Vault) NewGroup(parentID int64, name string) (*Group, error) {
+	parent := v.db.Root()
+	if parentID >= 0 {
+		var err error
+		if parent, err = v.group(parentID); err != nil {
+			return nil, err
+		}
+	}
+	g := parent.NewSubgroup()
+	g.Name = name
+	now := time.Now()
+	g.CreationTime = now
+	g.LastModificationTime = now
+	g.LastAccessTime = now
+	return &Group{g}, nil
+}
+
+func (v *Vault) group(id int64) (*keepass.Group, error) {
+	if id < 0 || id > 0xffffffff {
+		return nil, errors.New("mobile: no such group")
+	}
+	g := v.db.FindGroup(uint32(id))
+	if g == nil || g.IsRoot() {
+		return nil, errors.New("mobile: no such group")
+	}
+	return g, nil
+}
+
+// Search returns the entries whose title, username, URL or notes contain
+// every word of query, ignoring case.  An empty query matches all entries.
+func (v *Vault) Search(query string) *EntryList {
+	var list EntryList
+	for _, e := range v.db.Search(query) {
+		list.entries = append(list.entries, &Entry{e})
+	}
+	return &list
+}
+
+// Entry returns the entry with the given UUID.
+func (v *Vault) Entry(uuid string) (*Entry, error) {
+	id, err := uuids.Parse(uuid)
+	if err != nil {
+		return nil, err
+	}
+	e := v.db.Find(id)
+	if e == nil {
+		return nil, errors.New("mobile: no such entry")
+	}
+	return &Entry{e}, nil
+}
+
+// NewEntry creates an entry in the group with the given ID.
+func (v *Vault) NewEntry(groupID int64) (*Entry, error) {
+	g, err := v.group(groupID)
+	if err != nil {
+		return nil, err
+	}
+	e, err := g.NewEntry()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	e.CreationTime = now
+	e.LastModificationTime = now
+	e.LastAccessTime = now
+	return &Entry{e}, nil
+}
+
+// RemoveEntry deletes the entry with the given UUID.
+func (v *Vault) RemoveEntry(uuid string) error {
+	e, err := v.Entry(uuid)
+	if err != nil {
+		return err
+	}
+	return e.e.Parent().RemoveEntry(e.e)
+}
+
+// A Group is a group of entries.
+type Group struct {
+	g *keepass.Group
+}
+
+// ID returns the group's identifier.
+func (g *Group) ID() int64 { return int64(g.g.ID) }
+
+// Name returns the group's name.
+func (g *Group) Name() string { return g.g.Name }
+
+// ParentID returns the ID of the containing group or -1 for a top-level
+// group.
+func (g *Group) ParentID() int64 {
+	p := g.g.Parent()
+	if p == nil || p.IsRoot() {
+		return -1
+	}
+	return int64(p.ID)
+}
+
+// SetName renames the group.
+func (g *Group) SetName(name string) {
+	g.g.Name = name
+	g.g.LastModificationTime = time.Now()
+}
+
+// Entries returns the entries directly inside the group.
+func (g *Group) Entries() *EntryList {
+	var list EntryList
+	for _, e := range g.g.Entries() {
+		list.entries = append(list.entries, &Entry{e})
+	}
+	return &list
+}
+
+// GroupList is a list of groups.
+type GroupList struct {
+	groups []*Group
+}
+
+// Len returns the number of groups in the list.
+func (l *GroupList) Len() int { return len(l.groups) }
+
+// Get returns the i'th group.
+func (l *GroupList) Get(i int) *Group { return l.groups[i] }
+
+// EntryList is a list of entries.
+type EntryList struct {
+	entries []*Entry
+}
+
+// Len returns the number of entries in the list.
+func (l *EntryList) Len() int { return len(l.entries) }
+
+// Get returns the i'th entry.
+func (l *EntryList) Get(i int) *Entry { return l.entries[i] }
+
+// An Entry is a single password record.  Setters update the entry's
+// modification time.
+type Entry struct {
+	e *keepass.Entry
+}
+
+// UUID returns the entry's identifier.
+func (e *Entry) UUID() string { return e.e.UUID.String() }
+
+// GroupID returns the ID of the entry's group.
+func (e *Entry) GroupID() int64 { return int64(e.e.Parent().ID) }
+
+// Title returns the entry's title.
+func (e *Entry) Title() string { return e.e.Title }
+
+// Username returns the entry's username.
+func (e *Entry) Username() string { return e.e.Username }
+
+// Password returns the entry's password.
+func (e *Entry) Password() string { return e.e.Password }
+
+// URL returns the entry's URL.
+func (e *Entry) URL() string { return e.e.URL }
+
+// Notes returns the entry's notes.
+func (e *Entry) Notes() string { return e.e.Notes }
+
+// Modified returns the entry's modification time in Unix seconds.
+func (e *Entry) Modified() int64 { return e.e.LastModificationTime.Unix() }
+
+// SetTitle changes the entry's title.
+func (e *Entry) SetTitle(s string) { e.e.Title = s; e.touch() }
+
+// SetUsername changes the entry's username.
+func (e *Entry) SetUsername(s string) { e.e.Username = s; e.touch() }
+
+// SetPassword changes the entry's password.
+func (e *Entry) SetPassword(s string) { e.e.Password = s; e.touch() }
+
+// SetURL changes the entry's URL.
+func (e *Entry) SetURL(s string) { e.e.URL = s; e.touch() }
+
+// SetNotes changes the entry's notes.
+func (e *Entry) SetNotes(s string) { e.e.Notes = s; e.touch() }
+
+func (e *Entry) touch() {
+	e.e.LastModificationTime = time.Now()
+}
+
+// HasAttachment reports whether the entry has an attachment.
+func (e *Entry) HasAttachment() bool { return e.e.HasAttachment() }
+
+// AttachmentName returns the attachment's file name.
+func (e *Entry) AttachmentName() string { return e.e.Attachment.Name }
+
+// AttachmentSize returns the attachment's size in bytes.
+func (e *Entry) AttachmentSize() int64 { return int64(len(e.e.Attachment.Data)) }
+
+// SetAttachment replaces the entry's attachment.  An empty name removes it.
+func (e *Entry) SetAttachment(name string, data []byte) {
+	if name == "" {
+		data = nil
+	}
+	e.e.Attachment.Name = name
+	e.e.Attachment.Data = append([]byte(nil), data...)
+	e.touch()
+}
+
+// OpenAttachment returns a reader for the entry's attachment.
+func (e *Entry) OpenAttachment() *AttachmentReader {
+	return &AttachmentReader{data: e.e.Attachment.Data}
+}
+
+// AttachmentReader reads an attachment in chunks, so that large
+// attachments don't have to be copied across the language boundary at
+// once.
+type AttachmentReader struct {
+	data []byte
+	off  int
+}
+
+// Read returns up to max bytes of the attachment.  It returns an empty
+// slice once the whole attachment has been read.
+func (r *AttachmentReader) Read(max int) ([]byte, error) {
+	if max <= 0 {
+		return nil, errors.New("mobile: read size must be positive")
+	}
+	n := len(r.data) - r.off
+	if n > max {
+		n = max
+	}
+	chunk := append([]byte(nil), r.data[r.off:r.off+n]...)
+	r.off += n
+	return chunk, nil
+}
+
+// Remaining returns the number of bytes left to read.
+func (r *AttachmentReader) Remaining() int64 { return int64(len(r.data) - r.off) }
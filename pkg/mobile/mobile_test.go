@@ -0,0 +1,105 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mobile
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestVault(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &Vault{db}
+	g, err := v.NewGroup(-1, "Internet")
+	if err != nil {
+		t.Fatal("NewGroup:", err)
+	}
+	e, err := v.NewEntry(g.ID())
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	e.SetTitle("Mail")
+	e.SetUsername("bob")
+	e.SetPassword("xyzzy")
+	attachment := bytes.Repeat([]byte("0123456789"), 10)
+	e.SetAttachment("notes.txt", attachment)
+	other, err := v.NewEntry(g.ID())
+	if err != nil {
+		t.Fatal("NewEntry:", err)
+	}
+	other.SetTitle("Bank")
+
+	data, err := v.Bytes()
+	if err != nil {
+		t.Fatal("Bytes:", err)
+	}
+	if _, err := Open(data, "wrong", nil); err == nil {
+		t.Error("Open with wrong password succeeded")
+	}
+	v, err = Open(data, "swordfish", nil)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+
+	if groups := v.Groups(); groups.Len() != 1 || groups.Get(0).Name() != "Internet" || groups.Get(0).ParentID() != -1 {
+		t.Errorf("Groups() = %d groups; want only top-level Internet", groups.Len())
+	}
+	results := v.Search("MAIL bob")
+	if results.Len() != 1 || results.Get(0).Title() != "Mail" {
+		t.Fatalf("Search(\"MAIL bob\") returned %d entries; want Mail", results.Len())
+	}
+	if n := v.Search("").Len(); n != 2 {
+		t.Errorf("Search(\"\") returned %d entries; want 2", n)
+	}
+	e, err = v.Entry(results.Get(0).UUID())
+	if err != nil {
+		t.Fatal("Entry:", err)
+	}
+	if e.Password() != "xyzzy" {
+		t.Errorf("Password() = %q; want %q", e.Password(), "xyzzy")
+	}
+
+	r := e.OpenAttachment()
+	var got []byte
+	for r.Remaining() > 0 {
+		chunk, err := r.Read(32)
+		if err != nil {
+			t.Fatal("attachment Read:", err)
+		}
+		if len(chunk) > 32 {
+			t.Fatalf("attachment Read(32) returned %d bytes", len(chunk))
+		}
+		got = append(got, chunk...)
+	}
+	if chunk, _ := r.Read(32); len(chunk) != 0 {
+		t.Errorf("attachment Read at end = %q; want empty", chunk)
+	}
+	if !bytes.Equal(got, attachment) {
+		t.Errorf("attachment = %q; want %q", got, attachment)
+	}
+
+	if err := v.RemoveEntry(e.UUID()); err != nil {
+		t.Fatal("RemoveEntry:", err)
+	}
+	if _, err := v.Entry(e.UUID()); err == nil {
+		t.Error("Entry after RemoveEntry succeeded")
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package paper encodes data as text that can be printed and later typed
+// or scanned back in.  The data is split into numbered lines of base32,
+// each ending in a short checksum, so that a misread line is reported by
+// its number.  A header line holds the data's length and Streebog digest,
+// which catch lines that are missing or out of place.
+//
+// Characters that OCR and people confuse with base32 letters are read as
+// those letters: 0 as O, 1 as I and 8 as B.
+package paper // import "github.com/pedroalbanese/gostpass/pkg/paper"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pedroalbanese/gogost/gost34112012256"
+)
+
+const (
+	magic = "gostpass-paper-1"
+	// LineSize is the number of bytes encoded on each line.
+	LineSize = 30
+	// groupSize is the number of characters between spaces on a line.
+	groupSize = 6
+	// checksumSize is the number of checksum bytes on each line.
+	checksumSize = 2
+)
+
+// Errors
+var (
+	ErrNoHeader = errors.New("paper: no " + magic + " header line")
+	ErrDigest   = errors.New("paper: data does not match the digest in the header")
+)
+
+// A LineError reports a line that could not be read.
+type LineError struct {
+	Line int // number printed at the start of the line
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("paper: line %d: %v", e.Line, e.Err)
+}
+
+var (
+	encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	errChecksum = errors.New("checksum mismatch, check the line for typos")
+
+	misread = strings.NewReplacer("0", "O", "1", "I", "8", "B")
+)
+
+// Lines returns the number of data lines that Encode writes for n bytes.
+func Lines(n int) int {
+	return (n + LineSize - 1) / LineSize
+}
+
+// Encode writes the header line and the data lines for data to w.
+func Encode(w io.Writer, data []byte) error {
+	digest := gost34112012256.New()
+	digest.Write(data)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s %d %x\n", magic, len(data), digest.Sum(nil))
+	width := len(strconv.Itoa(Lines(len(data))))
+	if width < 4 {
+		width = 4
+	}
+	for i := 0; i < Lines(len(data)); i++ {
+		chunk := data[i*LineSize:]
+		if len(chunk) > LineSize {
+			chunk = chunk[:LineSize]
+		}
+		fmt.Fprintf(bw, "%0*d ", width, i+1)
+		text := encoding.EncodeToString(chunk)
+		for len(text) > 0 {
+			n := groupSize
+			if n > len(text) {
+				n = len(text)
+			}
+			bw.WriteString(" " + text[:n])
+			text = text[n:]
+		}
+		fmt.Fprintf(bw, "  %X\n", lineChecksum(i+1, chunk))
+	}
+	return bw.Flush()
+}
+
+// lineChecksum binds a line's data to its number.
+func lineChecksum(line int, chunk []byte) []byte {
+	h := gost34112012256.New()
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(line))
+	h.Write(n[:])
+	h.Write(chunk)
+	return h.Sum(nil)[:checksumSize]
+}
+
+// Decode reads the data written by Encode from r.  Lines that are neither
+// the header nor data lines, like instructions printed around them, are
+// skipped, and the data lines may come in any order.
+func Decode(r io.Reader) ([]byte, error) {
+	var (
+		size   = -1
+		digest []byte
+		chunks = make(map[int][]byte)
+	)
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == magic {
+			if size >= 0 {
+				return nil, errors.New("paper: more than one header line")
+			}
+			var err error
+			if len(fields) == 3 {
+				size, err = strconv.Atoi(fields[1])
+				if err == nil {
+					digest, err = hex.DecodeString(fields[2])
+				}
+			}
+			if len(fields) != 3 || err != nil || size < 0 || len(digest) != gost34112012256.Size {
+				return nil, fmt.Errorf("paper: malformed header line %q", s.Text())
+			}
+			continue
+		}
+		line, err := strconv.Atoi(fields[0])
+		if err != nil || len(fields) < 3 {
+			continue
+		}
+		chunk, err := decodeLine(line, fields[1:])
+		if err != nil {
+			return nil, &LineError{Line: line, Err: err}
+		}
+		if prev, ok := chunks[line]; ok && !bytes.Equal(prev, chunk) {
+			return nil, &LineError{Line: line, Err: errors.New("appears twice with different data")}
+		}
+		chunks[line] = chunk
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("paper: %v", err)
+	}
+	if size < 0 {
+		return nil, ErrNoHeader
+	}
+	// The header's size is only as good as the typing, so check it against
+	// the lines read before allocating for it.
+	if size > len(chunks)*LineSize {
+		line := 1
+		for chunks[line] != nil {
+			line++
+		}
+		return nil, &LineError{Line: line, Err: errors.New("missing")}
+	}
+	data := make([]byte, 0, size)
+	n := Lines(size)
+	for line := 1; line <= n; line++ {
+		chunk, ok := chunks[line]
+		if !ok {
+			return nil, &LineError{Line: line, Err: errors.New("missing")}
+		}
+		data = append(data, chunk...)
+	}
+	for line := range chunks {
+		if line < 1 || line > n {
+			return nil, &LineError{Line: line, Err: fmt.Errorf("beyond the %d lines in the header", n)}
+		}
+	}
+	h := gost34112012256.New()
+	h.Write(data)
+	if len(data) != size || !bytes.Equal(h.Sum(nil), digest) {
+		return nil, ErrDigest
+	}
+	return data, nil
+}
+
+// decodeLine decodes the fields after a line's number: the groups of
+// base32 and then the checksum.
+func decodeLine(line int, fields []string) ([]byte, error) {
+	text := strings.ToUpper(strings.Join(fields[:len(fields)-1], ""))
+	chunk, err := encoding.DecodeString(misread.Replace(text))
+	if err != nil {
+		return nil, fmt.Errorf("malformed data: %v", err)
+	}
+	sum, err := hex.DecodeString(fields[len(fields)-1])
+	if err != nil || !bytes.Equal(sum, lineChecksum(line, chunk)) {
+		return nil, errChecksum
+	}
+	return chunk, nil
+}
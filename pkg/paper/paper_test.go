@@ -0,0 +1,118 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package paper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, LineSize - 1, LineSize, LineSize + 1, 1000} {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		buf := new(bytes.Buffer)
+		if err := Encode(buf, data); err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != Lines(n)+1 {
+			t.Errorf("Encode(%d bytes) wrote %d lines; want %d", n, lines, Lines(n)+1)
+		}
+		got, err := Decode(buf)
+		if err != nil {
+			t.Errorf("Decode(Encode(%d bytes)): %v", n, err)
+			continue
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("Decode(Encode(%d bytes)) = %x; want %x", n, got, data)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	data := []byte(strings.Repeat("Kuznyechik and Streebog. ", 5))
+	buf := new(bytes.Buffer)
+	if err := Encode(buf, data); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	// Surrounding text, shuffled lines, lowercase and misread characters
+	// are all accepted.
+	sloppy := []string{"Paper backup", "", "1. Type the lines below.", lines[0]}
+	for i := len(lines) - 1; i > 0; i-- {
+		sloppy = append(sloppy, strings.Replace(strings.ToLower(lines[i]), "o", "0", -1))
+	}
+	text := strings.Join(sloppy, "\n")
+	if got, err := Decode(strings.NewReader(text)); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Decode(sloppy copy) = %q, %v; want %q, <nil>", got, err, data)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		line int
+	}{
+		{"NoHeader", strings.Join(lines[1:], "\n"), 0},
+		{"Missing", strings.Join(append(lines[:2:2], lines[3:]...), "\n"), 2},
+		{"Typo", strings.Join(append(append(lines[:2:2], typo(lines[2])), lines[3:]...), "\n"), 2},
+		{"Swapped", strings.Join(append(lines[:1:1], swap(lines[1], lines[2])...), "\n"), 1},
+		{"HugeSize", strings.Join(append([]string{hugeSize(lines[0])}, lines[1:]...), "\n"), len(lines)},
+	}
+	for _, test := range tests {
+		_, err := Decode(strings.NewReader(test.text))
+		if err == nil {
+			t.Errorf("%s: Decode succeeded", test.name)
+			continue
+		}
+		if test.line == 0 {
+			if err != ErrNoHeader {
+				t.Errorf("%s: Decode error = %v; want %v", test.name, err, ErrNoHeader)
+			}
+			continue
+		}
+		if le, ok := err.(*LineError); !ok || le.Line != test.line {
+			t.Errorf("%s: Decode error = %v; want error on line %d", test.name, err, test.line)
+		}
+	}
+}
+
+// typo changes the first data character of line.
+func typo(line string) string {
+	fields := strings.Fields(line)
+	c := "A"
+	if fields[1][0] == 'A' {
+		c = "B"
+	}
+	fields[1] = c + fields[1][1:]
+	return strings.Join(fields, " ")
+}
+
+// swap returns a and b with their line numbers exchanged.
+func swap(a, b string) []string {
+	fa, fb := strings.Fields(a), strings.Fields(b)
+	fa[0], fb[0] = fb[0], fa[0]
+	return []string{strings.Join(fb, " "), strings.Join(fa, " ")}
+}
+
+// hugeSize returns the header line with a size too large to allocate.
+func hugeSize(header string) string {
+	fields := strings.Fields(header)
+	fields[1] = "9000000000000000000"
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prompt reads passwords from a terminal with masked echo and live
+// feedback, like a strength meter, under the input.
+package prompt // import "github.com/pedroalbanese/gostpass/pkg/prompt"
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInterrupted is returned when the user presses Ctrl-C.
+var ErrInterrupted = errors.New("prompt: interrupted")
+
+// Password reads a password from the terminal f, which is put in raw mode
+// until the password is entered.  The prompt, a '*' per character and the
+// result of feedback, if not nil, are written to w and redrawn on every
+// key.  pasted reports whether any of the password arrived faster than it
+// can be typed, which means it came from the clipboard.
+func Password(f *os.File, w io.Writer, prompt string, feedback func(password string) string) (password string, pasted bool, err error) {
+	restore, err := makeRaw(f)
+	if err != nil {
+		return "", false, err
+	}
+	defer restore()
+	return read(f, w, prompt, feedback)
+}
+
+// read is the line editor of Password, reading keys from r.  Terminals
+// deliver a typed key per read and a paste in one read, which is how
+// pastes are told apart.
+func read(r io.Reader, w io.Writer, prompt string, feedback func(string) string) (string, bool, error) {
+	var pw []byte
+	pasted := false
+	redraw := func() {
+		line := "\r\x1b[K" + prompt + strings.Repeat("*", utf8.RuneCount(pw))
+		if feedback != nil {
+			if s := feedback(string(pw)); s != "" {
+				line += "  " + s
+			}
+		}
+		io.WriteString(w, line)
+	}
+	redraw()
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		if n == 0 && err != nil {
+			return "", false, err
+		}
+		keys := buf[:n]
+		if printable := strings.TrimRight(string(keys), "\r\n"); utf8.RuneCountInString(printable) > 1 && !strings.HasPrefix(printable, "\x1b") {
+			pasted = true
+		}
+	loop:
+		for i := 0; i < len(keys); i++ {
+			switch c := keys[i]; {
+			case c == '\r' || c == '\n':
+				io.WriteString(w, "\r\n")
+				return string(pw), pasted, nil
+			case c == 3: // Ctrl-C
+				io.WriteString(w, "\r\n")
+				return "", false, ErrInterrupted
+			case c == 4 && len(pw) == 0: // Ctrl-D
+				io.WriteString(w, "\r\n")
+				return "", false, io.EOF
+			case c == 0x7f || c == 8: // Backspace
+				if len(pw) > 0 {
+					_, size := utf8.DecodeLastRune(pw)
+					pw = pw[:len(pw)-size]
+				}
+			case c == 0x15: // Ctrl-U
+				pw = pw[:0]
+			case c == 0x1b:
+				// Escape sequences, like arrow keys, arrive whole.
+				break loop
+			case c >= 0x20:
+				pw = append(pw, c)
+			}
+		}
+		redraw()
+	}
+}
+
+// CapsLockLikely reports whether password looks like it was typed with
+// Caps Lock on: it has letters, and all of them are upper case.  Terminals
+// don't report the Caps Lock state, so this is the best that can be done.
+func CapsLockLikely(password string) bool {
+	letters := 0
+	for _, c := range password {
+		if unicode.IsLower(c) {
+			return false
+		}
+		if unicode.IsUpper(c) {
+			letters++
+		}
+	}
+	return letters >= 2
+}
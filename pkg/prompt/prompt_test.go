@@ -0,0 +1,103 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prompt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// keys delivers one chunk per Read, like a terminal in raw mode.
+type keys []string
+
+func (k *keys) Read(p []byte) (int, error) {
+	if len(*k) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, (*k)[0])
+	*k = (*k)[1:]
+	return n, nil
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   keys
+		want   string
+		pasted bool
+		err    error
+	}{
+		{name: "Typed", keys: keys{"a", "b", "c", "\r"}, want: "abc"},
+		{name: "Pasted", keys: keys{"hunter2", "\r"}, want: "hunter2", pasted: true},
+		{name: "PastedWithEnter", keys: keys{"hunter2\n"}, want: "hunter2", pasted: true},
+		{name: "Backspace", keys: keys{"a", "b", "\x7f", "c", "\r"}, want: "ac"},
+		{name: "BackspaceMultibyte", keys: keys{"п", "ж", "\x7f", "\r"}, want: "п"},
+		{name: "KillLine", keys: keys{"a", "b", "\x15", "c", "\r"}, want: "c"},
+		{name: "ArrowKey", keys: keys{"a", "\x1b[D", "b", "\r"}, want: "ab"},
+		{name: "Interrupt", keys: keys{"a", "\x03"}, err: ErrInterrupted},
+		{name: "EOF", keys: keys{"\x04"}, err: io.EOF},
+		{name: "NoEnter", keys: keys{"a"}, err: io.EOF},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		got, pasted, err := read(&test.keys, &out, "Password: ", nil)
+		if got != test.want || pasted != test.pasted || err != test.err {
+			t.Errorf("%s: read = %q, %t, %v; want %q, %t, %v", test.name, got, pasted, err, test.want, test.pasted, test.err)
+		}
+		if strings.Contains(out.String(), "hunter2") || strings.Contains(out.String(), "abc") {
+			t.Errorf("%s: password echoed: %q", test.name, out.String())
+		}
+	}
+}
+
+func TestReadFeedback(t *testing.T) {
+	k := keys{"a", "b", "\r"}
+	var out bytes.Buffer
+	var seen []string
+	feedback := func(pw string) string {
+		seen = append(seen, pw)
+		return "[" + strings.Repeat("#", len(pw)) + "]"
+	}
+	if _, _, err := read(&k, &out, "Password: ", feedback); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"", "a", "ab"}; strings.Join(seen, ",") != strings.Join(want, ",") {
+		t.Errorf("feedback called with %q; want %q", seen, want)
+	}
+	if want := "\r\x1b[KPassword: **  [##]\r\n"; !strings.HasSuffix(out.String(), want) {
+		t.Errorf("output = %q; want suffix %q", out.String(), want)
+	}
+}
+
+func TestCapsLockLikely(t *testing.T) {
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"", false},
+		{"A", false},
+		{"HUNTER2", true},
+		{"Hunter2", false},
+		{"ПАРОЛЬ", true},
+		{"1234!", false},
+	}
+	for _, test := range tests {
+		if got := CapsLockLikely(test.password); got != test.want {
+			t.Errorf("CapsLockLikely(%q) = %t; want %t", test.password, got, test.want)
+		}
+	}
+}
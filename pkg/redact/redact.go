@@ -0,0 +1,103 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redact scrubs known secrets from text before it is logged, so
+// that logs and error messages pasted into bug reports don't leak them.
+package redact // import "github.com/pedroalbanese/gostpass/pkg/redact"
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// Replacement is written in place of a secret.
+	Replacement = "[redacted]"
+
+	// MinLength is the length of the shortest secret that is scrubbed.
+	// Shorter ones would mangle unrelated text and give little away.
+	MinLength = 4
+
+	// MaxSecrets is how many secrets a Guard remembers.  Once full, the
+	// oldest is forgotten, so that a server taking passwords from the
+	// network can't be made to grow without bound.
+	MaxSecrets = 256
+)
+
+// A Guard holds secrets and scrubs them from text.  The zero value holds no
+// secrets and is ready to use.  A Guard is safe for concurrent use.
+type Guard struct {
+	mu       sync.RWMutex
+	secrets  []string // in the order added
+	replacer *strings.Replacer
+}
+
+// Add makes g scrub secret from now on.
+func (g *Guard) Add(secret string) {
+	if len(secret) < MinLength {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, s := range g.secrets {
+		if s == secret {
+			return
+		}
+	}
+	if len(g.secrets) >= MaxSecrets {
+		g.secrets = append(g.secrets[:0], g.secrets[1:]...)
+	}
+	g.secrets = append(g.secrets, secret)
+	// The replacer tries its arguments in order at each position, so the
+	// longest secrets go first, in case one contains another.
+	sorted := append([]string(nil), g.secrets...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	args := make([]string, 0, 2*len(sorted))
+	for _, s := range sorted {
+		args = append(args, s, Replacement)
+	}
+	g.replacer = strings.NewReplacer(args...)
+}
+
+// Scrub returns s with every secret replaced.
+func (g *Guard) Scrub(s string) string {
+	g.mu.RLock()
+	r := g.replacer
+	g.mu.RUnlock()
+	if r == nil {
+		return s
+	}
+	return r.Replace(s)
+}
+
+// Writer returns a writer that scrubs each write before passing it to w.
+// A secret split across two writes is not caught, which is fine for the
+// log package and fmt.Fprintf, which write a message at a time.
+func (g *Guard) Writer(w io.Writer) io.Writer {
+	return writer{g, w}
+}
+
+type writer struct {
+	g *Guard
+	w io.Writer
+}
+
+func (w writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, w.g.Scrub(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
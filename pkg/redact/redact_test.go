@@ -0,0 +1,69 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"testing"
+)
+
+func TestScrub(t *testing.T) {
+	var g Guard
+	if got := g.Scrub("nothing to hide"); got != "nothing to hide" {
+		t.Errorf("empty guard: Scrub = %q", got)
+	}
+	g.Add("hunter2")
+	g.Add("hunter2hunter2")
+	g.Add("abc") // too short
+	tests := []struct {
+		s, want string
+	}{
+		{"wrong password hunter2", "wrong password [redacted]"},
+		{"hunter2hunter2!", "[redacted]!"},
+		{"hunter2, hunter2", "[redacted], [redacted]"},
+		{"abc hunter", "abc hunter"},
+	}
+	for _, test := range tests {
+		if got := g.Scrub(test.s); got != test.want {
+			t.Errorf("Scrub(%q) = %q; want %q", test.s, got, test.want)
+		}
+	}
+}
+
+func TestAddForgetsOldest(t *testing.T) {
+	var g Guard
+	for i := 0; i <= MaxSecrets; i++ {
+		g.Add(fmt.Sprintf("secret%04d", i))
+	}
+	if got := g.Scrub("secret0000"); got != "secret0000" {
+		t.Errorf("oldest secret still scrubbed: %q", got)
+	}
+	if got := g.Scrub("secret0001"); got != Replacement {
+		t.Errorf("Scrub(secret0001) = %q; want %q", got, Replacement)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var g Guard
+	g.Add("correct horse")
+	var buf bytes.Buffer
+	l := log.New(g.Writer(&buf), "", 0)
+	l.Printf("open database: bad password %q", "correct horse")
+	if want := "open database: bad password \"[redacted]\"\n"; buf.String() != want {
+		t.Errorf("logged %q; want %q", buf.String(), want)
+	}
+}
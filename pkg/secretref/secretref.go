@@ -0,0 +1,241 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secretref resolves references to secrets that are kept outside
+// the database, like "env:GITHUB_TOKEN" or "keyring:github.com/octocat".
+// A reference is a backend scheme and a target separated by a colon.
+package secretref // import "github.com/pedroalbanese/gostpass/pkg/secretref"
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// A Backend fetches the secret that target names.
+type Backend interface {
+	Resolve(target string) (string, error)
+}
+
+// BackendFunc adapts a function to a Backend.
+type BackendFunc func(target string) (string, error)
+
+// Resolve returns f(target).
+func (f BackendFunc) Resolve(target string) (string, error) {
+	return f(target)
+}
+
+// Built-in backends.
+var (
+	// Env reads an environment variable.
+	Env Backend = BackendFunc(resolveEnv)
+
+	// File reads a file, without its trailing newline.
+	File Backend = BackendFunc(resolveFile)
+
+	// Exec runs a command, split on spaces and not passed to a shell, and
+	// takes its output without the trailing newline.
+	Exec Backend = BackendFunc(resolveExec)
+
+	// Keyring looks up a "service/account" password in the system keyring
+	// with secret-tool on Linux and the BSDs, or security on macOS.
+	Keyring Backend = BackendFunc(resolveKeyring)
+)
+
+// A Resolver maps schemes to the backends that handle them.
+type Resolver map[string]Backend
+
+// ErrSyntax is returned for a reference without a scheme.
+var ErrSyntax = errors.New("secretref: reference must look like scheme:target")
+
+// Parse splits ref into its scheme and target.
+func Parse(ref string) (scheme, target string, err error) {
+	i := strings.IndexByte(ref, ':')
+	if i <= 0 || i == len(ref)-1 {
+		return "", "", ErrSyntax
+	}
+	return ref[:i], ref[i+1:], nil
+}
+
+// Resolve returns the secret that ref points to.
+func (r Resolver) Resolve(ref string) (string, error) {
+	scheme, target, err := Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	b := r[scheme]
+	if b == nil {
+		return "", fmt.Errorf("secretref: %s: backend %q is not enabled", ref, scheme)
+	}
+	secret, err := b.Resolve(target)
+	if err != nil {
+		return "", fmt.Errorf("secretref: %s: %v", ref, err)
+	}
+	return secret, nil
+}
+
+func resolveEnv(name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", errors.New("not set")
+	}
+	return v, nil
+}
+
+func resolveFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return trimNewline(data), nil
+}
+
+func resolveExec(command string) (string, error) {
+	argv := strings.Fields(command)
+	if len(argv) == 0 {
+		return "", errors.New("empty command")
+	}
+	return output(exec.Command(argv[0], argv[1:]...))
+}
+
+// A keyringTool gives the commands that look up, store and delete the
+// password of a service and account in a system keyring.  store also
+// returns what to write to the command's standard input, which is where
+// the password goes, so that it doesn't show up in the process list.
+type keyringTool struct {
+	lookup func(service, account string) []string
+	store  func(service, account, password string) (argv []string, stdin string, err error)
+	delete func(service, account string) []string
+}
+
+// keyringTools gives the keyring tool for each system.
+var keyringTools = map[string]keyringTool{
+	"darwin": {
+		lookup: func(service, account string) []string {
+			return []string{"security", "find-generic-password", "-w", "-s", service, "-a", account}
+		},
+		// security only takes the password as an argument or from the
+		// terminal, so the command is given to its interactive mode on
+		// standard input instead.
+		store: func(service, account, password string) ([]string, string, error) {
+			for _, s := range []string{service, account, password} {
+				if strings.ContainsAny(s, "\"\\\n") {
+					return nil, "", errors.New(`keyring service, account and password can't contain ", \ or newlines`)
+				}
+			}
+			cmd := fmt.Sprintf("add-generic-password -U -s \"%s\" -a \"%s\" -w \"%s\"\n", service, account, password)
+			return []string{"security", "-i"}, cmd, nil
+		},
+		delete: func(service, account string) []string {
+			return []string{"security", "delete-generic-password", "-s", service, "-a", account}
+		},
+	},
+	"linux": {
+		lookup: func(service, account string) []string {
+			return []string{"secret-tool", "lookup", "service", service, "account", account}
+		},
+		store: func(service, account, password string) ([]string, string, error) {
+			return []string{"secret-tool", "store", "--label", service, "service", service, "account", account}, password, nil
+		},
+		delete: func(service, account string) []string {
+			return []string{"secret-tool", "clear", "service", service, "account", account}
+		},
+	},
+}
+
+// systemKeyring returns the keyring tool for this system.
+func systemKeyring() (keyringTool, error) {
+	tool, ok := keyringTools[runtime.GOOS]
+	if !ok {
+		if runtime.GOOS == "windows" {
+			return tool, errors.New("the system keyring is not supported on Windows")
+		}
+		tool = keyringTools["linux"]
+	}
+	return tool, nil
+}
+
+func resolveKeyring(target string) (string, error) {
+	i := strings.LastIndexByte(target, '/')
+	if i <= 0 || i == len(target)-1 {
+		return "", errors.New("keyring target must look like service/account")
+	}
+	return LookupKeyring(target[:i], target[i+1:])
+}
+
+// LookupKeyring returns the password of service and account in the system
+// keyring.
+func LookupKeyring(service, account string) (string, error) {
+	tool, err := systemKeyring()
+	if err != nil {
+		return "", err
+	}
+	argv := tool.lookup(service, account)
+	return output(exec.Command(argv[0], argv[1:]...))
+}
+
+// StoreKeyring saves password as the password of service and account in
+// the system keyring, replacing any previous one.
+func StoreKeyring(service, account, password string) error {
+	tool, err := systemKeyring()
+	if err != nil {
+		return err
+	}
+	argv, stdin, err := tool.store(service, account, password)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = strings.NewReader(stdin)
+	_, err = output(cmd)
+	return err
+}
+
+// DeleteKeyring removes the password of service and account from the
+// system keyring.
+func DeleteKeyring(service, account string) error {
+	tool, err := systemKeyring()
+	if err != nil {
+		return err
+	}
+	argv := tool.delete(service, account)
+	_, err = output(exec.Command(argv[0], argv[1:]...))
+	return err
+}
+
+// output runs cmd and returns its standard output.  Standard error is
+// included in the error if it fails.
+func output(cmd *exec.Cmd) (string, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return trimNewline(out), nil
+}
+
+func trimNewline(data []byte) string {
+	s := string(data)
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
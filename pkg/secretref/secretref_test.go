@@ -0,0 +1,120 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretref
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		ref            string
+		scheme, target string
+		err            error
+	}{
+		{"env:TOKEN", "env", "TOKEN", nil},
+		{"file:/run/secrets/db:password", "file", "/run/secrets/db:password", nil},
+		{"TOKEN", "", "", ErrSyntax},
+		{":TOKEN", "", "", ErrSyntax},
+		{"env:", "", "", ErrSyntax},
+	}
+	for _, test := range tests {
+		scheme, target, err := Parse(test.ref)
+		if scheme != test.scheme || target != test.target || err != test.err {
+			t.Errorf("Parse(%q) = %q, %q, %v; want %q, %q, %v", test.ref, scheme, target, err, test.scheme, test.target, test.err)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_secretref_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "secret")
+	if err := ioutil.WriteFile(path, []byte("from file\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("GOSTPASS_SECRETREF_TEST", "from env")
+	defer os.Unsetenv("GOSTPASS_SECRETREF_TEST")
+
+	r := Resolver{
+		"env":  Env,
+		"file": File,
+		"const": BackendFunc(func(target string) (string, error) {
+			if target == "fail" {
+				return "", errors.New("no")
+			}
+			return "const " + target, nil
+		}),
+	}
+	tests := []struct {
+		ref, want string
+		ok        bool
+	}{
+		{"env:GOSTPASS_SECRETREF_TEST", "from env", true},
+		{"env:GOSTPASS_SECRETREF_UNSET", "", false},
+		{"file:" + path, "from file", true},
+		{"file:" + filepath.Join(dir, "missing"), "", false},
+		{"const:x", "const x", true},
+		{"const:fail", "", false},
+		{"exec:echo hi", "", false}, // not enabled
+	}
+	for _, test := range tests {
+		got, err := r.Resolve(test.ref)
+		if got != test.want || (err == nil) != test.ok {
+			t.Errorf("Resolve(%q) = %q, %v; want %q, ok=%t", test.ref, got, err, test.want, test.ok)
+		}
+	}
+}
+
+func TestExec(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("no echo command")
+	}
+	got, err := Exec.Resolve("echo hunter2")
+	if err != nil || got != "hunter2" {
+		t.Errorf("Exec.Resolve(%q) = %q, %v; want %q, <nil>", "echo hunter2", got, err, "hunter2")
+	}
+}
+
+func TestKeyringStoreArgs(t *testing.T) {
+	const password = "0123456789abcdef"
+	for system, tool := range keyringTools {
+		argv, stdin, err := tool.store("gostpass /home/me/vault.kdb", "quickunlock", password)
+		if err != nil {
+			t.Errorf("%s: store: %v", system, err)
+			continue
+		}
+		for _, arg := range argv {
+			if strings.Contains(arg, password) {
+				t.Errorf("%s: store command %q has the password", system, argv)
+			}
+		}
+		if !strings.Contains(stdin, password) {
+			t.Errorf("%s: store input %q lacks the password", system, stdin)
+		}
+	}
+	if _, _, err := keyringTools["darwin"].store("gostpass", "quickunlock", "a\" -w \"b"); err == nil {
+		t.Error("darwin: store with a quote in the password succeeded")
+	}
+}
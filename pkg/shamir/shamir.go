@@ -0,0 +1,146 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shamir implements Shamir's secret sharing over GF(256).
+//
+// Each share is the secret's length plus one byte: the first byte is the
+// share's x coordinate and the rest are the polynomials' values at x.
+package shamir // import "github.com/pedroalbanese/gostpass/pkg/shamir"
+
+import (
+	"errors"
+	"io"
+)
+
+// Errors
+var (
+	ErrThreshold   = errors.New("shamir: threshold must be between 2 and the number of shares")
+	ErrShareCount  = errors.New("shamir: number of shares must be between 2 and 255")
+	ErrEmptySecret = errors.New("shamir: empty secret")
+	ErrShares      = errors.New("shamir: shares are malformed or inconsistent")
+)
+
+// Split divides secret into n shares, any t of which can reconstruct it.
+func Split(rand io.Reader, secret []byte, n, t int) ([][]byte, error) {
+	if n < 2 || n > 255 {
+		return nil, ErrShareCount
+	}
+	if t < 2 || t > n {
+		return nil, ErrThreshold
+	}
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][0] = byte(i + 1)
+	}
+	coeffs := make([]byte, t)
+	for j, s := range secret {
+		coeffs[0] = s
+		if _, err := io.ReadFull(rand, coeffs[1:]); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			share[j+1] = evaluate(coeffs, share[0])
+		}
+	}
+	for i := range coeffs {
+		coeffs[i] = 0
+	}
+	return shares, nil
+}
+
+// Combine reconstructs a secret from at least the threshold number of
+// shares.  Combining fewer shares silently produces garbage.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrShares
+	}
+	size := len(shares[0])
+	if size < 2 {
+		return nil, ErrShares
+	}
+	seen := make(map[byte]bool, len(shares))
+	for _, share := range shares {
+		if len(share) != size || share[0] == 0 || seen[share[0]] {
+			return nil, ErrShares
+		}
+		seen[share[0]] = true
+	}
+	secret := make([]byte, size-1)
+	for i, si := range shares {
+		// Lagrange basis polynomial for share i evaluated at 0.
+		basis := byte(1)
+		for j, sj := range shares {
+			if i != j {
+				basis = mul(basis, div(sj[0], sj[0]^si[0]))
+			}
+		}
+		for k := range secret {
+			secret[k] ^= mul(basis, si[k+1])
+		}
+	}
+	return secret, nil
+}
+
+// evaluate computes the polynomial with the given coefficients at x using
+// Horner's method.
+func evaluate(coeffs []byte, x byte) byte {
+	y := byte(0)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = mul(y, x) ^ coeffs[i]
+	}
+	return y
+}
+
+// Log and exp tables for GF(256) with the AES polynomial and generator 3.
+var (
+	logTable [256]byte
+	expTable [510]byte
+)
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		expTable[i] = x
+		expTable[i+255] = x
+		logTable[x] = byte(i)
+		// Multiply by the generator 3 = x + 1.
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+}
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+func div(a, b byte) byte {
+	if b == 0 {
+		panic("shamir: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
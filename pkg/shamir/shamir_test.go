@@ -0,0 +1,96 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("correct horse battery staple 123")
+	tests := []struct {
+		n, t   int
+		subset []int
+	}{
+		{n: 2, t: 2, subset: []int{0, 1}},
+		{n: 5, t: 3, subset: []int{0, 1, 2}},
+		{n: 5, t: 3, subset: []int{4, 2, 0}},
+		{n: 5, t: 3, subset: []int{0, 1, 2, 3, 4}},
+		{n: 255, t: 10, subset: []int{254, 1, 2, 3, 100, 5, 6, 7, 8, 9}},
+	}
+	for _, test := range tests {
+		shares, err := Split(fakerand.New(), secret, test.n, test.t)
+		if err != nil {
+			t.Errorf("Split(n=%d, t=%d): %v", test.n, test.t, err)
+			continue
+		}
+		if len(shares) != test.n {
+			t.Errorf("Split(n=%d, t=%d) returned %d shares", test.n, test.t, len(shares))
+			continue
+		}
+		var subset [][]byte
+		for _, i := range test.subset {
+			subset = append(subset, shares[i])
+		}
+		got, err := Combine(subset)
+		if err != nil {
+			t.Errorf("Combine(n=%d, t=%d, %v): %v", test.n, test.t, test.subset, err)
+			continue
+		}
+		if !bytes.Equal(got, secret) {
+			t.Errorf("Combine(n=%d, t=%d, %v) = %q; want %q", test.n, test.t, test.subset, got, secret)
+		}
+	}
+}
+
+func TestCombineBelowThreshold(t *testing.T) {
+	secret := []byte("swordfish")
+	shares, err := Split(fakerand.New(), secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Combine(shares[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(got, secret) {
+		t.Error("Combine with two of three required shares recovered the secret")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := Split(fakerand.New(), []byte("x"), 3, 4); err != ErrThreshold {
+		t.Errorf("Split(t > n) error = %v; want %v", err, ErrThreshold)
+	}
+	if _, err := Split(fakerand.New(), []byte("x"), 1, 1); err != ErrShareCount {
+		t.Errorf("Split(n = 1) error = %v; want %v", err, ErrShareCount)
+	}
+	if _, err := Split(fakerand.New(), nil, 3, 2); err != ErrEmptySecret {
+		t.Errorf("Split(nil) error = %v; want %v", err, ErrEmptySecret)
+	}
+	shares, err := Split(fakerand.New(), []byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Combine([][]byte{shares[0], shares[0]}); err != ErrShares {
+		t.Errorf("Combine(duplicate shares) error = %v; want %v", err, ErrShares)
+	}
+	if _, err := Combine([][]byte{shares[0], shares[1][:3]}); err != ErrShares {
+		t.Errorf("Combine(mismatched lengths) error = %v; want %v", err, ErrShares)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sharelink seals a secret for a one-time link.  The key is only
+// ever in the link, so the sealed box kept by the server reveals nothing
+// without it.  Boxes are sealed with Kuznyechik in MGM mode.
+package sharelink // import "github.com/pedroalbanese/gostpass/pkg/sharelink"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pedroalbanese/gogost/gost3412128"
+	"github.com/pedroalbanese/gogost/mgm"
+)
+
+// KeySize is the size of the keys returned by Seal.
+const KeySize = 32
+
+// Errors
+var (
+	ErrKey = errors.New("sharelink: wrong key")
+)
+
+// A Box holds a sealed secret.
+type Box struct {
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Seal encrypts secret under a new random key, binding it to id, and
+// returns the key and the box.
+func Seal(rand io.Reader, id string, secret []byte) (key []byte, box *Box, err error) {
+	key = make([]byte, KeySize)
+	if _, err := io.ReadFull(rand, key); err != nil {
+		return nil, nil, fmt.Errorf("sharelink: %v", err)
+	}
+	aead, err := mgm.NewMGM(gost3412128.NewCipher(key), gost3412128.BlockSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sharelink: %v", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand, nonce); err != nil {
+		return nil, nil, fmt.Errorf("sharelink: %v", err)
+	}
+	// MGM requires the nonce's most significant bit to be clear.
+	nonce[0] &= 0x7f
+	return key, &Box{Nonce: nonce, Ciphertext: aead.Seal(nil, nonce, secret, []byte(id))}, nil
+}
+
+// Open decrypts the box with key.  It returns ErrKey if the key is wrong
+// or the box was sealed for another id or tampered with.
+func (b *Box) Open(id string, key []byte) ([]byte, error) {
+	if len(key) != KeySize {
+		return nil, ErrKey
+	}
+	aead, err := mgm.NewMGM(gost3412128.NewCipher(key), gost3412128.BlockSize)
+	if err != nil {
+		return nil, fmt.Errorf("sharelink: %v", err)
+	}
+	if len(b.Nonce) != aead.NonceSize() {
+		return nil, ErrKey
+	}
+	secret, err := aead.Open(nil, b.Nonce, b.Ciphertext, []byte(id))
+	if err != nil {
+		return nil, ErrKey
+	}
+	return secret, nil
+}
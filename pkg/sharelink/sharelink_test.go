@@ -0,0 +1,51 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharelink
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+	key, box, err := Seal(rand.Reader, "abc", secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(box.Ciphertext, secret) {
+		t.Error("ciphertext contains the secret")
+	}
+	got, err := box.Open("abc", key)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("Open = %q; want %q", got, secret)
+	}
+
+	if _, err := box.Open("abd", key); err != ErrKey {
+		t.Errorf("Open with another id: %v; want ErrKey", err)
+	}
+	wrong := append([]byte(nil), key...)
+	wrong[0] ^= 1
+	if _, err := box.Open("abc", wrong); err != ErrKey {
+		t.Errorf("Open with a wrong key: %v; want ErrKey", err)
+	}
+	if _, err := box.Open("abc", key[:16]); err != ErrKey {
+		t.Errorf("Open with a short key: %v; want ErrKey", err)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sshkey parses SSH private keys, including OpenSSH-format keys
+// encrypted with a passphrase, which golang.org/x/crypto/ssh can't
+// decrypt.
+package sshkey // import "github.com/pedroalbanese/gostpass/pkg/sshkey"
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Errors
+var (
+	ErrNoKey      = errors.New("sshkey: no private key found")
+	ErrPassphrase = errors.New("sshkey: wrong passphrase")
+)
+
+const opensshMagic = "openssh-key-v1\x00"
+
+// opensshKey is the envelope of an "OPENSSH PRIVATE KEY" block.
+type opensshKey struct {
+	cipher     string
+	kdf        string
+	kdfOptions []byte
+	publicKey  []byte
+	private    []byte // encrypted unless cipher is "none"
+}
+
+// Encrypted reports whether the PEM-encoded private key needs a
+// passphrase.
+func Encrypted(pemBytes []byte) bool {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return false
+	}
+	if block.Type == "OPENSSH PRIVATE KEY" {
+		k, err := parseOpenSSH(block.Bytes)
+		return err == nil && k.cipher != "none"
+	}
+	return x509.IsEncryptedPEMBlock(block)
+}
+
+// ParseRawPrivateKey returns the private key in pemBytes, like
+// ssh.ParseRawPrivateKey.  Encrypted keys are decrypted with passphrase;
+// ErrPassphrase is returned if it doesn't fit.
+func ParseRawPrivateKey(pemBytes, passphrase []byte) (interface{}, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, ErrNoKey
+	}
+	switch {
+	case block.Type == "OPENSSH PRIVATE KEY":
+		k, err := parseOpenSSH(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if err := k.decrypt(passphrase); err != nil {
+			return nil, err
+		}
+		if key, err := k.ecdsaKey(); key != nil || err != nil {
+			return key, err
+		}
+		return ssh.ParseRawPrivateKey(pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: k.marshal()}))
+	case x509.IsEncryptedPEMBlock(block):
+		// Legacy PEM encryption has no reliable check, so a wrong
+		// passphrase usually shows up as a parse error.
+		key, err := ssh.ParseRawPrivateKeyWithPassphrase(pemBytes, passphrase)
+		if err != nil {
+			return nil, ErrPassphrase
+		}
+		return key, nil
+	default:
+		return ssh.ParseRawPrivateKey(pemBytes)
+	}
+}
+
+func parseOpenSSH(data []byte) (*opensshKey, error) {
+	if !bytes.HasPrefix(data, []byte(opensshMagic)) {
+		return nil, errors.New("sshkey: invalid OpenSSH private key")
+	}
+	data = data[len(opensshMagic):]
+	var fields [3][]byte
+	var ok bool
+	for i := range fields {
+		if fields[i], data, ok = readString(data); !ok {
+			return nil, errors.New("sshkey: truncated OpenSSH private key")
+		}
+	}
+	if len(data) < 4 {
+		return nil, errors.New("sshkey: truncated OpenSSH private key")
+	}
+	if n := binary.BigEndian.Uint32(data); n != 1 {
+		return nil, fmt.Errorf("sshkey: %d keys in one file; only one is supported", n)
+	}
+	k := &opensshKey{
+		cipher:     string(fields[0]),
+		kdf:        string(fields[1]),
+		kdfOptions: fields[2],
+	}
+	if k.publicKey, data, ok = readString(data[4:]); !ok {
+		return nil, errors.New("sshkey: truncated OpenSSH private key")
+	}
+	if k.private, _, ok = readString(data); !ok {
+		return nil, errors.New("sshkey: truncated OpenSSH private key")
+	}
+	return k, nil
+}
+
+// decrypt decrypts the private section in place and marks the key as
+// unencrypted.
+func (k *opensshKey) decrypt(passphrase []byte) error {
+	if k.cipher == "none" {
+		return nil
+	}
+	if k.kdf != "bcrypt" {
+		return fmt.Errorf("sshkey: unsupported key derivation %q", k.kdf)
+	}
+	salt, rest, ok := readString(k.kdfOptions)
+	if !ok || len(rest) != 4 {
+		return errors.New("sshkey: invalid bcrypt options")
+	}
+	rounds := int(binary.BigEndian.Uint32(rest))
+	var keyLen int
+	switch k.cipher {
+	case "aes128-ctr", "aes128-cbc":
+		keyLen = 16
+	case "aes192-ctr", "aes192-cbc":
+		keyLen = 24
+	case "aes256-ctr", "aes256-cbc":
+		keyLen = 32
+	default:
+		return fmt.Errorf("sshkey: unsupported cipher %q", k.cipher)
+	}
+	cbc := strings.HasSuffix(k.cipher, "-cbc")
+	if len(k.private)%aes.BlockSize != 0 {
+		return errors.New("sshkey: invalid private key length")
+	}
+	dk := bcryptPBKDF(passphrase, salt, rounds, keyLen+aes.BlockSize)
+	block, err := aes.NewCipher(dk[:keyLen])
+	if err != nil {
+		return err
+	}
+	iv := dk[keyLen:]
+	private := make([]byte, len(k.private))
+	if cbc {
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(private, k.private)
+	} else {
+		cipher.NewCTR(block, iv).XORKeyStream(private, k.private)
+	}
+	// The private section starts with the same random number twice.
+	if len(private) < 8 || !bytes.Equal(private[:4], private[4:8]) {
+		return ErrPassphrase
+	}
+	k.cipher, k.kdf, k.kdfOptions, k.private = "none", "none", nil, private
+	return nil
+}
+
+// ecdsaKey returns the decrypted key if it is an ECDSA key, which
+// golang.org/x/crypto/ssh doesn't read from OpenSSH-format files.  It
+// returns nil for other key types.
+func (k *opensshKey) ecdsaKey() (*ecdsa.PrivateKey, error) {
+	if len(k.private) < 8 {
+		return nil, nil
+	}
+	keyType, data, ok := readString(k.private[8:])
+	if !ok || !bytes.HasPrefix(keyType, []byte("ecdsa-sha2-")) {
+		return nil, nil
+	}
+	var curve elliptic.Curve
+	switch string(keyType) {
+	case ssh.KeyAlgoECDSA256:
+		curve = elliptic.P256()
+	case ssh.KeyAlgoECDSA384:
+		curve = elliptic.P384()
+	case ssh.KeyAlgoECDSA521:
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("sshkey: unsupported key type %q", keyType)
+	}
+	var fields [3][]byte // curve name, public point, private scalar
+	for i := range fields {
+		if fields[i], data, ok = readString(data); !ok {
+			return nil, errors.New("sshkey: truncated ECDSA private key")
+		}
+	}
+	x, y := elliptic.Unmarshal(curve, fields[1])
+	if x == nil {
+		return nil, errors.New("sshkey: invalid ECDSA public key")
+	}
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+		D:         new(big.Int).SetBytes(fields[2]),
+	}
+	if cx, cy := curve.ScalarBaseMult(key.D.Bytes()); cx.Cmp(x) != 0 || cy.Cmp(y) != 0 {
+		return nil, errors.New("sshkey: ECDSA private key does not match public key")
+	}
+	return key, nil
+}
+
+func (k *opensshKey) marshal() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(opensshMagic)
+	writeString(&buf, []byte(k.cipher))
+	writeString(&buf, []byte(k.kdf))
+	writeString(&buf, k.kdfOptions)
+	binary.Write(&buf, binary.BigEndian, uint32(1))
+	writeString(&buf, k.publicKey)
+	writeString(&buf, k.private)
+	return buf.Bytes()
+}
+
+// readString reads an SSH wire format string: a 32-bit big-endian length
+// followed by that many bytes.
+func readString(data []byte) (s, rest []byte, ok bool) {
+	if len(data) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(data)
+	if uint64(n) > uint64(len(data)-4) {
+		return nil, nil, false
+	}
+	return data[4 : 4+n], data[4+n:], true
+}
+
+func writeString(buf *bytes.Buffer, s []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(s)))
+	buf.Write(s)
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshkey
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseRawPrivateKey(t *testing.T) {
+	tests := []struct {
+		file      string
+		encrypted bool
+	}{
+		{"ed25519", false},
+		{"ed25519_encrypted", true},
+		{"ecdsa_encrypted", true},
+		{"rsa_legacy", true},
+	}
+	for _, test := range tests {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", test.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubData, err := ioutil.ReadFile(filepath.Join("testdata", test.file+".pub"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantPub, _, _, _, err := ssh.ParseAuthorizedKey(pubData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Encrypted(data); got != test.encrypted {
+			t.Errorf("Encrypted(%s) = %t; want %t", test.file, got, test.encrypted)
+		}
+		key, err := ParseRawPrivateKey(data, []byte("swordfish"))
+		if err != nil {
+			t.Errorf("ParseRawPrivateKey(%s): %v", test.file, err)
+			continue
+		}
+		signer, err := ssh.NewSignerFromKey(key)
+		if err != nil {
+			t.Errorf("ssh.NewSignerFromKey(%s): %v", test.file, err)
+			continue
+		}
+		if got, want := ssh.FingerprintSHA256(signer.PublicKey()), ssh.FingerprintSHA256(wantPub); got != want {
+			t.Errorf("ParseRawPrivateKey(%s) public key = %s; want %s", test.file, got, want)
+		}
+		if test.encrypted {
+			if _, err := ParseRawPrivateKey(data, []byte("trout")); err != ErrPassphrase {
+				t.Errorf("ParseRawPrivateKey(%s) with wrong passphrase error = %v; want %v", test.file, err, ErrPassphrase)
+			}
+		}
+	}
+}
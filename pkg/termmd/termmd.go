@@ -0,0 +1,266 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package termmd renders the Markdown commonly found in notes for display
+// in a terminal.  It understands headings, lists, block quotes, fenced
+// code blocks, rules, and inline code, emphasis and links; anything else is
+// shown as written.  Each source line stays one output line, so the
+// rendering never hides or reorders text.
+package termmd // import "github.com/pedroalbanese/gostpass/pkg/termmd"
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ANSI SGR sequences.  Each style is turned off with its own sequence
+// rather than a full reset, so that styles nest.
+const (
+	boldOn       = "\x1b[1m"
+	dimOn        = "\x1b[2m"
+	intensityOff = "\x1b[22m"
+	italicOn     = "\x1b[3m"
+	italicOff    = "\x1b[23m"
+	underlineOn  = "\x1b[4m"
+	underlineOff = "\x1b[24m"
+	codeOn       = "\x1b[36m"
+	colorOff     = "\x1b[39m"
+)
+
+// escapable are the characters that a backslash escapes.
+const escapable = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// rule is printed for a thematic break.
+var rule = strings.Repeat("─", 40)
+
+// A renderer holds the output style.
+type renderer struct {
+	color bool
+}
+
+func (r renderer) style(on, off, s string) string {
+	if !r.color {
+		return s
+	}
+	return on + s + off
+}
+
+// Sanitize removes the C0 and C1 control characters other than newline
+// and tab from s.  Notes come from whoever shared the database, and a
+// control character lets them drive the terminal: clear the screen, set
+// the clipboard with OSC 52 and worse.
+func Sanitize(s string) string {
+	return strings.Map(func(c rune) rune {
+		if c == '\n' || c == '\t' || !unicode.IsControl(c) {
+			return c
+		}
+		return -1
+	}, s)
+}
+
+// Render formats the Markdown src for a terminal.  If color is false, no
+// escape sequences are written; the markup is still replaced, so that
+// bullets and links read naturally.  Control characters in src are
+// removed, as by Sanitize; the only escape sequences in the output are
+// Render's own.
+func Render(src string, color bool) string {
+	r := renderer{color: color}
+	src = Sanitize(strings.Replace(src, "\r\n", "\n", -1))
+	var out []string
+	fence := ""
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				continue
+			}
+			out = append(out, "    "+r.style(codeOn, colorOff, expandTabs(line)))
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		out = append(out, r.block(line, trimmed))
+	}
+	return strings.Join(out, "\n")
+}
+
+// block renders a line outside a code block.
+func (r renderer) block(line, trimmed string) string {
+	if level, text := heading(trimmed); level > 0 {
+		if level == 1 {
+			return r.style(boldOn+underlineOn, underlineOff+intensityOff, r.inline(text))
+		}
+		return r.style(boldOn, intensityOff, r.inline(text))
+	}
+	if isRule(trimmed) {
+		return r.style(dimOn, intensityOff, rule)
+	}
+	if strings.HasPrefix(trimmed, ">") {
+		text := strings.TrimSpace(strings.TrimLeft(trimmed, ">"))
+		return r.style(dimOn, intensityOff, "│ ") + r.style(italicOn, italicOff, r.inline(text))
+	}
+	indent := strings.Repeat(" ", len(expandTabs(line))-len(strings.TrimLeft(expandTabs(line), " ")))
+	if marker, text := listItem(trimmed); marker != "" {
+		return indent + r.style(boldOn, intensityOff, marker) + " " + r.inline(text)
+	}
+	return r.inline(line)
+}
+
+// heading returns the level and text of an ATX heading, or 0.
+func heading(s string) (int, string) {
+	level := 0
+	for level < len(s) && s[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(s) && s[level] != ' ') {
+		return 0, ""
+	}
+	text := strings.TrimSpace(s[level:])
+	// A closing sequence of #s is not part of the text.
+	if t := strings.TrimRight(text, "#"); t != text && (t == "" || strings.HasSuffix(t, " ")) {
+		text = strings.TrimSpace(t)
+	}
+	return level, text
+}
+
+// isRule reports whether s is a thematic break: three or more -, * or _
+// and nothing else but spaces.
+func isRule(s string) bool {
+	if s == "" {
+		return false
+	}
+	c := s[0]
+	if c != '-' && c != '*' && c != '_' {
+		return false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case c:
+			n++
+		case ' ', '\t':
+		default:
+			return false
+		}
+	}
+	return n >= 3
+}
+
+// listItem returns the marker to show for a list item and the item's
+// text, or "" if s is not a list item.  Bullets become •; numbers are
+// kept.
+func listItem(s string) (marker, text string) {
+	if len(s) >= 2 && strings.IndexByte("-*+", s[0]) >= 0 && s[1] == ' ' {
+		text = strings.TrimSpace(s[2:])
+		// Task list items keep their checkbox.
+		switch {
+		case strings.HasPrefix(text, "[ ] "):
+			return "☐", text[4:]
+		case strings.HasPrefix(text, "[x] "), strings.HasPrefix(text, "[X] "):
+			return "☑", text[4:]
+		}
+		return "•", text
+	}
+	i := 0
+	for i < len(s) && i < 9 && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i > 0 && i+1 < len(s) && (s[i] == '.' || s[i] == ')') && s[i+1] == ' ' {
+		return s[:i+1], strings.TrimSpace(s[i+2:])
+	}
+	return "", ""
+}
+
+// inline renders code spans, emphasis and links in s.
+func (r renderer) inline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(escapable, s[i+1]) >= 0:
+			b.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			if j := strings.IndexByte(s[i+1:], '`'); j >= 0 {
+				b.WriteString(r.style(codeOn, colorOff, s[i+1:i+1+j]))
+				i += j + 2
+				continue
+			}
+		case (c == '*' || c == '_') && strings.HasPrefix(s[i:], string([]byte{c, c})):
+			delim := s[i : i+2]
+			if j := strings.Index(s[i+2:], delim); j > 0 && opens(s, i, 2) {
+				b.WriteString(r.style(boldOn, intensityOff, r.inline(s[i+2:i+2+j])))
+				i += j + 4
+				continue
+			}
+		case c == '*' || c == '_':
+			if j := strings.IndexByte(s[i+1:], c); j > 0 && opens(s, i, 1) && s[i+j] != ' ' {
+				b.WriteString(r.style(italicOn, italicOff, r.inline(s[i+1:i+1+j])))
+				i += j + 2
+				continue
+			}
+		case c == '[':
+			if text, url, n := link(s[i:]); n > 0 {
+				if text == url || text == "" {
+					b.WriteString(r.style(underlineOn, underlineOff, url))
+				} else {
+					b.WriteString(r.inline(text) + " " + r.style(dimOn, intensityOff, "("+url+")"))
+				}
+				i += n
+				continue
+			}
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String()
+}
+
+// opens reports whether the n-character delimiter at s[i] can open
+// emphasis: it is followed by a non-space and, for _, does not sit inside
+// a word like snake_case.
+func opens(s string, i, n int) bool {
+	if i+n >= len(s) || s[i+n] == ' ' {
+		return false
+	}
+	if s[i] == '_' && i > 0 {
+		p := rune(s[i-1])
+		return !unicode.IsLetter(p) && !unicode.IsDigit(p)
+	}
+	return true
+}
+
+// link parses an inline link [text](url) at the start of s and returns
+// its text, its URL and its length, or 0 if there is none.
+func link(s string) (text, url string, n int) {
+	mid := strings.Index(s, "](")
+	if mid < 0 {
+		return "", "", 0
+	}
+	end := strings.IndexByte(s[mid+2:], ')')
+	if end < 0 {
+		return "", "", 0
+	}
+	return s[1:mid], s[mid+2 : mid+2+end], mid + 2 + end + 1
+}
+
+// expandTabs replaces tabs with four spaces, so that indentation looks the
+// same in any terminal.
+func expandTabs(s string) string {
+	return strings.Replace(s, "\t", "    ", -1)
+}
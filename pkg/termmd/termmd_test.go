@@ -0,0 +1,87 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package termmd
+
+import "testing"
+
+func TestRenderPlain(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"# Recovery ##", "Recovery"},
+		{"### Steps", "Steps"},
+		{"#hashtag", "#hashtag"},
+		{"- one\n  * two\n+ three", "• one\n  • two\n• three"},
+		{"1. first\n10) tenth", "1. first\n10) tenth"},
+		{"- [ ] todo\n- [x] done", "☐ todo\n☑ done"},
+		{"> call the on-call", "│ call the on-call"},
+		{"---\n* * *", rule + "\n" + rule},
+		{"run `ssh -A host` **now**", "run ssh -A host now"},
+		{"*very* _important_ and snake_case_name", "very important and snake_case_name"},
+		{"2 * 3 * 4", "2 * 3 * 4"},
+		{"see [the wiki](https://wiki.example.com)", "see the wiki (https://wiki.example.com)"},
+		{"[https://x.example](https://x.example)", "https://x.example"},
+		{`\*not emphasis\*`, "*not emphasis*"},
+		{"```sh\n# not a heading\n\tsudo reboot\n```\nafter", "    # not a heading\n        sudo reboot\nafter"},
+		{"unclosed **bold and `code", "unclosed **bold and `code"},
+		{"a\x1b]52;c;cm0gLXJmIH4=\x07b\r\nc\rd", "a]52;c;cm0gLXJmIH4=b\ncd"},
+	}
+	for _, test := range tests {
+		if got := Render(test.src, false); got != test.want {
+			t.Errorf("Render(%q, false) = %q; want %q", test.src, got, test.want)
+		}
+	}
+}
+
+func TestRenderColor(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"# Title", "\x1b[1m\x1b[4mTitle\x1b[24m\x1b[22m"},
+		{"## Sub", "\x1b[1mSub\x1b[22m"},
+		{"- `x`", "\x1b[1m•\x1b[22m \x1b[36mx\x1b[39m"},
+		{"**a _b_**", "\x1b[1ma \x1b[3mb\x1b[23m\x1b[22m"},
+		{"```\ncode\n```", "    \x1b[36mcode\x1b[39m"},
+		{"**\x1b[2Jx**", "\x1b[1m[2Jx\x1b[22m"},
+	}
+	for _, test := range tests {
+		if got := Render(test.src, true); got != test.want {
+			t.Errorf("Render(%q, true) = %q; want %q", test.src, got, test.want)
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"plain\ttext\n", "plain\ttext\n"},
+		{"\x1b[2Jclear", "[2Jclear"},
+		{"\x1b]52;c;aGk=\x07", "]52;c;aGk="},
+		{"bell\x07 nul\x00 del\x7f", "bell nul del"},
+		{"c1 \u009b31m csi \u009d0;x\u009c", "c1 31m csi 0;x"},
+		{"\xc2\x9b", ""},
+		{"\x9b", "\ufffd"},
+		{"ünïcødé ✓", "ünïcødé ✓"},
+	}
+	for _, test := range tests {
+		if got := Sanitize(test.s); got != test.want {
+			t.Errorf("Sanitize(%q) = %q; want %q", test.s, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package urlmatch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// A List is a set of public suffix rules in the format of the Public
+// Suffix List (https://publicsuffix.org/list/): one rule per line, with
+// "*." wildcards and "!" exceptions.
+type List struct {
+	normal    map[string]bool
+	wildcard  map[string]bool // "*.name" rules, by name
+	exception map[string]bool // "!name" rules, by name
+}
+
+// ParseList reads a list in Public Suffix List format, such as
+// public_suffix_list.dat.  Comments and blank lines are skipped.
+func ParseList(r io.Reader) (*List, error) {
+	l := &List{
+		normal:    make(map[string]bool),
+		wildcard:  make(map[string]bool),
+		exception: make(map[string]bool),
+	}
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		line = strings.ToLower(line)
+		switch {
+		case strings.HasPrefix(line, "!"):
+			l.exception[line[1:]] = true
+		case strings.HasPrefix(line, "*."):
+			l.wildcard[line[2:]] = true
+		case strings.Contains(line, "*"):
+			return nil, fmt.Errorf("urlmatch: public suffix list line %d: unsupported wildcard %q", n, line)
+		default:
+			l.normal[line] = true
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("urlmatch: read public suffix list: %v", err)
+	}
+	return l, nil
+}
+
+// PublicSuffix returns the public suffix of host, like "co.uk" for
+// "mail.example.co.uk".  Hosts under no rule have their last label as the
+// suffix, as the list's algorithm prescribes.
+func (l *List) PublicSuffix(host string) string {
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(host), "."), ".")
+	for i := range labels {
+		if l.exception[strings.Join(labels[i:], ".")] {
+			return strings.Join(labels[i+1:], ".")
+		}
+	}
+	n := 1
+	for i := range labels {
+		count := len(labels) - i
+		if count <= n {
+			break
+		}
+		if l.normal[strings.Join(labels[i:], ".")] || i+1 < len(labels) && l.wildcard[strings.Join(labels[i+1:], ".")] {
+			n = count
+			break
+		}
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// BaseDomain returns the registrable domain of host: its public suffix and
+// one more label, like "example.co.uk" for "mail.example.co.uk".  IP
+// addresses and hosts that are public suffixes themselves are returned as
+// they are.
+func (l *List) BaseDomain(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	suffix := l.PublicSuffix(host)
+	if len(host) <= len(suffix) {
+		return host
+	}
+	rest := host[:len(host)-len(suffix)-1]
+	if i := strings.LastIndexByte(rest, '.'); i >= 0 {
+		rest = rest[i+1:]
+	}
+	return rest + "." + suffix
+}
+
+// DefaultList is a small built-in excerpt of the Public Suffix List,
+// covering common second-level registries and hosting domains.  Load the
+// full list with ParseList where precision matters.
+var DefaultList *List
+
+func init() {
+	var err error
+	DefaultList, err = ParseList(strings.NewReader(defaultRules))
+	if err != nil {
+		panic(err)
+	}
+}
+
+const defaultRules = `// ICANN
+ac.uk
+co.uk
+gov.uk
+ltd.uk
+me.uk
+net.uk
+org.uk
+plc.uk
+com.au
+edu.au
+gov.au
+net.au
+org.au
+co.nz
+net.nz
+org.nz
+co.jp
+ne.jp
+or.jp
+ac.jp
+go.jp
+com.br
+net.br
+org.br
+com.cn
+net.cn
+org.cn
+com.tr
+com.mx
+co.in
+net.in
+org.in
+co.kr
+com.sg
+com.hk
+com.tw
+co.za
+com.ua
+kiev.ua
+com.by
+com.kz
+ac.ru
+com.ru
+int.ru
+msk.ru
+net.ru
+org.ru
+pp.ru
+spb.ru
+*.ck
+!www.ck
+// Private
+appspot.com
+azurewebsites.net
+blogspot.com
+cloudfront.net
+github.io
+gitlab.io
+herokuapp.com
+netlify.app
+pages.dev
+vercel.app
+`
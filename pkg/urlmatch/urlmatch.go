@@ -0,0 +1,162 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package urlmatch decides whether an entry's URL applies to a page, for
+// autofill and lookups by URL.
+//
+// The default, BaseDomain, matches any host under the same registrable
+// domain as determined by the Public Suffix List, so an entry for
+// accounts.example.co.uk applies to www.example.co.uk but not to
+// example.co.uk.evil.io or other.co.uk.  An entry whose URL uses https
+// never matches a plain http page.
+package urlmatch // import "github.com/pedroalbanese/gostpass/pkg/urlmatch"
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Mode is how an entry's URL is compared with a page's.
+type Mode int
+
+// Modes, from loosest to strictest.
+const (
+	BaseDomain Mode = iota // same registrable domain
+	Host                   // same host and port
+	StartsWith             // page URL starts with the entry's
+	Exact                  // same URL, apart from the fragment
+	Regexp                 // page URL matches a regular expression
+	Never                  // never matches
+)
+
+var modeNames = [...]string{"base", "host", "prefix", "exact", "regex", "never"}
+
+func (m Mode) String() string {
+	if m < 0 || int(m) >= len(modeNames) {
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+	return modeNames[m]
+}
+
+// ParseMode returns the mode named by s, as returned by Mode.String.
+func ParseMode(s string) (Mode, error) {
+	for m, name := range modeNames {
+		if s == name {
+			return Mode(m), nil
+		}
+	}
+	return 0, fmt.Errorf("urlmatch: unknown mode %q; want one of %s", s, strings.Join(modeNames[:], ", "))
+}
+
+// A Rule matches page URLs for one entry.
+type Rule struct {
+	URL  string // entry's URL; a missing scheme matches http and https
+	Mode Mode
+
+	// Pattern is the regular expression for Regexp mode.  It must match
+	// the whole page URL.
+	Pattern string
+
+	// List holds the public suffixes for BaseDomain mode.  If nil,
+	// DefaultList is used.
+	List *List
+}
+
+// parse parses a URL that may lack a scheme, like "example.com/login".
+func parse(s string) (*url.URL, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "://") {
+		s = "//" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u, nil
+}
+
+// schemeAllowed reports whether a page with scheme page may use an entry
+// with scheme entry.  Upgrading from http to https is fine; the reverse
+// would send the credentials in the clear.
+func schemeAllowed(entry, page string) bool {
+	switch entry {
+	case "":
+		return page == "" || page == "http" || page == "https"
+	case "http":
+		return page == "http" || page == "https"
+	default:
+		return page == entry
+	}
+}
+
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+func port(u *url.URL) string {
+	if p := u.Port(); p != "" {
+		return p
+	}
+	return defaultPorts[u.Scheme]
+}
+
+// Match reports whether the rule's entry applies to the page at pageURL.
+func (r *Rule) Match(pageURL string) (bool, error) {
+	switch r.Mode {
+	case Never:
+		return false, nil
+	case Regexp:
+		re, err := regexp.Compile(`^(?:` + r.Pattern + `)$`)
+		if err != nil {
+			return false, fmt.Errorf("urlmatch: %v", err)
+		}
+		return re.MatchString(pageURL), nil
+	}
+	entry, err := parse(r.URL)
+	if err != nil {
+		return false, fmt.Errorf("urlmatch: entry URL: %v", err)
+	}
+	page, err := parse(pageURL)
+	if err != nil {
+		return false, fmt.Errorf("urlmatch: page URL: %v", err)
+	}
+	if entry.Hostname() == "" || page.Hostname() == "" {
+		return false, nil
+	}
+	if !schemeAllowed(entry.Scheme, page.Scheme) {
+		return false, nil
+	}
+	switch r.Mode {
+	case BaseDomain:
+		l := r.List
+		if l == nil {
+			l = DefaultList
+		}
+		return l.BaseDomain(entry.Hostname()) == l.BaseDomain(page.Hostname()), nil
+	case Host:
+		return entry.Hostname() == page.Hostname() && (entry.Scheme == "" || port(entry) == port(page)), nil
+	case StartsWith:
+		return entry.Host == page.Host && strings.HasPrefix(page.RequestURI(), entry.RequestURI()), nil
+	case Exact:
+		return entry.Host == page.Host && strings.TrimSuffix(entry.RequestURI(), "/") == strings.TrimSuffix(page.RequestURI(), "/"), nil
+	default:
+		return false, fmt.Errorf("urlmatch: unknown mode %v", r.Mode)
+	}
+}
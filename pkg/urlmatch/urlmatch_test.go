@@ -0,0 +1,98 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package urlmatch
+
+import "testing"
+
+func TestPublicSuffix(t *testing.T) {
+	tests := []struct {
+		host, suffix, base string
+	}{
+		{"example.com", "com", "example.com"},
+		{"mail.example.co.uk", "co.uk", "example.co.uk"},
+		{"co.uk", "co.uk", "co.uk"},
+		{"WWW.Example.COM.", "com", "example.com"},
+		{"user.github.io", "github.io", "user.github.io"},
+		{"a.b.foo.ck", "foo.ck", "b.foo.ck"},
+		{"www.ck", "ck", "www.ck"},
+		{"localhost", "localhost", "localhost"},
+		{"192.0.2.1", "1", "192.0.2.1"},
+	}
+	for _, test := range tests {
+		if got := DefaultList.PublicSuffix(test.host); got != test.suffix {
+			t.Errorf("PublicSuffix(%q) = %q; want %q", test.host, got, test.suffix)
+		}
+		if got := DefaultList.BaseDomain(test.host); got != test.base {
+			t.Errorf("BaseDomain(%q) = %q; want %q", test.host, got, test.base)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		page string
+		want bool
+	}{
+		{Rule{URL: "https://accounts.example.co.uk/login"}, "https://www.example.co.uk/", true},
+		{Rule{URL: "https://example.co.uk"}, "https://other.co.uk/", false},
+		{Rule{URL: "https://example.com"}, "https://example.com.evil.io/", false},
+		{Rule{URL: "https://example.com"}, "https://notexample.com/", false},
+		{Rule{URL: "https://example.com"}, "http://example.com/", false},
+		{Rule{URL: "http://example.com"}, "https://example.com/", true},
+		{Rule{URL: "example.com"}, "https://www.example.com/", true},
+		{Rule{URL: "example.com"}, "ftp://example.com/", false},
+		{Rule{URL: "alice.github.io"}, "https://bob.github.io/", false},
+
+		{Rule{URL: "https://example.com", Mode: Host}, "https://example.com:443/a", true},
+		{Rule{URL: "https://example.com", Mode: Host}, "https://www.example.com/", false},
+		{Rule{URL: "https://example.com:8443", Mode: Host}, "https://example.com/", false},
+
+		{Rule{URL: "https://example.com/app/", Mode: StartsWith}, "https://example.com/app/login?x=1", true},
+		{Rule{URL: "https://example.com/app/", Mode: StartsWith}, "https://example.com/other", false},
+
+		{Rule{URL: "https://example.com/login", Mode: Exact}, "https://example.com/login/#top", true},
+		{Rule{URL: "https://example.com/login", Mode: Exact}, "https://example.com/login?next=/", false},
+
+		{Rule{Mode: Regexp, Pattern: `https://(www\.)?example\.com/.*`}, "https://www.example.com/a", true},
+		{Rule{Mode: Regexp, Pattern: `https://example\.com/.*`}, "https://example.com.evil.io/https://example.com/", false},
+
+		{Rule{URL: "https://example.com", Mode: Never}, "https://example.com/", false},
+	}
+	for _, test := range tests {
+		got, err := test.rule.Match(test.page)
+		if err != nil {
+			t.Errorf("%+v.Match(%q): %v", test.rule, test.page, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%+v.Match(%q) = %t; want %t", test.rule, test.page, got, test.want)
+		}
+	}
+	if _, err := (&Rule{Mode: Regexp, Pattern: "("}).Match("https://example.com/"); err == nil {
+		t.Error("Match with bad pattern succeeded")
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for m := BaseDomain; m <= Never; m++ {
+		if got, err := ParseMode(m.String()); got != m || err != nil {
+			t.Errorf("ParseMode(%q) = %v, %v; want %v, <nil>", m.String(), got, err, m)
+		}
+	}
+	if _, err := ParseMode("fuzzy"); err == nil {
+		t.Error("ParseMode(\"fuzzy\") succeeded")
+	}
+}
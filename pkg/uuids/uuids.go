@@ -20,6 +20,7 @@ import (
 	"crypto/md5"
 	"crypto/rand"
 	"crypto/sha1"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"hash"
@@ -60,6 +61,21 @@ func Parse(s string) (UUID, error) {
 	return u, nil
 }
 
+// ParseBase64 parses a UUID in the standard base64 encoding of its 16
+// bytes, as used in KeePass 2 XML files.
+func ParseBase64(s string) (UUID, error) {
+	var u UUID
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return UUID{}, parseError{s, err}
+	}
+	if len(b) != len(u) {
+		return UUID{}, parseError{s, errSize}
+	}
+	copy(u[:], b)
+	return u, nil
+}
+
 var errSize = errors.New("wrong size")
 
 type parseError struct {
@@ -146,6 +162,12 @@ func (u UUID) IsZero() bool {
 	return u == UUID{}
 }
 
+// Base64 returns the standard base64 encoding of u's bytes, as used in
+// KeePass 2 XML files.
+func (u UUID) Base64() string {
+	return base64.StdEncoding.EncodeToString(u[:])
+}
+
 // String returns the dash-separated hex representation of u as a string.
 func (u UUID) String() string {
 	b := make([]byte, 0, 36)
@@ -126,6 +126,32 @@ func TestString(t *testing.T) {
 	}
 }
 
+func TestBase64(t *testing.T) {
+	tests := []struct {
+		u UUID
+		s string
+	}{
+		{UUID{}, "AAAAAAAAAAAAAAAAAAAAAA=="},
+		{DNS, "a6e4EJ2tEdGAtADAT9QwyA=="},
+	}
+	for _, test := range tests {
+		if s := test.u.Base64(); s != test.s {
+			t.Errorf("UUID(%v).Base64() = %q; want %q", [16]byte(test.u), s, test.s)
+		}
+		u, err := ParseBase64(test.s)
+		if err != nil {
+			t.Errorf("ParseBase64(%q) error: %v", test.s, err)
+		} else if u != test.u {
+			t.Errorf("ParseBase64(%q) = %v; want %v", test.s, u, test.u)
+		}
+	}
+	for _, bad := range []string{"", "AAAA", "a6e4EJ2tEdGAtADAT9QwyA", "not base64!"} {
+		if _, err := ParseBase64(bad); err == nil {
+			t.Errorf("ParseBase64(%q) did not return an error", bad)
+		}
+	}
+}
+
 func TestNew3(t *testing.T) {
 	tests := []struct {
 		namespace UUID
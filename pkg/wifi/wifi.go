@@ -0,0 +1,172 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wifi encodes Wi-Fi network credentials in the forms that phones
+// and other devices join networks from: the WIFI: URI that camera apps
+// read from QR codes, and the Wi-Fi Simple Configuration NDEF record that
+// NFC tags carry.
+package wifi // import "github.com/pedroalbanese/gostpass/pkg/wifi"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Security is a network's authentication type, named as in WIFI: URIs.
+type Security string
+
+// Security types.
+const (
+	Open Security = "nopass"
+	WEP  Security = "WEP"
+	WPA  Security = "WPA" // WPA or WPA2 Personal
+	SAE  Security = "SAE" // WPA3 Personal
+)
+
+// ParseSecurity parses a security type.  It accepts the WIFI: URI names
+// and common spellings like "wpa2", "wpa3" and "none", in any case.
+func ParseSecurity(s string) (Security, error) {
+	switch strings.ToLower(s) {
+	case "nopass", "none", "open", "":
+		return Open, nil
+	case "wep":
+		return WEP, nil
+	case "wpa", "wpa2", "wpa/wpa2", "wpa-psk", "wpa2-psk":
+		return WPA, nil
+	case "sae", "wpa3":
+		return SAE, nil
+	}
+	return "", fmt.Errorf("wifi: unknown security type %q; want nopass, WEP, WPA or SAE", s)
+}
+
+// A Network holds what a device needs to join a network.
+type Network struct {
+	SSID     string
+	Password string
+	Security Security
+	Hidden   bool
+}
+
+// Errors
+var (
+	ErrSSID     = errors.New("wifi: SSID must be 1 to 32 bytes")
+	ErrPassword = errors.New("wifi: network needs a password unless its security is nopass")
+)
+
+func (n *Network) check() error {
+	if len(n.SSID) == 0 || len(n.SSID) > 32 {
+		return ErrSSID
+	}
+	if n.Security != Open && n.Password == "" {
+		return ErrPassword
+	}
+	return nil
+}
+
+// uriEscaper escapes the characters that are special in WIFI: URI fields.
+var uriEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `"`, `\"`, `:`, `\:`)
+
+// URI returns the network as a WIFI: URI, the payload of a Wi-Fi QR code.
+func (n *Network) URI() (string, error) {
+	if err := n.check(); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString("WIFI:T:" + string(n.Security) + ";S:" + uriEscaper.Replace(n.SSID) + ";")
+	if n.Security != Open {
+		b.WriteString("P:" + uriEscaper.Replace(n.Password) + ";")
+	}
+	if n.Hidden {
+		b.WriteString("H:true;")
+	}
+	b.WriteString(";")
+	return b.String(), nil
+}
+
+// MIMEType is the NDEF record type of Wi-Fi Simple Configuration data.
+const MIMEType = "application/vnd.wfa.wsc"
+
+// Wi-Fi Simple Configuration attribute IDs.
+const (
+	attrAuthType   = 0x1003
+	attrCredential = 0x100e
+	attrEncrType   = 0x100f
+	attrMACAddress = 0x1020
+	attrNetworkKey = 0x1027
+	attrNetIndex   = 0x1026
+	attrSSID       = 0x1045
+	attrVendorExt  = 0x1049
+	attrVersion    = 0x104a
+)
+
+// Authentication and encryption type values.  WPA3 has no value of its
+// own in Wi-Fi Simple Configuration; SAE networks are written as WPA2,
+// which transition-mode networks accept.
+var wscTypes = map[Security][2]uint16{
+	Open: {0x0001, 0x0001},
+	WEP:  {0x0001, 0x0002},
+	WPA:  {0x0020, 0x0008},
+	SAE:  {0x0020, 0x0008},
+}
+
+func appendAttr(b []byte, id uint16, value []byte) []byte {
+	b = append(b, byte(id>>8), byte(id), byte(len(value)>>8), byte(len(value)))
+	return append(b, value...)
+}
+
+func uint16Value(v uint16) []byte {
+	return []byte{byte(v >> 8), byte(v)}
+}
+
+// NDEF returns the network as an NDEF message holding one Wi-Fi Simple
+// Configuration record, ready to be written to an NFC tag.
+func (n *Network) NDEF() ([]byte, error) {
+	if err := n.check(); err != nil {
+		return nil, err
+	}
+	if len(n.Password) > 64 {
+		return nil, errors.New("wifi: password must be at most 64 bytes")
+	}
+	types := wscTypes[n.Security]
+	var cred []byte
+	cred = appendAttr(cred, attrNetIndex, []byte{1})
+	cred = appendAttr(cred, attrSSID, []byte(n.SSID))
+	cred = appendAttr(cred, attrAuthType, uint16Value(types[0]))
+	cred = appendAttr(cred, attrEncrType, uint16Value(types[1]))
+	cred = appendAttr(cred, attrNetworkKey, []byte(n.Password))
+	// The broadcast address: any access point with the SSID.
+	cred = appendAttr(cred, attrMACAddress, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+
+	var payload []byte
+	payload = appendAttr(payload, attrVersion, []byte{0x10})
+	payload = appendAttr(payload, attrCredential, cred)
+	// Wi-Fi Alliance vendor extension with Version2 = 2.0.
+	payload = appendAttr(payload, attrVendorExt, []byte{0x00, 0x37, 0x2a, 0x00, 0x01, 0x20})
+	return ndefRecord(MIMEType, payload), nil
+}
+
+// ndefRecord returns a message of one NDEF short record with a MIME media
+// type.  Network payloads are always short enough.
+func ndefRecord(mimeType string, payload []byte) []byte {
+	const (
+		mb      = 0x80 // message begin
+		me      = 0x40 // message end
+		sr      = 0x10 // short record: one-byte payload length
+		tnfMIME = 0x02
+	)
+	b := []byte{mb | me | sr | tnfMIME, byte(len(mimeType)), byte(len(payload))}
+	b = append(b, mimeType...)
+	return append(b, payload...)
+}
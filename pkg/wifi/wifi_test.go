@@ -0,0 +1,80 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wifi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestURI(t *testing.T) {
+	tests := []struct {
+		n    Network
+		want string
+	}{
+		{Network{SSID: "Home", Password: "hunter22", Security: WPA}, "WIFI:T:WPA;S:Home;P:hunter22;;"},
+		{Network{SSID: `a;b,c"d:e\f`, Password: "p;w", Security: SAE, Hidden: true}, `WIFI:T:SAE;S:a\;b\,c\"d\:e\\f;P:p\;w;H:true;;`},
+		{Network{SSID: "Café", Password: "ignored", Security: Open}, "WIFI:T:nopass;S:Café;;"},
+	}
+	for _, test := range tests {
+		got, err := test.n.URI()
+		if err != nil || got != test.want {
+			t.Errorf("%+v.URI() = %q, %v; want %q, <nil>", test.n, got, err, test.want)
+		}
+	}
+	if _, err := (&Network{SSID: "Home", Security: WEP}).URI(); err != ErrPassword {
+		t.Errorf("URI() without password = %v; want %v", err, ErrPassword)
+	}
+	if _, err := (&Network{Security: Open}).URI(); err != ErrSSID {
+		t.Errorf("URI() without SSID = %v; want %v", err, ErrSSID)
+	}
+}
+
+func TestParseSecurity(t *testing.T) {
+	for s, want := range map[string]Security{"": Open, "none": Open, "WEP": WEP, "wpa2": WPA, "WPA3": SAE} {
+		if got, err := ParseSecurity(s); err != nil || got != want {
+			t.Errorf("ParseSecurity(%q) = %q, %v; want %q, <nil>", s, got, err, want)
+		}
+	}
+	if _, err := ParseSecurity("wpa-enterprise"); err == nil {
+		t.Error("ParseSecurity(\"wpa-enterprise\") succeeded")
+	}
+}
+
+func TestNDEF(t *testing.T) {
+	n := &Network{SSID: "Home", Password: "hunter22", Security: WPA}
+	got, err := n.NDEF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := hex.DecodeString("d2" + "17" + "42" +
+		hex.EncodeToString([]byte(MIMEType)) +
+		"104a000110" +
+		"100e002f" +
+		"1026000101" +
+		"10450004" + hex.EncodeToString([]byte("Home")) +
+		"100300020020" +
+		"100f00020008" +
+		"10270008" + hex.EncodeToString([]byte("hunter22")) +
+		"10200006ffffffffffff" +
+		"10490006" + "00372a000120")
+	if !bytes.Equal(got, want) {
+		t.Errorf("NDEF() =\n%x\nwant\n%x", got, want)
+	}
+	if int(got[2]) != len(got)-3-len(MIMEType) {
+		t.Errorf("payload length %d; record has %d bytes of payload", got[2], len(got)-3-len(MIMEType))
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+var (
+	pluginsPath = flag.String("plugins", "", "directory of plugin executables (default is gostpass/plugins in the user's configuration directory)")
+	keyPlugin   = flag.String("key_plugin", "", "name of a plugin that gives the database password, instead of asking for it")
+)
+
+// Plugins are executables in the plugins directory, named by their file
+// name without extension.  gostpass runs a plugin once per request, writes
+// the request to its standard input as a JSON object and reads the
+// response from its standard output:
+//
+//	{"type": "describe"}
+//	    {"description": "...", "capabilities": ["import", "key", "audit"]}
+//	{"type": "import", "filename": "export.csv", "data": "<base64>"}
+//	    {"entries": [{"path": "Group/Title", "username": "...", "password": "...", "url": "...", "notes": "..."}]}
+//	{"type": "key", "db": "/path/to/vault.kdb"}
+//	    {"password": "..."}
+//	{"type": "audit", "entries": [{"path": "...", "username": "...", "password": "...", "url": "...", "modified": "..."}]}
+//	    {"findings": [{"check": "...", "entries": ["Group/Title"], "detail": "..."}]}
+//
+// A plugin reports failure by exiting with a non-zero status or by setting
+// "error" in its response.  Its standard error is passed through.
+
+// A pluginRequest is sent to a plugin.
+type pluginRequest struct {
+	Type     string        `json:"type"`
+	Filename string        `json:"filename,omitempty"`
+	Data     []byte        `json:"data,omitempty"`
+	DB       string        `json:"db,omitempty"`
+	Entries  []pluginEntry `json:"entries,omitempty"`
+}
+
+// A pluginResponse is read from a plugin.
+type pluginResponse struct {
+	Error        string         `json:"error"`
+	Description  string         `json:"description"`
+	Capabilities []string       `json:"capabilities"`
+	Entries      []pluginEntry  `json:"entries"`
+	Password     string         `json:"password"`
+	Findings     []auditFinding `json:"findings"`
+}
+
+// A pluginEntry is an entry as plugins see it.
+type pluginEntry struct {
+	Path     string     `json:"path"`
+	Username string     `json:"username,omitempty"`
+	Password string     `json:"password,omitempty"`
+	URL      string     `json:"url,omitempty"`
+	Notes    string     `json:"notes,omitempty"`
+	Modified *time.Time `json:"modified,omitempty"`
+}
+
+func pluginsDir() (string, error) {
+	if *pluginsPath != "" {
+		return *pluginsPath, nil
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if runtime.GOOS == "windows" {
+		configHome = os.Getenv("APPDATA")
+	}
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("plugins: %v", err)
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "gostpass", "plugins"), nil
+}
+
+// listPlugins returns the plugins' names and paths.
+func listPlugins() (map[string]string, error) {
+	dir, err := pluginsDir()
+	if err != nil {
+		return nil, err
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("plugins: %v", err)
+	}
+	plugins := make(map[string]string)
+	for _, fi := range infos {
+		name := fi.Name()
+		if runtime.GOOS == "windows" {
+			if !strings.EqualFold(filepath.Ext(name), ".exe") {
+				continue
+			}
+		} else if !fi.Mode().IsRegular() || fi.Mode()&0111 == 0 {
+			continue
+		}
+		plugins[strings.TrimSuffix(name, filepath.Ext(name))] = filepath.Join(dir, name)
+	}
+	return plugins, nil
+}
+
+func sortedPluginNames(plugins map[string]string) []string {
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// callPlugin sends req to the named plugin and returns its response.
+func callPlugin(name string, req *pluginRequest) (*pluginResponse, error) {
+	plugins, err := listPlugins()
+	if err != nil {
+		return nil, err
+	}
+	path := plugins[name]
+	if path == "" {
+		return nil, fmt.Errorf("no plugin named %q", name)
+	}
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %v", name, err)
+	}
+	cmd := exec.Command(path)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %v", name, err)
+	}
+	resp := new(pluginResponse)
+	if err := json.Unmarshal(out, resp); err != nil {
+		return nil, fmt.Errorf("plugin %s: bad response: %v", name, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", name, resp.Error)
+	}
+	return resp, nil
+}
+
+// pluginsWith returns the names of the plugins that have capability.
+func pluginsWith(capability string) ([]string, error) {
+	plugins, err := listPlugins()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, name := range sortedPluginNames(plugins) {
+		resp, err := callPlugin(name, &pluginRequest{Type: "describe"})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range resp.Capabilities {
+			if c == capability {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// pluginPassword asks the -key_plugin plugin for the database password.
+func pluginPassword() (string, error) {
+	db, err := filepath.Abs(*dbPath)
+	if err != nil {
+		db = *dbPath
+	}
+	resp, err := callPlugin(*keyPlugin, &pluginRequest{Type: "key", DB: db})
+	if err != nil {
+		return "", err
+	}
+	secrets.Add(resp.Password)
+	return resp.Password, nil
+}
+
+// checkPlugins runs the audit plugins over entries.  The plugins see the
+// passwords, like any other audit check.
+func checkPlugins(entries []*keepass.Entry) ([]auditFinding, error) {
+	names, err := pluginsWith("audit")
+	if err != nil || len(names) == 0 {
+		return nil, err
+	}
+	req := &pluginRequest{Type: "audit"}
+	for _, e := range entries {
+		modified := e.LastModificationTime
+		req.Entries = append(req.Entries, pluginEntry{
+			Path:     entryPath(e),
+			Username: e.Username,
+			Password: e.Password,
+			URL:      e.URL,
+			Notes:    e.Notes,
+			Modified: &modified,
+		})
+	}
+	var findings []auditFinding
+	for _, name := range names {
+		resp, err := callPlugin(name, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range resp.Findings {
+			if f.Check == "" {
+				f.Check = name
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+// runPlugin lists plugins or imports a file with one.
+func runPlugin(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: plugin list | plugin import [-group path] name file")
+	}
+	switch args[0] {
+	case "list":
+		return listPluginsCommand()
+	case "import":
+		return importWithPlugin(args[1:])
+	default:
+		return fmt.Errorf("unknown plugin command %q; want list or import", args[0])
+	}
+}
+
+func listPluginsCommand() error {
+	plugins, err := listPlugins()
+	if err != nil {
+		return err
+	}
+	for _, name := range sortedPluginNames(plugins) {
+		resp, err := callPlugin(name, &pluginRequest{Type: "describe"})
+		if err != nil {
+			fmt.Printf("%s\t-\t%v\n", name, err)
+			continue
+		}
+		fmt.Printf("%s\t%s\t%s\n", name, strings.Join(resp.Capabilities, ","), resp.Description)
+	}
+	return nil
+}
+
+func importWithPlugin(args []string) error {
+	fs := flag.NewFlagSet("plugin import", flag.ContinueOnError)
+	group := fs.String("group", "", "put the entries under the group at `path`")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("usage: plugin import [-group path] name file")
+	}
+	data, err := ioutil.ReadFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	resp, err := callPlugin(fs.Arg(0), &pluginRequest{
+		Type:     "import",
+		Filename: filepath.Base(fs.Arg(1)),
+		Data:     data,
+	})
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, pe := range resp.Entries {
+		path := pe.Path
+		if *group != "" {
+			path = strings.TrimSuffix(*group, "/") + "/" + path
+		}
+		dir, title := splitItemPath(path)
+		if dir == "" || title == "" {
+			return fmt.Errorf("plugin %s: entry %q must be in a group", fs.Arg(0), pe.Path)
+		}
+		g, err := db.MkdirAll(dir)
+		if err != nil {
+			return fmt.Errorf("entry %q: %v", path, err)
+		}
+		e, err := g.NewEntry()
+		if err != nil {
+			return fmt.Errorf("entry %q: %v", path, err)
+		}
+		e.Title = title
+		e.Username = pe.Username
+		e.Password = pe.Password
+		e.URL = pe.URL
+		e.Notes = pe.Notes
+		e.CreationTime = now
+		e.LastModificationTime = now
+		e.LastAccessTime = now
+		if pe.Modified != nil {
+			e.LastModificationTime = *pe.Modified
+		}
+	}
+	if err := writeDatabase(db); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, tr("%d entries imported\n"), len(resp.Entries))
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// testPlugin answers each request type with a fixed response.
+const testPlugin = `#!/bin/sh
+req=$(cat)
+case "$req" in
+*'"type":"describe"'*) echo '{"description": "test plugin", "capabilities": ["import", "key", "audit"]}' ;;
+*'"type":"key"'*) echo '{"password": "swordfish"}' ;;
+*'"type":"import"'*) echo '{"entries": [{"path": "Bank/Checking", "username": "alice", "password": "hunter2"}]}' ;;
+*'"type":"audit"'*)
+	case "$req" in
+	*hunter2*) echo '{"findings": [{"entries": ["Imported/Bank/Checking"], "detail": "password is hunter2"}]}' ;;
+	*) echo '{"findings": []}' ;;
+	esac ;;
+*) echo '{"error": "unknown request"}' ;;
+esac
+`
+
+func TestPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test plugin needs a Unix shell")
+	}
+	dir, cleanup := newCommandTestDB(t, `{"key_rounds": 1}`)
+	defer cleanup()
+	defer func(plugins, key string) {
+		*pluginsPath, *keyPlugin = plugins, key
+	}(*pluginsPath, *keyPlugin)
+	*pluginsPath = filepath.Join(dir, "plugins")
+	if err := os.Mkdir(*pluginsPath, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(*pluginsPath, "test.sh"), []byte(testPlugin), 0700); err != nil {
+		t.Fatal(err)
+	}
+	// Not executable, so not a plugin.
+	if err := ioutil.WriteFile(filepath.Join(*pluginsPath, "README"), []byte("notes"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if names, err := pluginsWith("audit"); err != nil || !reflect.DeepEqual(names, []string{"test"}) {
+		t.Errorf("pluginsWith(audit) = %q, %v; want [test]", names, err)
+	}
+	if _, err := callPlugin("missing", &pluginRequest{Type: "describe"}); err == nil {
+		t.Error("callPlugin(missing) succeeded")
+	}
+	if _, err := callPlugin("test", &pluginRequest{Type: "bogus"}); err == nil {
+		t.Error("plugin error response not reported")
+	}
+
+	// The key plugin unlocks the database without the password file.
+	*passwordFile = filepath.Join(dir, "missing")
+	*keyPlugin = "test"
+	exportPath := filepath.Join(dir, "export.csv")
+	if err := ioutil.WriteFile(exportPath, []byte("anything"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := runPlugin([]string{"import", "-group", "Imported", "test", exportPath}); err != nil {
+		t.Fatal("plugin import:", err)
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := findEntryPath(db, "Imported/Bank/Checking")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Username != "alice" || e.Password != "hunter2" {
+		t.Errorf("imported entry = %q, %q; want alice, hunter2", e.Username, e.Password)
+	}
+	findings, err := checkPlugins(auditEntries(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []auditFinding{{Check: "test", Entries: []string{"Imported/Bank/Checking"}, Detail: "password is hunter2"}}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("checkPlugins = %+v; want %+v", findings, want)
+	}
+}
@@ -0,0 +1,408 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pedroalbanese/gostpass/pkg/prompt"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var (
+	promptBackend   = flag.String("prompt", "auto", "how to ask for passwords and confirmations: auto, tty, pinentry, dialog or windows")
+	pinentryProgram = flag.String("pinentry", "pinentry", "pinentry program for -prompt pinentry")
+)
+
+// A prompter asks the user for passwords and for confirmations.
+// Confirmations must come from a person, never from standard input, since
+// they guard secrets from the programs that gostpass talks to.
+type prompter interface {
+	Password(prompt string) (string, error)
+	Confirm(question string) (bool, error)
+}
+
+var prompters = map[string]prompter{
+	"auto":     autoPrompter{},
+	"tty":      ttyPrompter{},
+	"pinentry": pinentryPrompter{},
+	"dialog":   dialogPrompter{},
+	"windows":  windowsPrompter{},
+}
+
+func currentPrompter() (prompter, error) {
+	p := prompters[*promptBackend]
+	if p == nil {
+		return nil, fmt.Errorf("unknown -prompt %q; want auto, tty, pinentry, dialog or windows", *promptBackend)
+	}
+	return p, nil
+}
+
+var errPromptCancelled = errors.New("prompt cancelled")
+
+// autoPrompter uses the terminal if there is one and a desktop dialog
+// otherwise, like when started from a GUI launcher.
+type autoPrompter struct{}
+
+func (autoPrompter) Password(prompt string) (string, error) {
+	if terminalInput() {
+		return ttyPrompter{}.Password(prompt)
+	}
+	return desktopPrompter().Password(prompt)
+}
+
+func (autoPrompter) Confirm(question string) (bool, error) {
+	if tty, err := os.Open("/dev/tty"); err == nil {
+		tty.Close()
+		return ttyPrompter{}.Confirm(question)
+	}
+	return desktopPrompter().Confirm(question)
+}
+
+// terminalInput reports whether ttyPrompter can read a password: from a
+// terminal, or from a pipe or file on standard input in scripts.
+func terminalInput() bool {
+	if terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return true
+	}
+	if stdinProtocol {
+		tty, err := os.Open("/dev/tty")
+		if err != nil {
+			return false
+		}
+		tty.Close()
+		return true
+	}
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice == 0
+}
+
+func desktopPrompter() prompter {
+	if runtime.GOOS == "windows" {
+		return windowsPrompter{}
+	}
+	return dialogPrompter{}
+}
+
+// ttyPrompter reads passwords from the terminal, or from standard input if
+// it isn't one, and asks for confirmation on the controlling terminal.
+type ttyPrompter struct{}
+
+func (ttyPrompter) Password(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) && stdinProtocol {
+		tty, err := os.Open("/dev/tty")
+		if err != nil {
+			return "", errors.New(tr("no terminal to read the password from; use -password_file"))
+		}
+		defer tty.Close()
+		fd = int(tty.Fd())
+	}
+	if terminal.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		password, err := terminal.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(password), err
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", errors.New(tr("no password on standard input"))
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (ttyPrompter) Confirm(question string) (bool, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false, errors.New(tr("no terminal to ask for confirmation"))
+	}
+	defer tty.Close()
+	fmt.Fprintf(tty, tr("%s [y/N] "), question)
+	line, _ := bufio.NewReader(tty).ReadString('\n')
+	return isYes(line), nil
+}
+
+// promptNewPassword asks twice for a password that is about to protect
+// something.  On a terminal, the first time a strength meter is shown as
+// the password is typed.
+func promptNewPassword(msg, repeat string) (string, error) {
+	password, err := readNewPassword(msg)
+	if err != nil {
+		return "", err
+	}
+	if again, err := promptPassword(repeat); err != nil {
+		return "", err
+	} else if again != password {
+		return "", errors.New(tr("passwords do not match"))
+	}
+	return password, nil
+}
+
+func readNewPassword(msg string) (string, error) {
+	tty := *promptBackend == "tty" || *promptBackend == "auto" && terminalInput()
+	if !tty || !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return promptPassword(msg)
+	}
+	password, pasted, err := prompt.Password(os.Stdin, os.Stderr, msg, passwordMeter)
+	secrets.Add(password)
+	if err == prompt.ErrInterrupted {
+		return "", errPromptCancelled
+	} else if err != nil {
+		return "", err
+	}
+	if pasted {
+		fmt.Fprintln(os.Stderr, tr("The password was pasted; clear the clipboard."))
+	}
+	return password, nil
+}
+
+// passwordMeter rates a password as it is typed, by the same estimate as
+// the min_entropy audit policy, and warns if Caps Lock seems to be on.
+func passwordMeter(password string) string {
+	if password == "" {
+		return ""
+	}
+	bits := passwordEntropy(password)
+	strength := tr("strong")
+	switch {
+	case bits < 40:
+		strength = tr("weak")
+	case bits < 60:
+		strength = tr("fair")
+	case bits < 80:
+		strength = tr("good")
+	}
+	meter := fmt.Sprintf("[%s]", strength)
+	if prompt.CapsLockLikely(password) {
+		meter += " " + tr("Caps Lock may be on")
+	}
+	return meter
+}
+
+// pinentryPrompter talks to a GnuPG pinentry program with the Assuan
+// protocol, so that the same dialog as for GnuPG keys is shown.
+type pinentryPrompter struct{}
+
+func (pinentryPrompter) Password(prompt string) (string, error) {
+	return pinentry("SETDESC "+assuanEscape(strings.TrimSpace(prompt)), "GETPIN")
+}
+
+func (pinentryPrompter) Confirm(question string) (bool, error) {
+	_, err := pinentry("SETDESC "+assuanEscape(question), "CONFIRM")
+	if err == errPromptCancelled {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// pinentry runs the pinentry program, sends it commands and returns the
+// data it sent back.  A refusal of the last command is reported as
+// errPromptCancelled.
+func pinentry(commands ...string) (string, error) {
+	cmd := exec.Command(*pinentryProgram)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return "", err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("pinentry: %v", err)
+	}
+	defer cmd.Wait()
+	defer in.Close()
+	r := bufio.NewReader(out)
+	if _, err := assuanResponse(r); err != nil {
+		return "", fmt.Errorf("pinentry: %v", err)
+	}
+	commands = append([]string{"SETTITLE gostpass"}, commands...)
+	var data string
+	for i, c := range commands {
+		if _, err := io.WriteString(in, c+"\n"); err != nil {
+			return "", fmt.Errorf("pinentry: %v", err)
+		}
+		data, err = assuanResponse(r)
+		if _, refused := err.(assuanError); refused && i == len(commands)-1 {
+			return "", errPromptCancelled
+		} else if err != nil {
+			return "", fmt.Errorf("pinentry: %s: %v", strings.Fields(c)[0], err)
+		}
+	}
+	io.WriteString(in, "BYE\n")
+	return data, nil
+}
+
+// An assuanError is an ERR response.
+type assuanError string
+
+func (e assuanError) Error() string {
+	return string(e)
+}
+
+// assuanResponse reads lines up to OK or ERR and returns the data lines
+// joined.
+func assuanResponse(r *bufio.Reader) (string, error) {
+	var data bytes.Buffer
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "OK" || strings.HasPrefix(line, "OK "):
+			return data.String(), nil
+		case strings.HasPrefix(line, "ERR "):
+			return "", assuanError(line)
+		case strings.HasPrefix(line, "D "):
+			data.WriteString(assuanUnescape(line[2:]))
+		}
+		// Status and comment lines are ignored.
+	}
+}
+
+// assuanEscape percent-encodes the characters that can't appear in an
+// Assuan line.
+func assuanEscape(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func assuanUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// dialogCommands lists the programs tried, in order, to show desktop
+// dialogs on each system.  The prompt or question is appended as the last
+// argument.  For confirmations, exit status 0 means yes.
+var dialogCommands = map[string][]struct{ password, confirm []string }{
+	"darwin": {{
+		password: []string{"osascript", "-e", `on run argv
+text returned of (display dialog (item 1 of argv) default answer "" with hidden answer with title "gostpass")
+end run`},
+		confirm: []string{"osascript", "-e", `on run argv
+display dialog (item 1 of argv) buttons {"Deny", "Allow"} default button "Deny" cancel button "Deny" with title "gostpass"
+end run`},
+	}},
+	"linux": {
+		{
+			password: []string{"zenity", "--entry", "--hide-text", "--title=gostpass", "--text"},
+			confirm:  []string{"zenity", "--question", "--title=gostpass", "--text"},
+		},
+		{
+			password: []string{"kdialog", "--title", "gostpass", "--password"},
+			confirm:  []string{"kdialog", "--title", "gostpass", "--yesno"},
+		},
+	},
+}
+
+// dialogPrompter shows desktop dialogs with zenity, kdialog or osascript.
+type dialogPrompter struct{}
+
+func (dialogPrompter) command(password bool, text string) (*exec.Cmd, error) {
+	cmds := dialogCommands[runtime.GOOS]
+	if cmds == nil {
+		cmds = dialogCommands["linux"]
+	}
+	for _, c := range cmds {
+		argv := c.confirm
+		if password {
+			argv = c.password
+		}
+		path, err := exec.LookPath(argv[0])
+		if err != nil {
+			continue
+		}
+		return exec.Command(path, append(append([]string{}, argv[1:]...), text)...), nil
+	}
+	return nil, errors.New("no dialog program found; install zenity or kdialog, or use -prompt tty")
+}
+
+func (p dialogPrompter) Password(prompt string) (string, error) {
+	cmd, err := p.command(true, prompt)
+	if err != nil {
+		return "", err
+	}
+	return runPromptCommand(cmd)
+}
+
+func (p dialogPrompter) Confirm(question string) (bool, error) {
+	cmd, err := p.command(false, question)
+	if err != nil {
+		return false, err
+	}
+	return cmd.Run() == nil, nil
+}
+
+// windowsPrompter uses the Windows credential dialog through PowerShell.
+// The prompt is passed in the environment to avoid quoting it.
+type windowsPrompter struct{}
+
+const (
+	windowsPasswordScript = `$c = Get-Credential -UserName gostpass -Message $env:GOSTPASS_PROMPT; if (-not $c) { exit 1 }; $c.GetNetworkCredential().Password`
+	windowsConfirmScript  = `Add-Type -AssemblyName PresentationFramework; if ([System.Windows.MessageBox]::Show($env:GOSTPASS_PROMPT, 'gostpass', 'YesNo') -ne 'Yes') { exit 1 }`
+)
+
+func (windowsPrompter) command(script, text string) *exec.Cmd {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.Env = append(os.Environ(), "GOSTPASS_PROMPT="+text)
+	return cmd
+}
+
+func (p windowsPrompter) Password(prompt string) (string, error) {
+	return runPromptCommand(p.command(windowsPasswordScript, prompt))
+}
+
+func (p windowsPrompter) Confirm(question string) (bool, error) {
+	err := p.command(windowsConfirmScript, question).Run()
+	if _, ok := err.(*exec.ExitError); ok {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// runPromptCommand runs a dialog that prints the password.  A non-zero
+// exit status means the user cancelled.
+func runPromptCommand(cmd *exec.Cmd) (string, error) {
+	out, err := cmd.Output()
+	if _, ok := err.(*exec.ExitError); ok {
+		return "", errPromptCancelled
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
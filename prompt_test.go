@@ -0,0 +1,99 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestAssuanEscape(t *testing.T) {
+	for _, s := range []string{"plain", "100% sure", "two\nlines\r\n", "%0A"} {
+		if got := assuanUnescape(assuanEscape(s)); got != s {
+			t.Errorf("assuanUnescape(assuanEscape(%q)) = %q", s, got)
+		}
+	}
+	if got := assuanUnescape("trailing %4"); got != "trailing %4" {
+		t.Errorf("assuanUnescape(%q) = %q; want unchanged", "trailing %4", got)
+	}
+}
+
+const fakePinentry = `#!/bin/sh
+echo "OK Pleased to meet you"
+while read cmd rest; do
+	case "$cmd" in
+	GETPIN) echo "# comment"; echo "D s3cr%25t"; echo OK ;;
+	CONFIRM) echo "ERR 83886179 Operation cancelled" ;;
+	BYE) echo OK; exit 0 ;;
+	*) echo OK ;;
+	esac
+done
+`
+
+func TestPinentryPrompter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake pinentry is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "gostpass_prompt_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pinentry")
+	if err := ioutil.WriteFile(path, []byte(fakePinentry), 0700); err != nil {
+		t.Fatal(err)
+	}
+	oldBackend, oldProgram := *promptBackend, *pinentryProgram
+	*promptBackend, *pinentryProgram = "pinentry", path
+	defer func() { *promptBackend, *pinentryProgram = oldBackend, oldProgram }()
+
+	if got, err := promptPassword("Password: "); err != nil || got != "s3cr%t" {
+		t.Errorf("promptPassword() = %q, %v; want %q, <nil>", got, err, "s3cr%t")
+	}
+	p, err := currentPrompter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := p.Confirm("Release?"); ok || err != nil {
+		t.Errorf("Confirm() = %t, %v; want false, <nil>", ok, err)
+	}
+
+	*promptBackend = "carrier-pigeon"
+	if _, err := promptPassword("Password: "); err == nil {
+		t.Error("promptPassword with unknown backend succeeded")
+	}
+}
+
+func TestPasswordMeter(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{"", ""},
+		{"hunter2", "[weak]"},
+		{"correct horse battery", "[strong]"},
+		{"Tr0ub4dor", "[fair]"},
+		{"Tr0ub4dor&3", "[good]"},
+		{"HUNTER2", "[weak] Caps Lock may be on"},
+	}
+	for _, test := range tests {
+		if got := passwordMeter(test.password); got != test.want {
+			t.Errorf("passwordMeter(%q) = %q; want %q", test.password, got, test.want)
+		}
+	}
+}
@@ -104,7 +104,7 @@ func passwordCharset(form url.Values) []byte {
 func generatePasswordFromSet(n int, set []byte) (string, error) {
 	pw := make([]byte, n)
 	for i := range pw {
-		j, err := randInt(rand.Reader, len(set))
+		j, err := randInt(randSource, len(set))
 		if err != nil {
 			return "", err
 		}
@@ -123,7 +123,7 @@ func generatePassphrase(numWords int, includePossessives bool) (string, error) {
 	}
 	var buf bytes.Buffer
 	for i := 0; i < numWords; i++ {
-		w, err := randInt(rand.Reader, max)
+		w, err := randInt(randSource, max)
 		if err != nil {
 			return "", err
 		}
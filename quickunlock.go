@@ -0,0 +1,144 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/secretref"
+)
+
+// quickUnlockAccount is the keyring account that holds the key.
+const quickUnlockAccount = "quickunlock"
+
+// The system keyring, replaced in tests.
+var (
+	keyringLookup = secretref.LookupKeyring
+	keyringStore  = secretref.StoreKeyring
+	keyringDelete = secretref.DeleteKeyring
+)
+
+// Quick unlock keeps the database's computed key in the system keyring, so
+// that commands open the database with whatever the keyring asks for, like
+// the login session or a fingerprint, instead of the password.  Whether it
+// is on has to be known before the database is open, and asking the
+// keyring every time would bother those who never enabled it, so a file
+// next to the database records the keyring service.
+func quickUnlockFile() string {
+	return *dbPath + ".quickunlock"
+}
+
+// quickUnlockService returns the keyring service that quick unlock is
+// enabled with, or the empty string if it is off.
+func quickUnlockService() (string, error) {
+	data, err := ioutil.ReadFile(quickUnlockFile())
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("read quick unlock: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// quickUnlock opens the database with the key in the keyring.  It returns
+// nil if quick unlock is off or fails, after saying why, so that the
+// password is asked for instead.
+func quickUnlock() *keepass.Database {
+	service, err := quickUnlockService()
+	if err != nil || service == "" {
+		if err != nil {
+			fmt.Fprintf(stderr, "gostpass: %v\n", err)
+		}
+		return nil
+	}
+	var db *keepass.Database
+	secret, err := keyringLookup(service, quickUnlockAccount)
+	if err == nil {
+		secrets.Add(secret)
+		var key []byte
+		if key, err = hex.DecodeString(secret); err == nil {
+			db, err = openDatabase(&keepass.Options{ComputedKey: key})
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, tr("gostpass: quick unlock failed, asking for the password: %v\n"), err)
+		return nil
+	}
+	return db
+}
+
+// saveQuickUnlock puts db's key in the keyring under service and records
+// that quick unlock is on.
+func saveQuickUnlock(db *keepass.Database, service string) error {
+	if err := keyringStore(service, quickUnlockAccount, hex.EncodeToString(db.ComputedKey())); err != nil {
+		return fmt.Errorf("quick unlock: %v", err)
+	}
+	return ioutil.WriteFile(quickUnlockFile(), []byte(service+"\n"), 0600)
+}
+
+// refreshQuickUnlock replaces the key in the keyring with db's new one
+// after a rekey, if quick unlock is on.
+func refreshQuickUnlock(db *keepass.Database) error {
+	service, err := quickUnlockService()
+	if err != nil || service == "" {
+		return err
+	}
+	return saveQuickUnlock(db, service)
+}
+
+// runQuickUnlock turns quick unlock on or off.  Enabling it asks for the
+// password once; disabling it doesn't need the database.
+func runQuickUnlock(args []string) error {
+	if len(args) != 1 || (args[0] != "enable" && args[0] != "disable") {
+		return errors.New("usage: quickunlock enable|disable")
+	}
+	if args[0] == "disable" {
+		service, err := quickUnlockService()
+		if err != nil {
+			return err
+		}
+		if service == "" {
+			return errors.New(tr("quick unlock is not enabled"))
+		}
+		if err := keyringDelete(service, quickUnlockAccount); err != nil {
+			return fmt.Errorf("quick unlock: %v", err)
+		}
+		if err := os.Remove(quickUnlockFile()); err != nil {
+			return err
+		}
+		fmt.Printf(tr("quick unlock disabled for %s\n"), *dbPath)
+		return nil
+	}
+	path, err := filepath.Abs(*dbPath)
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	if err := saveQuickUnlock(db, "gostpass "+path); err != nil {
+		return err
+	}
+	fmt.Printf(tr("quick unlock enabled for %s\n"), *dbPath)
+	return nil
+}
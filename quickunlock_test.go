@@ -0,0 +1,104 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuickUnlock(t *testing.T) {
+	dir, cleanup := newCommandTestDB(t, `{"key_rounds": 1}`)
+	defer cleanup()
+	keyring := make(map[string]string)
+	oldLookup, oldStore, oldDelete := keyringLookup, keyringStore, keyringDelete
+	defer func() { keyringLookup, keyringStore, keyringDelete = oldLookup, oldStore, oldDelete }()
+	keyringLookup = func(service, account string) (string, error) {
+		secret, ok := keyring[service+"/"+account]
+		if !ok {
+			return "", errors.New("not found")
+		}
+		return secret, nil
+	}
+	keyringStore = func(service, account, secret string) error {
+		keyring[service+"/"+account] = secret
+		return nil
+	}
+	keyringDelete = func(service, account string) error {
+		delete(keyring, service+"/"+account)
+		return nil
+	}
+	// withoutPassword opens the database with a password file that has
+	// the wrong password, as a new process would, before the storage is
+	// set up.
+	wrongPassword := filepath.Join(dir, "wrong")
+	if err := ioutil.WriteFile(wrongPassword, []byte("hunter2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	withoutPassword := func() error {
+		rightPassword := *passwordFile
+		*passwordFile = wrongPassword
+		defer func() { *passwordFile = rightPassword }()
+		dbStorage = nil
+		_, err := openCommandDatabase()
+		return err
+	}
+
+	if err := withoutPassword(); err == nil {
+		t.Fatal("open with the wrong password before quickunlock enable succeeded")
+	}
+	if err := runQuickUnlock([]string{"enable"}); err != nil {
+		t.Fatal("quickunlock enable:", err)
+	}
+	if len(keyring) != 1 {
+		t.Fatalf("keyring after enable = %v; want the key", keyring)
+	}
+	if err := withoutPassword(); err != nil {
+		t.Error("open after quickunlock enable:", err)
+	}
+	// rekey changes the key, and the keyring has to follow.
+	if err := runRekey(nil); err != nil {
+		t.Fatal("rekey:", err)
+	}
+	if err := withoutPassword(); err != nil {
+		t.Error("open after rekey:", err)
+	}
+	// A stale key falls back to the password.
+	for k := range keyring {
+		keyring[k] = "00"
+	}
+	if _, err := openCommandDatabase(); err != nil {
+		t.Error("open with a stale key in the keyring:", err)
+	}
+
+	if err := runQuickUnlock([]string{"disable"}); err != nil {
+		t.Fatal("quickunlock disable:", err)
+	}
+	if len(keyring) != 0 {
+		t.Errorf("keyring after disable = %v; want empty", keyring)
+	}
+	if _, err := os.Stat(quickUnlockFile()); !os.IsNotExist(err) {
+		t.Errorf("stat %s after disable = %v; want not exist", quickUnlockFile(), err)
+	}
+	if err := withoutPassword(); err == nil {
+		t.Error("open with the wrong password after quickunlock disable succeeded")
+	}
+	if err := runQuickUnlock([]string{"disable"}); err == nil {
+		t.Error("quickunlock disable when not enabled succeeded")
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Unlock rate limiting flags.
+func init() {
+	flag.DurationVar(&unlocks.backoff, "unlock_backoff", 1*time.Second, "delay imposed after the first failed unlock, doubling with each further failure")
+	flag.IntVar(&unlocks.maxFailures, "unlock_max_failures", 10, "number of consecutive failed unlocks before locking out (0 disables lockout)")
+	flag.DurationVar(&unlocks.lockout, "unlock_lockout", 15*time.Minute, "how long unlocking is refused after too many failures")
+	unlocks.now = time.Now
+}
+
+// unlocks is the global unlock limiter.  Protected by mu.
+var unlocks unlockLimiter
+
+// unlockLimiter throttles attempts to unlock the database.  The database
+// belongs to a single grain, so attempts are counted globally rather than
+// per client: an attacker can't sidestep the limit by changing addresses.
+type unlockLimiter struct {
+	backoff     time.Duration
+	maxFailures int
+	lockout     time.Duration
+	now         func() time.Time
+
+	failures int
+	next     time.Time // earliest time of the next allowed attempt
+}
+
+// allow returns an error if an unlock attempt may not be made now.
+func (ul *unlockLimiter) allow() error {
+	now := ul.now()
+	if now.Before(ul.next) {
+		return rateLimitError{wait: ul.next.Sub(now)}
+	}
+	return nil
+}
+
+// fail records a failed unlock attempt.
+func (ul *unlockLimiter) fail(r *http.Request) {
+	ul.failures++
+	delay := ul.backoff
+	for i := 1; i < ul.failures && delay < ul.lockout; i++ {
+		delay *= 2
+	}
+	if ul.maxFailures > 0 && ul.failures >= ul.maxFailures || delay > ul.lockout {
+		delay = ul.lockout
+	}
+	ul.next = ul.now().Add(delay)
+	log.Printf("failed unlock attempt %d from %s; next attempt allowed in %v", ul.failures, r.RemoteAddr, delay)
+}
+
+// succeed resets the failure count after a successful unlock.
+func (ul *unlockLimiter) succeed() {
+	ul.failures = 0
+	ul.next = time.Time{}
+}
+
+// record records the outcome of an unlock attempt.  err is the error
+// from opening the database; only user errors (wrong credentials) count as
+// failures.
+func (ul *unlockLimiter) record(r *http.Request, err error) {
+	switch {
+	case err == nil:
+		ul.succeed()
+	case isUserError(err):
+		ul.fail(r)
+	}
+}
+
+type rateLimitError struct {
+	wait time.Duration
+}
+
+func (e rateLimitError) Error() string {
+	return fmt.Sprintf("too many failed unlock attempts; retry in %v", e.wait)
+}
+
+func (e rateLimitError) UserError() string {
+	return fmt.Sprintf("Too many failed attempts. Try again in %v.", e.wait.Round(time.Second))
+}
+
+func (rateLimitError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnlockLimiter(t *testing.T) {
+	now := time.Date(2019, time.July, 1, 22, 0, 0, 0, time.UTC)
+	ul := &unlockLimiter{
+		backoff:     1 * time.Second,
+		maxFailures: 4,
+		lockout:     1 * time.Minute,
+		now:         func() time.Time { return now },
+	}
+	r := httptest.NewRequest("POST", "/_/start", nil)
+	wrongPassword := userError{msg: "wrong", err: errors.New("wrong")}
+
+	if err := ul.allow(); err != nil {
+		t.Fatal("first attempt:", err)
+	}
+	// Failures double the delay: 1s, 2s, 4s, then lockout.
+	for i, want := range []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 1 * time.Minute} {
+		ul.record(r, wrongPassword)
+		if err := ul.allow(); err == nil {
+			t.Errorf("after failure %d: allow() = <nil>; want error", i+1)
+		}
+		now = now.Add(want - time.Millisecond)
+		if err := ul.allow(); err == nil {
+			t.Errorf("after failure %d: allow() %v later = <nil>; want error", i+1, want-time.Millisecond)
+		}
+		now = now.Add(time.Millisecond)
+		if err := ul.allow(); err != nil {
+			t.Errorf("after failure %d: allow() %v later = %v; want <nil>", i+1, want, err)
+		}
+	}
+
+	// Server errors don't count against the user.
+	ul.record(r, errors.New("disk on fire"))
+	if err := ul.allow(); err != nil {
+		t.Errorf("after server error: allow() = %v; want <nil>", err)
+	}
+
+	ul.record(r, nil)
+	ul.record(r, wrongPassword)
+	now = now.Add(1 * time.Second)
+	if err := ul.allow(); err != nil {
+		t.Errorf("after success and one failure: allow() 1s later = %v; want <nil>", err)
+	}
+}
+
+func TestFailedUnlockNotScrubbed(t *testing.T) {
+	_, cleanup := newCommandTestDB(t, `{"key_rounds": 1}`)
+	defer cleanup()
+	oldUnlocks := unlocks
+	defer func() { unlocks = oldUnlocks }()
+	unlocks = unlockLimiter{backoff: time.Second, maxFailures: 4, lockout: time.Minute, now: time.Now}
+
+	// An attacker sends the address they connect from as the password,
+	// hoping it gets scrubbed from the failed attempt's log line.
+	form := url.Values{"password": {"203.0.113.7"}}
+	r := httptest.NewRequest("POST", "/_/start", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.RemoteAddr = "203.0.113.7:4321"
+	if err := startSession(httptest.NewRecorder(), r); err == nil {
+		t.Fatal("startSession with the wrong password succeeded")
+	}
+	if got, want := secrets.Scrub("failed unlock attempt 1 from "+r.RemoteAddr), "failed unlock attempt 1 from 203.0.113.7:4321"; got != want {
+		t.Errorf("after a failed unlock, Scrub(...) = %q; want %q", got, want)
+	}
+}
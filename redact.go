@@ -0,0 +1,51 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+
+	"github.com/pedroalbanese/gostpass/pkg/redact"
+)
+
+// secrets holds the passwords that the process has been given, so that
+// they are scrubbed from logs, error messages and panics.  Anything that
+// reads a password should add it.
+var secrets redact.Guard
+
+// stderr is standard error with secrets scrubbed.  Errors and logs go
+// there; prompts and output that are meant to show a secret, like a newly
+// generated password, go to os.Stderr.
+var stderr = secrets.Writer(os.Stderr)
+
+func init() {
+	log.SetOutput(stderr)
+}
+
+// scrubPanic reports a panic with secrets scrubbed from the message and
+// stack trace and exits, like the runtime would.  It must be deferred
+// first thing in main; panics in other goroutines are not caught, except
+// in HTTP handlers, which the net/http server reports through the log.
+func scrubPanic() {
+	v := recover()
+	if v == nil {
+		return
+	}
+	fmt.Fprintf(stderr, "panic: %v\n\n%s", v, debug.Stack())
+	os.Exit(2)
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// tagsField is the entry field that holds its tags, separated by commas
+// or spaces.  KDB1 entries have no tags of their own.
+const tagsField = "tags"
+
+// entryTags returns e's tags.
+func entryTags(e *keepass.Entry) ([]string, error) {
+	f, _, err := e.Field(tagsField)
+	if err != nil {
+		return nil, err
+	}
+	return strings.FieldsFunc(f.Value, func(r rune) bool { return r == ',' || r == ' ' }), nil
+}
+
+// An entryFilter selects entries for a bulk operation.
+type entryFilter func(*keepass.Entry) (bool, error)
+
+// parseEntryFilter parses a filter of space-separated terms, all of which
+// must match:
+//
+//	tag:NAME          the entry's tags field includes NAME
+//	group:PATH        the entry is in the group at PATH or below it
+//	older-than:AGE    the password was set more than AGE before now
+//	WORD              the title or notes contain WORD, as in search
+//
+// Entries in the recycle bin never match.
+func parseEntryFilter(s string, now time.Time) (entryFilter, error) {
+	var terms []entryFilter
+	var words []string
+	for _, term := range strings.Fields(s) {
+		i := strings.IndexByte(term, ':')
+		if i < 0 {
+			words = append(words, term)
+			continue
+		}
+		key, value := term[:i], term[i+1:]
+		switch key {
+		case "tag":
+			terms = append(terms, func(e *keepass.Entry) (bool, error) {
+				tags, err := entryTags(e)
+				for _, t := range tags {
+					if t == value {
+						return true, err
+					}
+				}
+				return false, err
+			})
+		case "group":
+			path := strings.Trim(value, "/")
+			terms = append(terms, func(e *keepass.Entry) (bool, error) {
+				p := e.Parent().Path()
+				return path == "" || p == path || strings.HasPrefix(p, path+"/"), nil
+			})
+		case "older-than":
+			age, err := parseAge(value)
+			if err != nil {
+				return nil, err
+			}
+			cutoff := now.Add(-age)
+			terms = append(terms, func(e *keepass.Entry) (bool, error) {
+				return e.PasswordChanged().Before(cutoff), nil
+			})
+		default:
+			return nil, fmt.Errorf("unknown filter term %q; want tag:, group:, older-than: or a word", key+":")
+		}
+	}
+	if len(words) > 0 {
+		pq := parseQuery(strings.Join(words, " "))
+		terms = append(terms, func(e *keepass.Entry) (bool, error) {
+			return pq.matchesEntry(e), nil
+		})
+	}
+	return func(e *keepass.Entry) (bool, error) {
+		if e.Parent().InRecycleBin() {
+			return false, nil
+		}
+		for _, t := range terms {
+			if ok, err := t(e); !ok || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}, nil
+}
+
+// passwordClasses are the character sets that password policies name.
+var passwordClasses = map[string]string{
+	"mixed":   upperLetters + lowerLetters + digits + specialChars,
+	"alnum":   upperLetters + lowerLetters + digits,
+	"letters": upperLetters + lowerLetters,
+	"digits":  digits,
+	"hex":     "0123456789abcdef",
+}
+
+// A passwordPolicy describes the passwords to generate, as LENGTH-CLASS:
+// "24-mixed" is 24 characters from all classes, and "6-words" a passphrase
+// of 6 words from -words_file.
+type passwordPolicy struct {
+	length int
+	class  string
+}
+
+func parsePasswordPolicy(s string) (passwordPolicy, error) {
+	i := strings.IndexByte(s, '-')
+	if i < 0 {
+		return passwordPolicy{}, fmt.Errorf("invalid policy %q; want LENGTH-CLASS, like 24-mixed", s)
+	}
+	n, err := strconv.Atoi(s[:i])
+	p := passwordPolicy{length: n, class: s[i+1:]}
+	if p.class == "words" {
+		if err != nil || n < 1 || n > 50 {
+			return passwordPolicy{}, fmt.Errorf("invalid policy %q: passphrases must have 1-50 words", s)
+		}
+		return p, nil
+	}
+	if _, ok := passwordClasses[p.class]; !ok {
+		return passwordPolicy{}, fmt.Errorf("invalid policy %q: class must be mixed, alnum, letters, digits, hex or words", s)
+	}
+	if err != nil || n < 1 || n > 200 {
+		return passwordPolicy{}, fmt.Errorf("invalid policy %q: length must be 1-200", s)
+	}
+	return p, nil
+}
+
+func (p passwordPolicy) generate() (string, error) {
+	if p.class == "words" {
+		return generatePassphrase(p.length, false)
+	}
+	return generatePasswordFromSet(p.length, []byte(passwordClasses[p.class]))
+}
+
+// A regenRecord reports a regenerated password, so that the systems that
+// use it can be updated.
+type regenRecord struct {
+	Path     string `json:"path"`
+	UUID     string `json:"uuid"`
+	Username string `json:"username,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Password string `json:"password"`
+}
+
+// regenerate gives every entry that matches filter a new password from
+// policy, keeping the old one in its history.  Entries whose password
+// comes from a secret reference or whose release is refused are skipped
+// with a warning.
+func regenerate(db *keepass.Database, filter entryFilter, policy passwordPolicy, now time.Time, warn func(*keepass.Entry, error)) ([]regenRecord, error) {
+	var report []regenRecord
+	for _, e := range db.Entries() {
+		ok, err := filter(e)
+		if err != nil {
+			warn(e, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if _, ok := e.CustomData.Get(secretRefKey); ok {
+			warn(e, errors.New("password is a secret reference; change it at its source"))
+			continue
+		}
+		// The report hands the new password out, so it is released
+		// like the old one would be.
+		if err := releaseEntry(e, "regen"); err != nil {
+			warn(e, err)
+			continue
+		}
+		password, err := policy.generate()
+		if err != nil {
+			return nil, err
+		}
+		e.AddRevision()
+		e.Password = password
+		e.LastModificationTime = now
+		report = append(report, regenRecord{
+			Path:     entryPath(e),
+			UUID:     e.UUID.String(),
+			Username: e.Username,
+			URL:      e.URL,
+			Password: password,
+		})
+	}
+	return report, nil
+}
+
+// runRegen regenerates the passwords of matching entries in one go, for
+// credentials that other systems are updated from, and reports the new
+// values as JSON.
+func runRegen(args []string) error {
+	fs := flag.NewFlagSet("regen", flag.ContinueOnError)
+	filterText := fs.String("filter", "", "select entries with `terms` like tag:rotate-me, group:Work/DB, older-than:90d or words")
+	policyText := fs.String("policy", "20-mixed", "generate passwords as LENGTH-CLASS, where class is mixed, alnum, letters, digits, hex or words")
+	out := fs.String("o", "", "write the report to `file` with mode 0600 instead of standard output")
+	dryRun := fs.Bool("n", false, "only list the matching entries")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || strings.TrimSpace(*filterText) == "" {
+		return errors.New("usage: regen -filter terms [-policy LENGTH-CLASS] [-o file] [-n]")
+	}
+	now := time.Now()
+	filter, err := parseEntryFilter(*filterText, now)
+	if err != nil {
+		return err
+	}
+	policy, err := parsePasswordPolicy(*policyText)
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	warn := func(e *keepass.Entry, err error) {
+		fmt.Fprintf(stderr, "gostpass regen: %s: %v\n", entryPath(e), err)
+	}
+	if *dryRun {
+		for _, e := range db.Entries() {
+			ok, err := filter(e)
+			if err != nil {
+				warn(e, err)
+			} else if ok {
+				fmt.Println(entryPath(e))
+			}
+		}
+		return nil
+	}
+	report, err := regenerate(db, filter, policy, now, warn)
+	if err != nil {
+		return err
+	}
+	if len(report) == 0 {
+		return errors.New("no entries match the filter")
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	// Save before reporting, so that no system is given a password that
+	// the database doesn't have.
+	if err := writeDatabase(db); err != nil {
+		return err
+	}
+	if *out != "" {
+		err = writeSecretFile(*out, data)
+	} else {
+		_, err = os.Stdout.Write(data)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, tr("%d passwords regenerated\n"), len(report))
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestParsePasswordPolicy(t *testing.T) {
+	good := map[string]passwordPolicy{
+		"24-mixed": {24, "mixed"},
+		"32-hex":   {32, "hex"},
+		"6-words":  {6, "words"},
+	}
+	for s, want := range good {
+		if got, err := parsePasswordPolicy(s); err != nil || got != want {
+			t.Errorf("parsePasswordPolicy(%q) = %v, %v; want %v, <nil>", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"mixed", "24", "0-mixed", "201-alnum", "51-words", "24-emoji", "x-digits"} {
+		if _, err := parsePasswordPolicy(s); err == nil {
+			t.Errorf("parsePasswordPolicy(%q) succeeded", s)
+		}
+	}
+	pw, err := passwordPolicy{16, "hex"}.generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pw) != 16 || strings.Trim(pw, "0123456789abcdef") != "" {
+		t.Errorf("16-hex password = %q", pw)
+	}
+}
+
+func TestRegenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_regen_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(path string) { *accessLogPath = path }(*accessLogPath)
+	*accessLogPath = filepath.Join(dir, "access.log")
+
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	newEntry := func(path, tags string) *keepass.Entry {
+		dir, title := splitItemPath(path)
+		g, err := db.MkdirAll(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title, e.Password = title, "old "+title
+		if tags != "" {
+			if err := e.SetField(keepass.Field{Name: tagsField, Value: tags}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return e
+	}
+	primary := newEntry("Work/DB/Primary", "prod, rotate-me")
+	newEntry("Work/DB/Replica", "prod")
+	web := newEntry("Work/Web/Admin", "rotate-me")
+	ref := newEntry("Work/DB/Vault", "rotate-me")
+	ref.CustomData.Set(secretRefKey, "env:DB_PASSWORD")
+	newEntry("Home/Router", "rotate-me")
+
+	filter, err := parseEntryFilter("tag:rotate-me group:/Work/", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warned []string
+	report, err := regenerate(db, filter, passwordPolicy{24, "mixed"}, now, func(e *keepass.Entry, err error) {
+		warned = append(warned, entryPath(e))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 2 {
+		t.Fatalf("report = %+v; want Work/DB/Primary and Work/Web/Admin", report)
+	}
+	for i, e := range []*keepass.Entry{primary, web} {
+		r := report[i]
+		if r.Path != entryPath(e) || r.Password != e.Password || len(e.Password) != 24 {
+			t.Errorf("report[%d] = %+v; entry %s has password %q", i, r, entryPath(e), e.Password)
+		}
+		if len(e.History) != 1 || e.History[0].Password != "old "+e.Title {
+			t.Errorf("%s history = %+v; want old password", entryPath(e), e.History)
+		}
+		if !e.LastModificationTime.Equal(now) {
+			t.Errorf("%s modified %v; want %v", entryPath(e), e.LastModificationTime, now)
+		}
+	}
+	if len(warned) != 1 || warned[0] != "Work/DB/Vault" {
+		t.Errorf("warnings for %v; want [Work/DB/Vault]", warned)
+	}
+	if ref.Password != "old Vault" {
+		t.Errorf("secret reference entry's password changed to %q", ref.Password)
+	}
+
+	if _, err := parseEntryFilter("label:x", now); err == nil {
+		t.Error("parseEntryFilter with unknown term succeeded")
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+var accessLogPath = flag.String("access_log", "", "path to the log of secrets released to commands and servers (default is -db path with \".access.log\" appended)")
+
+// confirmReleaseKey is the entry custom data key that marks entries whose
+// secrets are only released after the user agrees.
+const confirmReleaseKey = "gostpass.confirm_release"
+
+// An accessRecord is a line of the access log.
+type accessRecord struct {
+	Time    time.Time `json:"time"`
+	Entry   string    `json:"entry"`
+	UUID    string    `json:"uuid"`
+	Client  string    `json:"client"`
+	Allowed bool      `json:"allowed"`
+}
+
+func accessLogFile() string {
+	if *accessLogPath != "" || *dbPath == "" {
+		return *accessLogPath
+	}
+	return *dbPath + ".access.log"
+}
+
+// releaseMu serializes confirmations, which servers may ask for from
+// several goroutines.
+var releaseMu sync.Mutex
+
+// releaseEntry must be called before e's secrets are handed to client.  If
+// the entry requires confirmation, the user is asked; the access is
+// recorded either way.  It returns an error if the user refused.  A
+// secret reference is resolved into e's password.
+func releaseEntry(e *keepass.Entry, client string) error {
+	releaseMu.Lock()
+	defer releaseMu.Unlock()
+	allowed := true
+	if b, _ := e.CustomData.Bool(confirmReleaseKey); b {
+		allowed = releaseAsker(fmt.Sprintf(tr("Release %s to %s?"), entryPath(e), client))
+	}
+	err := appendAccessLog(accessRecord{
+		Time:    time.Now(),
+		Entry:   entryPath(e),
+		UUID:    e.UUID.String(),
+		Client:  client,
+		Allowed: allowed,
+	})
+	if err != nil {
+		// A full disk or read-only vault directory shouldn't lock the
+		// user out of their secrets.
+		fmt.Fprintf(stderr, "gostpass: access log: %v\n", err)
+	}
+	if !allowed {
+		return fmt.Errorf(tr("%s: release to %s was refused"), entryPath(e), client)
+	}
+	return resolveSecretRef(e)
+}
+
+// releaseAsker is replaced in tests.
+var releaseAsker = askRelease
+
+// askRelease asks question with the -prompt backend.  If nobody can be
+// asked, the answer is no.
+func askRelease(question string) bool {
+	p, err := currentPrompter()
+	if err != nil {
+		fmt.Fprintf(stderr, "gostpass: %v\n", err)
+		return false
+	}
+	ok, err := p.Confirm(question)
+	if err != nil {
+		fmt.Fprintf(stderr, "gostpass: %v\n", err)
+	}
+	return ok
+}
+
+func appendAccessLog(rec accessRecord) error {
+	path := accessLogFile()
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// readAccessLog returns the records of the access log, oldest first.
+func readAccessLog(r io.Reader) ([]accessRecord, error) {
+	var recs []accessRecord
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		var rec accessRecord
+		if err := json.Unmarshal(s.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("access log line %d: %v", n, err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs, s.Err()
+}
+
+// runLog prints the access log, newest last, or with "changes" or
+// "verify", the change log.
+func runLog(args []string) error {
+	if len(args) > 0 && (args[0] == "changes" || args[0] == "verify") {
+		return runLogChanges(args[1:], args[0] == "verify")
+	}
+	fs := flag.NewFlagSet("log", flag.ContinueOnError)
+	n := fs.Int("n", 0, "print only the last `n` records")
+	entry := fs.String("entry", "", "print only records for this entry `path` or UUID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: log [-n count] [-entry path] | log changes | log verify")
+	}
+	f, err := os.Open(accessLogFile())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+	recs, err := readAccessLog(f)
+	if err != nil {
+		return err
+	}
+	if *entry != "" {
+		var matched []accessRecord
+		for _, rec := range recs {
+			if rec.UUID == *entry || rec.Entry == strings.Trim(*entry, "/") {
+				matched = append(matched, rec)
+			}
+		}
+		recs = matched
+	}
+	if *n > 0 && len(recs) > *n {
+		recs = recs[len(recs)-*n:]
+	}
+	for _, rec := range recs {
+		verb := tr("released")
+		if !rec.Allowed {
+			verb = tr("refused ")
+		}
+		fmt.Printf(tr("%s  %s  %s  to %s\n"), rec.Time.Local().Format("2006-01-02 15:04:05"), verb, rec.Entry, rec.Client)
+	}
+	return nil
+}
+
+// runConfirmRelease sets or clears the confirmation requirement of
+// entries.
+func runConfirmRelease(args []string) error {
+	fs := flag.NewFlagSet("confirm-release", flag.ContinueOnError)
+	off := fs.Bool("off", false, "release the entries without asking again")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: confirm-release [-off] path...")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	for _, path := range fs.Args() {
+		e, err := findEntryPath(db, path)
+		if err != nil {
+			return err
+		}
+		if *off {
+			e.CustomData.Delete(confirmReleaseKey)
+		} else {
+			e.CustomData.SetBool(confirmReleaseKey, true)
+		}
+	}
+	return writeDatabase(db)
+}
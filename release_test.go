@@ -0,0 +1,99 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestReleaseEntry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_release_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldPath, oldAsker := *accessLogPath, releaseAsker
+	*accessLogPath = filepath.Join(dir, "access.log")
+	defer func() { *accessLogPath, releaseAsker = oldPath, oldAsker }()
+	var asked []string
+	answer := false
+	releaseAsker = func(question string) bool {
+		asked = append(asked, question)
+		return answer
+	}
+
+	db, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	open, err := g.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	open.Title = "Wiki"
+	guarded, err := g.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	guarded.Title = "Prod"
+	guarded.CustomData.SetBool(confirmReleaseKey, true)
+
+	if err := releaseEntry(open, "exec"); err != nil {
+		t.Errorf("releaseEntry(Work/Wiki): %v", err)
+	}
+	if err := releaseEntry(guarded, "git-credential"); err == nil {
+		t.Error("releaseEntry(Work/Prod) refused by user succeeded")
+	}
+	answer = true
+	if err := releaseEntry(guarded, "git-credential"); err != nil {
+		t.Errorf("releaseEntry(Work/Prod) allowed by user: %v", err)
+	}
+	if len(asked) != 2 || asked[0] != "Release Work/Prod to git-credential?" {
+		t.Errorf("asked %q; want 2 questions about Work/Prod", asked)
+	}
+
+	f, err := os.Open(*accessLogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	recs, err := readAccessLog(f)
+	if err != nil {
+		t.Fatal("readAccessLog:", err)
+	}
+	want := []accessRecord{
+		{Entry: "Work/Wiki", Client: "exec", Allowed: true},
+		{Entry: "Work/Prod", Client: "git-credential", Allowed: false},
+		{Entry: "Work/Prod", Client: "git-credential", Allowed: true},
+	}
+	if len(recs) != len(want) {
+		t.Fatalf("access log has %d records; want %d", len(recs), len(want))
+	}
+	for i, rec := range recs {
+		if rec.Entry != want[i].Entry || rec.Client != want[i].Client || rec.Allowed != want[i].Allowed || rec.Time.IsZero() {
+			t.Errorf("access log record %d = %+v; want %+v", i, rec, want[i])
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// renderTemplate executes the template text with functions that look up
+// fields in db.  {{entry "Work/DB" "password"}} is the password of the
+// entry titled DB in the Work group; the entry may also be given by UUID.
+func renderTemplate(db *keepass.Database, name, text string) ([]byte, error) {
+	funcs := template.FuncMap{
+		"entry": func(path, field string) (string, error) {
+			get := entryFields[field]
+			if get == nil {
+				return "", fmt.Errorf("unknown field %q; want one of %s", field, strings.Join(sortedEntryFields(), ", "))
+			}
+			e, err := findEntryPath(db, path)
+			if err != nil {
+				return "", err
+			}
+			if err := releaseEntry(e, "render"); err != nil {
+				return "", err
+			}
+			return get(e), nil
+		},
+	}
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeSecretFile writes data to path, readable only by the owner, even
+// if the file already existed with wider permissions.
+func writeSecretFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// runRender renders a Go template that refers to database entries, to
+// produce configuration files that contain secrets.
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	out := fs.String("o", "", "write to `file` with mode 0600 instead of standard output")
+	tmpfs := fs.Bool("tmpfs", false, "write to a new file in memory-backed storage and print its path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: render [-o file | -tmpfs] template")
+	}
+	if *out != "" && *tmpfs {
+		return errors.New("-o and -tmpfs are mutually exclusive")
+	}
+	text, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(fs.Arg(0))
+	data, err := renderTemplate(db, name, string(text))
+	if err != nil {
+		return err
+	}
+	switch {
+	case *tmpfs:
+		dir, err := secretDir()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, strings.TrimSuffix(name, ".tmpl"))
+		if err := writeSecretFile(path, data); err != nil {
+			os.RemoveAll(dir)
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	case *out != "":
+		return writeSecretFile(*out, data)
+	default:
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+}
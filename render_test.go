@@ -0,0 +1,85 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := g.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Title = "DB"
+	e.Username = "app"
+	e.Password = "hunter2"
+
+	got, err := renderTemplate(db, "db.conf", `user={{entry "Work/DB" "username"}} password={{entry "Work/DB" "password" | printf "%q"}}`)
+	if err != nil {
+		t.Fatal("renderTemplate:", err)
+	}
+	if want := `user=app password="hunter2"`; string(got) != want {
+		t.Errorf("renderTemplate = %q; want %q", got, want)
+	}
+	for _, text := range []string{`{{entry "Work/Mail" "password"}}`, `{{entry "Work/DB" "pin"}}`, `{{entry "Work/DB"}}`} {
+		if _, err := renderTemplate(db, "bad", text); err == nil {
+			t.Errorf("renderTemplate(%q) succeeded; want error", text)
+		}
+	}
+}
+
+func TestWriteSecretFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_render_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.conf")
+	if err := ioutil.WriteFile(path, []byte("old contents that are longer"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSecretFile(path, []byte("secret")); err != nil {
+		t.Fatal("writeSecretFile:", err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "secret" {
+		t.Errorf("file contents = %q; want %q", data, "secret")
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Errorf("file mode = %v; want 0600", perm)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pedroalbanese/gostpass/pkg/drbg"
+)
+
+var rngSource = flag.String("rng_source", "os", "random source for database seeds, IVs and generated passwords: \"os\" for the operating system's, or \"drbg\" for CTR_DRBG over Kuznyechik seeded from it; both are health-tested")
+
+// randSource is the random source chosen by -rng_source.  Until initRand
+// is called, it is the operating system's.
+var randSource io.Reader = drbg.HealthTested(rand.Reader)
+
+// initRand sets randSource from -rng_source.
+func initRand() error {
+	switch *rngSource {
+	case "os":
+		return nil
+	case "drbg":
+		host, _ := os.Hostname()
+		// The personalization string keeps processes seeded from the
+		// same state, like restored VM snapshots, apart.
+		d, err := drbg.New(randSource, []byte(fmt.Sprintf("gostpass %d %.30s", os.Getpid(), host)))
+		if err != nil {
+			return fmt.Errorf("rng: %v", err)
+		}
+		randSource = d
+		return nil
+	default:
+		return fmt.Errorf("unknown -rng_source %q", *rngSource)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/drbg"
+)
+
+func TestInitRand(t *testing.T) {
+	defer func(src string, r io.Reader) { *rngSource, randSource = src, r }(*rngSource, randSource)
+	*rngSource = "drbg"
+	if err := initRand(); err != nil {
+		t.Fatal("initRand:", err)
+	}
+	if _, ok := randSource.(*drbg.DRBG); !ok {
+		t.Errorf("randSource = %T; want *drbg.DRBG", randSource)
+	}
+	if _, err := generatePasswordFromSet(20, []byte("abc")); err != nil {
+		t.Error("generatePasswordFromSet:", err)
+	}
+	*rngSource = "dice"
+	if err := initRand(); err == nil {
+		t.Error("initRand with -rng_source=dice = <nil>; want error")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// parseAge parses a duration like time.ParseDuration, but also accepts a
+// whole number of days or weeks, like "365d" or "2w".
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if !strings.HasSuffix(s, suffix) {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSuffix(s, suffix), 10, 16)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+// stalePasswords returns the entries whose passwords were set before
+// cutoff, oldest first.  Entries without a password and entries in the
+// recycle bin are skipped.
+func stalePasswords(db *keepass.Database, cutoff time.Time) []*keepass.Entry {
+	var stale []*keepass.Entry
+	for _, e := range db.Entries() {
+		if e.Password == "" || e.Parent().InRecycleBin() {
+			continue
+		}
+		if e.PasswordChanged().Before(cutoff) {
+			stale = append(stale, e)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].PasswordChanged().Before(stale[j].PasswordChanged())
+	})
+	return stale
+}
+
+// runRotate lists entries with old passwords and walks through replacing
+// them: a new password is generated and copied to the clipboard, and once
+// the user confirms it was changed on the site, it is recorded in the
+// database.  The old password is kept in the entry's history.
+func runRotate(args []string) error {
+	fs := flag.NewFlagSet("rotate", flag.ContinueOnError)
+	olderThan := fs.String("older-than", "365d", "minimum password `age`, like 90d, 12w or 720h")
+	length := fs.Int("length", 20, "length of generated passwords")
+	list := fs.Bool("n", false, "only list entries with old passwords")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: rotate [-older-than age] [-length n] [-n]")
+	}
+	age, err := parseAge(*olderThan)
+	if err != nil {
+		return err
+	}
+	if *length < 1 || *length > 200 {
+		return errors.New("-length must be 1-200")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	stale := stalePasswords(db, now.Add(-age))
+	charset := []byte(upperLetters + lowerLetters + digits + specialChars)
+	rotated := 0
+	for _, e := range stale {
+		days := int(now.Sub(e.PasswordChanged()).Hours() / 24)
+		fmt.Printf(tr("%s  %q  user %q  password is %d days old\n"), "/"+e.Parent().Path(), e.Title, e.Username, days)
+		if *list || !confirm(tr("Rotate now?")) {
+			continue
+		}
+		password, err := generatePasswordFromSet(*length, charset)
+		if err != nil {
+			return err
+		}
+		if err := copyToClipboard(password); err != nil {
+			fmt.Fprintf(os.Stderr, tr("%v\nNew password: %s\n"), err, password)
+		} else {
+			fmt.Println(tr("New password copied to the clipboard."))
+		}
+		if e.URL != "" {
+			fmt.Printf(tr("Change it at %s\n"), e.URL)
+		}
+		if !confirm(tr("Changed it on the site? Record the new password?")) {
+			continue
+		}
+		e.AddRevision()
+		e.Password = password
+		e.LastModificationTime = time.Now()
+		// Save after each rotation: the site already has the new password,
+		// so it must not be lost if a later one fails.
+		if err := writeDatabase(db); err != nil {
+			return err
+		}
+		rotated++
+	}
+	fmt.Printf(tr("%d entries with passwords older than %s, %d rotated\n"), len(stale), *olderThan, rotated)
+	return nil
+}
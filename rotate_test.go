@@ -0,0 +1,80 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+		ok   bool
+	}{
+		{"365d", 365 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"720h", 720 * time.Hour, true},
+		{"d", 0, false},
+		{"-3d", 0, false},
+		{"-1h", 0, false},
+		{"1y", 0, false},
+	}
+	for _, test := range tests {
+		got, err := parseAge(test.s)
+		if got != test.want || (err == nil) != test.ok {
+			t.Errorf("parseAge(%q) = %v, %v; want %v, ok=%t", test.s, got, err, test.want, test.ok)
+		}
+	}
+}
+
+func TestStalePasswords(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := db.Root().NewSubgroup()
+	now := time.Date(2019, time.July, 1, 22, 0, 0, 0, time.UTC)
+	newEntry := func(password string, age time.Duration) *keepass.Entry {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Password = password
+		e.CreationTime = now.Add(-age)
+		e.LastModificationTime = now.Add(-age)
+		return e
+	}
+	old := newEntry("hunter2", 400*24*time.Hour)
+	older := newEntry("xyzzy", 500*24*time.Hour)
+	newEntry("", 500*24*time.Hour)
+	newEntry("fresh", 10*24*time.Hour)
+	changed := newEntry("new", 500*24*time.Hour)
+	changed.History = []keepass.Revision{{Password: "old", Modified: now.Add(-500 * 24 * time.Hour)}}
+	changed.LastModificationTime = now.Add(-24 * time.Hour)
+	recycled := newEntry("gone", 500*24*time.Hour)
+	if err := db.RecycleEntry(recycled); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := stalePasswords(db, now.Add(-365*24*time.Hour))
+	if len(stale) != 2 || stale[0] != older || stale[1] != old {
+		t.Errorf("stalePasswords = %v; want [older old]", stale)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// newKeyFileData returns the contents of a new random key file.
+func newKeyFileData() ([]byte, error) {
+	var raw [32]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return nil, err
+	}
+	return []byte(hex.EncodeToString(raw[:])), nil
+}
+
+// rotateKeyFile replaces the database's key file with a freshly generated
+// one.  The current credentials must be given; the password is kept.  The
+// database is written atomically, so on failure the old key file still
+// opens it.  The new key file is sent as the response and is the only copy.
+func rotateKeyFile(w http.ResponseWriter, r *http.Request) error {
+	password, keyfile, err := readCredentials(r)
+	if err != nil {
+		return err
+	}
+	newKeyFile, err := newKeyFileData()
+	if err != nil {
+		return fmt.Errorf("rotate key file: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err := unlocks.allow(); err != nil {
+		return err
+	}
+	db, err := openDatabase(&keepass.Options{
+		Password: password,
+		KeyFile:  optReader(keyfile),
+	})
+	unlocks.record(r, err)
+	if err != nil {
+		return err
+	}
+	secrets.Add(password)
+	err = db.SetKey(flagOptions(&keepass.Options{
+		Password: password,
+		KeyFile:  bytes.NewReader(newKeyFile),
+	}))
+	if err != nil {
+		return fmt.Errorf("rotate key file: %v", err)
+	}
+	if err := writeDatabase(db); err != nil {
+		return fmt.Errorf("rotate key file: %v", err)
+	}
+	if err := rewrapEscrow(db); err != nil {
+		return fmt.Errorf("rotate key file: database rotated, but escrow is stale: %v", err)
+	}
+	if _, err := sessions.new(w, sessionData{Key: db.ComputedKey()}); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="gostpass.key"`)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(len(newKeyFile)))
+	w.Header().Set("Cache-Control", "no-store")
+	_, err = w.Write(newKeyFile)
+	return err
+}
+
+// runKeyfile rotates the -keyfile from the command line.  The new key
+// file is written next to the old one first; only once the database is
+// written and reopens with it is the old key file overwritten, removed
+// and replaced by the new one.
+func runKeyfile(args []string) error {
+	if len(args) != 1 || args[0] != "rotate" {
+		return errors.New("usage: keyfile rotate")
+	}
+	if *keyFilePath == "" {
+		return errors.New("keyfile rotate: no -keyfile to rotate")
+	}
+	if err := initDatabase(); err != nil {
+		return err
+	}
+	opts, err := commandOptions()
+	if err != nil {
+		return err
+	}
+	password := opts.Password
+	db, err := openDatabase(opts)
+	if err != nil {
+		return err
+	}
+	newKeyFile, err := newKeyFileData()
+	if err != nil {
+		return fmt.Errorf("keyfile rotate: %v", err)
+	}
+	oldPath := *keyFilePath
+	newPath := oldPath + ".new"
+	if err := writeSecretFile(newPath, newKeyFile); err != nil {
+		return fmt.Errorf("keyfile rotate: %v", err)
+	}
+
+	// The database is checked against, and from now on opened with, the
+	// new key file.
+	*keyFilePath = newPath
+	err = rekeyDatabase(db, flagOptions(&keepass.Options{
+		Password:  password,
+		KeyFile:   bytes.NewReader(newKeyFile),
+		KeyRounds: db.KeyRounds(),
+	}))
+	*keyFilePath = oldPath
+	if err != nil {
+		// The write is atomic: unless the database on disk already opens
+		// with the new key file, the old one still does.
+		_, oerr := openDatabase(&keepass.Options{
+			Password: password,
+			KeyFile:  bytes.NewReader(newKeyFile),
+		})
+		if oerr != nil {
+			os.Remove(newPath)
+			return fmt.Errorf("keyfile rotate: %v", err)
+		}
+	}
+	if serr := shredFile(oldPath); serr != nil {
+		return fmt.Errorf("keyfile rotate: database rotated, but the old key file is not erased: %v; the new key file is %s", serr, newPath)
+	}
+	if rerr := os.Rename(newPath, oldPath); rerr != nil {
+		return fmt.Errorf("keyfile rotate: database rotated, but the new key file is still %s: %v", newPath, rerr)
+	}
+	if err != nil {
+		return fmt.Errorf("keyfile rotate: %v", err)
+	}
+	return nil
+}
+
+// shredFile overwrites the file at path with random bytes, flushes it to
+// disk and removes it.
+func shredFile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err == nil {
+		_, err = io.CopyN(f, rand.Reader, fi.Size())
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestKeyfileRotate(t *testing.T) {
+	dir, cleanup := newCommandTestDB(t, "")
+	defer cleanup()
+	oldKeyFile := *keyFilePath
+	defer func() { *keyFilePath = oldKeyFile }()
+	*keyFilePath = filepath.Join(dir, "vault.key")
+	if err := ioutil.WriteFile(*keyFilePath, []byte("0123456789abcdef0123456789abcdef"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	templatePath := filepath.Join(dir, "template.json")
+	if err := ioutil.WriteFile(templatePath, []byte(`{"key_rounds": 1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := runInit([]string{"-template", templatePath}); err != nil {
+		t.Fatal("init:", err)
+	}
+	before, err := ioutil.ReadFile(*keyFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runKeyfile([]string{"rotate"}); err != nil {
+		t.Fatal("keyfile rotate:", err)
+	}
+	after, err := ioutil.ReadFile(*keyFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(before, after) {
+		t.Error("key file unchanged after keyfile rotate")
+	}
+	if _, err := os.Stat(*keyFilePath + ".new"); !os.IsNotExist(err) {
+		t.Errorf("stat new key file: %v; want it renamed into place", err)
+	}
+	if _, err := openCommandDatabase(); err != nil {
+		t.Error("open with the rotated key file:", err)
+	}
+	_, err = openDatabase(&keepass.Options{Password: "swordfish", KeyFile: bytes.NewReader(before)})
+	if err == nil {
+		t.Error("database still opens with the old key file")
+	}
+}
@@ -40,7 +40,10 @@ func handleSearch(w http.ResponseWriter, r *http.Request) error {
 	if pq == nil {
 		data.Query = ""
 	} else {
-		data.Results = search(db, pq)
+		data.Results, err = filterReadable(r, db, search(db, pq))
+		if err != nil {
+			return err
+		}
 	}
 	return tmpl.ExecuteTemplate(w, "search.html", data)
 }
@@ -48,7 +51,7 @@ func handleSearch(w http.ResponseWriter, r *http.Request) error {
 func search(db *keepass.Database, q *parsedQuery) []*keepass.Entry {
 	var results []*keepass.Entry
 	for _, e := range db.Entries() {
-		if q.matchesText(e.Title) || q.matchesText(e.Notes) {
+		if q.matchesEntry(e) {
 			results = append(results, e)
 		}
 	}
@@ -56,7 +59,8 @@ func search(db *keepass.Database, q *parsedQuery) []*keepass.Entry {
 }
 
 type parsedQuery struct {
-	pats []*textsearch.Pattern
+	words []string
+	pats  []*textsearch.Pattern
 }
 
 func parseQuery(query string) *parsedQuery {
@@ -82,15 +86,19 @@ func parseQuery(query string) *parsedQuery {
 	}
 	m := textsearch.New(language.Und, textsearch.Loose)
 	if len(words) == 1 {
-		return &parsedQuery{pats: []*textsearch.Pattern{m.CompileString(query)}}
+		return &parsedQuery{words: words, pats: []*textsearch.Pattern{m.CompileString(query)}}
 	}
-	pq := &parsedQuery{pats: make([]*textsearch.Pattern, len(words))}
+	pq := &parsedQuery{words: words, pats: make([]*textsearch.Pattern, len(words))}
 	for i := range words {
 		pq.pats[i] = m.CompileString(words[i])
 	}
 	return pq
 }
 
+func (pq *parsedQuery) matchesEntry(e *keepass.Entry) bool {
+	return pq.matchesText(e.Title) || pq.matchesText(e.Notes)
+}
+
 func (pq *parsedQuery) matchesText(s string) bool {
 	if pq == nil || len(pq.pats) == 0 {
 		return false
@@ -0,0 +1,227 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// An entryIndex maps the trigrams of entry titles and notes to entries, so
+// that a search only has to match the entries that contain every trigram
+// of the query rather than the whole database.  It is kept current by
+// calling update and remove as entries change, which makes it worth
+// having in sessions that search one database many times, like the
+// terminal browser.
+//
+// search matches loosely, ignoring case and diacritics by collation rules
+// that no byte-level folding reproduces, so only printable ASCII, where
+// lower-casing is all it takes, is indexed.  Entries with other text are
+// candidates for every query, and queries with other text scan everything.
+type entryIndex struct {
+	seq     int
+	entries map[*keepass.Entry]*indexedEntry
+	grams   map[trigram]map[*keepass.Entry]struct{}
+	// unindexed holds the entries with text outside printable ASCII.
+	unindexed map[*keepass.Entry]struct{}
+}
+
+// A trigram is three lower-cased bytes of a field.
+type trigram struct {
+	field int
+	s     [3]byte
+}
+
+// Fields of an entry that search matches.
+const (
+	indexTitle = iota
+	indexNotes
+	indexFields
+)
+
+type indexedEntry struct {
+	// seq orders results the way the database orders its entries.
+	seq   int
+	grams []trigram
+}
+
+// newEntryIndex indexes all entries of db.
+func newEntryIndex(db *keepass.Database) *entryIndex {
+	ix := &entryIndex{
+		entries:   make(map[*keepass.Entry]*indexedEntry),
+		grams:     make(map[trigram]map[*keepass.Entry]struct{}),
+		unindexed: make(map[*keepass.Entry]struct{}),
+	}
+	for _, e := range db.Entries() {
+		ix.update(e)
+	}
+	return ix
+}
+
+// update indexes a new entry or re-indexes one whose title or notes have
+// changed.
+func (ix *entryIndex) update(e *keepass.Entry) {
+	ie := ix.entries[e]
+	if ie == nil {
+		ie = &indexedEntry{seq: ix.seq}
+		ix.seq++
+		ix.entries[e] = ie
+	}
+	ix.unlink(e, ie)
+	fields := [indexFields]string{indexTitle: e.Title, indexNotes: e.Notes}
+	for _, s := range fields {
+		if !indexable(s) {
+			ix.unindexed[e] = struct{}{}
+			return
+		}
+	}
+	for field, s := range fields {
+		for _, g := range trigrams(field, s) {
+			m := ix.grams[g]
+			if m == nil {
+				m = make(map[*keepass.Entry]struct{})
+				ix.grams[g] = m
+			}
+			m[e] = struct{}{}
+			ie.grams = append(ie.grams, g)
+		}
+	}
+}
+
+// remove drops an entry that was deleted from the database.
+func (ix *entryIndex) remove(e *keepass.Entry) {
+	if ie := ix.entries[e]; ie != nil {
+		ix.unlink(e, ie)
+		delete(ix.entries, e)
+	}
+}
+
+func (ix *entryIndex) unlink(e *keepass.Entry, ie *indexedEntry) {
+	for _, g := range ie.grams {
+		delete(ix.grams[g], e)
+		if len(ix.grams[g]) == 0 {
+			delete(ix.grams, g)
+		}
+	}
+	ie.grams = nil
+	delete(ix.unindexed, e)
+}
+
+// search returns the same entries as search over the indexed database.
+func (ix *entryIndex) search(q *parsedQuery) []*keepass.Entry {
+	if q == nil {
+		return nil
+	}
+	candidates := make(map[*keepass.Entry]struct{})
+	for field := 0; field < indexFields; field++ {
+		m, ok := ix.fieldCandidates(field, q.words)
+		if !ok {
+			candidates = nil
+			break
+		}
+		for e := range m {
+			candidates[e] = struct{}{}
+		}
+	}
+	var results []*keepass.Entry
+	add := func(e *keepass.Entry) {
+		if q.matchesEntry(e) {
+			results = append(results, e)
+		}
+	}
+	if candidates == nil {
+		for e := range ix.entries {
+			add(e)
+		}
+	} else {
+		for e := range candidates {
+			add(e)
+		}
+		for e := range ix.unindexed {
+			if _, ok := candidates[e]; !ok {
+				add(e)
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return ix.entries[results[i]].seq < ix.entries[results[j]].seq
+	})
+	return results
+}
+
+// fieldCandidates returns the indexed entries whose field contains every
+// trigram of words.  It returns false if the trigrams rule nothing out.
+func (ix *entryIndex) fieldCandidates(field int, words []string) (map[*keepass.Entry]struct{}, bool) {
+	var grams []trigram
+	for _, w := range words {
+		if !indexable(w) {
+			return nil, false
+		}
+		grams = append(grams, trigrams(field, w)...)
+	}
+	if len(grams) == 0 {
+		return nil, false
+	}
+	// Intersect starting from the rarest trigram.
+	sort.Slice(grams, func(i, j int) bool { return len(ix.grams[grams[i]]) < len(ix.grams[grams[j]]) })
+	m := make(map[*keepass.Entry]struct{})
+	for e := range ix.grams[grams[0]] {
+		m[e] = struct{}{}
+	}
+	for _, g := range grams[1:] {
+		if len(m) == 0 {
+			break
+		}
+		for e := range m {
+			if _, ok := ix.grams[g][e]; !ok {
+				delete(m, e)
+			}
+		}
+	}
+	return m, true
+}
+
+// indexable reports whether s is printable ASCII, allowing whitespace.
+func indexable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < ' ' || c > '~') && c != '\t' && c != '\n' && c != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// trigrams returns the distinct trigrams of an indexable string.
+func trigrams(field int, s string) []trigram {
+	var grams []trigram
+	seen := make(map[trigram]bool)
+	for i := 0; i+3 <= len(s); i++ {
+		g := trigram{field: field}
+		for j := range g.s {
+			c := s[i+j]
+			if 'A' <= c && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			g.s[j] = c
+		}
+		if !seen[g] {
+			seen[g] = true
+			grams = append(grams, g)
+		}
+	}
+	return grams
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestEntryIndex(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]*keepass.Entry)
+	for _, f := range []struct{ title, notes string }{
+		{"GitHub", "personal account"},
+		{"GitLab", "Work account\nrecovery codes in the safe"},
+		{"Café Wi-Fi", "password on the receipt"},
+		{"Bank", "PIN is not stored here"},
+		{"Mail", "Résumé attached"},
+		{"ab", ""},
+	} {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title, e.Notes = f.title, f.notes
+		entries[f.title] = e
+	}
+	ix := newEntryIndex(db)
+
+	queries := []string{
+		"git", "GIT", "github", "hub lab", "account", "work account",
+		"cafe", "café", "wi-fi", "resume", "RÉSUMÉ", "ab", "a", "pin stored",
+		"nothing", "gitlab safe", " git",
+	}
+	check := func(when string) {
+		t.Helper()
+		for _, q := range queries {
+			pq := parseQuery(q)
+			if got, want := ix.search(pq), search(db, pq); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: index search %q = %s; want %s", when, q, entryTitles(got), entryTitles(want))
+			}
+		}
+	}
+	check("initially")
+
+	entries["GitHub"].Title = "Codeberg"
+	ix.update(entries["GitHub"])
+	entries["Bank"].Notes = "GitHub sponsors card"
+	ix.update(entries["Bank"])
+	entries["Café Wi-Fi"].Title = "Cafe"
+	ix.update(entries["Café Wi-Fi"])
+	check("after update")
+
+	if err := g.RemoveEntry(entries["GitLab"]); err != nil {
+		t.Fatal(err)
+	}
+	ix.remove(entries["GitLab"])
+	e, err := g.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Title = "Gitea"
+	ix.update(e)
+	check("after remove and add")
+}
+
+func entryTitles(es []*keepass.Entry) []string {
+	titles := make([]string, len(es))
+	for i, e := range es {
+		titles[i] = e.Title
+	}
+	return titles
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/secretref"
+)
+
+var secretBackendsFlag = flag.String("secret_backends", "env,file,keyring", "comma-separated backends that entries' secret references may use: env, file, keyring and exec; exec runs commands named in the database, so only enable it for databases you wrote")
+
+// secretRefKey is the entry custom data key of a reference to a password
+// kept outside the database.  Such an entry stores no password: it is
+// resolved when released, and never written back.
+const secretRefKey = "gostpass.secret_ref"
+
+var secretBackends = map[string]secretref.Backend{
+	"env":     secretref.Env,
+	"exec":    secretref.Exec,
+	"file":    secretref.File,
+	"keyring": secretref.Keyring,
+}
+
+func secretBackendNames() string {
+	names := make([]string, 0, len(secretBackends))
+	for name := range secretBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// secretResolver returns a resolver with the -secret_backends backends.
+func secretResolver() (secretref.Resolver, error) {
+	r := make(secretref.Resolver)
+	for _, name := range strings.Split(*secretBackendsFlag, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		b := secretBackends[name]
+		if b == nil {
+			return nil, fmt.Errorf("-secret_backends: unknown backend %q; want %s", name, secretBackendNames())
+		}
+		r[name] = b
+	}
+	return r, nil
+}
+
+// resolveSecretRef fills in e's password from its secret reference, if it
+// has one.  It is called by releaseEntry, so that only released entries
+// reach out to their backends.
+func resolveSecretRef(e *keepass.Entry) error {
+	ref, ok := e.CustomData.Get(secretRefKey)
+	if !ok {
+		return nil
+	}
+	r, err := secretResolver()
+	if err != nil {
+		return err
+	}
+	password, err := r.Resolve(ref)
+	if err != nil {
+		return fmt.Errorf("%s: %v", entryPath(e), err)
+	}
+	secrets.Add(password)
+	e.Password = password
+	return nil
+}
+
+// clearSecretRefs empties the passwords of entries with secret references
+// before db is written, in case they were resolved.
+func clearSecretRefs(db *keepass.Database) {
+	for _, e := range db.Entries() {
+		if _, ok := e.CustomData.Get(secretRefKey); ok {
+			e.Password = ""
+		}
+	}
+}
+
+// runSecretRef shows, sets or removes an entry's secret reference.
+func runSecretRef(args []string) error {
+	fs := flag.NewFlagSet("secret-ref", flag.ContinueOnError)
+	off := fs.Bool("off", false, "remove the reference; the entry is left without a password")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 || *off && fs.NArg() != 1 {
+		return errors.New("usage: secret-ref [-off] path [scheme:target]")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	e, err := findEntryPath(db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	switch {
+	case *off:
+		e.CustomData.Delete(secretRefKey)
+	case fs.NArg() == 1:
+		ref, ok := e.CustomData.Get(secretRefKey)
+		if !ok {
+			return fmt.Errorf(tr("%s has no secret reference"), entryPath(e))
+		}
+		fmt.Println(ref)
+		return nil
+	default:
+		ref := fs.Arg(1)
+		scheme, _, err := secretref.Parse(ref)
+		if err != nil {
+			return err
+		}
+		if secretBackends[scheme] == nil {
+			return fmt.Errorf("unknown backend %q; want %s", scheme, secretBackendNames())
+		}
+		e.CustomData.Set(secretRefKey, ref)
+		e.Password = ""
+	}
+	return writeDatabase(db)
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSecretRef(t *testing.T) {
+	_, cleanup := newCommandTestDB(t, `{"key_rounds": 1, "entries": [{"path": "CI/GitHub"}]}`)
+	defer cleanup()
+	defer func(backends string) { *secretBackendsFlag = backends }(*secretBackendsFlag)
+	os.Setenv("GOSTPASS_TEST_TOKEN", "ghp_secret")
+	defer os.Unsetenv("GOSTPASS_TEST_TOKEN")
+
+	if err := runSecretRef([]string{"CI/GitHub", "carrier-pigeon:coo"}); err == nil {
+		t.Error("secret-ref with unknown backend succeeded")
+	}
+	if err := runSecretRef([]string{"CI/GitHub", "env:GOSTPASS_TEST_TOKEN"}); err != nil {
+		t.Fatal("secret-ref:", err)
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := findEntryPath(db, "CI/GitHub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := releaseEntry(e, "test"); err != nil {
+		t.Fatal("releaseEntry:", err)
+	}
+	if e.Password != "ghp_secret" {
+		t.Errorf("password after release = %q; want %q", e.Password, "ghp_secret")
+	}
+
+	// The resolved password is not saved.
+	if err := writeDatabase(db); err != nil {
+		t.Fatal(err)
+	}
+	if db, err = openCommandDatabase(); err != nil {
+		t.Fatal(err)
+	}
+	if e, err = findEntryPath(db, "CI/GitHub"); err != nil {
+		t.Fatal(err)
+	}
+	if e.Password != "" {
+		t.Errorf("stored password = %q; want empty", e.Password)
+	}
+
+	*secretBackendsFlag = "file"
+	if err := releaseEntry(e, "test"); err == nil {
+		t.Error("releaseEntry with env backend disabled succeeded")
+	}
+	*secretBackendsFlag = "env,exec,carrier-pigeon"
+	if err := releaseEntry(e, "test"); err == nil {
+		t.Error("releaseEntry with unknown backend in -secret_backends succeeded")
+	}
+
+	if err := runSecretRef([]string{"-off", "CI/GitHub"}); err != nil {
+		t.Fatal("secret-ref -off:", err)
+	}
+	if err := runSecretRef([]string{"CI/GitHub"}); err == nil {
+		t.Error("secret-ref after -off found a reference")
+	}
+}
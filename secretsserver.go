@@ -0,0 +1,279 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// A secretsServer serves the fields of entries in one group over HTTP, for
+// secret operators like the External Secrets Operator webhook provider.
+// The database is reopened when the file changes.  It is only written to
+// add entries with drop tokens.
+type secretsServer struct {
+	token []byte
+	group string
+
+	mu      sync.Mutex
+	key     []byte
+	db      *keepass.Database
+	modTime time.Time
+}
+
+// database returns the database, reopening it if it changed on disk.
+func (s *secretsServer) database() (*keepass.Database, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fi, err := os.Stat(*dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if s.db != nil && fi.ModTime().Equal(s.modTime) {
+		return s.db, nil
+	}
+	db, err := openDatabase(&keepass.Options{ComputedKey: s.key})
+	if err != nil {
+		return nil, err
+	}
+	s.db, s.modTime = db, fi.ModTime()
+	return db, nil
+}
+
+// ServeHTTP answers GET /v1/entries/PATH with the fields of the entry at
+// PATH, relative to the served group, as a JSON object, and GET
+// /v1/attachments/PATH with the entry's attachment.  POST /v1/drop adds
+// an entry with a drop token.
+func (s *secretsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	db, err := s.database()
+	if err != nil {
+		log.Printf("secrets server: %v", err)
+		http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	scope, ok := s.authorize(db, r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	const entriesPrefix, attachmentsPrefix = "/v1/entries/", "/v1/attachments/"
+	var path string
+	attachment := false
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/v1/drop":
+		s.drop(w, r, scope)
+		return
+	case r.Method != "GET":
+		http.NotFound(w, r)
+		return
+	case strings.HasPrefix(r.URL.Path, entriesPrefix):
+		path = r.URL.Path[len(entriesPrefix):]
+	case strings.HasPrefix(r.URL.Path, attachmentsPrefix):
+		path = r.URL.Path[len(attachmentsPrefix):]
+		attachment = true
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	path = strings.Trim(path, "/")
+	if g := strings.Trim(s.group, "/"); g != "" {
+		path = g + "/" + path
+	}
+	e, err := findEntryPath(db, path)
+	if err != nil || e.Parent().InRecycleBin() || scope != nil && !scope.allows(e) {
+		http.NotFound(w, r)
+		return
+	}
+	if attachment && e.Attachment.Name == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if attachment && scope != nil && !scope.Attachments {
+		http.Error(w, "token cannot read attachments", http.StatusForbidden)
+		return
+	}
+	client := "secrets-server client " + r.RemoteAddr
+	if scope != nil {
+		client += " with token " + scope.ID
+	}
+	if err := releaseEntry(e, client); err != nil {
+		log.Printf("secrets server: %v", err)
+		http.Error(w, "release refused", http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	if attachment {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(e.Attachment.Data)
+		return
+	}
+	fields := make(map[string]string, len(entryFields))
+	for name, get := range entryFields {
+		fields[name] = get(e)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fields)
+}
+
+// maxDropSize bounds the body of a drop request.
+const maxDropSize = 64 << 10
+
+// drop adds the entry given as a JSON object of fields to the drop token's
+// group.  The response holds only the new entry's UUID.
+func (s *secretsServer) drop(w http.ResponseWriter, r *http.Request, scope *apiToken) {
+	if scope == nil || scope.Drop == nil {
+		http.Error(w, "not a drop token", http.StatusForbidden)
+		return
+	}
+	if *readOnly {
+		http.Error(w, "server is read-only", http.StatusForbidden)
+		return
+	}
+	var fields struct {
+		Title    string `json:"title"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+		URL      string `json:"url"`
+		Notes    string `json:"notes"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDropSize)).Decode(&fields); err != nil {
+		http.Error(w, "body must be a JSON object of entry fields", http.StatusBadRequest)
+		return
+	}
+	if fields.Title == "" {
+		http.Error(w, "title is required", http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	db, err := openDatabase(&keepass.Options{ComputedKey: s.key})
+	if err != nil {
+		log.Printf("secrets server: %v", err)
+		http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	g := db.FindGroup(*scope.Drop)
+	if g == nil || g.InRecycleBin() {
+		http.Error(w, "drop group no longer exists", http.StatusGone)
+		return
+	}
+	e, err := g.NewEntry()
+	if err != nil {
+		log.Printf("secrets server: drop: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	e.Title = fields.Title
+	e.Username = fields.Username
+	e.Password = fields.Password
+	e.URL = fields.URL
+	e.Notes = fields.Notes
+	if err := writeStorage(dbStorage, db, "secrets-server token "+scope.ID); err != nil {
+		log.Printf("secrets server: drop: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	s.db = nil
+	log.Printf("secrets server: token %s added entry %v to %s", scope.ID, e.UUID, g.Path())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"uuid": e.UUID.String()})
+}
+
+// authorize checks the request's bearer token.  It returns the token's
+// scope, which is nil for the -token_file token, and whether the token is
+// valid.
+func (s *secretsServer) authorize(db *keepass.Database, r *http.Request) (*apiToken, bool) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return nil, false
+	}
+	secret := auth[len("Bearer "):]
+	if s.token != nil && subtle.ConstantTimeCompare([]byte(secret), s.token) == 1 {
+		return nil, true
+	}
+	tokens, err := readAPITokens(db)
+	if err != nil {
+		log.Printf("secrets server: %v", err)
+		return nil, false
+	}
+	t := findAPIToken(tokens, secret)
+	return t, t != nil
+}
+
+// runSecretsServer serves entries of a group to cluster secret operators.
+// With the External Secrets Operator, point a webhook provider at
+// http://HOST/v1/entries/{{ .remoteRef.key }} with the token as a bearer
+// Authorization header and select a field with a JSON path like
+// "$.password".  Clients send either the -token_file token, which can read
+// everything served, or one made with token create, which can read only
+// its groups.
+func runSecretsServer(args []string) error {
+	fs := flag.NewFlagSet("secrets-server", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:8200", "address to listen on")
+	group := fs.String("group", "", "`path` of the group to serve; \"/\" serves every entry")
+	tokenFile := fs.String("token_file", "", "`file` with a bearer token that can read every entry served, besides those made with token create")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || *group == "" {
+		return errors.New("usage: secrets-server -group path [-token_file file] [-listen addr]")
+	}
+	var token []byte
+	if *tokenFile != "" {
+		var err error
+		if token, err = ioutil.ReadFile(*tokenFile); err != nil {
+			return err
+		}
+		token = bytes.TrimSpace(token)
+		if len(token) < 16 {
+			return errors.New("token must be at least 16 characters")
+		}
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	if db.FindGroupPath(*group) == nil {
+		return fmt.Errorf("%s: no such group", *group)
+	}
+	if token == nil {
+		if tokens, err := readAPITokens(db); err != nil {
+			return err
+		} else if len(tokens) == 0 {
+			return errors.New("no -token_file and no tokens in the database; make one with token create")
+		}
+	}
+	s := &secretsServer{token: token, group: *group, key: db.ComputedKey()}
+	if _, err := s.database(); err != nil {
+		return err
+	}
+	log.Printf("serving secrets from %s on %s", *group, *listen)
+	return http.ListenAndServe(*listen, s)
+}
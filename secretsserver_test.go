@@ -0,0 +1,409 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestSecretsServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_secrets_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldPath := *dbPath
+	*dbPath = filepath.Join(dir, "vault.kdb")
+	defer func() { *dbPath = oldPath }()
+	if err := initDatabase(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"Kubernetes/prod", "Personal"} {
+		g, err := db.MkdirAll(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title = "db"
+		e.Password = "secret of " + path
+	}
+	if err := writeDatabase(db); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &secretsServer{token: []byte("0123456789abcdef"), group: "Kubernetes", key: db.ComputedKey()}
+	tests := []struct {
+		path, token string
+		code        int
+		password    string
+	}{
+		{"/v1/entries/prod/db", "0123456789abcdef", http.StatusOK, "secret of Kubernetes/prod"},
+		{"/v1/entries/prod/db", "wrong", http.StatusUnauthorized, ""},
+		{"/v1/entries/../Personal/db", "0123456789abcdef", http.StatusNotFound, ""},
+		{"/v1/entries/Personal/db", "0123456789abcdef", http.StatusNotFound, ""},
+		{"/other", "0123456789abcdef", http.StatusNotFound, ""},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "http://localhost"+test.path, nil)
+		r.Header.Set("Authorization", "Bearer "+test.token)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("GET %s with token %q = %d; want %d", test.path, test.token, w.Code, test.code)
+			continue
+		}
+		if test.code != http.StatusOK {
+			continue
+		}
+		var fields map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &fields); err != nil {
+			t.Errorf("GET %s: %v", test.path, err)
+		} else if fields["password"] != test.password {
+			t.Errorf("GET %s password = %q; want %q", test.path, fields["password"], test.password)
+		}
+	}
+}
+
+func TestSecretsServer_APITokens(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_secrets_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldPath := *dbPath
+	*dbPath = filepath.Join(dir, "vault.kdb")
+	defer func() { *dbPath = oldPath }()
+	if err := initDatabase(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"Monitoring/Grafana", "Production"} {
+		g, err := db.MkdirAll(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title = "api"
+		e.Password = "secret of " + path
+		e.Attachment.Name = "ca.pem"
+		e.Attachment.Data = []byte("certificate of " + path)
+	}
+	monitoring, secret, err := newAPIToken(fakerand.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	monitoring.Groups = []uint32{db.FindGroupPath("Monitoring").ID}
+	withAttachments, attachmentSecret, err := newAPIToken(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withAttachments.Attachments = true
+	if err := writeAPITokens(db, []*apiToken{monitoring, withAttachments}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeDatabase(db); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &secretsServer{group: "/", key: db.ComputedKey()}
+	tests := []struct {
+		path, token string
+		code        int
+		body        string
+	}{
+		{"/v1/entries/Monitoring/Grafana/api", secret, http.StatusOK, "secret of Monitoring/Grafana"},
+		{"/v1/entries/Production/api", secret, http.StatusNotFound, ""},
+		{"/v1/attachments/Monitoring/Grafana/api", secret, http.StatusForbidden, ""},
+		{"/v1/entries/Production/api", attachmentSecret, http.StatusOK, "secret of Production"},
+		{"/v1/attachments/Production/api", attachmentSecret, http.StatusOK, "certificate of Production"},
+		{"/v1/entries/Production/api", monitoring.ID + ".00", http.StatusUnauthorized, ""},
+		{"/v1/entries/Production/api", "", http.StatusUnauthorized, ""},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "http://localhost"+test.path, nil)
+		r.Header.Set("Authorization", "Bearer "+test.token)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("GET %s with token %q = %d; want %d", test.path, test.token, w.Code, test.code)
+			continue
+		}
+		if test.code != http.StatusOK {
+			continue
+		}
+		got := w.Body.String()
+		if strings.HasPrefix(test.path, "/v1/entries/") {
+			var fields map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &fields); err != nil {
+				t.Errorf("GET %s: %v", test.path, err)
+				continue
+			}
+			got = fields["password"]
+		}
+		if got != test.body {
+			t.Errorf("GET %s = %q; want %q", test.path, got, test.body)
+		}
+	}
+}
+
+func TestSecretsServer_Drop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_secrets_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldPath := *dbPath
+	*dbPath = filepath.Join(dir, "vault.kdb")
+	defer func() { *dbPath = oldPath }()
+	if err := initDatabase(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbox, err := db.MkdirAll("Team/Inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := inbox.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Title = "existing"
+	e.Password = "hunter2"
+	dropper, secret, err := newAPIToken(fakerand.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropper.Drop = &inbox.ID
+	if err := writeAPITokens(db, []*apiToken{dropper}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeDatabase(db); err != nil {
+		t.Fatal(err)
+	}
+	s := &secretsServer{token: []byte("0123456789abcdef"), group: "/", key: db.ComputedKey()}
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "http://localhost"+path, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w
+	}
+
+	w := do("POST", "/v1/drop", secret, `{"title": "CI deploy key", "password": "s3cret"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /v1/drop = %d %q; want %d", w.Code, w.Body, http.StatusCreated)
+	}
+	// A drop token can't read, not even what it dropped.
+	if w := do("GET", "/v1/entries/Team/Inbox/CI%20deploy%20key", secret, ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET dropped entry with drop token = %d; want %d", w.Code, http.StatusNotFound)
+	}
+	if w := do("GET", "/v1/entries/Team/Inbox/existing", secret, ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET existing entry with drop token = %d; want %d", w.Code, http.StatusNotFound)
+	}
+	w = do("GET", "/v1/entries/Team/Inbox/CI%20deploy%20key", "0123456789abcdef", "")
+	var fields map[string]string
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &fields) != nil || fields["password"] != "s3cret" {
+		t.Errorf("GET dropped entry = %d %q; want its fields", w.Code, w.Body)
+	}
+
+	for _, test := range []struct {
+		token, body string
+		code        int
+	}{
+		{"0123456789abcdef", `{"title": "x"}`, http.StatusForbidden},
+		{secret, `{"password": "no title"}`, http.StatusBadRequest},
+		{secret, `not json`, http.StatusBadRequest},
+	} {
+		if w := do("POST", "/v1/drop", test.token, test.body); w.Code != test.code {
+			t.Errorf("POST /v1/drop %s with token %q = %d; want %d", test.body, test.token, w.Code, test.code)
+		}
+	}
+}
+
+func TestSecretsServer_DropFirstGroup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_secrets_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldPath := *dbPath
+	*dbPath = filepath.Join(dir, "vault.kdb")
+	defer func() { *dbPath = oldPath }()
+	if err := initDatabase(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first group created gets ID 0.
+	inbox := db.Root().NewSubgroup()
+	inbox.Name = "Inbox"
+	if inbox.ID != 0 {
+		t.Fatalf("first group ID = %d; want 0", inbox.ID)
+	}
+	e, err := inbox.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Title = "existing"
+	e.Password = "hunter2"
+	dropper, secret, err := newAPIToken(fakerand.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropper.Drop = &inbox.ID
+	if err := writeAPITokens(db, []*apiToken{dropper}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeDatabase(db); err != nil {
+		t.Fatal(err)
+	}
+	s := &secretsServer{token: []byte("0123456789abcdef"), group: "/", key: db.ComputedKey()}
+	do := func(method, path, body string) int {
+		r := httptest.NewRequest(method, "http://localhost"+path, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+secret)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := do("GET", "/v1/entries/Inbox/existing", ""); code != http.StatusNotFound {
+		t.Errorf("GET existing entry with drop token = %d; want %d", code, http.StatusNotFound)
+	}
+	if code := do("POST", "/v1/drop", `{"title": "CI deploy key"}`); code != http.StatusCreated {
+		t.Errorf("POST /v1/drop = %d; want %d", code, http.StatusCreated)
+	}
+	db, err = openDatabase(&keepass.Options{ComputedKey: s.key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := readAPITokens(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].Drop == nil || *tokens[0].Drop != 0 {
+		t.Errorf("stored drop token lost its target group 0")
+	}
+}
+
+func TestRmDropsTokenGroups(t *testing.T) {
+	_, cleanup := newCommandTestDB(t, `{"key_rounds": 1, "groups": ["Team/Ops/Oncall", "Team/Dev"]}`)
+	defer cleanup()
+	db, err := openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, oncall, dev := db.FindGroupPath("Team/Ops"), db.FindGroupPath("Team/Ops/Oncall"), db.FindGroupPath("Team/Dev")
+	var tokens []*apiToken
+	for i, groups := range [][]uint32{{ops.ID}, {oncall.ID, dev.ID}, nil} {
+		tok, _, err := newAPIToken(fakerand.New())
+		if err != nil {
+			t.Fatal(err)
+		}
+		tok.ID = fmt.Sprint(i)
+		tok.Groups = groups
+		tokens = append(tokens, tok)
+	}
+	dropper, _, err := newAPIToken(fakerand.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropper.ID = "drop"
+	dropper.Drop = &oncall.ID
+	if err := writeAPITokens(db, append(tokens, dropper)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeDatabase(db); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runRm([]string{"-r", "Team/Ops"}); err != nil {
+		t.Fatal("rm:", err)
+	}
+	db, err = openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens, err := readAPITokens(db); err != nil || len(tokens) != 4 {
+		t.Errorf("tokens after recycling = %v, %v; want all 4 kept", tokens, err)
+	}
+	if err := runRm([]string{"-r", keepass.RecycleBinName + "/Ops"}); err != nil {
+		t.Fatal("rm from recycle bin:", err)
+	}
+	if err := runMkdir([]string{"New/Sub"}); err != nil {
+		t.Fatal("mkdir:", err)
+	}
+
+	db, err = openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readAPITokens(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scopes := make(map[string][]uint32)
+	for _, tok := range got {
+		scopes[tok.ID] = tok.Groups
+	}
+	want := map[string][]uint32{"1": {dev.ID}, "2": nil}
+	if !reflect.DeepEqual(scopes, want) {
+		t.Errorf("token scopes after rm = %v; want %v", scopes, want)
+	}
+	for _, path := range []string{"New", "New/Sub"} {
+		g := db.FindGroupPath(path)
+		for _, tok := range got {
+			for _, id := range tok.Groups {
+				if id == g.ID {
+					t.Errorf("token %s can read new group %s", tok.ID, path)
+				}
+			}
+		}
+	}
+}
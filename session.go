@@ -60,7 +60,8 @@ func (ss *sessionStorage) new(w http.ResponseWriter, data sessionData) (*session
 	}
 	s := &session{
 		Data: sessionData{
-			Key: append([]byte(nil), data.Key...), // defensive copy
+			Key:   append([]byte(nil), data.Key...), // defensive copy
+			Decoy: data.Decoy,
 		},
 		Expires: ss.now().Add(ss.expiry),
 	}
@@ -91,11 +92,21 @@ func (ss *sessionStorage) dbFromRequest(w http.ResponseWriter, r *http.Request)
 		}
 		return nil, errInvalidSession
 	}
-	return openDatabase(&keepass.Options{
+	return openStorage(s.Data.storage(), &keepass.Options{
 		ComputedKey: s.Data.Key,
 	})
 }
 
+// storageFromRequest returns the storage of the database that the
+// request's session unlocked.
+func (ss *sessionStorage) storageFromRequest(r *http.Request) *storage {
+	s := ss.fromRequest(r)
+	if !ss.isValid(s) {
+		return dbStorage
+	}
+	return s.Data.storage()
+}
+
 // fromRequest obtains the request's session data. If the session information is
 // in any way invalid, fromRequest returns nil. It does not return an error so
 // as to potentially avoid leaking information to an attacker.
@@ -215,7 +226,8 @@ func (ss *sessionStorage) refreshKey() (*sessionKeyFile, error) {
 }
 
 type sessionData struct {
-	Key kdbcrypt.ComputedKey `json:"key"`
+	Key   kdbcrypt.ComputedKey `json:"key"`
+	Decoy bool                 `json:"decoy,omitempty"` // session unlocked the duress database
 }
 
 type session struct {
@@ -0,0 +1,390 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pedroalbanese/gostpass/pkg/sharelink"
+)
+
+var sharesPath = flag.String("shares", "", "path to the directory of share links (default is -db path with \".shares\" appended)")
+
+// maxShareFailures is how many wrong keys a share link takes before it is
+// deleted, so that its key can't be guessed online.
+const maxShareFailures = 5
+
+// A shareRecord is a share link's file.  Only the box is secret, and its
+// key is in the link alone.
+type shareRecord struct {
+	Entry    string         `json:"entry"`
+	Field    string         `json:"field"`
+	Expires  time.Time      `json:"expires"`
+	Views    int            `json:"views"`
+	Failures int            `json:"failures,omitempty"`
+	Box      *sharelink.Box `json:"box"`
+}
+
+// shareMu serializes changes to share records between requests.
+var shareMu sync.Mutex
+
+func sharesDir() string {
+	if *sharesPath != "" {
+		return *sharesPath
+	}
+	return *dbPath + ".shares"
+}
+
+var shareEncoding = base64.RawURLEncoding
+
+// shareFile returns the path of the record for id, or "" if id isn't one
+// that newShare could have made.
+func shareFile(id string) string {
+	if b, err := shareEncoding.DecodeString(id); err != nil || len(b) != 16 {
+		return ""
+	}
+	return filepath.Join(sharesDir(), id+".json")
+}
+
+// readShare returns the record for id, or nil if there is none.
+func readShare(id string) (*shareRecord, error) {
+	path := shareFile(id)
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read share: %v", err)
+	}
+	rec := new(shareRecord)
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, fmt.Errorf("read share %s: %v", id, err)
+	}
+	return rec, nil
+}
+
+func writeShare(id string, rec *shareRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("write share: %v", err)
+	}
+	if err := os.MkdirAll(sharesDir(), 0700); err != nil {
+		return fmt.Errorf("write share: %v", err)
+	}
+	st, err := newStorage(shareFile(id))
+	if err != nil {
+		return fmt.Errorf("write share: %v", err)
+	}
+	defer st.Close()
+	wc, err := st.writer()
+	if err != nil {
+		return fmt.Errorf("write share: %v", err)
+	}
+	_, err = wc.Write(data)
+	cerr := wc.Close()
+	if err != nil {
+		return fmt.Errorf("write share: %v", err)
+	}
+	if cerr != nil {
+		return fmt.Errorf("write share: close: %v", cerr)
+	}
+	return nil
+}
+
+func removeShare(id string) error {
+	if err := os.Remove(shareFile(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove share: %v", err)
+	}
+	return nil
+}
+
+// listShares returns the IDs of the share links, removing expired ones.
+func listShares(now time.Time) ([]string, error) {
+	names, err := filepath.Glob(filepath.Join(sharesDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, name := range names {
+		id := strings.TrimSuffix(filepath.Base(name), ".json")
+		rec, err := readShare(id)
+		if err != nil {
+			return nil, err
+		}
+		if rec == nil {
+			continue
+		}
+		if now.After(rec.Expires) {
+			if err := removeShare(id); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// newShare seals secret in a new share link and returns its ID and key.
+func newShare(entry, field, secret string, expires time.Time, views int) (id, key string, err error) {
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", "", fmt.Errorf("new share: %v", err)
+	}
+	id = shareEncoding.EncodeToString(raw[:])
+	k, box, err := sharelink.Seal(rand.Reader, id, []byte(secret))
+	if err != nil {
+		return "", "", err
+	}
+	rec := &shareRecord{Entry: entry, Field: field, Expires: expires, Views: views, Box: box}
+	if err := writeShare(id, rec); err != nil {
+		return "", "", err
+	}
+	return id, shareEncoding.EncodeToString(k), nil
+}
+
+// shareBaseURL guesses the server's URL from -listen and -tls_cert.
+func shareBaseURL() string {
+	scheme := "http"
+	if *tlsCert != "" {
+		scheme = "https"
+	}
+	host, port, err := net.SplitHostPort(*listen)
+	if err != nil {
+		return scheme + "://" + *listen
+	}
+	if host == "" || host == "::" || host == "0.0.0.0" {
+		host = "localhost"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port)
+}
+
+// runShare makes a link that reveals one field of an entry to whoever has
+// it, a limited number of times, from the server started with the same
+// -db or -shares.
+func runShare(args []string) error {
+	fs := flag.NewFlagSet("share", flag.ContinueOnError)
+	field := fs.String("field", "password", "entry field to share: "+strings.Join(sortedEntryFields(), ", "))
+	ttl := fs.Duration("ttl", time.Hour, "how long the link works")
+	views := fs.Int("views", 1, "how many times the secret can be revealed")
+	baseURL := fs.String("url", "", "the server's URL (default is guessed from -listen)")
+	list := fs.Bool("list", false, "list the links that still work")
+	revoke := fs.Bool("revoke", false, "delete the links with the given IDs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	now := time.Now()
+	switch {
+	case *list:
+		ids, err := listShares(now)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			rec, err := readShare(id)
+			if err != nil || rec == nil {
+				continue
+			}
+			fmt.Printf(tr("%s  %s %s  views left %d  expires %s\n"), id, rec.Entry, rec.Field, rec.Views, rec.Expires.Local().Format("2006-01-02 15:04"))
+		}
+		return nil
+	case *revoke:
+		for _, id := range fs.Args() {
+			if shareFile(id) == "" {
+				return fmt.Errorf("%q is not a share link ID", id)
+			}
+			if err := removeShare(id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: share [-field name] [-ttl duration] [-views n] [-url url] entry | share -list | share -revoke id...")
+	}
+	get := entryFields[*field]
+	if get == nil {
+		return fmt.Errorf("unknown field %q; want one of %s", *field, strings.Join(sortedEntryFields(), ", "))
+	}
+	if *ttl <= 0 || *views < 1 {
+		return errors.New("-ttl and -views must be positive")
+	}
+	if _, err := listShares(now); err != nil {
+		return err
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	e, err := findEntryPath(db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := releaseEntry(e, "share"); err != nil {
+		return err
+	}
+	id, key, err := newShare(entryPath(e), *field, get(e), now.Add(*ttl), *views)
+	if err != nil {
+		return err
+	}
+	base := *baseURL
+	if base == "" {
+		base = shareBaseURL()
+	}
+	// The key is in the fragment, which browsers don't send to the server.
+	fmt.Printf("%s/s/%s#%s\n", strings.TrimRight(base, "/"), id, key)
+	return nil
+}
+
+const sharePage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="referrer" content="no-referrer">
+<meta name="robots" content="noindex">
+<title>Shared secret</title>
+<link rel="stylesheet" href="/style.css">
+</head>
+<body>
+<h1>Shared secret</h1>
+<p>Someone shared a secret with you.  It can only be revealed a limited number of times, so copy it somewhere safe.</p>
+<button id="reveal" type="button">Reveal</button>
+<pre id="secret"></pre>
+<script src="/_/share.js"></script>
+</body>
+</html>
+`
+
+// shareScript posts the key from the fragment when the button is clicked,
+// so that link previews don't use up views.
+const shareScript = `(function() {
+  var button = document.getElementById('reveal');
+  var out = document.getElementById('secret');
+  var key = location.hash.slice(1);
+  history.replaceState(null, '', location.pathname);
+  if (!key) {
+    button.hidden = true;
+    out.textContent = 'This link is incomplete.';
+    return;
+  }
+  button.addEventListener('click', function() {
+    button.disabled = true;
+    var xhr = new XMLHttpRequest();
+    xhr.open('POST', location.pathname);
+    xhr.setRequestHeader('Content-Type', 'application/x-www-form-urlencoded');
+    xhr.onload = function() {
+      button.hidden = xhr.status === 200;
+      out.textContent = xhr.responseText;
+    };
+    xhr.onerror = function() {
+      button.disabled = false;
+      out.textContent = 'Could not reach the server.';
+    };
+    xhr.send('key=' + encodeURIComponent(key));
+  });
+})();
+`
+
+const shareGoneMessage = "This link has expired or was already used."
+
+// viewShare serves the page that reveals a share link's secret.
+func viewShare(w http.ResponseWriter, r *http.Request) error {
+	shareMu.Lock()
+	rec, err := readShare(mux.Vars(r)["id"])
+	shareMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if rec == nil || time.Now().After(rec.Expires) {
+		http.Error(w, shareGoneMessage, http.StatusNotFound)
+		return nil
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err = io.WriteString(w, sharePage)
+	return err
+}
+
+func serveShareScript(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	_, err := io.WriteString(w, shareScript)
+	return err
+}
+
+// openShare reveals a share link's secret to a request with its key, and
+// uses up a view.  Wrong keys count against the link.
+func openShare(w http.ResponseWriter, r *http.Request) error {
+	id := mux.Vars(r)["id"]
+	key, _ := shareEncoding.DecodeString(r.FormValue("key"))
+	shareMu.Lock()
+	defer shareMu.Unlock()
+	rec, err := readShare(id)
+	if err != nil {
+		return err
+	}
+	if rec == nil || time.Now().After(rec.Expires) {
+		if rec != nil {
+			removeShare(id)
+		}
+		http.Error(w, shareGoneMessage, http.StatusNotFound)
+		return nil
+	}
+	secret, err := rec.Box.Open(id, key)
+	if err == sharelink.ErrKey {
+		if rec.Failures++; rec.Failures >= maxShareFailures {
+			err = removeShare(id)
+		} else {
+			err = writeShare(id, rec)
+		}
+		if err != nil {
+			return err
+		}
+		http.Error(w, "This link is damaged.", http.StatusForbidden)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	// Use up the view before revealing, so a failed write can't give
+	// away more views than allowed.
+	if rec.Views--; rec.Views <= 0 {
+		err = removeShare(id)
+	} else {
+		err = writeShare(id, rec)
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, err = w.Write(secret)
+	return err
+}
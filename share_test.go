@@ -0,0 +1,88 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestShareLinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_share_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(p string) { *sharesPath = p }(*sharesPath)
+	*sharesPath = dir
+
+	open := func(id, key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/s/"+id, strings.NewReader(url.Values{"key": {key}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		appHandler{f: openShare, noXSRF: true}.ServeHTTP(w, mux.SetURLVars(r, map[string]string{"id": id}))
+		return w
+	}
+
+	id, key, err := newShare("Web/Mail", "password", "hunter2", time.Now().Add(time.Hour), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if w := open(id, key); w.Code != http.StatusOK || w.Body.String() != "hunter2" {
+			t.Errorf("view %d: %d %q; want 200 \"hunter2\"", i+1, w.Code, w.Body.String())
+		}
+	}
+	if w := open(id, key); w.Code != http.StatusNotFound {
+		t.Errorf("view 3: status %d; want 404", w.Code)
+	}
+
+	// Wrong keys use up the link.
+	id, key, err = newShare("Web/Mail", "password", "hunter2", time.Now().Add(time.Hour), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrong := shareEncoding.EncodeToString(make([]byte, 32))
+	for i := 0; i < maxShareFailures; i++ {
+		if w := open(id, wrong); w.Code != http.StatusForbidden {
+			t.Errorf("wrong key %d: status %d; want 403", i+1, w.Code)
+		}
+	}
+	if w := open(id, key); w.Code != http.StatusNotFound {
+		t.Errorf("after %d wrong keys: status %d; want 404", maxShareFailures, w.Code)
+	}
+
+	id, key, err = newShare("Web/Mail", "password", "hunter2", time.Now().Add(-time.Second), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := open(id, key); w.Code != http.StatusNotFound {
+		t.Errorf("expired: status %d; want 404", w.Code)
+	}
+	if shareFile("../../etc/passwd") != "" {
+		t.Error("shareFile accepted a path")
+	}
+	if ids, err := listShares(time.Now()); err != nil || len(ids) != 0 {
+		t.Errorf("listShares = %v, %v; want none", ids, err)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/shamir"
+)
+
+// splitKey splits the database's computed key into "n" Shamir shares, any
+// "t" of which can unlock the database, and sends them as hex, one share
+// per line.
+func splitKey(w http.ResponseWriter, r *http.Request) error {
+	n, err := strconv.Atoi(r.FormValue("n"))
+	if err != nil {
+		return userError{
+			msg: "Number of shares must be a number.",
+			err: fmt.Errorf("split key: n: %v", err),
+		}
+	}
+	t, err := strconv.Atoi(r.FormValue("t"))
+	if err != nil {
+		return userError{
+			msg: "Threshold must be a number.",
+			err: fmt.Errorf("split key: t: %v", err),
+		}
+	}
+	mu.Lock()
+	db, err := sessions.dbFromRequest(w, r)
+	mu.Unlock()
+	if err != nil {
+		return err
+	}
+	shares, err := shamir.Split(rand.Reader, db.ComputedKey(), n, t)
+	if err == shamir.ErrShareCount || err == shamir.ErrThreshold {
+		return userError{
+			msg: "Need at least 2 and at most 255 shares, and a threshold between 2 and the number of shares.",
+			err: fmt.Errorf("split key: %v", err),
+		}
+	} else if err != nil {
+		return fmt.Errorf("split key: %v", err)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	for _, share := range shares {
+		if _, err := fmt.Fprintln(w, hex.EncodeToString(share)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverKey starts a session from the hex-encoded Shamir shares given in
+// the "share" form values.  Shares may also be given as one
+// whitespace-separated value.
+func recoverKey(w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	shares, err := parseShares(strings.Join(r.Form["share"], " "))
+	if err != nil {
+		return rootRedirectError{userError{
+			msg: "Key shares must be hex-encoded.",
+			err: fmt.Errorf("recover key: %v", err),
+		}}
+	}
+	key, err := shamir.Combine(shares)
+	if err != nil {
+		return rootRedirectError{userError{
+			msg: "Key shares are malformed or duplicated.",
+			err: fmt.Errorf("recover key: %v", err),
+		}}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if err := unlocks.allow(); err != nil {
+		return rootRedirectError{err}
+	}
+	db, err := openDatabase(&keepass.Options{ComputedKey: key})
+	unlocks.record(r, err)
+	if isUserError(err) {
+		return rootRedirectError{err}
+	} else if err != nil {
+		return err
+	}
+	if _, err := sessions.new(w, sessionData{Key: db.ComputedKey()}); err != nil {
+		return err
+	}
+	return redirectRoute(w, r, "listGroups")
+}
+
+// parseShares decodes the whitespace-separated hex shares in s.
+func parseShares(s string) ([][]byte, error) {
+	var shares [][]byte
+	for _, f := range strings.Fields(s) {
+		share, err := hex.DecodeString(f)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+	return shares, nil
+}
+
+// runSplitKey prints Shamir shares of the database's computed key, one
+// hex share per line and, with -qr, each as a QR code too.  Any -t of
+// them give recover-key the key.
+func runSplitKey(args []string) error {
+	fs := flag.NewFlagSet("split-key", flag.ContinueOnError)
+	n := fs.Int("n", 5, "number of shares")
+	t := fs.Int("t", 3, "number of shares needed to recover the key")
+	qr := fs.Bool("qr", false, "also draw each share as a QR code with "+qrencodeProgram)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("usage: split-key [-n shares] [-t threshold] [-qr]")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	shares, err := shamir.Split(rand.Reader, db.ComputedKey(), *n, *t)
+	if err != nil {
+		return fmt.Errorf("split key: %v", err)
+	}
+	for _, share := range shares {
+		s := hex.EncodeToString(share)
+		secrets.Add(s)
+		fmt.Println(s)
+		if *qr {
+			if err := drawQR(s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runRecoverKey opens the database with the key recombined from the
+// shares on standard input, whitespace-separated and ended by an empty
+// line or end of file, and sets a new password.
+func runRecoverKey(args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: recover-key < shares")
+	}
+	if terminal.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintln(os.Stderr, tr("Enter the key shares, one per line, then an empty line:"))
+	}
+	var text []string
+	for {
+		line, err := stdin.ReadString('\n')
+		if strings.TrimSpace(line) == "" && (err != nil || len(text) > 0) {
+			break
+		}
+		text = append(text, line)
+		if err != nil {
+			break
+		}
+	}
+	shares, err := parseShares(strings.Join(text, " "))
+	if err != nil {
+		return fmt.Errorf("recover key: %v", err)
+	}
+	key, err := shamir.Combine(shares)
+	if err != nil {
+		return fmt.Errorf("recover key: %v", err)
+	}
+	if err := initDatabase(); err != nil {
+		return err
+	}
+	db, err := openDatabase(&keepass.Options{ComputedKey: key})
+	if err != nil {
+		return fmt.Errorf("recover key: %v", err)
+	}
+	opts, err := newDatabaseOptions()
+	if err != nil {
+		return err
+	}
+	opts.KeyRounds = db.KeyRounds()
+	if err := rekeyDatabase(db, opts); err != nil {
+		return fmt.Errorf("recover key: %v", err)
+	}
+	fmt.Printf(tr("%s: recovered, with a new password\n"), *dbPath)
+	return nil
+}
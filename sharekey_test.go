@@ -0,0 +1,64 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/shamir"
+)
+
+func TestRecoverKey(t *testing.T) {
+	dir, cleanup := newCommandTestDB(t, `{"key_rounds": 1}`)
+	defer cleanup()
+	oldStdin := stdin
+	defer func() { stdin = oldStdin }()
+	db, err := openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := shamir.Split(rand.Reader, db.ComputedKey(), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runSplitKey([]string{"-n", "2", "-t", "3"}); err == nil {
+		t.Error("split-key with a threshold above the number of shares succeeded")
+	}
+
+	stdin = bufio.NewReader(strings.NewReader(hex.EncodeToString(shares[0]) + "\n" + hex.EncodeToString(shares[3]) + "\n\n"))
+	if err := runRecoverKey(nil); err == nil {
+		t.Error("recover-key with too few shares succeeded")
+	}
+
+	newPassword := filepath.Join(dir, "new-password")
+	if err := ioutil.WriteFile(newPassword, []byte("hunter2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	*passwordFile = newPassword
+	input := "\n" + hex.EncodeToString(shares[4]) + "\n" + hex.EncodeToString(shares[1]) + " " + hex.EncodeToString(shares[2]) + "\n\nnot a share\n"
+	stdin = bufio.NewReader(strings.NewReader(input))
+	if err := runRecoverKey(nil); err != nil {
+		t.Fatal("recover-key:", err)
+	}
+	if _, err := openCommandDatabase(); err != nil {
+		t.Error("open with the new password after recover-key:", err)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/termmd"
+)
+
+// groupNotesKey is the custom data key that holds a group's notes.  KDB1
+// groups have no notes field of their own.
+const groupNotesKey = "gostpass.notes"
+
+// notesColor reports whether notes written to standard output should be
+// styled: only on a terminal, and not if the user set NO_COLOR.
+func notesColor() bool {
+	return os.Getenv("NO_COLOR") == "" && terminal.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// writeNotes writes notes after a blank line, rendered as Markdown unless
+// raw is set.  Either way, control characters are removed.
+func writeNotes(w io.Writer, notes string, raw, color bool) {
+	if notes == "" {
+		return
+	}
+	if raw {
+		notes = termmd.Sanitize(strings.Replace(notes, "\r\n", "\n", -1))
+	} else {
+		notes = termmd.Render(notes, color)
+	}
+	fmt.Fprintf(w, "\n%s\n", notes)
+}
+
+// writeEntry writes e's fields and notes, with password in place of the
+// actual password.
+func writeEntry(w io.Writer, e *keepass.Entry, password string, raw, color bool) {
+	fmt.Fprintln(w, entryPath(e))
+	fmt.Fprintf(w, tr("  username  %s\n"), e.Username)
+	fmt.Fprintf(w, tr("  password  %s\n"), password)
+	if e.URL != "" {
+		fmt.Fprintf(w, tr("  url       %s\n"), e.URL)
+	}
+	if e.Attachment.Name != "" {
+		fmt.Fprintf(w, tr("  file      %s\n"), e.Attachment.Name)
+	}
+	writeNotes(w, e.Notes, raw, color)
+}
+
+// writeGroup writes g's subgroups, entries and notes.
+func writeGroup(w io.Writer, g *keepass.Group, raw, color bool) {
+	path := g.Path()
+	if path == "" {
+		path = "/"
+	}
+	fmt.Fprintln(w, path)
+	for _, sub := range g.Groups() {
+		fmt.Fprintf(w, "  %s/\n", sub.Name)
+	}
+	for _, e := range g.Entries() {
+		fmt.Fprintf(w, "  %s\n", e.Title)
+	}
+	notes, _ := g.CustomData.Get(groupNotesKey)
+	writeNotes(w, notes, raw, color)
+}
+
+// runShow prints an entry or a group with its notes rendered as Markdown.
+func runShow(args []string) error {
+	fs := flag.NewFlagSet("show", flag.ContinueOnError)
+	raw := fs.Bool("raw", false, "print notes as written instead of rendering their Markdown")
+	reveal := fs.Bool("reveal", false, "show the password instead of masking it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: show [-raw] [-reveal] path")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	if g := db.FindGroupPath(fs.Arg(0)); g != nil {
+		writeGroup(os.Stdout, g, *raw, notesColor())
+		return nil
+	}
+	e, err := findEntryPath(db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	password := masked
+	if *reveal {
+		if err := releaseEntry(e, "show"); err != nil {
+			return err
+		}
+		password = e.Password
+	}
+	writeEntry(os.Stdout, e, password, *raw, notesColor())
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestShow(t *testing.T) {
+	template := `{"key_rounds": 1, "entries": [{"path": "Ops/Restore", "username": "root", "notes": "## Steps\n1. Stop the **app**\n- run ` + "`restore.sh`" + `"}]}`
+	dir, cleanup := newCommandTestDB(t, template)
+	defer cleanup()
+	notesPath := filepath.Join(dir, "notes.md")
+	if err := ioutil.WriteFile(notesPath, []byte("# On call\nPage *ops* first."), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := runCommand([]string{"mkdir", "-notes", notesPath, "Ops", "Ops/DB"}); code != 0 {
+		t.Fatalf("mkdir exited with %d", code)
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	writeGroup(buf, db.FindGroupPath("Ops"), false, false)
+	want := "Ops\n  DB/\n  Restore\n\nOn call\nPage ops first.\n"
+	if buf.String() != want {
+		t.Errorf("group Ops:\n%s\nwant:\n%s", buf, want)
+	}
+
+	e, err := findEntryPath(db, "Ops/Restore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	writeEntry(buf, e, masked, false, false)
+	want = "Ops/Restore\n  username  root\n  password  ********\n\nSteps\n1. Stop the app\n• run restore.sh\n"
+	if buf.String() != want {
+		t.Errorf("entry Ops/Restore:\n%s\nwant:\n%s", buf, want)
+	}
+	buf.Reset()
+	writeEntry(buf, e, masked, true, false)
+	if want := "\n" + e.Notes + "\n"; !bytes.HasSuffix(buf.Bytes(), []byte(want)) {
+		t.Errorf("raw entry Ops/Restore:\n%s\nwant notes as written", buf)
+	}
+
+	// Control characters are removed from notes, raw or not.
+	for _, raw := range []bool{false, true} {
+		buf.Reset()
+		writeNotes(buf, "copy \x1b]52;c;cm0gLXJmIH4=\x07me", raw, false)
+		if bytes.ContainsAny(buf.Bytes(), "\x1b\x07") {
+			t.Errorf("notes with raw=%t = %q; want control characters removed", raw, buf)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pedroalbanese/gostpass/pkg/gostsig"
+)
+
+var (
+	signKeyPath   = flag.String("sign_key", "", "path to hex GOST R 34.10-2012 private key; if set, every save writes a detached signature to the -db path with \".sig\" appended")
+	verifyKeyPath = flag.String("verify_key", "", "path to hex GOST R 34.10-2012 public key; if set, the database is only opened if its signature matches")
+)
+
+// The signature covers the encrypted file, so it can be checked by anyone
+// holding the public key, and lives next to the database like the escrow
+// record.
+func signatureFile() string {
+	return *dbPath + ".sig"
+}
+
+// readKeyFile reads a hex-encoded key from path.
+func readKeyFile(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return key, nil
+}
+
+// verifySignature reads the whole database from r and checks it against
+// the detached signature.  It returns a reader to the verified bytes, so
+// that what is decrypted is exactly what was checked.
+func verifySignature(r io.Reader) (io.Reader, error) {
+	pub, err := readKeyFile(*verifyKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("verify signature: public key: %v", err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("verify signature: %v", err)
+	}
+	sig, err := ioutil.ReadFile(signatureFile())
+	if os.IsNotExist(err) {
+		return nil, userError{
+			msg: "Database is not signed.",
+			err: errors.New("verify signature: no signature file"),
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("verify signature: %v", err)
+	}
+	if err := gostsig.Verify(pub, data, sig); err == gostsig.ErrBadSignature {
+		return nil, userError{
+			msg: "Database signature does not match.  The database may have been modified without the signing key.",
+			err: fmt.Errorf("verify signature: %v", err),
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("verify signature: %v", err)
+	}
+	return bytes.NewReader(data), nil
+}
+
+// signDatabase signs data, the database as it will be written.
+func signDatabase(data []byte) ([]byte, error) {
+	prv, err := readKeyFile(*signKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("sign database: private key: %v", err)
+	}
+	sig, err := gostsig.Sign(rand.Reader, prv, data)
+	if err != nil {
+		return nil, fmt.Errorf("sign database: %v", err)
+	}
+	return sig, nil
+}
+
+// writeSignature replaces the detached signature.
+func writeSignature(sig []byte) error {
+	st, err := newStorage(signatureFile())
+	if err != nil {
+		return fmt.Errorf("sign database: %v", err)
+	}
+	defer st.Close()
+	wc, err := st.writer()
+	if err != nil {
+		return fmt.Errorf("sign database: %v", err)
+	}
+	_, err = wc.Write(sig)
+	cerr := wc.Close()
+	if err != nil {
+		return fmt.Errorf("sign database: %v", err)
+	}
+	if cerr != nil {
+		return fmt.Errorf("sign database: close: %v", cerr)
+	}
+	return nil
+}
+
+// runSignKeygen writes a new signing key pair for -sign_key and -verify_key.
+func runSignKeygen(args []string) error {
+	fs := flag.NewFlagSet("sign-keygen", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("usage: sign-keygen private.key public.key")
+	}
+	prv, pub, err := gostsig.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	if err := writeSecretFile(fs.Arg(0), []byte(hex.EncodeToString(prv)+"\n")); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fs.Arg(1), []byte(hex.EncodeToString(pub)+"\n"), 0644)
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestSignedDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_signature_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldPath, oldSign, oldVerify := *dbPath, *signKeyPath, *verifyKeyPath
+	*dbPath = filepath.Join(dir, "vault.kdb")
+	*signKeyPath = filepath.Join(dir, "sign.key")
+	*verifyKeyPath = filepath.Join(dir, "sign.pub")
+	defer func() { *dbPath, *signKeyPath, *verifyKeyPath = oldPath, oldSign, oldVerify }()
+	if err := runSignKeygen([]string{*signKeyPath, *verifyKeyPath}); err != nil {
+		t.Fatal("sign-keygen:", err)
+	}
+	if err := initDatabase(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeDatabase(db); err != nil {
+		t.Fatal("writeDatabase:", err)
+	}
+	opts := &keepass.Options{Password: "swordfish"}
+	if _, err := openDatabase(opts); err != nil {
+		t.Fatal("openDatabase of signed database:", err)
+	}
+
+	// Rewrite the database without signing it, as someone without the
+	// private key would.
+	*signKeyPath = ""
+	if err := writeDatabase(db); err != nil {
+		t.Fatal("writeDatabase:", err)
+	}
+	if _, err := openDatabase(opts); !isUserError(err) {
+		t.Errorf("openDatabase of modified database error = %v; want user error", err)
+	}
+	if err := os.Remove(signatureFile()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openDatabase(opts); !isUserError(err) {
+		t.Errorf("openDatabase of unsigned database error = %v; want user error", err)
+	}
+}
@@ -0,0 +1,420 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/sshkey"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+const sshUsage = `usage: ssh add path keyfile
+       ssh list
+       ssh load [-a socket] [-t lifetime] [-c] [path...]
+       ssh agent [-a socket] [-t lifetime] [-c] [path...]
+       ssh export [-public] path`
+
+// isSSHKey reports whether data is an SSH private key: its first PEM block
+// is a private key and it has no certificates, which would make it a TLS
+// key bundle.
+func isSSHKey(data []byte) bool {
+	b, _ := pem.Decode(data)
+	if b == nil || !strings.HasSuffix(b.Type, "PRIVATE KEY") {
+		return false
+	}
+	certs, _ := pemCertificates(data)
+	return len(certs) == 0
+}
+
+// entrySSHKey returns the private key attached to e, decrypted with the
+// entry's password.
+func entrySSHKey(e *keepass.Entry) (interface{}, error) {
+	if !e.HasAttachment() || !isSSHKey(e.Attachment.Data) {
+		return nil, fmt.Errorf("%s: no SSH key attached", entryPath(e))
+	}
+	key, err := sshkey.ParseRawPrivateKey(e.Attachment.Data, []byte(e.Password))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", entryPath(e), err)
+	}
+	return key, nil
+}
+
+// sshKeyEntries returns the entries at paths, or all entries with SSH keys
+// outside the recycle bin if no paths are given.
+func sshKeyEntries(db *keepass.Database, paths []string) ([]*keepass.Entry, error) {
+	if len(paths) > 0 {
+		entries := make([]*keepass.Entry, 0, len(paths))
+		for _, path := range paths {
+			e, err := findEntryPath(db, path)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		}
+		return entries, nil
+	}
+	var entries []*keepass.Entry
+	for _, e := range db.Entries() {
+		if e.HasAttachment() && isSSHKey(e.Attachment.Data) && !e.Parent().InRecycleBin() {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// runSSH manages SSH private keys stored as entry attachments.  An
+// encrypted key's passphrase is the entry's password.
+func runSSH(args []string) error {
+	if len(args) == 0 {
+		return errors.New(sshUsage)
+	}
+	switch args[0] {
+	case "add":
+		if len(args) != 3 {
+			return errors.New(sshUsage)
+		}
+		return sshAdd(args[1], args[2])
+	case "list":
+		if len(args) != 1 {
+			return errors.New(sshUsage)
+		}
+		return sshList()
+	case "load", "agent":
+		return sshLoad(args[0], args[1:])
+	case "export":
+		return sshExport(args[1:])
+	default:
+		return errors.New(sshUsage)
+	}
+}
+
+// sshAdd attaches the private key in file to the entry at path, creating
+// the entry if necessary.  If the key is encrypted, its passphrase is
+// asked for unless the entry's password already opens it.
+func sshAdd(path, file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if !isSSHKey(data) {
+		return fmt.Errorf("%s: not an SSH private key", file)
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	e, err := findEntryPath(db, path)
+	if _, ok := err.(pathNotFoundError); ok {
+		dir, title := splitItemPath(path)
+		if title == "" {
+			return err
+		}
+		g, err := db.MkdirAll(dir)
+		if err != nil {
+			return err
+		}
+		if e, err = g.NewEntry(); err != nil {
+			return err
+		}
+		e.Title = title
+		e.CreationTime = now
+	} else if err != nil {
+		return err
+	} else if e.HasAttachment() && !isSSHKey(e.Attachment.Data) {
+		return fmt.Errorf("%s: already has attachment %q", path, e.Attachment.Name)
+	} else {
+		e.AddRevision()
+	}
+	passphrase := e.Password
+	key, err := sshkey.ParseRawPrivateKey(data, []byte(passphrase))
+	if err == sshkey.ErrPassphrase {
+		if passphrase, err = promptPassword(fmt.Sprintf(tr("Passphrase for %s: "), file)); err != nil {
+			return err
+		}
+		key, err = sshkey.ParseRawPrivateKey(data, []byte(passphrase))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	e.Attachment.Name = filepath.Base(file)
+	e.Attachment.Data = data
+	if sshkey.Encrypted(data) {
+		e.Password = passphrase
+	}
+	e.LastModificationTime = now
+	if err := writeDatabase(db); err != nil {
+		return err
+	}
+	fmt.Printf("%s  %s  %s\n", ssh.FingerprintSHA256(signer.PublicKey()), signer.PublicKey().Type(), entryPath(e))
+	return nil
+}
+
+// sshList prints the fingerprint and type of every stored SSH key.
+func sshList() error {
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	entries, err := sshKeyEntries(db, nil)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		key, err := entrySSHKey(e)
+		if err == nil {
+			var signer ssh.Signer
+			if signer, err = ssh.NewSignerFromKey(key); err == nil {
+				fmt.Printf("%s  %s  %s\n", ssh.FingerprintSHA256(signer.PublicKey()), signer.PublicKey().Type(), entryPath(e))
+				continue
+			}
+		}
+		fmt.Fprintf(stderr, "gostpass ssh: %v\n", err)
+	}
+	return nil
+}
+
+// sshLoad adds keys to the ssh-agent at $SSH_AUTH_SOCK ("load") or starts
+// an agent holding only them ("agent").
+func sshLoad(name string, args []string) error {
+	fs := flag.NewFlagSet("ssh "+name, flag.ContinueOnError)
+	socket := fs.String("a", "", "agent `socket` (default is $SSH_AUTH_SOCK for load, a new temporary socket for agent)")
+	lifetime := fs.String("t", "", "remove the keys from the agent after this `age`, like 8h or 1d")
+	confirmUse := fs.Bool("c", false, "ask for confirmation each time a key is used")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var secs uint32
+	if *lifetime != "" {
+		d, err := parseAge(*lifetime)
+		if err != nil {
+			return err
+		}
+		secs = uint32(d / time.Second)
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	entries, err := sshKeyEntries(db, fs.Args())
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("no SSH keys in the database")
+	}
+	keys := make([]agent.AddedKey, 0, len(entries))
+	for _, e := range entries {
+		key, err := entrySSHKey(e)
+		if err != nil {
+			return err
+		}
+		if err := releaseEntry(e, "ssh "+name); err != nil {
+			return err
+		}
+		keys = append(keys, agent.AddedKey{
+			PrivateKey:       key,
+			Comment:          entryPath(e),
+			LifetimeSecs:     secs,
+			ConfirmBeforeUse: *confirmUse,
+		})
+	}
+	if name == "agent" {
+		return serveSSHAgent(*socket, keys)
+	}
+	if *socket == "" {
+		if *socket = os.Getenv("SSH_AUTH_SOCK"); *socket == "" {
+			return errors.New("SSH_AUTH_SOCK is not set; give the agent socket with -a")
+		}
+	}
+	conn, err := net.Dial("unix", *socket)
+	if err != nil {
+		return fmt.Errorf("connect to agent: %v", err)
+	}
+	defer conn.Close()
+	client := agent.NewClient(conn)
+	for _, k := range keys {
+		if err := client.Add(k); err != nil {
+			return fmt.Errorf("%s: %v", k.Comment, err)
+		}
+		fmt.Printf(tr("Loaded %s\n"), k.Comment)
+	}
+	return nil
+}
+
+// serveSSHAgent runs an ssh-agent holding keys until interrupted.
+func serveSSHAgent(socket string, keys []agent.AddedKey) error {
+	if socket == "" {
+		dir, err := secretDir()
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		socket = filepath.Join(dir, "agent.sock")
+	}
+	ag := newConfirmingAgent()
+	for _, k := range keys {
+		if err := ag.Add(k); err != nil {
+			return fmt.Errorf("%s: %v", k.Comment, err)
+		}
+	}
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		<-sigs
+		l.Close()
+	}()
+	fmt.Printf("SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", socket)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			// Closing the listener on a signal is the normal way out.
+			return nil
+		}
+		go func() {
+			defer conn.Close()
+			agent.ServeAgent(ag, conn)
+		}()
+	}
+}
+
+// confirmingAgent asks on the terminal before signing with keys added with
+// ConfirmBeforeUse, which agent.NewKeyring accepts but doesn't enforce.
+// It is an ExtendedAgent, so that agent.ServeAgent passes on requests for
+// rsa-sha2-256 and rsa-sha2-512 signatures, which OpenSSH 8.8 and later
+// require for RSA keys, instead of making SHA-1 ones.
+type confirmingAgent struct {
+	agent.ExtendedAgent
+
+	mu      sync.Mutex
+	confirm map[string]string // fingerprint to comment
+	prompt  sync.Mutex        // one question at a time
+}
+
+func newConfirmingAgent() *confirmingAgent {
+	return &confirmingAgent{
+		ExtendedAgent: agent.NewKeyring().(agent.ExtendedAgent),
+		confirm:       make(map[string]string),
+	}
+}
+
+func (a *confirmingAgent) Add(key agent.AddedKey) error {
+	if key.ConfirmBeforeUse {
+		signer, err := ssh.NewSignerFromKey(key.PrivateKey)
+		if err != nil {
+			return err
+		}
+		a.mu.Lock()
+		a.confirm[ssh.FingerprintSHA256(signer.PublicKey())] = key.Comment
+		a.mu.Unlock()
+	}
+	return a.ExtendedAgent.Add(key)
+}
+
+func (a *confirmingAgent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+func (a *confirmingAgent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	a.mu.Lock()
+	comment, ask := a.confirm[ssh.FingerprintSHA256(key)]
+	a.mu.Unlock()
+	if ask {
+		a.prompt.Lock()
+		ok := confirm(fmt.Sprintf(tr("Allow use of SSH key %s?"), comment))
+		a.prompt.Unlock()
+		if !ok {
+			return nil, errors.New("agent: use of key refused")
+		}
+	}
+	return a.ExtendedAgent.SignWithFlags(key, data, flags)
+}
+
+// Extension reports every extension as unsupported.
+func (a *confirmingAgent) Extension(extensionType string, contents []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
+
+// sshExport prints an entry's key unencrypted in PKCS#8 PEM, which most
+// tools other than OpenSSH read, or its public key in authorized_keys
+// format.
+func sshExport(args []string) error {
+	fs := flag.NewFlagSet("ssh export", flag.ContinueOnError)
+	public := fs.Bool("public", false, "print the public key in authorized_keys format")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New(sshUsage)
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	e, err := findEntryPath(db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	key, err := entrySSHKey(e)
+	if err != nil {
+		return err
+	}
+	if *public {
+		signer, err := ssh.NewSignerFromKey(key)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+		return err
+	}
+	if err := releaseEntry(e, "ssh export"); err != nil {
+		return err
+	}
+	if k, ok := key.(*ed25519.PrivateKey); ok {
+		key = *k
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("%s: %v", fs.Arg(0), err)
+	}
+	return pem.Encode(os.Stdout, &pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/sshkey"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func TestIsSSHKey(t *testing.T) {
+	key, err := ioutil.ReadFile("pkg/sshkey/testdata/ed25519_encrypted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isSSHKey(key) {
+		t.Error("isSSHKey(OpenSSH key) = false; want true")
+	}
+	cert := []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n")
+	if isSSHKey(append(append([]byte{}, key...), cert...)) {
+		t.Error("isSSHKey(key and certificate) = true; want false")
+	}
+	if isSSHKey([]byte("ssh-ed25519 AAAA")) {
+		t.Error("isSSHKey(public key) = true; want false")
+	}
+	if _, err := sshkey.ParseRawPrivateKey(key, []byte("swordfish")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConfirmingAgent(t *testing.T) {
+	ag := newConfirmingAgent()
+	for i, confirmUse := range []bool{false, true} {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ag.Add(agent.AddedKey{PrivateKey: priv, ConfirmBeforeUse: confirmUse}); err != nil {
+			t.Fatal(err)
+		}
+		signer, err := ssh.NewSignerFromKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Standard input is empty in tests, so confirmation is refused.
+		_, err = ag.Sign(signer.PublicKey(), []byte("challenge"))
+		if confirmUse && err == nil {
+			t.Errorf("key %d: Sign without confirmation succeeded; want error", i)
+		} else if !confirmUse && err != nil {
+			t.Errorf("key %d: Sign: %v", i, err)
+		}
+	}
+}
+
+func TestConfirmingAgent_RSA(t *testing.T) {
+	ag := newConfirmingAgent()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ag.Add(agent.AddedKey{PrivateKey: priv}); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Go through the agent protocol, as ssh does.
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	go func() {
+		defer c2.Close()
+		agent.ServeAgent(ag, c2)
+	}()
+	client := agent.NewClient(c1)
+	for flags, format := range map[agent.SignatureFlags]string{
+		agent.SignatureFlagRsaSha256: ssh.SigAlgoRSASHA2256,
+		agent.SignatureFlagRsaSha512: ssh.SigAlgoRSASHA2512,
+	} {
+		sig, err := client.SignWithFlags(signer.PublicKey(), []byte("challenge"), flags)
+		if err != nil {
+			t.Errorf("SignWithFlags(%d): %v", flags, err)
+			continue
+		}
+		if sig.Format != format {
+			t.Errorf("SignWithFlags(%d) signature format = %q; want %q", flags, sig.Format, format)
+		}
+		if err := signer.PublicKey().Verify([]byte("challenge"), sig); err != nil {
+			t.Errorf("SignWithFlags(%d) signature doesn't verify: %v", flags, err)
+		}
+	}
+}
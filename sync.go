@@ -0,0 +1,416 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/pedroalbanese/gogost/gost3412128"
+	"github.com/pedroalbanese/gogost/mgm"
+	"github.com/pedroalbanese/gostpass/pkg/gosthmac"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/uuids"
+)
+
+// Sync exchanges the entries that changed between two copies of the same
+// database.  Both sides must have the same key: it authenticates the peers
+// and keys the channel, so no certificates are needed.  Each message is
+// sealed with Kuznyechik-MGM, under keys for each direction that are
+// derived from the database key and both sides' random nonces.
+//
+// The protocol runs in turns, client first:
+//
+//	nonces -> indexes of entry UUIDs and modification times
+//	       -> UUIDs of the entries each side wants
+//	       -> the wanted entries
+//
+// An entry is wanted if the peer's copy was modified later or it is
+// missing.  Deletions only travel as moves to the recycle bin.
+
+const (
+	syncNonceSize = 32
+	// maxSyncMessage bounds a message, which may carry attachments.
+	maxSyncMessage = 256 << 20
+	// maxSyncFirstMessage bounds the first message, an index, which is
+	// read before anything shows that the peer has the key.
+	maxSyncFirstMessage = 16 << 20
+	syncTimeout         = 5 * time.Minute
+	// syncHandshakeTimeout is how long a peer has to send its first
+	// message; once it authenticates, the deadline becomes syncTimeout.
+	syncHandshakeTimeout = 15 * time.Second
+)
+
+var errSyncKey = errors.New("sync: peer's database has a different key, or the connection was tampered with")
+
+// syncConn sends and receives sealed messages.
+type syncConn struct {
+	rw               io.ReadWriter
+	send, recv       cipher.AEAD
+	sendSeq, recvSeq uint64
+}
+
+// newSyncConn runs the handshake on rw.
+func newSyncConn(rw io.ReadWriter, db *keepass.Database, client bool) (*syncConn, error) {
+	ours := make([]byte, syncNonceSize)
+	if _, err := io.ReadFull(rand.Reader, ours); err != nil {
+		return nil, fmt.Errorf("sync: %v", err)
+	}
+	theirs := make([]byte, syncNonceSize)
+	var err error
+	if client {
+		if _, err = rw.Write(ours); err == nil {
+			_, err = io.ReadFull(rw, theirs)
+		}
+	} else {
+		if _, err = io.ReadFull(rw, theirs); err == nil {
+			_, err = rw.Write(ours)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sync: handshake: %v", err)
+	}
+	clientNonce, serverNonce := ours, theirs
+	if !client {
+		clientNonce, serverNonce = theirs, ours
+	}
+	base := gosthmac.Sum256(db.ComputedKey(), []byte("gostpass sync"))
+	key := func(direction string) (cipher.AEAD, error) {
+		h := gosthmac.New256(base)
+		h.Write([]byte(direction))
+		h.Write(clientNonce)
+		h.Write(serverNonce)
+		return mgm.NewMGM(gost3412128.NewCipher(h.Sum(nil)), gost3412128.BlockSize)
+	}
+	c := &syncConn{rw: rw}
+	toServer, err := key("client to server")
+	if err != nil {
+		return nil, fmt.Errorf("sync: %v", err)
+	}
+	toClient, err := key("server to client")
+	if err != nil {
+		return nil, fmt.Errorf("sync: %v", err)
+	}
+	c.send, c.recv = toServer, toClient
+	if !client {
+		c.send, c.recv = toClient, toServer
+	}
+	return c, nil
+}
+
+// nonce returns the MGM nonce for a message's sequence number, which keeps
+// messages from being replayed, dropped or reordered.
+func syncNonce(aead cipher.AEAD, seq uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+	return nonce
+}
+
+func (c *syncConn) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("sync: %v", err)
+	}
+	sealed := c.send.Seal(nil, syncNonce(c.send, c.sendSeq), data, nil)
+	c.sendSeq++
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(sealed)))
+	if _, err := c.rw.Write(append(n[:], sealed...)); err != nil {
+		return fmt.Errorf("sync: %v", err)
+	}
+	return nil
+}
+
+func (c *syncConn) read(v interface{}) error {
+	var n [4]byte
+	if _, err := io.ReadFull(c.rw, n[:]); err != nil {
+		return fmt.Errorf("sync: %v", err)
+	}
+	size := binary.BigEndian.Uint32(n[:])
+	if size > maxSyncMessage || (c.recvSeq == 0 && size > maxSyncFirstMessage) {
+		return fmt.Errorf("sync: message of %d bytes is too large", size)
+	}
+	// The buffer grows as the message arrives, rather than trusting the
+	// length up front.
+	var sealed bytes.Buffer
+	if _, err := io.CopyN(&sealed, c.rw, int64(size)); err != nil {
+		return fmt.Errorf("sync: %v", err)
+	}
+	data, err := c.recv.Open(nil, syncNonce(c.recv, c.recvSeq), sealed.Bytes(), nil)
+	if err != nil {
+		return errSyncKey
+	}
+	if c.recvSeq == 0 {
+		if conn, ok := c.rw.(net.Conn); ok {
+			conn.SetDeadline(time.Now().Add(syncTimeout))
+		}
+	}
+	c.recvSeq++
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("sync: %v", err)
+	}
+	return nil
+}
+
+// syncRecord is an entry as sent to the peer.
+type syncRecord struct {
+	UUID           uuids.UUID         `json:"uuid"`
+	Group          string             `json:"group"`
+	Title          string             `json:"title"`
+	Icon           keepass.Icon       `json:"icon"`
+	URL            string             `json:"url"`
+	Username       string             `json:"username"`
+	Password       string             `json:"password"`
+	Notes          string             `json:"notes"`
+	Created        time.Time          `json:"created"`
+	Modified       time.Time          `json:"modified"`
+	Accessed       time.Time          `json:"accessed"`
+	Expires        time.Time          `json:"expires"`
+	AttachmentName string             `json:"attachment_name,omitempty"`
+	AttachmentData []byte             `json:"attachment_data,omitempty"`
+	CustomData     keepass.CustomData `json:"custom_data,omitempty"`
+	History        []keepass.Revision `json:"history,omitempty"`
+}
+
+func newSyncRecord(e *keepass.Entry) syncRecord {
+	return syncRecord{
+		UUID:           e.UUID,
+		Group:          e.Parent().Path(),
+		Title:          e.Title,
+		Icon:           e.Icon,
+		URL:            e.URL,
+		Username:       e.Username,
+		Password:       e.Password,
+		Notes:          e.Notes,
+		Created:        e.CreationTime,
+		Modified:       e.LastModificationTime,
+		Accessed:       e.LastAccessTime,
+		Expires:        e.ExpiryTime,
+		AttachmentName: e.Attachment.Name,
+		AttachmentData: e.Attachment.Data,
+		CustomData:     e.CustomData,
+		History:        e.History,
+	}
+}
+
+// apply stores the record in db, replacing the entry with the same UUID.
+// The replaced state is kept in the entry's history.
+func (rec *syncRecord) apply(db *keepass.Database) error {
+	g, err := db.MkdirAll(rec.Group)
+	if err != nil {
+		return err
+	}
+	if g.IsRoot() {
+		return fmt.Errorf("sync: entry %v is not in a group", rec.UUID)
+	}
+	e := db.Find(rec.UUID)
+	if e == nil {
+		if e, err = g.NewEntry(); err != nil {
+			return err
+		}
+		e.UUID = rec.UUID
+	} else {
+		e.AddRevision()
+		if err := e.SetParent(g); err != nil {
+			return err
+		}
+	}
+	history := append(e.History, rec.History...)
+	e.Title = rec.Title
+	e.Icon = rec.Icon
+	e.URL = rec.URL
+	e.Username = rec.Username
+	e.Password = rec.Password
+	e.Notes = rec.Notes
+	e.CreationTime = rec.Created
+	e.LastModificationTime = rec.Modified
+	e.LastAccessTime = rec.Accessed
+	e.ExpiryTime = rec.Expires
+	e.Attachment.Name = rec.AttachmentName
+	e.Attachment.Data = rec.AttachmentData
+	e.CustomData = rec.CustomData
+	e.History = dedupRevisions(history)
+	return nil
+}
+
+// dedupRevisions drops repeated revisions, which both copies of an entry
+// have in common, keeping the order.
+func dedupRevisions(revs []keepass.Revision) []keepass.Revision {
+	seen := make(map[keepass.Revision]bool)
+	var out []keepass.Revision
+	for _, rev := range revs {
+		rev.Modified = rev.Modified.UTC()
+		if !seen[rev] {
+			seen[rev] = true
+			out = append(out, rev)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Modified.Before(out[j].Modified) })
+	return out
+}
+
+// syncIndexItem is an entry in the index each side sends.
+type syncIndexItem struct {
+	UUID     uuids.UUID `json:"uuid"`
+	Modified time.Time  `json:"modified"`
+}
+
+// syncDatabase exchanges changed entries with the peer on rw and applies
+// the ones received to db.  It returns how many entries were received and
+// sent.  db is not written.
+func syncDatabase(rw io.ReadWriter, db *keepass.Database, client bool) (received, sent int, err error) {
+	c, err := newSyncConn(rw, db, client)
+	if err != nil {
+		return 0, 0, err
+	}
+	// exchange sends ours and receives theirs, in the client's turn first.
+	exchange := func(ours, theirs interface{}) error {
+		if client {
+			if err := c.write(ours); err != nil {
+				return err
+			}
+			return c.read(theirs)
+		}
+		if err := c.read(theirs); err != nil {
+			return err
+		}
+		return c.write(ours)
+	}
+
+	var index []syncIndexItem
+	for _, e := range db.Entries() {
+		index = append(index, syncIndexItem{e.UUID, e.LastModificationTime})
+	}
+	var peerIndex []syncIndexItem
+	if err := exchange(index, &peerIndex); err != nil {
+		return 0, 0, err
+	}
+	var want []uuids.UUID
+	for _, item := range peerIndex {
+		if e := db.Find(item.UUID); e == nil || item.Modified.After(e.LastModificationTime) {
+			want = append(want, item.UUID)
+		}
+	}
+	var peerWants []uuids.UUID
+	if err := exchange(want, &peerWants); err != nil {
+		return 0, 0, err
+	}
+	var records []syncRecord
+	for _, id := range peerWants {
+		if e := db.Find(id); e != nil {
+			records = append(records, newSyncRecord(e))
+		}
+	}
+	var peerRecords []syncRecord
+	if err := exchange(records, &peerRecords); err != nil {
+		return 0, 0, err
+	}
+	for i := range peerRecords {
+		if err := peerRecords[i].apply(db); err != nil {
+			return 0, 0, err
+		}
+	}
+	return len(peerRecords), len(records), nil
+}
+
+// runSync syncs the database with a peer running sync -listen.
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	peer := fs.String("peer", "", "`host:port` of a peer running sync -listen")
+	listen := fs.String("listen", "", "`address` to accept peers on")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || (*peer == "") == (*listen == "") {
+		return errors.New("usage: sync -peer host:port | sync -listen address")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	if *listen != "" {
+		return serveSync(*listen, db.ComputedKey())
+	}
+	conn, err := net.DialTimeout("tcp", *peer, 30*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(syncHandshakeTimeout))
+	received, sent, err := syncDatabase(conn, db, true)
+	if err != nil {
+		return err
+	}
+	if received > 0 {
+		if err := writeDatabase(db); err != nil {
+			return err
+		}
+	}
+	fmt.Printf(tr("%d entries received from %s, %d sent\n"), received, *peer, sent)
+	return nil
+}
+
+// serveSync syncs with peers one at a time.  The database is read afresh
+// for each, so that changes made meanwhile are not lost.  A peer that
+// does not prove it has the key within syncHandshakeTimeout is dropped,
+// so it can only hold up the others that long.
+func serveSync(addr string, key []byte) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	log.Printf("accepting sync peers on %s", l.Addr())
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		conn.SetDeadline(time.Now().Add(syncHandshakeTimeout))
+		received, sent, err := serveSyncPeer(conn, key)
+		conn.Close()
+		if err != nil {
+			log.Printf("sync with %s: %v", conn.RemoteAddr(), err)
+			continue
+		}
+		log.Printf("sync with %s: %d entries received, %d sent", conn.RemoteAddr(), received, sent)
+	}
+}
+
+func serveSyncPeer(conn net.Conn, key []byte) (received, sent int, err error) {
+	db, err := openDatabase(&keepass.Options{ComputedKey: key})
+	if err != nil {
+		return 0, 0, err
+	}
+	received, sent, err = syncDatabase(conn, db, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	if received > 0 {
+		err = writeDatabase(db)
+	}
+	return received, sent, err
+}
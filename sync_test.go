@@ -0,0 +1,179 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func TestSyncDatabase(t *testing.T) {
+	t0 := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	a, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	work, err := a.MkdirAll("Work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mail, err := work.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mail.Title = "Mail"
+	mail.Password = "hunter2"
+	mail.LastModificationTime = t0
+	buf := new(bytes.Buffer)
+	if err := a.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	b, err := keepass.Open(buf, &keepass.Options{Password: "swordfish"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a changes the password later; b adds an entry and moves Mail, earlier.
+	mail.Password = "correct horse"
+	mail.LastModificationTime = t0.Add(time.Hour)
+	bank, err := work.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bank.Title = "Bank"
+	bank.LastModificationTime = t0
+	home, err := b.MkdirAll("Home/Net")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vpn, err := home.NewEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vpn.Title = "VPN"
+	vpn.Attachment.Name = "client.ovpn"
+	vpn.Attachment.Data = []byte("remote vpn.example.com\n")
+	vpn.CustomData.Set("gostpass.test", "1")
+	vpn.LastModificationTime = t0
+	bmail := b.Find(mail.UUID)
+	bmail.Username = "bob"
+	bmail.LastModificationTime = t0.Add(time.Minute)
+
+	type result struct {
+		received, sent int
+		err            error
+	}
+	c1, c2 := net.Pipe()
+	done := make(chan result)
+	go func() {
+		received, sent, err := syncDatabase(c2, b, false)
+		done <- result{received, sent, err}
+	}()
+	received, sent, err := syncDatabase(c1, a, true)
+	if err != nil {
+		t.Fatal("client:", err)
+	}
+	server := <-done
+	if server.err != nil {
+		t.Fatal("server:", server.err)
+	}
+	if received != 1 || sent != 2 || server.received != 2 || server.sent != 1 {
+		t.Errorf("client received %d, sent %d; server received %d, sent %d; want 1, 2, 2, 1",
+			received, sent, server.received, server.sent)
+	}
+
+	for _, db := range []*keepass.Database{a, b} {
+		if got := entryTitles(db.Entries()); len(got) != 3 {
+			t.Errorf("entries = %q; want Mail, Bank and VPN", got)
+		}
+		e := db.Find(mail.UUID)
+		if e == nil || e.Password != "correct horse" || e.Username != "" {
+			t.Errorf("Mail = %+v; want a's newer copy", e)
+		}
+		e = db.Find(vpn.UUID)
+		if e == nil || e.Parent().Path() != "Home/Net" || string(e.Attachment.Data) != "remote vpn.example.com\n" {
+			t.Fatalf("VPN = %+v; want it in Home/Net with its attachment", e)
+		}
+		if v, _ := e.CustomData.Get("gostpass.test"); v != "1" {
+			t.Errorf("VPN custom data = %v; want gostpass.test=1", e.CustomData)
+		}
+	}
+	// b's replaced copy of Mail is kept in its history.
+	if h := b.Find(mail.UUID).History; len(h) == 0 || h[len(h)-1].Username != "bob" {
+		t.Errorf("b's Mail history = %+v; want the replaced revision", h)
+	}
+}
+
+func TestSyncDatabase_WrongKey(t *testing.T) {
+	a, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := keepass.New(&keepass.Options{Password: "hunter2", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1, c2 := net.Pipe()
+	done := make(chan error)
+	go func() {
+		_, _, err := syncDatabase(c2, b, false)
+		c2.Close()
+		done <- err
+	}()
+	if _, _, err := syncDatabase(c1, a, true); err == nil {
+		t.Error("client: syncDatabase succeeded with a different key")
+	}
+	c1.Close()
+	if err := <-done; err != errSyncKey {
+		t.Errorf("server: syncDatabase = %v; want %v", err, errSyncKey)
+	}
+}
+
+func TestSyncDatabase_FirstMessageTooLarge(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Password: "swordfish", Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	done := make(chan error)
+	go func() {
+		_, _, err := syncDatabase(c2, db, false)
+		c2.Close()
+		done <- err
+	}()
+	if _, err := c1.Write(make([]byte, syncNonceSize)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(c1, make([]byte, syncNonceSize)); err != nil {
+		t.Fatal(err)
+	}
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], maxSyncFirstMessage+1)
+	if _, err := c1.Write(n[:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("syncDatabase = %v; want a message too large error", err)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// runSystemdCred writes one field of an entry exactly, without a trailing
+// newline, so that services can consume it like a systemd credential.  For
+// example, in a unit:
+//
+//	ExecStartPre=/usr/bin/gostpass -db /etc/app/vault.kdb -password_credential master \
+//	    systemd-cred -o ${RUNTIME_DIRECTORY}/db-password Services/DB password
+//	LoadCredentialEncrypted=master:/etc/app/master.cred
+func runSystemdCred(args []string) error {
+	fs := flag.NewFlagSet("systemd-cred", flag.ContinueOnError)
+	out := fs.String("o", "", "write to `file` with mode 0600 instead of standard output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("usage: systemd-cred [-o file] entry field")
+	}
+	get := entryFields[fs.Arg(1)]
+	if get == nil {
+		return fmt.Errorf("unknown field %q; want one of %s", fs.Arg(1), strings.Join(sortedEntryFields(), ", "))
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	e, err := findEntryPath(db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := releaseEntry(e, "systemd-cred"); err != nil {
+		return err
+	}
+	if *out != "" {
+		return writeSecretFile(*out, []byte(get(e)))
+	}
+	_, err = os.Stdout.WriteString(get(e))
+	return err
+}
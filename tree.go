@@ -0,0 +1,256 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/uuids"
+)
+
+// splitItemPath splits a slash-separated path into the path of its parent
+// group and its last element.
+func splitItemPath(path string) (dir, base string) {
+	path = strings.Trim(path, "/")
+	i := strings.LastIndex(path, "/")
+	return path[:i+1], path[i+1:]
+}
+
+// entryFields are the entry fields that commands can refer to by name.
+var entryFields = map[string]func(*keepass.Entry) string{
+	"title":    func(e *keepass.Entry) string { return e.Title },
+	"username": func(e *keepass.Entry) string { return e.Username },
+	"password": func(e *keepass.Entry) string { return e.Password },
+	"url":      func(e *keepass.Entry) string { return e.URL },
+	"notes":    func(e *keepass.Entry) string { return e.Notes },
+	"uuid":     func(e *keepass.Entry) string { return e.UUID.String() },
+}
+
+func sortedEntryFields() []string {
+	names := make([]string, 0, len(entryFields))
+	for name := range entryFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// entryPath returns the path of e as accepted by findEntryPath: its group's
+// path followed by its title.
+func entryPath(e *keepass.Entry) string {
+	if p := e.Parent().Path(); p != "" {
+		return p + "/" + e.Title
+	}
+	return e.Title
+}
+
+// A pathNotFoundError is returned by findEntryPath if there is no entry
+// at the path.
+type pathNotFoundError string
+
+func (e pathNotFoundError) Error() string {
+	return string(e) + ": not found"
+}
+
+// findEntryPath returns the entry at path, whose last element is the
+// entry's title.  It is an error if several entries in the group share the
+// title.  The entry's UUID may be given instead of a path.
+func findEntryPath(db *keepass.Database, path string) (*keepass.Entry, error) {
+	if uuid, err := uuids.Parse(path); err == nil {
+		if e := db.Find(uuid); e != nil {
+			return e, nil
+		}
+	}
+	dir, title := splitItemPath(path)
+	g := db.FindGroupPath(dir)
+	if g == nil || title == "" {
+		return nil, pathNotFoundError(path)
+	}
+	var found *keepass.Entry
+	for _, e := range g.Entries() {
+		if e.Title != title {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("%s: more than one entry has this title", path)
+		}
+		found = e
+	}
+	if found == nil {
+		return nil, pathNotFoundError(path)
+	}
+	return found, nil
+}
+
+// runMkdir creates groups, along with any missing parents, and optionally
+// sets their notes.
+func runMkdir(args []string) error {
+	fs := flag.NewFlagSet("mkdir", flag.ContinueOnError)
+	notesPath := fs.String("notes", "", "set the groups' notes (Markdown) to the contents of `file`, or standard input if -")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: mkdir [-notes file] path...")
+	}
+	var notes []byte
+	if *notesPath != "" {
+		var err error
+		if *notesPath == "-" {
+			notes, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			notes, err = ioutil.ReadFile(*notesPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	for _, path := range fs.Args() {
+		g, err := db.MkdirAll(path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case *notesPath == "":
+		case g.IsRoot():
+			return errors.New("the root group can't have notes")
+		case len(notes) == 0:
+			g.CustomData.Delete(groupNotesKey)
+		default:
+			g.CustomData.Set(groupNotesKey, string(notes))
+		}
+	}
+	return writeDatabase(db)
+}
+
+// runMv moves or renames a group or entry.  If the destination is an
+// existing group, the source is moved into it; otherwise the source is
+// moved into the destination's parent group and takes its last element as
+// its name.
+func runMv(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: mv src dst")
+	}
+	src, dst := args[0], args[1]
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	parent := db.FindGroupPath(dst)
+	name := ""
+	if parent == nil {
+		var dir string
+		dir, name = splitItemPath(dst)
+		if parent = db.FindGroupPath(dir); parent == nil {
+			return fmt.Errorf("%s: no such group", dir)
+		}
+	}
+	now := time.Now()
+	if g := db.FindGroupPath(src); g != nil && !g.IsRoot() {
+		if g != parent {
+			if err := g.SetParent(parent); err != nil {
+				return err
+			}
+		}
+		if name != "" {
+			g.Name = name
+		}
+		g.LastModificationTime = now
+	} else {
+		e, err := findEntryPath(db, src)
+		if err != nil {
+			return err
+		}
+		if name != "" && name != e.Title {
+			e.AddRevision()
+			e.Title = name
+		}
+		if err := e.SetParent(parent); err != nil {
+			return err
+		}
+		e.LastModificationTime = now
+	}
+	return writeDatabase(db)
+}
+
+// runRm moves groups and entries to the recycle bin, or deletes them for
+// good if they are already there.
+func runRm(args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	recursive := fs.Bool("r", false, "delete groups that are not empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: rm [-r] path...")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	for _, path := range fs.Args() {
+		if g := db.FindGroupPath(path); g != nil && !g.IsRoot() {
+			if !*recursive && (g.NGroups() > 0 || g.NEntries() > 0) {
+				return fmt.Errorf("%s: group is not empty (use -r)", path)
+			}
+			purge := g.InRecycleBin()
+			var ids []uint32
+			if purge {
+				ids = subtreeGroupIDs(g)
+			}
+			if err := db.RecycleGroup(g); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+			if err := dropGroupACLs(db, ids); err != nil {
+				return err
+			}
+			if err := dropTokenGroups(db, ids); err != nil {
+				return err
+			}
+			reportRm(path, purge)
+			continue
+		}
+		e, err := findEntryPath(db, path)
+		if err != nil {
+			return err
+		}
+		purge := e.Parent().InRecycleBin()
+		if err := db.RecycleEntry(e); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		reportRm(path, purge)
+	}
+	return writeDatabase(db)
+}
+
+func reportRm(path string, purged bool) {
+	if purged {
+		fmt.Printf(tr("%s: deleted\n"), path)
+	} else {
+		fmt.Printf(tr("%s: moved to %s\n"), path, keepass.RecycleBinName)
+	}
+}
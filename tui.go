@@ -0,0 +1,539 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Panes of the browser.
+const (
+	groupPane = iota
+	entryPane
+)
+
+// Keys that aren't characters, as returned by readKey.  keyNone is a
+// sequence that the browser doesn't handle.
+const (
+	keyNone rune = -iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEsc
+	keyBackspace
+	keyEnter
+	keyTab
+	keyInterrupt
+)
+
+// A tuiAction is work that handleKey leaves to the terminal loop, because
+// it needs the clipboard, a prompt or a save.
+type tuiAction int
+
+const (
+	tuiNone tuiAction = iota
+	tuiQuit
+	tuiCopyPassword
+	tuiCopyUsername
+	tuiReveal
+	tuiEdit
+)
+
+// tuiModel is the state of the browser, kept apart from the terminal.
+type tuiModel struct {
+	db     *keepass.Database
+	index  *entryIndex
+	groups []*keepass.Group // depth first, starting with the root
+	depths []int
+	group  int
+	entry  int
+	focus  int
+
+	query     string
+	searching bool
+
+	// revealed is the entry whose password is shown, if any.
+	revealed *keepass.Entry
+	status   string
+}
+
+func newTUIModel(db *keepass.Database) *tuiModel {
+	m := &tuiModel{db: db, index: newEntryIndex(db)}
+	var walk func(g *keepass.Group, depth int)
+	walk = func(g *keepass.Group, depth int) {
+		m.groups = append(m.groups, g)
+		m.depths = append(m.depths, depth)
+		for _, sub := range g.Groups() {
+			walk(sub, depth+1)
+		}
+	}
+	walk(db.Root(), 0)
+	return m
+}
+
+// entries returns the entries in the right pane: the search results if
+// there is a query, otherwise the selected group's entries.
+func (m *tuiModel) entries() []*keepass.Entry {
+	if m.query != "" {
+		return m.index.search(parseQuery(m.query))
+	}
+	return m.groups[m.group].Entries()
+}
+
+// selected returns the selected entry or nil if the pane is empty.
+func (m *tuiModel) selected() *keepass.Entry {
+	es := m.entries()
+	if m.entry >= len(es) {
+		return nil
+	}
+	return es[m.entry]
+}
+
+func clampIndex(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+// move moves the selection in the focused pane.  While there is a query,
+// the group pane doesn't filter anything, so the entries move instead.
+func (m *tuiModel) move(delta int) {
+	m.revealed = nil
+	if m.focus == groupPane && m.query == "" {
+		m.group = clampIndex(m.group+delta, len(m.groups))
+		m.entry = 0
+		return
+	}
+	m.entry = clampIndex(m.entry+delta, len(m.entries()))
+}
+
+// handleKey updates the model for a key press and returns what else
+// needs to be done.
+func (m *tuiModel) handleKey(k rune) tuiAction {
+	m.status = ""
+	if m.searching {
+		switch k {
+		case keyUp:
+			m.move(-1)
+			return tuiNone
+		case keyDown:
+			m.move(1)
+			return tuiNone
+		case keyEnter:
+			m.searching = false
+			m.focus = entryPane
+		case keyEsc:
+			m.searching = false
+			m.query = ""
+		case keyBackspace:
+			_, n := utf8.DecodeLastRuneInString(m.query)
+			m.query = m.query[:len(m.query)-n]
+		case keyInterrupt:
+			return tuiQuit
+		default:
+			if k >= ' ' {
+				m.query += string(k)
+			}
+		}
+		m.entry = 0
+		m.revealed = nil
+		return tuiNone
+	}
+	switch k {
+	case 'q', keyInterrupt:
+		return tuiQuit
+	case keyUp, 'k':
+		m.move(-1)
+	case keyDown, 'j':
+		m.move(1)
+	case keyTab:
+		m.focus = 1 - m.focus
+	case keyLeft, 'h':
+		m.focus = groupPane
+	case keyRight, 'l', keyEnter:
+		m.focus = entryPane
+	case '/':
+		m.searching = true
+	case keyEsc:
+		m.query = ""
+		m.entry = 0
+		m.revealed = nil
+	case 'c', 'u', 'r', 'e':
+		if m.selected() == nil {
+			return tuiNone
+		}
+		switch k {
+		case 'c':
+			return tuiCopyPassword
+		case 'u':
+			return tuiCopyUsername
+		case 'r':
+			if m.revealed != nil {
+				m.revealed = nil
+				return tuiNone
+			}
+			return tuiReveal
+		case 'e':
+			return tuiEdit
+		}
+	}
+	return tuiNone
+}
+
+// fitText truncates or pads s to n columns, counting a rune as a column.
+func fitText(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	s = strings.Map(func(r rune) rune {
+		if r < ' ' {
+			return ' '
+		}
+		return r
+	}, s)
+	if c := utf8.RuneCountInString(s); c <= n {
+		return s + strings.Repeat(" ", n-c)
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+// scrollStart returns the first row to show so that sel is visible.
+func scrollStart(sel, rows int) int {
+	if sel < rows {
+		return 0
+	}
+	return sel - rows + 1
+}
+
+const (
+	ansiReverse = "\x1b[7m"
+	ansiBold    = "\x1b[1m"
+	ansiReset   = "\x1b[0m"
+)
+
+// render draws the whole screen.  Lines end in "\r\n" because the terminal
+// is in raw mode.
+func (m *tuiModel) render(w io.Writer, width, height int) error {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	const detailRows = 5
+	listRows := height - detailRows - 3
+	if width < 20 || listRows < 1 {
+		b.WriteString(tr("Terminal too small"))
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	line := func(s string) {
+		b.WriteString(s)
+		b.WriteString("\r\n")
+	}
+	mark := func(s string, selected, focused bool) string {
+		switch {
+		case selected && focused:
+			return ansiReverse + s + ansiReset
+		case selected:
+			return ansiBold + s + ansiReset
+		default:
+			return s
+		}
+	}
+
+	line(ansiReverse + fitText(" gostpass  "+*dbPath, width) + ansiReset)
+	leftWidth := width / 3
+	rightWidth := width - leftWidth - 3
+	entries := m.entries()
+	groupStart := scrollStart(m.group, listRows)
+	entryStart := scrollStart(m.entry, listRows)
+	for row := 0; row < listRows; row++ {
+		left := strings.Repeat(" ", leftWidth)
+		if i := groupStart + row; i < len(m.groups) {
+			name := m.groups[i].Name
+			if i == 0 {
+				name = "/"
+			}
+			left = fitText(strings.Repeat("  ", m.depths[i])+name, leftWidth)
+			left = mark(left, i == m.group, m.focus == groupPane && m.query == "")
+		}
+		right := ""
+		if i := entryStart + row; i < len(entries) {
+			text := entries[i].Title
+			if m.query != "" {
+				text = entryPath(entries[i])
+			}
+			right = mark(fitText(text, rightWidth), i == m.entry, m.focus == entryPane || m.query != "")
+		}
+		line(left + " │ " + right)
+	}
+	line(strings.Repeat("─", width))
+
+	details := make([]string, detailRows)
+	if e := m.selected(); e != nil {
+		password := masked
+		if m.revealed == e {
+			password = e.Password
+		}
+		notes := e.Notes
+		if i := strings.IndexByte(notes, '\n'); i >= 0 {
+			notes = notes[:i] + " …"
+		}
+		details = []string{
+			entryPath(e),
+			tr("username: ") + e.Username,
+			tr("password: ") + password,
+			tr("url:      ") + e.URL,
+			tr("notes:    ") + notes,
+		}
+	}
+	for _, d := range details {
+		line(fitText(d, width))
+	}
+
+	switch {
+	case m.searching:
+		b.WriteString(fitText("/"+m.query, width))
+	case m.status != "":
+		b.WriteString(fitText(m.status, width))
+	default:
+		b.WriteString(fitText(tr("↑↓ move  Tab switch  / search  c copy password  u copy username  r reveal  e edit  q quit"), width))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// readKey reads a key press from a terminal in raw mode.
+func readKey(r *bufio.Reader) (rune, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return keyNone, err
+	}
+	switch c {
+	case '\r', '\n':
+		return keyEnter, nil
+	case '\t':
+		return keyTab, nil
+	case 127, '\b':
+		return keyBackspace, nil
+	case 3:
+		return keyInterrupt, nil
+	case 27:
+		// A lone Esc arrives by itself; escape sequences arrive at once.
+		if r.Buffered() == 0 {
+			return keyEsc, nil
+		}
+		if c, _, err = r.ReadRune(); err != nil || c != '[' && c != 'O' {
+			return keyNone, err
+		}
+		for {
+			if c, _, err = r.ReadRune(); err != nil {
+				return keyNone, err
+			}
+			if c < '0' || c > ';' {
+				break
+			}
+		}
+		switch c {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'C':
+			return keyRight, nil
+		case 'D':
+			return keyLeft, nil
+		}
+		return keyNone, nil
+	}
+	return c, nil
+}
+
+// tuiTerminal switches the terminal between the browser's raw mode on the
+// alternate screen and its normal mode, for prompts.
+type tuiTerminal struct {
+	fd    int
+	state *terminal.State
+}
+
+func (t *tuiTerminal) raw() error {
+	state, err := terminal.MakeRaw(t.fd)
+	if err != nil {
+		return err
+	}
+	t.state = state
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	return nil
+}
+
+func (t *tuiTerminal) restore() {
+	fmt.Print("\x1b[?25h\x1b[?1049l")
+	terminal.Restore(t.fd, t.state)
+}
+
+// cooked runs f with the terminal in its normal mode.
+func (t *tuiTerminal) cooked(f func() error) error {
+	t.restore()
+	err := f()
+	if rerr := t.raw(); rerr != nil {
+		// The loop can't continue without raw mode.
+		fmt.Fprintf(stderr, "gostpass tui: %v\n", rerr)
+		os.Exit(1)
+	}
+	return err
+}
+
+// promptField asks for a new value of an entry field on standard input.
+// An empty answer keeps the current value.
+func promptField(label, current string) (string, error) {
+	fmt.Printf("%s [%s]: ", label, current)
+	line, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if line = strings.TrimRight(line, "\r\n"); line == "" {
+		return current, nil
+	}
+	return line, nil
+}
+
+// editEntry asks for new values of e's fields and reports whether any
+// changed.  The previous state is kept in the history.
+func editEntry(e *keepass.Entry) (bool, error) {
+	fmt.Println(entryPath(e))
+	title, err := promptField(tr("Title"), e.Title)
+	if err != nil {
+		return false, err
+	}
+	username, err := promptField(tr("Username"), e.Username)
+	if err != nil {
+		return false, err
+	}
+	url, err := promptField(tr("URL"), e.URL)
+	if err != nil {
+		return false, err
+	}
+	password, err := promptPassword(tr("Password (empty keeps it): "))
+	if err != nil {
+		return false, err
+	}
+	if password == "" {
+		password = e.Password
+	}
+	if title == e.Title && username == e.Username && url == e.URL && password == e.Password {
+		return false, nil
+	}
+	e.AddRevision()
+	e.Title, e.Username, e.URL, e.Password = title, username, url, password
+	e.LastModificationTime = time.Now()
+	return true, nil
+}
+
+// runTUI browses the database in the terminal.
+func runTUI(args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: tui")
+	}
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return errors.New("tui needs a terminal")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	m := newTUIModel(db)
+	t := &tuiTerminal{fd: fd}
+	if err := t.raw(); err != nil {
+		return err
+	}
+	defer t.restore()
+
+	// release only leaves raw mode if the user will be asked.
+	release := func(e *keepass.Entry) error {
+		if b, _ := e.CustomData.Bool(confirmReleaseKey); !b {
+			return releaseEntry(e, "tui")
+		}
+		return t.cooked(func() error { return releaseEntry(e, "tui") })
+	}
+	report := func(err error, done string) {
+		if err != nil {
+			m.status = err.Error()
+		} else {
+			m.status = done
+		}
+	}
+	for {
+		width, height, err := terminal.GetSize(fd)
+		if err != nil {
+			return err
+		}
+		if err := m.render(os.Stdout, width, height); err != nil {
+			return err
+		}
+		k, err := readKey(stdin)
+		if err != nil {
+			return err
+		}
+		e := m.selected()
+		switch m.handleKey(k) {
+		case tuiQuit:
+			return nil
+		case tuiCopyPassword:
+			err := release(e)
+			if err == nil {
+				err = copyToClipboard(e.Password)
+			}
+			report(err, tr("Password copied to the clipboard."))
+		case tuiCopyUsername:
+			report(copyToClipboard(e.Username), tr("Username copied to the clipboard."))
+		case tuiReveal:
+			if err := release(e); err != nil {
+				report(err, "")
+			} else {
+				m.revealed = e
+			}
+		case tuiEdit:
+			var changed bool
+			err := t.cooked(func() error {
+				var err error
+				if changed, err = editEntry(e); err != nil || !changed {
+					return err
+				}
+				m.index.update(e)
+				return writeDatabase(db)
+			})
+			switch {
+			case err != nil:
+				report(err, "")
+			case changed:
+				report(nil, tr("Saved."))
+			}
+		}
+	}
+}
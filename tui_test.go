@@ -0,0 +1,143 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+func newTUITestModel(t *testing.T) *tuiModel {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ent := range []struct{ group, title, password string }{
+		{"Mail", "Work mail", "hunter2"},
+		{"Mail", "Home mail", "letmein"},
+		{"Web/Shops", "Bookshop", "s3cret"},
+	} {
+		g, err := db.MkdirAll(ent.group)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title, e.Password = ent.title, ent.password
+	}
+	return newTUIModel(db)
+}
+
+func TestTUIModel(t *testing.T) {
+	m := newTUITestModel(t)
+	var names []string
+	for i, g := range m.groups {
+		names = append(names, strings.Repeat(" ", m.depths[i])+g.Name)
+	}
+	if got, want := strings.Join(names, ","), "Root, Mail, Web,  Shops"; got != want {
+		t.Errorf("groups = %q; want %q", got, want)
+	}
+
+	m.handleKey(keyDown)
+	if e := m.selected(); e == nil || e.Title != "Work mail" {
+		t.Fatalf("after moving to Mail, selected = %v; want Work mail", e)
+	}
+	m.handleKey(keyTab)
+	m.handleKey('j')
+	if e := m.selected(); e.Title != "Home mail" {
+		t.Errorf("after moving down the entries, selected = %q; want Home mail", e.Title)
+	}
+	if a := m.handleKey('r'); a != tuiReveal {
+		t.Errorf("r = %v; want tuiReveal", a)
+	}
+
+	// Searching is incremental and ignores the selected group.
+	for _, k := range "bo" {
+		m.handleKey(k)
+	}
+	if m.query != "" {
+		t.Errorf("typing before / set query %q", m.query)
+	}
+	m.handleKey('/')
+	for _, k := range "boox" {
+		m.handleKey(k)
+	}
+	if es := m.entries(); len(es) != 0 {
+		t.Errorf("search %q found %d entries; want 0", m.query, len(es))
+	}
+	m.handleKey(keyBackspace)
+	m.handleKey('k')
+	if es := m.entries(); len(es) != 1 || es[0].Title != "Bookshop" {
+		t.Errorf("search %q = %v; want Bookshop", m.query, es)
+	}
+	if a := m.handleKey('q'); a != tuiNone || m.query != "bookq" {
+		t.Errorf("q while searching = %v, query %q; want tuiNone, query %q", a, m.query, "bookq")
+	}
+	m.handleKey(keyEsc)
+	if m.searching || m.query != "" {
+		t.Errorf("after Esc, searching = %t, query = %q; want false, \"\"", m.searching, m.query)
+	}
+	if a := m.handleKey('q'); a != tuiQuit {
+		t.Errorf("q = %v; want tuiQuit", a)
+	}
+}
+
+func TestTUIRenderMasksPasswords(t *testing.T) {
+	m := newTUITestModel(t)
+	m.handleKey(keyDown)
+	var buf bytes.Buffer
+	if err := m.render(&buf, 80, 24); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Error("password shown before it was revealed")
+	}
+	if !strings.Contains(buf.String(), masked) {
+		t.Error("masked password not shown")
+	}
+	m.revealed = m.selected()
+	buf.Reset()
+	if err := m.render(&buf, 80, 24); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "hunter2") {
+		t.Error("revealed password not shown")
+	}
+	m.handleKey('j')
+	if m.revealed != nil {
+		t.Error("password still revealed after moving the selection")
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("a\x1b[A\x1b[B\x1b[3~\x1bOC\r\t\x7fж\x03\x1b"))
+	want := []rune{'a', keyUp, keyDown, keyNone, keyRight, keyEnter, keyTab, keyBackspace, 'ж', keyInterrupt, keyEsc}
+	for i, w := range want {
+		k, err := readKey(r)
+		if err != nil {
+			t.Fatalf("key %d: %v", i, err)
+		}
+		if k != w {
+			t.Errorf("key %d = %d; want %d", i, k, w)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+)
+
+// A vaultTemplate describes the skeleton of a new database, so that every
+// team member's database starts with the same layout.  Templates are JSON:
+//
+//	{
+//	  "key_rounds": 100000,
+//	  "groups": ["Work/VPN", "Work/Servers", "Personal"],
+//	  "entries": [
+//	    {"path": "Work/VPN/Office", "url": "vpn.example.com"}
+//	  ],
+//	  "policy": {"max_reused": 0, "min_entropy": 60}
+//	}
+//
+// Entries are placeholders: their passwords are left empty to be filled in.
+// The policy is the one audit checks when no -policy is given.
+type vaultTemplate struct {
+	KeyRounds int                  `json:"key_rounds"`
+	Groups    []string             `json:"groups"`
+	Entries   []vaultTemplateEntry `json:"entries"`
+	Policy    json.RawMessage      `json:"policy"`
+}
+
+type vaultTemplateEntry struct {
+	Path     string `json:"path"`
+	Username string `json:"username"`
+	URL      string `json:"url"`
+	Notes    string `json:"notes"`
+}
+
+func readVaultTemplate(path string) (*vaultTemplate, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	t := new(vaultTemplate)
+	if err := dec.Decode(t); err != nil {
+		return nil, fmt.Errorf("template %s: %v", path, err)
+	}
+	if t.KeyRounds < 0 {
+		return nil, fmt.Errorf("template %s: key_rounds must not be negative", path)
+	}
+	if len(t.Policy) > 0 {
+		if _, err := parseAuditPolicy(t.Policy); err != nil {
+			return nil, fmt.Errorf("template %s: policy: %v", path, err)
+		}
+	}
+	for _, e := range t.Entries {
+		if dir, title := splitItemPath(e.Path); dir == "" || title == "" {
+			return nil, fmt.Errorf("template %s: entry %q must be in a group", path, e.Path)
+		}
+	}
+	return t, nil
+}
+
+// apply adds the template's groups, entries and policy to db.
+func (t *vaultTemplate) apply(db *keepass.Database, now time.Time) error {
+	for _, path := range t.Groups {
+		if _, err := db.MkdirAll(path); err != nil {
+			return fmt.Errorf("group %q: %v", path, err)
+		}
+	}
+	for _, te := range t.Entries {
+		dir, title := splitItemPath(te.Path)
+		g, err := db.MkdirAll(dir)
+		if err != nil {
+			return fmt.Errorf("entry %q: %v", te.Path, err)
+		}
+		e, err := g.NewEntry()
+		if err != nil {
+			return fmt.Errorf("entry %q: %v", te.Path, err)
+		}
+		e.Title = title
+		e.Username = te.Username
+		e.URL = te.URL
+		e.Notes = te.Notes
+		e.CreationTime = now
+		e.LastModificationTime = now
+		e.LastAccessTime = now
+	}
+	if len(t.Policy) > 0 {
+		db.CustomData().Set(auditPolicyKey, string(t.Policy))
+	}
+	return nil
+}
+
+// runInit creates the -db database, either from a template or with the
+// same groups as a database created in the web interface.
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	templatePath := fs.String("template", "", "create the groups, entries and audit policy in template `file` (JSON)")
+	preset := fs.String("kdf-preset", "", kdfPresetUsage()+" (default is the template's key_rounds, or interactive)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.New("usage: init [-kdf-preset name] [-template file]")
+	}
+	var t *vaultTemplate
+	if *templatePath != "" {
+		var err error
+		if t, err = readVaultTemplate(*templatePath); err != nil {
+			return err
+		}
+	}
+	if err := initDatabase(); err != nil {
+		return err
+	}
+	if dbStorage.exists() {
+		return fmt.Errorf(tr("%s already exists"), *dbPath)
+	}
+	rounds, err := presetKeyRounds(*preset)
+	if err != nil {
+		return err
+	}
+	opts, err := newDatabaseOptions()
+	if err != nil {
+		return err
+	}
+	if rounds > 0 {
+		opts.KeyRounds = rounds
+	} else if t != nil {
+		opts.KeyRounds = t.KeyRounds
+	}
+	db, err := keepass.New(opts)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if t == nil {
+		prepopulateDB(db, now)
+	} else if err := t.apply(db, now); err != nil {
+		return err
+	}
+	return writeDatabase(db)
+}
+
+// newDatabaseOptions returns the key for a new database.  The password is
+// asked twice, unless it comes from -password_file or -password_credential.
+func newDatabaseOptions() (*keepass.Options, error) {
+	var password string
+	var err error
+	if *passwordFile != "" || *passwordCred != "" {
+		password, err = readPassword(tr("New password: "))
+	} else {
+		password, err = promptNewPassword(tr("New password: "), tr("Repeat new password: "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	opts := flagOptions(&keepass.Options{Password: password})
+	if *keyFilePath != "" {
+		kf, err := ioutil.ReadFile(*keyFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("read key file: %v", err)
+		}
+		opts.KeyFile = bytes.NewReader(kf)
+	}
+	if opts.Password == "" && opts.KeyFile == nil {
+		return nil, errors.New(tr("a password or key file is required"))
+	}
+	return opts, nil
+}
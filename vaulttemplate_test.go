@@ -0,0 +1,84 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitTemplate(t *testing.T) {
+	const template = `{
+		"key_rounds": 1,
+		"groups": ["Work/Servers", "Personal"],
+		"entries": [
+			{"path": "Work/VPN/Office", "username": "alice", "url": "vpn.example.com"}
+		],
+		"policy": {"max_reused": 0, "max_age": "90d"}
+	}`
+	_, cleanup := newCommandTestDB(t, template)
+	defer cleanup()
+	db, err := openCommandDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"Work/Servers", "Personal"} {
+		if db.FindGroupPath(path) == nil {
+			t.Errorf("group %q missing", path)
+		}
+	}
+	e, err := findEntryPath(db, "Work/VPN/Office")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Username != "alice" || e.URL != "vpn.example.com" || e.Password != "" {
+		t.Errorf("entry = %q, %q, %q; want \"alice\", \"vpn.example.com\", \"\"", e.Username, e.URL, e.Password)
+	}
+	policy, err := storedAuditPolicy(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy == nil || policy.MaxReused == nil || *policy.MaxReused != 0 || policy.MaxAge != "90d" {
+		t.Errorf("stored policy = %+v; want max_reused 0, max_age 90d", policy)
+	}
+
+	if err := runInit(nil); err == nil {
+		t.Error("init over an existing database succeeded")
+	}
+}
+
+func TestReadVaultTemplate_Invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostpass_init_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, template := range []string{
+		`{"groups": ["Work"], "extra": true}`,
+		`{"entries": [{"path": "Orphan"}]}`,
+		`{"policy": {"max_age": "soon"}}`,
+		`{"key_rounds": -1}`,
+	} {
+		path := filepath.Join(dir, "t.json")
+		if err := ioutil.WriteFile(path, []byte(template), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readVaultTemplate(path); err == nil {
+			t.Errorf("readVaultTemplate(%s) = <nil>; want error", template)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/wifi"
+)
+
+// qrencodeProgram draws QR codes.  It reads the payload from standard
+// input, so that the password doesn't show up in the process list.
+var qrencodeProgram = "qrencode"
+
+// entryNetwork returns the Wi-Fi network that e describes.  Entries in the
+// Wi-Fi group are titled with the network name and hold its password; the
+// fields ssid, security (nopass, WEP, WPA or SAE) and hidden override the
+// defaults.  Without a password, the network is taken to be open.
+func entryNetwork(e *keepass.Entry) (*wifi.Network, error) {
+	field := func(name string) (string, error) {
+		f, _, err := e.Field(name)
+		return f.Value, err
+	}
+	n := &wifi.Network{SSID: e.Title, Password: e.Password, Security: wifi.WPA}
+	if e.Password == "" {
+		n.Security = wifi.Open
+	}
+	ssid, err := field("ssid")
+	if err != nil {
+		return nil, err
+	}
+	if ssid != "" {
+		n.SSID = ssid
+	}
+	security, err := field("security")
+	if err != nil {
+		return nil, err
+	}
+	if security != "" {
+		if n.Security, err = wifi.ParseSecurity(security); err != nil {
+			return nil, err
+		}
+	}
+	hidden, err := field("hidden")
+	if err != nil {
+		return nil, err
+	}
+	if hidden != "" {
+		if n.Hidden, err = strconv.ParseBool(hidden); err != nil {
+			return nil, fmt.Errorf("hidden: %v", err)
+		}
+	}
+	return n, nil
+}
+
+// runWiFiQR shows a QR code that joins the network in a Wi-Fi entry, or
+// writes it as an image or an NFC tag payload.
+func runWiFiQR(args []string) error {
+	fs := flag.NewFlagSet("wifi-qr", flag.ContinueOnError)
+	png := fs.String("png", "", "write the QR code to PNG `file` with "+qrencodeProgram)
+	ndef := fs.String("ndef", "", "write an NDEF message for an NFC tag to `file`")
+	uri := fs.Bool("uri", false, "print the WIFI: payload instead of drawing the QR code")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: wifi-qr [-png file] [-ndef file] [-uri] entry")
+	}
+	db, err := openCommandDatabase()
+	if err != nil {
+		return err
+	}
+	e, err := findEntryPath(db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := releaseEntry(e, "wifi-qr"); err != nil {
+		return err
+	}
+	n, err := entryNetwork(e)
+	if err != nil {
+		return fmt.Errorf("%s: %v", entryPath(e), err)
+	}
+	payload, err := n.URI()
+	if err != nil {
+		return fmt.Errorf("%s: %v", entryPath(e), err)
+	}
+	if *ndef != "" {
+		msg, err := n.NDEF()
+		if err != nil {
+			return fmt.Errorf("%s: %v", entryPath(e), err)
+		}
+		if err := writeSecretFile(*ndef, msg); err != nil {
+			return err
+		}
+	}
+	if *png != "" {
+		if err := pipeToCommand("QR code", [][]string{{qrencodeProgram, "-o", *png}}, payload); err != nil {
+			return err
+		}
+		// The image holds the password as much as the payload does.
+		if err := os.Chmod(*png, 0600); err != nil {
+			return err
+		}
+	}
+	if *uri || (*png == "" && *ndef == "" && !terminal.IsTerminal(int(os.Stdout.Fd()))) {
+		fmt.Println(payload)
+		return nil
+	}
+	if *png != "" || *ndef != "" {
+		return nil
+	}
+	if err := drawQR(payload); err != nil {
+		return fmt.Errorf("%v; use -uri to print the payload for another QR program", err)
+	}
+	return nil
+}
+
+// drawQR draws payload as a QR code on standard output with
+// qrencodeProgram.
+func drawQR(payload string) error {
+	path, err := exec.LookPath(qrencodeProgram)
+	if err != nil {
+		return fmt.Errorf("QR code: %s not found", qrencodeProgram)
+	}
+	cmd := exec.Command(path, "-t", "ANSIUTF8")
+	cmd.Stdin = strings.NewReader(payload)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("QR code: %s: %v", qrencodeProgram, err)
+	}
+	return nil
+}
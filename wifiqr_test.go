@@ -0,0 +1,72 @@
+// Copyright 2026 The Sandpass Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/pedroalbanese/gostpass/pkg/fakerand"
+	"github.com/pedroalbanese/gostpass/pkg/keepass"
+	"github.com/pedroalbanese/gostpass/pkg/wifi"
+)
+
+func TestEntryNetwork(t *testing.T) {
+	db, err := keepass.New(&keepass.Options{Rand: fakerand.New(), KeyRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := db.MkdirAll("Wi-Fi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newEntry := func(title, password string, fields ...keepass.Field) *keepass.Entry {
+		e, err := g.NewEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Title, e.Password = title, password
+		for _, f := range fields {
+			if err := e.SetField(f); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return e
+	}
+	tests := []struct {
+		e    *keepass.Entry
+		want wifi.Network
+	}{
+		{newEntry("Home", "hunter22"), wifi.Network{SSID: "Home", Password: "hunter22", Security: wifi.WPA}},
+		{newEntry("Café", ""), wifi.Network{SSID: "Café", Security: wifi.Open}},
+		{
+			newEntry("Office", "s3cret!!",
+				keepass.Field{Name: "ssid", Value: "CORP-5G"},
+				keepass.Field{Name: "security", Value: "wpa3"},
+				keepass.Field{Name: "hidden", Value: "true", Protected: true}),
+			wifi.Network{SSID: "CORP-5G", Password: "s3cret!!", Security: wifi.SAE, Hidden: true},
+		},
+	}
+	for _, test := range tests {
+		n, err := entryNetwork(test.e)
+		if err != nil {
+			t.Errorf("entryNetwork(%s): %v", test.e.Title, err)
+		} else if *n != test.want {
+			t.Errorf("entryNetwork(%s) = %+v; want %+v", test.e.Title, *n, test.want)
+		}
+	}
+	if _, err := entryNetwork(newEntry("Lab", "x", keepass.Field{Name: "security", Value: "wpa-eap"})); err == nil {
+		t.Error("entryNetwork with unknown security succeeded")
+	}
+}